@@ -350,6 +350,9 @@ var SchemaPlanTemplate = template.Must(template.
 {{- if .Comment -}}
 {{- printf "-- %s%s\n" (slice .Comment 0 1 | upper ) (slice .Comment 1) -}}
 {{- end -}}
+{{- range .Warnings -}}
+{{- printf "-- WARNING: %s\n" . -}}
+{{- end -}}
 {{- println .Cmd -}}
 {{- end -}}
 {{- else -}}
@@ -366,6 +369,9 @@ var SchemaDiffTemplate = template.Must(template.
 {{- if .Comment -}}
 {{- printf "-- %s%s\n" (slice .Comment 0 1 | upper ) (slice .Comment 1) -}}
 {{- end -}}
+{{- range .Warnings -}}
+{{- printf "-- WARNING: %s\n" . -}}
+{{- end -}}
 {{- println .Cmd -}}
 {{- end -}}
 {{- else -}}
@@ -902,7 +902,7 @@ CREATE TABLE "public"."users" ("id" integer NOT NULL)
 CREATE TABLE "other"."posts" ("id" integer NOT NULL)`, diff("test1?sslmode=disable", "test2?sslmode=disable"))
 		// diff schemas
 		require.Equal(t, `-- Drop "posts" table
-DROP TABLE "posts"
+DROP TABLE "posts" RESTRICT
 -- Create "users" table
 CREATE TABLE "users" ("id" integer NOT NULL)`, diff("test2?sslmode=disable&search_path=other", "test2?sslmode=disable&search_path=public"))
 		// diff between schema and database
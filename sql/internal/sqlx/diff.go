@@ -21,6 +21,9 @@ type (
 	// diff capabilities, like diffing custom types or attributes.
 	Diff struct {
 		DiffDriver
+		// opts holds the options that are applied on all
+		// diff calls. See the WithOptions method for more info.
+		opts []schema.DiffOption
 	}
 
 	// A DiffDriver wraps all required methods for diffing elements that may
@@ -66,53 +69,281 @@ type (
 	Normalizer interface {
 		Normalize(from, to *schema.Table) error
 	}
+
+	// An ObjectDiffer wraps the SchemaObjectDiff method for diffing driver-specific
+	// schema objects. If the DiffDriver implements the ObjectDiffer interface, the
+	// object changes are returned before the table changes of the schema.
+	ObjectDiffer interface {
+		SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error)
+	}
+
+	// A RealmObjectDiffer wraps the RealmObjectDiff method for diffing driver-specific
+	// realm objects (e.g. database-level objects). If the DiffDriver implements the
+	// RealmObjectDiffer interface, the object changes are returned after the changes
+	// of the realm schemas.
+	RealmObjectDiffer interface {
+		RealmObjectDiff(from, to *schema.Realm) ([]schema.Change, error)
+	}
+
+	// A ModifySupporter wraps the SupportsModify method for reporting if a modify change can
+	// be applied by the driver in place. If the DiffDriver implements the ModifySupporter
+	// interface, pairs of drop and add changes are merged (see schema.WithMergeDropAdd)
+	// only if the resulting modify change is supported.
+	ModifySupporter interface {
+		SupportsModify(schema.Change) bool
+	}
+
+	// An ObjectMover wraps the MovedObject method for matching schema objects that are
+	// moved between schemas (see schema.WithSchemaMove). If the DiffDriver implements
+	// the ObjectMover interface, such objects are moved instead of being dropped from
+	// one schema and added to the other.
+	ObjectMover interface {
+		// MovedObject returns the object with the given name in the "from"
+		// schema, and the object it is moved to in the "to" schema, if exist.
+		MovedObject(from, to *schema.Schema, name string) (schema.Object, schema.Object, bool)
+	}
+
+	// A ForeignKeyAttrDiffer wraps the ForeignKeyAttrChanged method for reporting if
+	// the driver-specific attributes of a foreign key were changed (e.g. deferrability).
+	// If the DiffDriver implements this interface, such changes are reported as
+	// schema.ChangeAttr on the ModifyForeignKey change.
+	ForeignKeyAttrDiffer interface {
+		ForeignKeyAttrChanged(from, to []schema.Attr) bool
+	}
 )
 
+// WithOptions implements the schema.DifferWithOptions interface and returns a copy
+// of the Diff that applies the given options on top of the existing ones.
+func (d *Diff) WithOptions(opts ...schema.DiffOption) schema.Differ {
+	return &Diff{
+		DiffDriver: d.DiffDriver,
+		opts:       append(d.opts[:len(d.opts):len(d.opts)], opts...),
+	}
+}
+
 // RealmDiff implements the schema.Differ for Realm objects and returns a list of changes
 // that need to be applied in order to move a database from the current state to the desired.
 func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
-	var changes []schema.Change
+	var (
+		changes []schema.Change
+		opts    = schema.NewDiffOptions(d.opts...)
+	)
+	if opts.FoldIdentifiers {
+		to = copyState(to)
+		for _, s2 := range to.Schemas {
+			s2.Name = foldIdent(s2.Name, func(r *schema.Realm, n string) bool { _, ok := r.Schema(n); return ok }, from, to)
+		}
+	}
+	moves, err := d.schemaMoves(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
 	// Drop or modify schema.
+	var drops []schema.Change
 	for _, s1 := range from.Schemas {
+		if !opts.Managed(s1.Name) {
+			continue
+		}
 		s2, ok := to.Schema(s1.Name)
 		if !ok {
-			changes = append(changes, &schema.DropSchema{S: s1})
+			// Schemas are dropped after their elements are moved out.
+			if moves.from[s1] {
+				drops = append(drops, &schema.DropSchema{S: s1})
+			} else {
+				changes = append(changes, &schema.DropSchema{S: s1})
+			}
 			continue
 		}
-		change, err := d.SchemaDiff(s1, s2)
+		change, err := d.schemaDiff(moves.without(s1), moves.without(s2), opts)
 		if err != nil {
 			return nil, err
 		}
 		changes = append(changes, change...)
 	}
 	// Add schemas.
+	var adds []schema.Change
 	for _, s1 := range to.Schemas {
-		if _, ok := from.Schema(s1.Name); ok {
+		if _, ok := from.Schema(s1.Name); ok || !opts.Managed(s1.Name) {
 			continue
 		}
 		changes = append(changes, &schema.AddSchema{S: s1})
-		for _, t := range s1.Tables {
-			changes = append(changes, &schema.AddTable{T: t})
+		if d, ok := d.DiffDriver.(ObjectDiffer); ok {
+			change, err := d.SchemaObjectDiff(schema.New(s1.Name), moves.without(s1))
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change...)
+		}
+		// Tables of new schemas are added after the
+		// moved tables, as they may reference them.
+		for _, t := range moves.without(s1).Tables {
+			adds = append(adds, &schema.AddTable{T: t})
 		}
 	}
-	return changes, nil
+	changes = append(append(append(changes, moves.changes...), adds...), drops...)
+	if d, ok := d.DiffDriver.(RealmObjectDiffer); ok {
+		change, err := d.RealmObjectDiff(from, to)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change...)
+	}
+	return additiveOnly(changes, opts)
+}
+
+// schemaMoves holds the tables and objects that are moved between the realm schemas.
+type schemaMoves struct {
+	changes []schema.Change
+	// Schemas that elements are moved from.
+	from map[*schema.Schema]bool
+	// Moved elements of both states.
+	tables  map[*schema.Table]bool
+	objects map[schema.Object]bool
+}
+
+// schemaMoves returns the moves that were hinted by the schema.WithSchemaMove option. Hints
+// that do not match the given states (e.g. the move was already applied) are ignored.
+func (d *Diff) schemaMoves(from, to *schema.Realm, opts *schema.DiffOptions) (*schemaMoves, error) {
+	moves := &schemaMoves{
+		from:    make(map[*schema.Schema]bool),
+		tables:  make(map[*schema.Table]bool),
+		objects: make(map[schema.Object]bool),
+	}
+	names := make([]string, 0, len(opts.SchemaMoves))
+	for n := range opts.SchemaMoves {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		q, name, ok := strings.Cut(n, ".")
+		if !ok || q == "" || name == "" {
+			return nil, fmt.Errorf("invalid schema move %q: expect a qualified name (schema.name)", n)
+		}
+		// The name is the same in both states, and only the schema is different.
+		s1, ok1 := from.Schema(q)
+		s2, ok2 := to.Schema(opts.SchemaMoves[n])
+		if !ok1 || !ok2 || s1.Name == s2.Name || !opts.Managed(s1.Name) || !opts.Managed(s2.Name) {
+			continue
+		}
+		t1, ok1 := s1.Table(name)
+		t2, ok2 := s2.Table(name)
+		switch {
+		case ok1 && ok2:
+			// Tables that exist in both schemas (of any state), are not moved.
+			if s, ok := to.Schema(s1.Name); ok {
+				if _, ok := s.Table(name); ok {
+					continue
+				}
+			}
+			if s, ok := from.Schema(s2.Name); ok {
+				if _, ok := s.Table(name); ok {
+					continue
+				}
+			}
+			moves.from[s1] = true
+			moves.tables[t1], moves.tables[t2] = true, true
+			moves.changes = append(moves.changes, &schema.MoveTable{From: t1, To: t2})
+			change, err := d.tableDiff(t1, t2, opts)
+			if err != nil {
+				return nil, err
+			}
+			if len(change) > 0 {
+				moves.changes = append(moves.changes, &schema.ModifyTable{T: t2, Changes: change})
+			}
+		case !ok1 && !ok2:
+			m, ok := d.DiffDriver.(ObjectMover)
+			if !ok {
+				continue
+			}
+			o1, o2, ok := m.MovedObject(s1, s2, name)
+			if !ok {
+				continue
+			}
+			// Similar to tables, objects that exist in both schemas (of any state), are not moved.
+			if s, ok := to.Schema(s1.Name); ok {
+				if _, _, ok := m.MovedObject(s1, s, name); ok {
+					continue
+				}
+			}
+			if s, ok := from.Schema(s2.Name); ok {
+				if _, _, ok := m.MovedObject(s, s2, name); ok {
+					continue
+				}
+			}
+			moves.from[s1] = true
+			moves.objects[o1], moves.objects[o2] = true, true
+			moves.changes = append(moves.changes, &schema.MoveObject{From: o1, To: o2})
+			if d, ok := d.DiffDriver.(ObjectDiffer); ok {
+				change, err := d.SchemaObjectDiff(schema.New(s2.Name).AddObjects(o1), schema.New(s2.Name).AddObjects(o2))
+				if err != nil {
+					return nil, err
+				}
+				moves.changes = append(moves.changes, change...)
+			}
+		}
+	}
+	return moves, nil
+}
+
+// without returns the schema without its moved tables and objects.
+func (m *schemaMoves) without(s *schema.Schema) *schema.Schema {
+	if len(m.tables) == 0 && len(m.objects) == 0 {
+		return s
+	}
+	c := *s
+	c.Tables, c.Objects = nil, nil
+	for _, t := range s.Tables {
+		if !m.tables[t] {
+			c.Tables = append(c.Tables, t)
+		}
+	}
+	for _, o := range s.Objects {
+		if !m.objects[o] {
+			c.Objects = append(c.Objects, o)
+		}
+	}
+	return &c
 }
 
 // SchemaDiff implements the schema.Differ interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	opts := schema.NewDiffOptions(d.opts...)
+	if opts.FoldIdentifiers {
+		to = copyState(to)
+	}
+	changes, err := d.schemaDiff(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	return additiveOnly(changes, opts)
+}
+
+func (d *Diff) schemaDiff(from, to *schema.Schema, opts *schema.DiffOptions) ([]schema.Change, error) {
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched schema names: %q != %q", from.Name, to.Name)
 	}
 	var changes []schema.Change
 	// Drop or modify attributes (collations, charset, etc).
-	if change := d.SchemaAttrDiff(from, to); len(change) > 0 {
+	if change := ignoreAttrs(d.SchemaAttrDiff(from, to), opts); len(change) > 0 {
 		changes = append(changes, &schema.ModifySchema{
 			S:       to,
 			Changes: change,
 		})
 	}
+	if d, ok := d.DiffDriver.(ObjectDiffer); ok {
+		change, err := d.SchemaObjectDiff(from, to)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change...)
+	}
 
+	if opts.FoldIdentifiers {
+		for _, t2 := range to.Tables {
+			t2.Name = foldIdent(t2.Name, func(s *schema.Schema, n string) bool { _, ok := s.Table(n); return ok }, from, to)
+		}
+	}
 	// Drop or modify tables.
 	for _, t1 := range from.Tables {
 		t2, ok := to.Table(t1.Name)
@@ -120,7 +351,7 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 			changes = append(changes, &schema.DropTable{T: t1})
 			continue
 		}
-		change, err := d.TableDiff(t1, t2)
+		change, err := d.tableDiff(t1, t2, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -143,6 +374,55 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 // TableDiff implements the schema.TableDiffer interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
+	opts := schema.NewDiffOptions(d.opts...)
+	if opts.FoldIdentifiers {
+		to = copyState(to)
+	}
+	changes, err := d.tableDiff(from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	return additiveOnly(changes, opts)
+}
+
+// columnOrderWarning reports a warning in case the column order of the desired
+// state cannot be kept. Existing columns are not reordered by the Differ, and
+// new columns are added at the end of the table.
+func columnOrderWarning(from, to *schema.Table, opts *schema.DiffOptions) {
+	if opts.WarnFunc == nil {
+		return
+	}
+	var (
+		want  = make([]string, 0, len(to.Columns))
+		added = make([]string, 0, len(to.Columns))
+		got   = make([]string, 0, len(to.Columns))
+	)
+	for _, c1 := range from.Columns {
+		if _, ok := to.Column(c1.Name); ok {
+			got = append(got, c1.Name)
+		}
+	}
+	for _, c2 := range to.Columns {
+		want = append(want, c2.Name)
+		if _, ok := from.Column(c2.Name); !ok {
+			added = append(added, c2.Name)
+		}
+	}
+	if got = append(got, added...); !ValuesEqual(got, want) {
+		opts.Warnf("table %q: columns are not reordered, the resulting column order is (%s) instead of (%s)", to.Name, strings.Join(got, ", "), strings.Join(want, ", "))
+	}
+}
+
+// additiveOnly filters out the non-additive changes, if requested.
+func additiveOnly(changes []schema.Change, opts *schema.DiffOptions) ([]schema.Change, error) {
+	if !opts.AdditiveOnly {
+		return changes, nil
+	}
+	changes, _, err := schema.AdditiveOnly(changes)
+	return changes, err
+}
+
+func (d *Diff) tableDiff(from, to *schema.Table, opts *schema.DiffOptions) ([]schema.Change, error) {
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
@@ -156,8 +436,11 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
+	if opts.FoldIdentifiers {
+		foldTable(from, to)
+	}
 	// PK modification is not supported.
-	if pk1, pk2 := from.PrimaryKey, to.PrimaryKey; (pk1 != nil) != (pk2 != nil) || (pk1 != nil) && d.pkChange(pk1, pk2) != schema.NoChange {
+	if pk1, pk2 := from.PrimaryKey, to.PrimaryKey; (pk1 != nil) != (pk2 != nil) || (pk1 != nil) && d.pkChange(pk1, pk2, opts) != schema.NoChange {
 		return nil, fmt.Errorf("changing %q table primary key is not supported", to.Name)
 	}
 
@@ -166,7 +449,8 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if err != nil {
 		return nil, err
 	}
-	changes = append(changes, change...)
+	changes = append(changes, ignoreAttrs(change, opts)...)
+	changes = append(changes, opts.AttrChanges(from.Attrs, to.Attrs)...)
 
 	// Drop or modify columns.
 	for _, c1 := range from.Columns {
@@ -175,10 +459,15 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 			changes = append(changes, &schema.DropColumn{C: c1})
 			continue
 		}
-		change, err := d.ColumnChange(from, c1, c2)
+		change, err := d.columnChange(from, c1, c2, opts)
 		if err != nil {
 			return nil, err
 		}
+		// Custom attribute changes are reported as table changes.
+		for _, c := range opts.AttrChanges(c1.Attrs, c2.Attrs) {
+			attrStmt(c).C = c2
+			changes = append(changes, c)
+		}
 		if change != schema.NoChange {
 			changes = append(changes, &schema.ModifyColumn{
 				From:   c1,
@@ -193,9 +482,10 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 			changes = append(changes, &schema.AddColumn{C: c1})
 		}
 	}
+	columnOrderWarning(from, to, opts)
 
 	// Index changes.
-	changes = append(changes, d.indexDiff(from, to)...)
+	changes = append(changes, d.indexDiff(from, to, opts)...)
 
 	// Drop or modify foreign-keys.
 	for _, fk1 := range from.ForeignKeys {
@@ -218,12 +508,216 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 			changes = append(changes, &schema.AddForeignKey{F: fk1})
 		}
 	}
+	if opts.MergeDropAdd {
+		return d.mergeDropAdd(from, changes, opts)
+	}
 	return changes, nil
 }
 
+// foldIdent returns the name of a desired element folded to lowercase, in case the current
+// state has no element with this name, but has one with its folded form that is not used
+// by another element of the desired state. Otherwise, the name is returned as is.
+func foldIdent[T any](name string, exists func(T, string) bool, from, to T) string {
+	if exists(from, name) {
+		return name
+	}
+	if l := strings.ToLower(name); l != name && exists(from, l) && !exists(to, l) {
+		return l
+	}
+	return name
+}
+
+// copyState returns a deep copy of the given state (e.g. a realm), in which all
+// elements that are reachable from it, and the references between them (e.g. the
+// columns of indexes and foreign keys), are copied. It is used to fold the desired
+// identifiers without modifying the state that was passed to the Differ.
+func copyState[T any](v T) T {
+	c := &stateCopier{copied: make(map[stateRef]reflect.Value)}
+	return c.copy(reflect.ValueOf(v)).Interface().(T)
+}
+
+type (
+	// stateCopier deep copies the elements of a state.
+	stateCopier struct {
+		copied map[stateRef]reflect.Value
+	}
+	// stateRef identifies a copied pointer.
+	stateRef struct {
+		p uintptr
+		t reflect.Type
+	}
+)
+
+func (c *stateCopier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		k := stateRef{p: v.Pointer(), t: v.Type()}
+		if n, ok := c.copied[k]; ok {
+			return n
+		}
+		n := reflect.New(v.Type().Elem())
+		c.copied[k] = n
+		n.Elem().Set(c.copy(v.Elem()))
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type()).Elem()
+		n.Set(c.copy(v.Elem()))
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			n.SetMapIndex(it.Key(), c.copy(it.Value()))
+		}
+		return n
+	case reflect.Struct:
+		// Unexported fields are copied as is.
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if n.Field(i).CanSet() {
+				n.Field(i).Set(c.copy(v.Field(i)))
+			}
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// foldTable folds the identifiers of the desired table (columns, indexes
+// and foreign keys) that match the lowercased identifiers of the current one.
+func foldTable(from, to *schema.Table) {
+	for _, c := range to.Columns {
+		c.Name = foldIdent(c.Name, func(t *schema.Table, n string) bool { _, ok := t.Column(n); return ok }, from, to)
+	}
+	for _, idx := range to.Indexes {
+		if idx.Name != "" {
+			idx.Name = foldIdent(idx.Name, func(t *schema.Table, n string) bool { _, ok := t.Index(n); return ok }, from, to)
+		}
+	}
+	for _, fk := range to.ForeignKeys {
+		if fk.Symbol != "" {
+			fk.Symbol = foldIdent(fk.Symbol, func(t *schema.Table, n string) bool { _, ok := t.ForeignKey(n); return ok }, from, to)
+		}
+	}
+}
+
+// mergeDropAdd merges pairs of drop and add changes that target the same
+// object into a single modify change, if it is supported by the driver.
+// The modify change is placed in the position of the drop change, and pairs
+// of identical objects are kept as is.
+func (d *Diff) mergeDropAdd(from *schema.Table, changes []schema.Change, opts *schema.DiffOptions) ([]schema.Change, error) {
+	var (
+		merged  = make([]schema.Change, 0, len(changes))
+		removed = make(map[int]bool)
+		// add searches the add change that matches the drop change at position i.
+		add = func(i int, match func(schema.Change) (schema.Change, error)) (schema.Change, error) {
+			for j := i + 1; j < len(changes); j++ {
+				if removed[j] {
+					continue
+				}
+				m, err := match(changes[j])
+				if err != nil {
+					return nil, err
+				}
+				if m == nil {
+					continue
+				}
+				if s, ok := d.DiffDriver.(ModifySupporter); ok && !s.SupportsModify(m) {
+					return nil, nil
+				}
+				removed[j] = true
+				return m, nil
+			}
+			return nil, nil
+		}
+	)
+	for i, c := range changes {
+		if removed[i] {
+			continue
+		}
+		var (
+			m   schema.Change
+			err error
+		)
+		switch drop := c.(type) {
+		case *schema.DropColumn:
+			m, err = add(i, func(c schema.Change) (schema.Change, error) {
+				a, ok := c.(*schema.AddColumn)
+				if !ok || a.C.Name != drop.C.Name {
+					return nil, nil
+				}
+				k, err := d.columnChange(from, drop.C, a.C, opts)
+				if err != nil || k == schema.NoChange {
+					return nil, err
+				}
+				return &schema.ModifyColumn{From: drop.C, To: a.C, Change: k}, nil
+			})
+		case *schema.DropIndex:
+			m, err = add(i, func(c schema.Change) (schema.Change, error) {
+				a, ok := c.(*schema.AddIndex)
+				// Indexes are identified by their names, or by their parts
+				// in case of an unnamed index and a generated index name.
+				if !ok || a.I.Name != drop.I.Name && (a.I.Name != "" || !d.IsGeneratedIndexName(from, drop.I) || d.partsChange(drop.I, a.I) != schema.NoChange) {
+					return nil, nil
+				}
+				if k := d.indexChange(drop.I, a.I, opts); k != schema.NoChange {
+					return &schema.ModifyIndex{From: drop.I, To: a.I, Change: k}, nil
+				}
+				return nil, nil
+			})
+		case *schema.DropForeignKey:
+			m, err = add(i, func(c schema.Change) (schema.Change, error) {
+				a, ok := c.(*schema.AddForeignKey)
+				if !ok || a.F.Symbol == "" || a.F.Symbol != drop.F.Symbol {
+					return nil, nil
+				}
+				if k := d.fkChange(drop.F, a.F); k != schema.NoChange {
+					return &schema.ModifyForeignKey{From: drop.F, To: a.F, Change: k}, nil
+				}
+				return nil, nil
+			})
+		case *schema.DropCheck:
+			m, err = add(i, func(c schema.Change) (schema.Change, error) {
+				a, ok := c.(*schema.AddCheck)
+				if !ok || a.C.Name == "" || a.C.Name != drop.C.Name {
+					return nil, nil
+				}
+				return &schema.ModifyCheck{From: drop.C, To: a.C}, nil
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			m = c
+		}
+		merged = append(merged, m)
+	}
+	return merged, nil
+}
+
 // indexDiff returns the schema changes (if any) for migrating table
 // indexes from current state to the desired state.
-func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
+func (d *Diff) indexDiff(from, to *schema.Table, opts *schema.DiffOptions) []schema.Change {
 	var (
 		changes []schema.Change
 		exists  = make(map[*schema.Index]bool)
@@ -233,7 +727,7 @@ func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
 		idx2, ok := to.Index(idx1.Name)
 		// Found directly.
 		if ok {
-			if change := d.indexChange(idx1, idx2); change != schema.NoChange {
+			if change := d.indexChange(idx1, idx2, opts); change != schema.NoChange {
 				changes = append(changes, &schema.ModifyIndex{
 					From:   idx1,
 					To:     idx2,
@@ -266,25 +760,97 @@ func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
 }
 
 // pkChange returns the schema changes (if any) for migrating one primary key to the other.
-func (d *Diff) pkChange(from, to *schema.Index) schema.ChangeKind {
-	change := d.indexChange(from, to)
+func (d *Diff) pkChange(from, to *schema.Index, opts *schema.DiffOptions) schema.ChangeKind {
+	change := d.indexChange(from, to, opts)
 	return change & ^schema.ChangeUnique
 }
 
 // indexChange returns the schema changes (if any) for migrating one index to the other.
-func (d *Diff) indexChange(from, to *schema.Index) schema.ChangeKind {
-	var change schema.ChangeKind
+func (d *Diff) indexChange(from, to *schema.Index, opts *schema.DiffOptions) schema.ChangeKind {
+	var (
+		change           schema.ChangeKind
+		fromAttr, toAttr = opts.FilterAttrs(from.Attrs), opts.FilterAttrs(to.Attrs)
+	)
 	if from.Unique != to.Unique {
 		change |= schema.ChangeUnique
 	}
-	if d.IndexAttrChanged(from.Attrs, to.Attrs) {
+	if d.IndexAttrChanged(fromAttr, toAttr) {
 		change |= schema.ChangeAttr
 	}
 	change |= d.partsChange(from, to)
-	change |= CommentChange(from.Attrs, to.Attrs)
+	change |= CommentChange(fromAttr, toAttr)
 	return change
 }
 
+// columnChange returns the schema changes (if any) for migrating one column to the other.
+// The ignored attributes are removed from the column copies that are passed to the driver.
+func (d *Diff) columnChange(t *schema.Table, from, to *schema.Column, opts *schema.DiffOptions) (schema.ChangeKind, error) {
+	if opts.Filtering() {
+		c1, c2 := *from, *to
+		c1.Attrs, c2.Attrs = opts.FilterAttrs(from.Attrs), opts.FilterAttrs(to.Attrs)
+		from, to = &c1, &c2
+	}
+	return d.ColumnChange(t, from, to)
+}
+
+// AttrStmt returns the statement of the given attribute change, and reports
+// if it was reported by a custom AttrDiffer. An empty statement means the
+// change should not be planned.
+func AttrStmt(c schema.Change) (string, bool) {
+	if s := attrStmt(c); s != nil {
+		return s.Cmd, true
+	}
+	return "", false
+}
+
+// attrStmt returns the AttrStmt clause of the change, if exists.
+func attrStmt(c schema.Change) *schema.AttrStmt {
+	var extra []schema.Clause
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		extra = c.Extra
+	case *schema.DropAttr:
+		extra = c.Extra
+	case *schema.ModifyAttr:
+		extra = c.Extra
+	}
+	for _, e := range extra {
+		if s, ok := e.(*schema.AttrStmt); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// ignoreAttrs filters out the attribute changes that should be ignored.
+func ignoreAttrs(changes []schema.Change, opts *schema.DiffOptions) []schema.Change {
+	if !opts.Filtering() {
+		return changes
+	}
+	filtered := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		var a schema.Attr
+		switch c := c.(type) {
+		case *schema.AddAttr:
+			a = c.A
+		case *schema.DropAttr:
+			a = c.A
+		case *schema.ModifyAttr:
+			a = c.To
+		case *schema.AddCheck:
+			a = c.C
+		case *schema.DropCheck:
+			a = c.C
+		case *schema.ModifyCheck:
+			a = c.To
+		}
+		if !opts.Ignored(a) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
 func (d *Diff) partsChange(fromI, toI *schema.Index) schema.ChangeKind {
 	from, to := fromI.Parts, toI.Parts
 	if len(from) != len(to) {
@@ -343,6 +909,9 @@ func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 	if d.ReferenceChanged(from.OnDelete, to.OnDelete) {
 		change |= schema.ChangeDeleteAction
 	}
+	if d, ok := d.DiffDriver.(ForeignKeyAttrDiffer); ok && d.ForeignKeyAttrChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
 	return change
 }
 
@@ -70,7 +70,9 @@ func DetachCycles(changes []schema.Change) ([]schema.Change, error) {
 	}
 	planned := make([]schema.Change, len(changes))
 	copy(planned, changes)
-	sort.Slice(planned, func(i, j int) bool {
+	// A stable sort keeps the order of changes of the same
+	// table, such as a table move and its modification.
+	sort.SliceStable(planned, func(i, j int) bool {
 		return sorted[table(planned[i])] < sorted[table(planned[j])]
 	})
 	return planned, nil
@@ -254,6 +256,8 @@ func table(change schema.Change) (t string) {
 		t = change.T.Name
 	case *schema.ModifyTable:
 		t = change.T.Name
+	case *schema.MoveTable:
+		t = change.To.Name
 	}
 	return
 }
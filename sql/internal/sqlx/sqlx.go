@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"ariga.io/atlas/sql/schema"
 )
@@ -94,9 +95,16 @@ func ScanStrings(rows *sql.Rows) ([]string, error) {
 // Reference elements are added as stubs and should be linked manually by the
 // caller.
 func SchemaFKs(s *schema.Schema, rows *sql.Rows) error {
+	return SchemaFKsFunc(s, rows, nil, nil)
+}
+
+// SchemaFKsFunc is like SchemaFKs, but allows drivers to scan additional columns
+// that follow the common ones into dest. The scanned function (if not nil) is called
+// with the foreign key of each row after it was scanned.
+func SchemaFKsFunc(s *schema.Schema, rows *sql.Rows, dest []any, scanned func(*schema.ForeignKey)) error {
 	for rows.Next() {
 		var name, table, column, tSchema, refTable, refColumn, refSchema, updateRule, deleteRule string
-		if err := rows.Scan(&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule); err != nil {
+		if err := rows.Scan(append([]any{&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule}, dest...)...); err != nil {
 			return err
 		}
 		t, ok := s.Table(table)
@@ -145,6 +153,9 @@ func SchemaFKs(s *schema.Schema, rows *sql.Rows) error {
 		if _, ok := fk.RefColumn(rc.Name); !ok {
 			fk.RefColumns = append(fk.RefColumns, rc)
 		}
+		if scanned != nil {
+			scanned(fk)
+		}
 	}
 	return nil
 }
@@ -195,10 +206,29 @@ func ValuesEqual(v1, v2 []string) bool {
 	return true
 }
 
+// NormalizeReference returns the canonical form of the given referential action.
+// i.e. upper-cased and with its words separated by one space. For example, both
+// "no_action" and "NOACTION" are normalized to NO ACTION. Empty actions are kept
+// empty, as their defaults are determined by the drivers.
+func NormalizeReference(o schema.ReferenceOption) schema.ReferenceOption {
+	s := strings.Join(strings.FieldsFunc(strings.ToUpper(string(o)), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '_'
+	}), " ")
+	switch s {
+	case "NOACTION":
+		return schema.NoAction
+	case "SETNULL":
+		return schema.SetNull
+	case "SETDEFAULT":
+		return schema.SetDefault
+	}
+	return schema.ReferenceOption(s)
+}
+
 // ModeInspectSchema returns the InspectMode or its default.
 func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectObjects
 	}
 	return o.Mode
 }
@@ -206,7 +236,7 @@ func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 // ModeInspectRealm returns the InspectMode or its default.
 func ModeInspectRealm(o *schema.InspectRealmOption) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectObjects
 	}
 	return o.Mode
 }
@@ -216,6 +246,9 @@ type Builder struct {
 	bytes.Buffer
 	QuoteChar byte    // quoting identifiers
 	Schema    *string // schema qualifier
+	// Quote reports if the given identifier should be
+	// quoted. A nil function quotes all identifiers.
+	Quote func(string) bool
 }
 
 // P writes a list of phrases to the builder separated and
@@ -236,9 +269,15 @@ func (b *Builder) P(phrases ...string) *Builder {
 	return b
 }
 
-// Ident writes the given string quoted as an SQL identifier.
+// Ident writes the given string quoted as an SQL identifier,
+// unless the Quote function reports it should be written as-is.
 func (b *Builder) Ident(s string) *Builder {
-	if s != "" {
+	switch {
+	case s == "":
+	case b.Quote != nil && !b.Quote(s):
+		b.WriteString(s)
+		b.WriteByte(' ')
+	default:
 		b.WriteByte(b.QuoteChar)
 		b.WriteString(s)
 		b.WriteByte(b.QuoteChar)
@@ -324,6 +363,7 @@ func (b *Builder) Wrap(f func(b *Builder)) *Builder {
 func (b *Builder) Clone() *Builder {
 	return &Builder{
 		QuoteChar: b.QuoteChar,
+		Quote:     b.Quote,
 		Buffer:    *bytes.NewBufferString(b.Buffer.String()),
 	}
 }
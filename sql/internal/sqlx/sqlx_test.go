@@ -17,6 +17,7 @@ func TestModeInspectRealm(t *testing.T) {
 	m := ModeInspectRealm(nil)
 	require.True(t, m.Is(schema.InspectSchemas))
 	require.True(t, m.Is(schema.InspectTables))
+	require.True(t, m.Is(schema.InspectObjects))
 
 	m = ModeInspectRealm(&schema.InspectRealmOption{})
 	require.True(t, m.Is(schema.InspectSchemas))
@@ -31,6 +32,7 @@ func TestModeInspectSchema(t *testing.T) {
 	m := ModeInspectSchema(nil)
 	require.True(t, m.Is(schema.InspectSchemas))
 	require.True(t, m.Is(schema.InspectTables))
+	require.True(t, m.Is(schema.InspectObjects))
 
 	m = ModeInspectSchema(&schema.InspectOptions{})
 	require.True(t, m.Is(schema.InspectSchemas))
@@ -82,6 +84,15 @@ func TestBuilder_Qualifier(t *testing.T) {
 
 }
 
+func TestBuilder_Quote(t *testing.T) {
+	b := &Builder{QuoteChar: '"', Quote: func(s string) bool { return s != "users" }}
+	b.P("CREATE TABLE").Table(schema.NewTable("users").SetSchema(schema.New("public"))).Wrap(func(b *Builder) {
+		b.Ident("id").P("int")
+	})
+	require.Equal(t, `CREATE TABLE "public".users ("id" int)`, b.String())
+	require.Equal(t, b.String(), b.Clone().String())
+}
+
 func TestMayWrap(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -178,6 +189,25 @@ func TestIsQuoted(t *testing.T) {
 	}
 }
 
+func TestNormalizeReference(t *testing.T) {
+	for o, want := range map[schema.ReferenceOption]schema.ReferenceOption{
+		"":            "",
+		"cascade":     schema.Cascade,
+		"Restrict":    schema.Restrict,
+		"NO ACTION":   schema.NoAction,
+		"no  action":  schema.NoAction,
+		"NOACTION":    schema.NoAction,
+		"no_action":   schema.NoAction,
+		" set null ":  schema.SetNull,
+		"SETNULL":     schema.SetNull,
+		"set_default": schema.SetDefault,
+		"SETDEFAULT":  schema.SetDefault,
+		"Set	Default": schema.SetDefault,
+	} {
+		require.Equal(t, want, NormalizeReference(o), o)
+	}
+}
+
 func TestReverseChanges(t *testing.T) {
 	tests := []struct {
 		input  []schema.Change
@@ -235,3 +265,22 @@ func TestReverseChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyState(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	users.AddIndexes(schema.NewIndex("users_id").AddColumns(users.Columns[0]))
+	posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", "int"))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	s := schema.New("public").AddTables(users, posts).SetComment("comment")
+
+	c := copyState(s)
+	require.Equal(t, s, c)
+	require.NotSame(t, s, c)
+	c.Tables[0].Columns[0].Name = "uid"
+	require.Equal(t, "id", users.Columns[0].Name)
+	// References between the copied elements are kept.
+	require.Same(t, c, c.Tables[0].Schema)
+	require.Same(t, c.Tables[0].Columns[0], c.Tables[0].Indexes[0].Parts[0].C)
+	require.Same(t, c.Tables[0], c.Tables[1].ForeignKeys[0].RefTable)
+	require.Same(t, c.Tables[0].Columns[0], c.Tables[1].ForeignKeys[0].RefColumns[0])
+}
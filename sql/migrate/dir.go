@@ -207,7 +207,7 @@ var (
 					"{{ with .Version }}{{ . }}{{ else }}{{ now }}{{ end }}{{ with .Name }}_{{ . }}{{ end }}.sql",
 				)),
 				C: template.Must(template.New("").Parse(
-					`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
+					`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ range .Warnings }}-- WARNING: {{ println . }}{{ end }}{{ range .Provenance }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
 				)),
 			},
 		},
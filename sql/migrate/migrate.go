@@ -52,6 +52,16 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// Warnings reported by the planner for the change. For example,
+		// the objects that cause a restricted drop to fail, or the reasons
+		// the change is unsafe. The DefaultFormatter writes them as comments.
+		Warnings []string
+
+		// Provenance holds the descriptions of the logical changes that
+		// produced the statement (see schema.Describe), if requested by
+		// the PlanWithProvenance option.
+		Provenance []string
 	}
 )
 
@@ -85,7 +95,91 @@ type (
 		// PlanWithSchemaQualifier allows setting a custom schema to prefix
 		// tables and other resources. An empty string indicates no qualifier.
 		SchemaQualifier *string
-	}
+
+		// Coalesce indicates if the planner should combine alterations of the
+		// same table into one statement, if supported by the driver. For example,
+		// multiple ALTER TABLE statements with ADD or DROP COLUMN sub-commands.
+		// Drivers may also group statements that run outside a transaction,
+		// like adding values to enum types in PostgreSQL.
+		Coalesce bool
+
+		// ColumnGuards indicates if the planner should guard column additions and
+		// removals with existence checks, if supported by the driver. For example,
+		// ADD COLUMN IF NOT EXISTS. This allows re-running partially applied
+		// migrations that add or drop columns.
+		ColumnGuards bool
+
+		// UniquePreflight indicates if the planner should attach a pre-flight query
+		// to the creation of unique indexes on existing tables, if supported by the
+		// driver. The query lists the duplicate values that would fail the creation
+		// of the index, and can be used to validate the data before applying.
+		UniquePreflight bool
+
+		// CreateSchemas indicates if the planner should create the schemas that
+		// are referenced by the changes (e.g. the schema of a new table), but do
+		// not exist in the database, if supported by the driver.
+		CreateSchemas bool
+
+		// CostEstimate indicates if the planner should annotate changes that
+		// rewrite existing tables with the size of these tables, if supported by
+		// the driver. Sizes are queried from the database, and can be used to
+		// estimate the time it takes to apply such changes.
+		CostEstimate bool
+
+		// DropBehavior controls how the planner drops objects that other objects
+		// depend on, if supported by the driver. For example, DROP TABLE ... CASCADE.
+		// Drivers may allow overriding it per change using driver-specific clauses.
+		DropBehavior DropBehavior
+
+		// LockTimeout and StatementTimeout, if set, limit the time that the statements
+		// of the plan wait for locks and run, if supported by the driver. For example,
+		// in PostgreSQL, a blocked DDL fails fast instead of queueing other queries.
+		LockTimeout, StatementTimeout time.Duration
+
+		// Provenance indicates if the planner should describe the logical changes
+		// that produced each statement (e.g. `modify "users".column "email": set not null`)
+		// in the Provenance field of the planned changes, if supported by the driver.
+		Provenance bool
+
+		// SafetyWarnings indicates if the planner should explain in the warnings of the
+		// planned changes why they are unsafe, if supported by the driver. For example,
+		// changes that remove data, or may fail on the existing rows of a table.
+		SafetyWarnings bool
+
+		// RedactSecrets indicates if the planner should mask secrets in the planned
+		// statements, such as passwords in connection strings, if supported by the
+		// driver. Redacted plans are meant for reviewing, and cannot be applied.
+		RedactSecrets bool
+
+		// BackfillDefaults indicates if the planner should add columns with volatile
+		// default values (e.g. random UUIDs) to existing tables in steps that do not
+		// rewrite the table, if supported by the driver. i.e. add the column without
+		// a default, backfill the existing rows, and then set its default and NOT NULL.
+		BackfillDefaults bool
+
+		// IdempotentDDL indicates if the planner should wrap statements that cannot be
+		// guarded with IF NOT EXISTS (e.g. adding constraints) in blocks that ignore the
+		// errors of existing objects, if supported by the driver. This allows re-running
+		// partially applied migrations. See ColumnGuards for guarding column changes.
+		IdempotentDDL bool
+
+		// QuotePolicy controls how the planner quotes identifiers (e.g. table, column,
+		// constraint and index names) in the planned statements, if supported by the
+		// driver. Identifiers that are reserved words are quoted by all policies.
+		QuotePolicy QuotePolicy
+
+		// SafeNotNull indicates if the planner should set existing columns to NOT NULL
+		// in steps that do not block the table while it is scanned, if supported by the
+		// driver. For example, in PostgreSQL, by validating a CHECK (c IS NOT NULL)
+		// constraint before SET NOT NULL.
+		SafeNotNull bool
+	}
+
+	// DropBehavior describes the behavior of dropping objects that other objects depend on.
+	DropBehavior uint8
+
+	// QuotePolicy describes how identifiers are quoted in planned statements.
+	QuotePolicy uint8
 
 	// PlanOption allows configuring a drivers' plan using functional arguments.
 	PlanOption func(*PlanOptions)
@@ -286,6 +380,173 @@ func PlanWithSchemaQualifier(q string) PlannerOption {
 	}
 }
 
+// PlanWithCoalesce instructs the driver to combine alterations of the
+// same table into one statement, in case it is supported by the driver.
+func PlanWithCoalesce() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.Coalesce = true
+		})
+	}
+}
+
+// PlanWithColumnGuards instructs the driver to guard column
+// additions and removals with existence checks, if supported.
+func PlanWithColumnGuards() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.ColumnGuards = true
+		})
+	}
+}
+
+// PlanWithUniquePreflight instructs the driver to attach duplicate-check
+// queries to the creation of unique indexes on existing tables, if supported.
+func PlanWithUniquePreflight() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.UniquePreflight = true
+		})
+	}
+}
+
+// PlanWithCreateSchemas instructs the driver to create the schemas that
+// are referenced by the planned changes but are missing in the database.
+func PlanWithCreateSchemas() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.CreateSchemas = true
+		})
+	}
+}
+
+// PlanWithCostEstimate instructs the driver to annotate the planned
+// changes that rewrite existing tables with the size of these tables.
+func PlanWithCostEstimate() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.CostEstimate = true
+		})
+	}
+}
+
+// PlanWithSafetyWarnings instructs the driver to annotate the planned changes
+// that are unsafe (e.g. may lose data or fail on existing rows) with warnings
+// describing the reason. The DefaultFormatter writes them as SQL comments.
+func PlanWithSafetyWarnings() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.SafetyWarnings = true
+		})
+	}
+}
+
+// PlanWithRedactedSecrets instructs the driver to mask the secrets (e.g.
+// passwords in connection strings) that are written in the planned statements.
+func PlanWithRedactedSecrets() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.RedactSecrets = true
+		})
+	}
+}
+
+// PlanWithBackfilledDefaults instructs the driver to add columns with volatile
+// default values without rewriting their tables, by backfilling the existing
+// rows before setting the default value and the NOT NULL constraint.
+func PlanWithBackfilledDefaults() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.BackfillDefaults = true
+		})
+	}
+}
+
+// PlanWithIdempotentDDL instructs the driver to wrap the statements that cannot be
+// guarded with IF NOT EXISTS in blocks that ignore the errors of existing objects.
+// For example, PostgreSQL constraint additions are wrapped in DO blocks.
+func PlanWithIdempotentDDL() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.IdempotentDDL = true
+		})
+	}
+}
+
+// PlanWithSafeNotNull instructs the driver to set existing columns to NOT NULL
+// without blocking their tables while the existing rows are scanned. For example,
+// PostgreSQL validates a NOT NULL check constraint before the column is altered.
+func PlanWithSafeNotNull() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.SafeNotNull = true
+		})
+	}
+}
+
+// List of drop behaviors.
+const (
+	// DropDefault uses the default behavior of the driver.
+	DropDefault DropBehavior = iota
+	// DropRestrict refuses to drop objects that other objects depend on.
+	DropRestrict
+	// DropCascade drops the objects that depend on the dropped objects.
+	DropCascade
+)
+
+// PlanWithTimeouts instructs the driver to limit the time that the statements of
+// the plan wait for locks and run. A zero duration leaves the timeout unchanged.
+func PlanWithTimeouts(lock, statement time.Duration) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.LockTimeout, o.StatementTimeout = lock, statement
+		})
+	}
+}
+
+// PlanWithProvenance instructs the driver to describe the logical changes that
+// produced each planned statement. The DefaultFormatter writes these descriptions
+// as comments above the statements, to help reviewing the generated migrations.
+func PlanWithProvenance() PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.Provenance = true
+		})
+	}
+}
+
+// PlanWithDropBehavior instructs the driver to drop objects
+// using the given behavior, in case it is supported by the driver.
+func PlanWithDropBehavior(b DropBehavior) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.DropBehavior = b
+		})
+	}
+}
+
+// List of quote policies.
+const (
+	// QuoteDefault uses the default policy of the driver.
+	QuoteDefault QuotePolicy = iota
+	// QuoteAlways quotes all identifiers.
+	QuoteAlways
+	// QuoteMinimal quotes only the identifiers that require it. For example,
+	// reserved words, or names that are not folded by the database as-is.
+	QuoteMinimal
+)
+
+// PlanWithQuotePolicy instructs the driver to quote the identifiers
+// of the planned statements using the given policy, in case it is
+// supported by the driver.
+func PlanWithQuotePolicy(q QuotePolicy) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, func(o *PlanOptions) {
+			o.QuotePolicy = q
+		})
+	}
+}
+
 // PlanFormat sets the Formatter of a Planner.
 func PlanFormat(fmt Formatter) PlannerOption {
 	return func(p *Planner) {
@@ -322,7 +583,43 @@ func (p *Planner) PlanSchema(ctx context.Context, name string, to StateReader) (
 	return p.plan(ctx, name, to, false)
 }
 
+// PlanPhases is like Plan, but splits the changeset into dependency phases (see SplitPhases),
+// and plans each phase separately. The returned plans are named after their phases, and are
+// versioned by their sequence numbers (e.g. 001_types, 002_tables). Callers that write them
+// to a directory with existing migration files are expected to prefix their versions.
+func (p *Planner) PlanPhases(ctx context.Context, name string, to StateReader) ([]*Plan, error) {
+	changes, err := p.changes(ctx, to, true)
+	if err != nil {
+		return nil, err
+	}
+	phases := SplitPhases(changes)
+	plans := make([]*Plan, 0, len(phases))
+	for i, ph := range phases {
+		n := ph.Name
+		if name != "" {
+			n = name + "_" + ph.Name
+		}
+		plan, err := p.drv.PlanChanges(ctx, n, ph.Changes, p.opts...)
+		if err != nil {
+			return nil, fmt.Errorf("plan phase %q: %w", ph.Name, err)
+		}
+		plan.Version, plan.Name = fmt.Sprintf("%03d", i+1), n
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
 func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmScope bool) (*Plan, error) {
+	changes, err := p.changes(ctx, to, realmScope)
+	if err != nil {
+		return nil, err
+	}
+	return p.drv.PlanChanges(ctx, name, changes, p.opts...)
+}
+
+// changes returns the changeset for moving the current
+// state of the migration directory to the desired state.
+func (p *Planner) changes(ctx context.Context, to StateReader, realmScope bool) ([]schema.Change, error) {
 	from, err := NewExecutor(p.drv, p.dir, NopRevisionReadWriter{})
 	if err != nil {
 		return nil, err
@@ -372,7 +669,7 @@ func (p *Planner) plan(ctx context.Context, name string, to StateReader, realmSc
 	if len(changes) == 0 {
 		return nil, ErrNoPlan
 	}
-	return p.drv.PlanChanges(ctx, name, changes, p.opts...)
+	return changes, nil
 }
 
 // WritePlan writes the given Plan to the Dir based on the configured Formatter.
@@ -399,6 +696,131 @@ func (p *Planner) WritePlan(plan *Plan) error {
 	return nil
 }
 
+type (
+	// A Phase groups the changes of a changeset that are applied together. Phases
+	// are ordered by their dependencies. For example, types are created before the
+	// tables that use them, and foreign keys are added after the tables they reference.
+	Phase struct {
+		// Name of the phase. e.g. "types" or "tables".
+		Name string
+
+		// Changes of the phase, in their original order.
+		Changes []schema.Change
+	}
+
+	// TableDependent is an optional interface implemented by schema objects that
+	// are defined on tables (e.g. rules or statistics). These objects are created
+	// after the table phases, instead of along with the types.
+	TableDependent interface {
+		schema.Object
+		TableDependent()
+	}
+)
+
+// The phases of a changeset, in their dependency order.
+const (
+	phaseTypes = iota
+	phaseTables
+	phaseIndexes
+	phaseConstraints
+	phaseChecks
+	phaseObjects
+	phaseDrops
+)
+
+var phaseNames = [...]string{
+	phaseTypes:       "types",
+	phaseTables:      "tables",
+	phaseIndexes:     "indexes",
+	phaseConstraints: "constraints",
+	phaseChecks:      "checks",
+	phaseObjects:     "objects",
+	phaseDrops:       "drops",
+}
+
+// SplitPhases splits the given changeset into its dependency phases, and returns the
+// non-empty ones in order:
+//
+//   - types: creating and modifying schemas and objects (e.g. enum types).
+//   - tables: creating, modifying and dropping tables and their columns.
+//   - indexes: creating and modifying indexes.
+//   - constraints: adding and modifying foreign keys.
+//   - checks: adding and modifying check constraints.
+//   - objects: creating and modifying objects that are defined on tables.
+//   - drops: dropping objects and schemas.
+//
+// Table modifications are split between the phases. i.e. an index added to a table is
+// created in the "indexes" phase, after its columns were added in the "tables" phase.
+// Drops of indexes and constraints are kept in the "tables" phase, as they may be
+// required for dropping the columns they use.
+func SplitPhases(changes []schema.Change) []*Phase {
+	var phases [len(phaseNames)][]schema.Change
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema, *schema.ModifySchema:
+			phases[phaseTypes] = append(phases[phaseTypes], c)
+		case *schema.AddObject:
+			phases[objectPhase(c.O)] = append(phases[objectPhase(c.O)], c)
+		case *schema.ModifyObject:
+			phases[objectPhase(c.To)] = append(phases[objectPhase(c.To)], c)
+		case *schema.MoveObject:
+			phases[objectPhase(c.To)] = append(phases[objectPhase(c.To)], c)
+		case *schema.DropObject:
+			// Objects that are defined on tables are dropped
+			// before the tables, as they are dropped with them.
+			if _, ok := c.O.(TableDependent); ok {
+				phases[phaseTables] = append(phases[phaseTables], c)
+			} else {
+				phases[phaseDrops] = append(phases[phaseDrops], c)
+			}
+		case *schema.DropSchema:
+			phases[phaseDrops] = append(phases[phaseDrops], c)
+		case *schema.ModifyTable:
+			var split [len(phaseNames)][]schema.Change
+			for _, tc := range c.Changes {
+				i := tableChangePhase(tc)
+				split[i] = append(split[i], tc)
+			}
+			for i := range split {
+				if len(split[i]) > 0 {
+					phases[i] = append(phases[i], &schema.ModifyTable{T: c.T, Changes: split[i]})
+				}
+			}
+		default:
+			phases[phaseTables] = append(phases[phaseTables], c)
+		}
+	}
+	split := make([]*Phase, 0, len(phases))
+	for i := range phases {
+		if len(phases[i]) > 0 {
+			split = append(split, &Phase{Name: phaseNames[i], Changes: phases[i]})
+		}
+	}
+	return split
+}
+
+// objectPhase returns the phase of the object creation or modification.
+func objectPhase(o schema.Object) int {
+	if _, ok := o.(TableDependent); ok {
+		return phaseObjects
+	}
+	return phaseTypes
+}
+
+// tableChangePhase returns the phase of the table modification.
+func tableChangePhase(c schema.Change) int {
+	switch c.(type) {
+	case *schema.AddIndex, *schema.ModifyIndex, *schema.RenameIndex:
+		return phaseIndexes
+	case *schema.AddForeignKey, *schema.ModifyForeignKey:
+		return phaseConstraints
+	case *schema.AddCheck, *schema.ModifyCheck, *schema.RenameCheck:
+		return phaseChecks
+	default:
+		return phaseTables
+	}
+}
+
 var (
 	// ErrNoPendingFiles is returned if there are no pending migration files to execute on the managed database.
 	ErrNoPendingFiles = errors.New("sql/migrate: execute: nothing to do")
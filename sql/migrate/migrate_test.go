@@ -82,6 +82,36 @@ func TestPlanner_WritePlan(t *testing.T) {
 	requireFileEqual(t, d, "add_t1_and_t2.down.sql", "DROP TABLE t1 IF EXISTS\nDROP TABLE t2\n")
 }
 
+func TestPlanner_WritePlanProvenance(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	plan := &migrate.Plan{
+		Name: "add_t1",
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1(c int)", Comment: "create t1", Provenance: []string{`add table "t1"`}},
+		},
+	}
+	pl := migrate.NewPlanner(nil, d, migrate.PlanWithChecksum(false))
+	require.NoError(t, pl.WritePlan(plan))
+	v := time.Now().UTC().Format("20060102150405")
+	requireFileEqual(t, d, v+"_add_t1.sql", "-- create t1\n-- add table \"t1\"\nCREATE TABLE t1(c int);\n")
+}
+
+func TestPlanner_WritePlanWarnings(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	plan := &migrate.Plan{
+		Name: "drop_t1",
+		Changes: []*migrate.Change{
+			{Cmd: "DROP TABLE t1", Comment: "drop t1", Warnings: []string{"drops table t1 along with all of its rows"}},
+		},
+	}
+	pl := migrate.NewPlanner(nil, d, migrate.PlanWithChecksum(false))
+	require.NoError(t, pl.WritePlan(plan))
+	v := time.Now().UTC().Format("20060102150405")
+	requireFileEqual(t, d, v+"_drop_t1.sql", "-- drop t1\n-- WARNING: drops table t1 along with all of its rows\nDROP TABLE t1;\n")
+}
+
 func TestPlanner_Plan(t *testing.T) {
 	var (
 		drv = &mockDriver{}
@@ -145,6 +175,56 @@ func TestPlanner_PlanSchema(t *testing.T) {
 	require.Nil(t, plan)
 }
 
+func TestPlanner_PlanPhases(t *testing.T) {
+	var (
+		drv = &mockDriver{}
+		ctx = context.Background()
+	)
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl := migrate.NewPlanner(drv, d, migrate.PlanWithChecksum(false))
+	plans, err := pl.PlanPhases(ctx, "", migrate.Realm(nil))
+	require.ErrorIs(t, err, migrate.ErrNoPlan)
+	require.Nil(t, plans)
+
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		posts = schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int"))
+		c     = schema.NewIntColumn("author_id", "int")
+	)
+	drv.changes = []schema.Change{
+		&schema.AddSchema{S: schema.New("blog")},
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T: posts,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: c},
+				&schema.AddIndex{I: schema.NewIndex("author_id").AddColumns(c)},
+				&schema.AddForeignKey{F: schema.NewForeignKey("author_fk").AddColumns(c).SetRefTable(users).AddRefColumns(users.Columns[0])},
+			},
+		},
+	}
+	plans, err = pl.PlanPhases(ctx, "", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Len(t, plans, 4)
+	for _, p := range plans {
+		require.NoError(t, pl.WritePlan(p))
+	}
+	require.Equal(t, 4, countFiles(t, d))
+	requireFileEqual(t, d, "001_types.sql", "add schema \"blog\";\n")
+	requireFileEqual(t, d, "002_tables.sql", "add table \"users\";\nmodify \"posts\": add column \"author_id\";\n")
+	requireFileEqual(t, d, "003_indexes.sql", "modify \"posts\": add index \"author_id\";\n")
+	requireFileEqual(t, d, "004_constraints.sql", "modify \"posts\": add foreign key \"author_fk\";\n")
+
+	d, err = migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	pl = migrate.NewPlanner(drv, d)
+	plans, err = pl.PlanPhases(ctx, "init", migrate.Realm(nil))
+	require.NoError(t, err)
+	require.Equal(t, "001", plans[0].Version)
+	require.Equal(t, "init_types", plans[0].Name)
+}
+
 func TestExecutor_Replay(t *testing.T) {
 	ctx := context.Background()
 	d, err := migrate.NewLocalDir(filepath.FromSlash("testdata/migrate"))
@@ -581,8 +661,18 @@ func (m *mockDriver) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
 	return m.changes, nil
 }
 
-func (m *mockDriver) PlanChanges(context.Context, string, []schema.Change, ...migrate.PlanOption) (*migrate.Plan, error) {
-	return m.plan, nil
+func (m *mockDriver) PlanChanges(_ context.Context, name string, changes []schema.Change, _ ...migrate.PlanOption) (*migrate.Plan, error) {
+	if m.plan != nil {
+		return m.plan, nil
+	}
+	// Describe the changes, if no plan was set.
+	plan := &migrate.Plan{Name: name}
+	for _, c := range changes {
+		for _, d := range schema.Describe(c) {
+			plan.Changes = append(plan.Changes, &migrate.Change{Cmd: d})
+		}
+	}
+	return plan, nil
 }
 
 func (m *mockDriver) ApplyChanges(_ context.Context, changes []schema.Change, _ ...migrate.PlanOption) error {
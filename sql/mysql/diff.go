@@ -174,6 +174,7 @@ func (*diff) IndexPartAttrChanged(fromI, toI *schema.Index, i int) bool {
 
 // ReferenceChanged reports if the foreign key referential action was changed.
 func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	from, to = sqlx.NormalizeReference(from), sqlx.NormalizeReference(to)
 	// According to MySQL docs, foreign key constraints are checked
 	// immediately, so NO ACTION is the same as RESTRICT. Specifying
 	// RESTRICT (or NO ACTION) is the same as omitting the ON DELETE
@@ -283,11 +283,26 @@ func (s *state) dropTable(drop *schema.DropTable) {
 // modifyTable builds and appends the migration changes for
 // bringing the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
-	var changes [2][]schema.Change
+	var (
+		changes [2][]schema.Change
+		custom  []*migrate.Change
+	)
 	if len(modify.T.Columns) == 0 {
 		return fmt.Errorf("table %q has no columns; drop the table instead", modify.T.Name)
 	}
 	for _, change := range skipAutoChanges(modify.Changes) {
+		// Custom attribute changes are applied by their statements,
+		// and changes without statements are skipped (reported only).
+		if stmt, ok := sqlx.AttrStmt(change); ok {
+			if stmt != "" {
+				custom = append(custom, &migrate.Change{
+					Cmd:     stmt,
+					Source:  modify,
+					Comment: fmt.Sprintf("modify %q table attribute", modify.T.Name),
+				})
+			}
+			continue
+		}
 		switch change := change.(type) {
 		// Foreign-key modification is translated into 2 steps.
 		// Dropping the current foreign key and creating a new one.
@@ -330,6 +345,9 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			}
 		}
 	}
+	for _, c := range custom {
+		s.append(c)
+	}
 	return nil
 }
 
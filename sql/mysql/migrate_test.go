@@ -361,6 +361,36 @@ func TestPlanChanges(t *testing.T) {
 				Changes:    []*migrate.Change{{Cmd: "ALTER DATABASE `test` CHARSET utf8", Reverse: "ALTER DATABASE `test` CHARSET latin1"}},
 			},
 		},
+		// Custom attribute with a statement.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &schema.Comment{Text: "a"}, To: &schema.Comment{Text: "b"},
+							Extra: []schema.Clause{&schema.AttrStmt{Cmd: "CALL audit_table('users')"}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{{Cmd: "CALL audit_table('users')"}},
+			},
+		},
+		// Custom attributes without statements are not planned.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+					Changes: []schema.Change{
+						&schema.DropAttr{A: &schema.Comment{Text: "a"}, Extra: []schema.Clause{&schema.AttrStmt{}}},
+						&schema.AddAttr{A: &schema.Comment{Text: "b"}, Extra: []schema.Clause{&schema.AttrStmt{C: schema.NewIntColumn("id", "int")}}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{Reversible: true},
+		},
 		{
 			changes: []schema.Change{
 				&schema.DropSchema{S: &schema.Schema{Name: "atlas", Attrs: []schema.Attr{&schema.Charset{V: "latin"}}}},
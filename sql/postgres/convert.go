@@ -14,6 +14,20 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// canonicalInt returns the canonical name of the given integer
+// type, or its lowercased name if it is not an alias.
+func canonicalInt(t string) string {
+	switch t = strings.ToLower(t); t {
+	case TypeInt2:
+		return TypeSmallInt
+	case TypeInt, TypeInt4:
+		return TypeInteger
+	case TypeInt8:
+		return TypeBigInt
+	}
+	return t
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
@@ -43,29 +57,23 @@ func FormatType(t schema.Type) (string, error) {
 		}
 		f = t.T
 	case *schema.IntegerType:
-		switch f = strings.ToLower(t.T); f {
-		case TypeSmallInt, TypeInteger, TypeBigInt:
-		case TypeInt2:
-			f = TypeSmallInt
-		case TypeInt, TypeInt4:
-			f = TypeInteger
-		case TypeInt8:
-			f = TypeBigInt
-		}
+		f = canonicalInt(t.T)
 	case *IntervalType:
 		f = strings.ToLower(t.T)
 		if t.F != "" {
 			f += " " + strings.ToLower(t.F)
 		}
-		if t.Precision != nil && *t.Precision != defaultTimePrecision {
+		// Precision is allowed only if the field restriction (if any) includes
+		// seconds. Otherwise, it is ignored, as PostgreSQL reports the default.
+		if t.Precision != nil && *t.Precision != defaultTimePrecision && intervalSeconds(t.F) {
 			f += fmt.Sprintf("(%d)", *t.Precision)
 		}
 	case *schema.StringType:
 		switch f = strings.ToLower(t.T); f {
 		case TypeText:
-		// CHAR(n) is alias for CHARACTER(n). If not length was
-		// specified, the definition is equivalent to CHARACTER(1).
-		case TypeChar, TypeCharacter:
+		// CHAR(n) and BPCHAR(n) are aliases for CHARACTER(n). If not length
+		// was specified, the definition is equivalent to CHARACTER(1).
+		case TypeChar, TypeCharacter, TypeBPChar:
 			n := t.Size
 			if n == 0 {
 				n = 1
@@ -112,11 +120,13 @@ func FormatType(t schema.Type) (string, error) {
 			return "", fmt.Errorf("postgres: unexpected decimal type: %q", t.T)
 		}
 		switch p, s := t.Precision, t.Scale; {
+		// NUMERIC without precision (and scale) describes an unbounded
+		// numeric column. It is not equivalent to NUMERIC(p, 0).
 		case p == 0 && s == 0:
-		case s < 0:
-			return "", fmt.Errorf("postgres: decimal type must have scale >= 0: %d", s)
-		case p == 0 && s > 0:
+		case p == 0:
 			return "", fmt.Errorf("postgres: decimal type must have precision between 1 and 1000: %d", p)
+		// NUMERIC(p, 0) and NUMERIC(p) are the same. Note, negative
+		// scales are allowed since PostgreSQL 15 (e.g. NUMERIC(2, -3)).
 		case s == 0:
 			f = fmt.Sprintf("%s(%d)", f, p)
 		default:
@@ -138,6 +148,8 @@ func FormatType(t schema.Type) (string, error) {
 		f = strings.ToLower(t.T)
 	case *UUIDType:
 		f = strings.ToLower(t.T)
+	case *OIDType:
+		f = strings.ToLower(t.T)
 	case *schema.SpatialType:
 		f = strings.ToLower(t.T)
 	case *NetworkType:
@@ -188,10 +200,38 @@ func ParseType(typ string) (schema.Type, error) {
 	// to improve this in future versions by ensuring this against the database.
 	if ut, ok := t.(*schema.UnsupportedType); ok {
 		t = &UserDefinedType{T: ut.T}
+		// Array types are named internally as their element types
+		// with an underscore prefix (e.g. _int4 for int4[]).
+		if elem, ok := internalArray(typ); ok {
+			t = elem
+		}
 	}
 	return t, nil
 }
 
+// internalArray reports if the given type is the internal name of an
+// array of a builtin type (e.g. _int4), and returns its array type.
+func internalArray(typ string) (*ArrayType, bool) {
+	typ = strings.TrimSpace(typ)
+	if len(typ) < 2 || typ[0] != '_' {
+		return nil, false
+	}
+	name := typ[1:]
+	d, err := parseColumn(name)
+	if err != nil {
+		return nil, false
+	}
+	t, err := columnType(d)
+	if err != nil {
+		return nil, false
+	}
+	switch t.(type) {
+	case *schema.UnsupportedType, *UserDefinedType, *ArrayType:
+		return nil, false
+	}
+	return &ArrayType{T: name + "[]", Type: t}, true
+}
+
 func columnType(c *columnDesc) (schema.Type, error) {
 	var typ schema.Type
 	switch t := c.typ; strings.ToLower(t) {
@@ -203,7 +243,7 @@ func columnType(c *columnDesc) (schema.Type, error) {
 		typ = &schema.BoolType{T: t}
 	case TypeBytea:
 		typ = &schema.BinaryType{T: t}
-	case TypeCharacter, TypeChar, TypeCharVar, TypeVarChar, TypeText:
+	case TypeCharacter, TypeChar, TypeBPChar, TypeCharVar, TypeVarChar, TypeText:
 		// A `character` column without length specifier is equivalent to `character(1)`,
 		// but `varchar` without length accepts strings of any size (same as `text`).
 		typ = &schema.StringType{T: t, Size: int(c.size)}
@@ -247,6 +287,8 @@ func columnType(c *columnDesc) (schema.Type, error) {
 		typ = &UUIDType{T: t}
 	case TypeXML:
 		typ = &XMLType{T: t}
+	case TypeOID, TypeLO:
+		typ = &OIDType{T: t}
 	case TypeArray:
 		// Ignore multi-dimensions or size constraints
 		// as they are ignored by the database.
@@ -280,9 +322,14 @@ func columnType(c *columnDesc) (schema.Type, error) {
 		// https://postgresql.org/docs/current/catalog-pg-type.html
 		typ = newEnumType(c.fmtype, c.typid)
 	case "d":
-		// Use user-defined for domain types as we do not
-		// support their creation at this stage.
-		typ = &UserDefinedType{T: c.fmtype}
+		// Use user-defined for domain types as we do not support their
+		// creation at this stage, except for the lo domain of the lo
+		// extension, which is an OID type that references large objects.
+		if strings.EqualFold(c.fmtype, TypeLO) {
+			typ = &OIDType{T: TypeLO}
+		} else {
+			typ = &UserDefinedType{T: c.fmtype}
+		}
 	}
 	return typ, nil
 }
@@ -313,6 +360,12 @@ func intervalField(t string) (string, bool) {
 	return matches[1], true
 }
 
+// intervalSeconds reports if the interval field restriction
+// (or its absence) stores seconds, and therefore, its precision.
+func intervalSeconds(f string) bool {
+	return f == "" || strings.HasSuffix(strings.ToUpper(f), "SECOND")
+}
+
 // columnDesc represents a column descriptor.
 type columnDesc struct {
 	typ           string // data_type
@@ -342,8 +395,8 @@ func parseColumn(s string) (*columnDesc, error) {
 			parts: parts,
 		}
 	)
-	switch c.parts[0] {
-	case TypeVarChar, TypeCharVar, TypeChar, TypeCharacter:
+	switch strings.ToLower(c.parts[0]) {
+	case TypeVarChar, TypeCharVar, TypeChar, TypeCharacter, TypeBPChar:
 		if err := parseCharParts(c.parts, c); err != nil {
 			return nil, err
 		}
@@ -400,7 +453,7 @@ func parseColumn(s string) (*columnDesc, error) {
 }
 
 func parseCharParts(parts []string, c *columnDesc) error {
-	j := strings.Join(parts, " ")
+	j := strings.ToLower(strings.Join(parts, " "))
 	switch {
 	case strings.HasPrefix(j, TypeVarChar):
 		c.typ = TypeVarChar
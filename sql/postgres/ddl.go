@@ -0,0 +1,1246 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// EvalSQL evaluates the given SQL DDL statements (e.g. the content of a schema.sql
+// file) into the schema s, which can then be diffed against the inspected schema.
+// Column types are parsed using ParseType, and the names of unnamed constraints and
+// indexes are generated the same way PostgreSQL does. For example:
+//
+//	s := schema.New("public")
+//	warns, err := postgres.EvalSQL(ddl, s)
+//	if err != nil {
+//		return err
+//	}
+//	changes, err := drv.SchemaDiff(current, s)
+//
+// Statements (or parts of them) that are not modeled by the driver, such as CREATE
+// FUNCTION or table storage options, are skipped and reported in the returned warnings.
+// Objects that are qualified with other schemas are skipped as well. SET statements
+// only configure the session, and are ignored.
+func EvalSQL(ddl string, s *schema.Schema) ([]string, error) {
+	stmts, err := migrate.Stmts(ddl)
+	if err != nil {
+		return nil, err
+	}
+	e := &ddlEval{s: s, names: make(map[string]bool), enums: make(map[string]*schema.EnumType)}
+	for _, t := range s.Tables {
+		e.names[t.Name] = true
+	}
+	for _, stmt := range stmts {
+		toks, err := lexDDL(stmt.Text)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scanning statement at position %d: %w", stmt.Pos, err)
+		}
+		e.stmt = stmt
+		if err := e.eval(&ddlParser{src: stmt.Text, toks: toks}); err != nil {
+			return nil, fmt.Errorf("postgres: evaluating statement at position %d: %w", stmt.Pos, err)
+		}
+	}
+	if err := e.resolve(); err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	return e.warnings, nil
+}
+
+type (
+	// ddlEval holds the state of an EvalSQL call.
+	ddlEval struct {
+		s        *schema.Schema
+		stmt     *migrate.Stmt
+		warnings []string
+		// Names of the relations and constraints
+		// that were defined, or generated, so far.
+		names map[string]bool
+		enums map[string]*schema.EnumType
+		refs  []*ddlRef
+	}
+
+	// ddlRef is a foreign key reference that is
+	// resolved after all statements were evaluated.
+	ddlRef struct {
+		fk           *schema.ForeignKey
+		schema, name string
+		columns      []string
+	}
+)
+
+// eval evaluates a single statement.
+func (e *ddlEval) eval(p *ddlParser) error {
+	switch {
+	case p.eof(), p.accept("SET"), p.accept("RESET"):
+	case p.accept("CREATE", "SCHEMA"):
+		p.accept("IF", "NOT", "EXISTS")
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if name != e.s.Name {
+			e.warnf("skipping schema %q", name)
+		}
+	case p.accept("CREATE", "TYPE"):
+		return e.createType(p)
+	case p.accept("CREATE", "TABLE"), p.accept("CREATE", "UNLOGGED", "TABLE"):
+		return e.createTable(p)
+	case p.accept("CREATE", "INDEX"):
+		return e.createIndex(p, false)
+	case p.accept("CREATE", "UNIQUE", "INDEX"):
+		return e.createIndex(p, true)
+	case p.accept("ALTER", "TABLE"):
+		return e.alterTable(p)
+	case p.accept("COMMENT", "ON"):
+		return e.comment(p)
+	default:
+		e.warnf("skipping unsupported statement: %s", stmtHead(e.stmt.Text))
+	}
+	return nil
+}
+
+// createType evaluates the CREATE TYPE statement. Only enum types are supported.
+func (e *ddlEval) createType(p *ddlParser) error {
+	q, name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !p.accept("AS", "ENUM") {
+		e.warnf("skipping unsupported type %q", name)
+		return nil
+	}
+	if !e.managed(q) {
+		e.warnf("skipping type %q of schema %q", name, q)
+		return nil
+	}
+	enum := &schema.EnumType{T: name, Schema: e.s}
+	if err := p.list(func() error {
+		v, err := p.str()
+		if err != nil {
+			return err
+		}
+		enum.Values = append(enum.Values, v)
+		return nil
+	}); err != nil {
+		return err
+	}
+	e.enums[name] = enum
+	return nil
+}
+
+// createTable evaluates the CREATE TABLE statement.
+func (e *ddlEval) createTable(p *ddlParser) error {
+	p.accept("IF", "NOT", "EXISTS")
+	q, name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !e.managed(q) {
+		e.warnf("skipping table %q of schema %q", name, q)
+		return nil
+	}
+	if _, ok := e.s.Table(name); ok {
+		return fmt.Errorf("table %q was already defined", name)
+	}
+	t := schema.NewTable(name)
+	e.s.AddTables(t)
+	e.names[name] = true
+	// Table constraints are evaluated after the
+	// columns, as they may be defined before them.
+	var constraints []func() error
+	if err := p.list(func() error {
+		switch {
+		case p.is("CONSTRAINT"), p.is("PRIMARY", "KEY"), p.is("UNIQUE"), p.is("FOREIGN", "KEY"), p.is("CHECK"):
+			c, err := e.tableConstraint(p, t)
+			if err != nil {
+				return err
+			}
+			constraints = append(constraints, c)
+		case p.is("LIKE"), p.is("EXCLUDE"):
+			e.warnf("table %q: skipping unsupported clause: %s", name, p.raw(nil))
+		default:
+			return e.column(p, t)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, c := range constraints {
+		if err := c(); err != nil {
+			return err
+		}
+	}
+	if !p.eof() {
+		e.warnf("table %q: skipping unsupported options: %s", name, p.rest())
+	}
+	return nil
+}
+
+// column evaluates a column definition and adds it to the table.
+func (e *ddlEval) column(p *ddlParser, t *schema.Table) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, ok := t.Column(name); ok {
+		return fmt.Errorf("column %q was already defined in table %q", name, t.Name)
+	}
+	clause := func() bool {
+		return p.is("NOT", "NULL") || p.is("NULL") || p.is("DEFAULT") || p.is("CONSTRAINT") || p.is("PRIMARY", "KEY") ||
+			p.is("UNIQUE") || p.is("REFERENCES") || p.is("CHECK") || p.is("GENERATED") || p.is("COLLATE")
+	}
+	raw := p.raw(clause)
+	if raw == "" {
+		return fmt.Errorf("missing type for column %q", name)
+	}
+	typ, err := ParseType(raw)
+	if err != nil {
+		return err
+	}
+	c := schema.NewColumn(name)
+	c.Type = &schema.ColumnType{Type: typ, Raw: raw, Null: true}
+	// Serial columns are NOT NULL implicitly.
+	if _, ok := typ.(*SerialType); ok {
+		c.Type.Null = false
+	}
+	t.AddColumns(c)
+	for !p.eof() && !p.is(",") && !p.is(")") {
+		var symbol string
+		if p.accept("CONSTRAINT") {
+			if symbol, err = p.ident(); err != nil {
+				return err
+			}
+		}
+		switch {
+		case p.accept("NOT", "NULL"):
+			c.Type.Null = false
+		case p.accept("NULL"):
+			c.Type.Null = true
+		case p.accept("DEFAULT"):
+			c.SetDefault(&schema.RawExpr{X: p.raw(clause)})
+		case p.accept("COLLATE"):
+			v, err := p.ident()
+			if err != nil {
+				return err
+			}
+			c.SetCollation(v)
+		case p.accept("PRIMARY", "KEY"):
+			if err := e.primaryKey(t, symbol, []*schema.Column{c}); err != nil {
+				return err
+			}
+		case p.accept("UNIQUE"):
+			e.unique(t, symbol, []*schema.Column{c})
+		case p.accept("REFERENCES"):
+			if err := e.references(p, t, symbol, []*schema.Column{c}); err != nil {
+				return err
+			}
+		case p.accept("CHECK"):
+			if err := e.check(p, t, symbol); err != nil {
+				return err
+			}
+		case p.accept("GENERATED", "ALWAYS", "AS", "IDENTITY"):
+			if err := e.identity(p, c, "ALWAYS"); err != nil {
+				return err
+			}
+		case p.accept("GENERATED", "BY", "DEFAULT", "AS", "IDENTITY"):
+			if err := e.identity(p, c, "BY DEFAULT"); err != nil {
+				return err
+			}
+		case p.accept("GENERATED", "ALWAYS", "AS"):
+			x, err := p.group()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("STORED"); err != nil {
+				return err
+			}
+			c.SetGeneratedExpr(&schema.GeneratedExpr{Expr: x, Type: "STORED"})
+		default:
+			e.warnf("column %q: skipping unsupported clause: %s", name, p.raw(nil))
+		}
+	}
+	return nil
+}
+
+// identity evaluates the identity clause of a column.
+func (e *ddlEval) identity(p *ddlParser, c *schema.Column, gen string) error {
+	id := &Identity{Generation: gen, Sequence: &Sequence{}}
+	c.Type.Null = false
+	c.AddAttrs(id)
+	if !p.accept("(") {
+		return nil
+	}
+	for !p.accept(")") {
+		var (
+			v   *int64
+			err error
+		)
+		switch {
+		case p.accept("START"):
+			p.accept("WITH")
+			v = &id.Sequence.Start
+		case p.accept("INCREMENT"):
+			p.accept("BY")
+			v = &id.Sequence.Increment
+		case p.eof():
+			return fmt.Errorf("unexpected end of identity options of column %q", c.Name)
+		default:
+			e.warnf("column %q: skipping unsupported identity option: %s", c.Name, p.next().v)
+			continue
+		}
+		if *v, err = p.int(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableConstraint evaluates a table constraint, and returns a function for
+// adding it to the table after all columns of the table were evaluated.
+func (e *ddlEval) tableConstraint(p *ddlParser, t *schema.Table) (func() error, error) {
+	var (
+		symbol string
+		err    error
+	)
+	if p.accept("CONSTRAINT") {
+		if symbol, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		names, err := p.idents()
+		if err != nil {
+			return nil, err
+		}
+		e.skipClauses(p, t)
+		return func() error {
+			columns, err := tableColumns(t, names)
+			if err != nil {
+				return err
+			}
+			return e.primaryKey(t, symbol, columns)
+		}, nil
+	case p.accept("UNIQUE"):
+		nulls := p.accept("NULLS", "NOT", "DISTINCT")
+		p.accept("NULLS", "DISTINCT")
+		names, err := p.idents()
+		if err != nil {
+			return nil, err
+		}
+		e.skipClauses(p, t)
+		return func() error {
+			columns, err := tableColumns(t, names)
+			if err != nil {
+				return err
+			}
+			idx := e.unique(t, symbol, columns)
+			if nulls {
+				idx.AddAttrs(&IndexNullsDistinct{V: false})
+			}
+			return nil
+		}, nil
+	case p.accept("FOREIGN", "KEY"):
+		names, err := p.idents()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("REFERENCES"); err != nil {
+			return nil, err
+		}
+		// The referenced columns are resolved later, but the
+		// clauses of the reference are parsed at this stage.
+		fk := schema.NewForeignKey(symbol).SetTable(t)
+		if err := e.reference(p, fk); err != nil {
+			return nil, err
+		}
+		return func() error {
+			columns, err := tableColumns(t, names)
+			if err != nil {
+				return err
+			}
+			fk.AddColumns(columns...)
+			if fk.Symbol == "" {
+				fk.Symbol = e.name(t.Name, columnNames(columns), "fkey")
+			}
+			t.AddForeignKeys(fk)
+			return nil
+		}, nil
+	default:
+		if err := p.expect("CHECK"); err != nil {
+			return nil, err
+		}
+		x, err := p.group()
+		if err != nil {
+			return nil, err
+		}
+		noInherit := p.accept("NO", "INHERIT")
+		return func() error {
+			ck := e.addCheck(t, symbol, x)
+			if noInherit {
+				ck.AddAttrs(&NoInherit{})
+			}
+			return nil
+		}, nil
+	}
+}
+
+// skipClauses skips the index clauses of PRIMARY KEY and UNIQUE constraints.
+func (e *ddlEval) skipClauses(p *ddlParser, t *schema.Table) {
+	if !p.eof() && !p.is(",") && !p.is(")") {
+		e.warnf("table %q: skipping unsupported constraint clause: %s", t.Name, p.raw(nil))
+	}
+}
+
+// primaryKey sets the primary key of the table.
+func (e *ddlEval) primaryKey(t *schema.Table, symbol string, columns []*schema.Column) error {
+	if t.PrimaryKey != nil {
+		return fmt.Errorf("multiple primary keys for table %q are not allowed", t.Name)
+	}
+	if symbol == "" {
+		symbol = e.name(t.Name, nil, "pkey")
+	}
+	for _, c := range columns {
+		c.Type.Null = false
+	}
+	t.SetPrimaryKey(schema.NewPrimaryKey(columns...).SetName(symbol))
+	return nil
+}
+
+// unique adds a UNIQUE constraint to the table.
+func (e *ddlEval) unique(t *schema.Table, symbol string, columns []*schema.Column) *schema.Index {
+	if symbol == "" {
+		symbol = e.name(t.Name, columnNames(columns), "key")
+	}
+	idx := schema.NewUniqueIndex(symbol).AddColumns(columns...).AddAttrs(&Constraint{N: symbol, T: "u"})
+	t.AddIndexes(idx)
+	return idx
+}
+
+// references adds a foreign key to the table from the given columns.
+func (e *ddlEval) references(p *ddlParser, t *schema.Table, symbol string, columns []*schema.Column) error {
+	if symbol == "" {
+		symbol = e.name(t.Name, columnNames(columns), "fkey")
+	}
+	fk := schema.NewForeignKey(symbol).SetTable(t).AddColumns(columns...)
+	if err := e.reference(p, fk); err != nil {
+		return err
+	}
+	t.AddForeignKeys(fk)
+	return nil
+}
+
+// reference evaluates the REFERENCES clause of a foreign key.
+func (e *ddlEval) reference(p *ddlParser, fk *schema.ForeignKey) error {
+	q, name, err := p.name()
+	if err != nil {
+		return err
+	}
+	ref := &ddlRef{fk: fk, schema: q, name: name}
+	if p.is("(") {
+		if ref.columns, err = p.idents(); err != nil {
+			return err
+		}
+	}
+	e.refs = append(e.refs, ref)
+	for {
+		switch {
+		case p.accept("ON", "DELETE"):
+			if fk.OnDelete, err = p.action(); err != nil {
+				return err
+			}
+		case p.accept("ON", "UPDATE"):
+			if fk.OnUpdate, err = p.action(); err != nil {
+				return err
+			}
+		case p.accept("DEFERRABLE"):
+			d := &Deferrable{}
+			switch {
+			case p.accept("INITIALLY", "DEFERRED"):
+				d.InitiallyDeferred = true
+			default:
+				p.accept("INITIALLY", "IMMEDIATE")
+			}
+			fk.AddAttrs(d)
+		case p.accept("NOT", "DEFERRABLE"), p.accept("INITIALLY", "IMMEDIATE"), p.accept("MATCH", "SIMPLE"):
+		case p.is("MATCH"):
+			e.warnf("foreign key %q: skipping unsupported clause: %s", fk.Symbol, p.raw(nil))
+		default:
+			return nil
+		}
+	}
+}
+
+// check adds a column CHECK constraint to the table.
+func (e *ddlEval) check(p *ddlParser, t *schema.Table, symbol string) error {
+	x, err := p.group()
+	if err != nil {
+		return err
+	}
+	ck := e.addCheck(t, symbol, x)
+	if p.accept("NO", "INHERIT") {
+		ck.AddAttrs(&NoInherit{})
+	}
+	return nil
+}
+
+// addCheck adds a CHECK constraint with the given expression to the table. Similar to
+// PostgreSQL, unnamed constraints are named after the column they reference, if only
+// one column is referenced by the expression.
+func (e *ddlEval) addCheck(t *schema.Table, symbol, x string) *schema.Check {
+	if symbol == "" {
+		var columns []string
+		toks, _ := lexDDL(x)
+		for i, tok := range toks {
+			if tok.t != tokIdent && tok.t != tokQuoted || i+1 < len(toks) && toks[i+1].v == "(" {
+				continue
+			}
+			n := tok.v
+			if tok.t == tokIdent {
+				n = strings.ToLower(n)
+			}
+			if _, ok := t.Column(n); ok && (len(columns) == 0 || columns[0] != n) {
+				columns = append(columns, n)
+			}
+		}
+		if len(columns) != 1 {
+			columns = nil
+		}
+		symbol = e.name(t.Name, columns, "check")
+	}
+	ck := schema.NewCheck().SetName(symbol).SetExpr(x)
+	t.AddChecks(ck)
+	return ck
+}
+
+// createIndex evaluates the CREATE INDEX statement.
+func (e *ddlEval) createIndex(p *ddlParser, unique bool) error {
+	// Building the index concurrently does not affect its definition.
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	var (
+		name string
+		err  error
+	)
+	if !p.is("ON") {
+		if name, err = p.ident(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("ON"); err != nil {
+		return err
+	}
+	p.accept("ONLY")
+	q, tname, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !e.managed(q) {
+		e.warnf("skipping index %q of table %q in schema %q", name, tname, q)
+		return nil
+	}
+	t, ok := e.s.Table(tname)
+	if !ok {
+		return fmt.Errorf("table %q was not found for index %q", tname, name)
+	}
+	idx := schema.NewIndex(name).SetUnique(unique)
+	if p.accept("USING") {
+		m, err := p.ident()
+		if err != nil {
+			return err
+		}
+		idx.AddAttrs(&IndexType{T: strings.ToUpper(m)})
+	}
+	var names []string
+	if err := p.list(func() error {
+		part := schema.NewIndexPart()
+		switch tok := p.peek(); {
+		case tok.t == tokParen && tok.v == "(":
+			x, err := p.group()
+			if err != nil {
+				return err
+			}
+			part.SetExpr(&schema.RawExpr{X: x})
+			names = append(names, "expr")
+		case p.isColumnPart():
+			n, err := p.ident()
+			if err != nil {
+				return err
+			}
+			c, ok := t.Column(n)
+			if !ok {
+				return fmt.Errorf("column %q was not found in table %q", n, t.Name)
+			}
+			part.SetColumn(c)
+			names = append(names, n)
+		default:
+			part.SetExpr(&schema.RawExpr{X: p.raw(p.isPartClause)})
+			names = append(names, "expr")
+		}
+		if p.accept("COLLATE") {
+			if _, err := p.ident(); err != nil {
+				return err
+			}
+			e.warnf("index %q: skipping unsupported collation of part %d", name, len(idx.Parts)+1)
+		}
+		if tok := p.peek(); tok.t == tokIdent && !p.isPartClause() {
+			op, err := p.ident()
+			if err != nil {
+				return err
+			}
+			part.AddAttrs(&IndexOpClass{Name: op})
+		}
+		switch {
+		case p.accept("DESC"):
+			part.SetDesc(true)
+		default:
+			p.accept("ASC")
+		}
+		switch {
+		case p.accept("NULLS", "FIRST"):
+			part.AddAttrs(&IndexColumnProperty{NullsFirst: true})
+		case p.accept("NULLS", "LAST"):
+			part.AddAttrs(&IndexColumnProperty{NullsLast: true})
+		}
+		idx.AddParts(part)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if idx.Name == "" {
+		idx.Name = e.name(t.Name, names, "idx")
+	}
+	for !p.eof() {
+		switch {
+		case p.accept("INCLUDE"):
+			names, err := p.idents()
+			if err != nil {
+				return err
+			}
+			columns, err := tableColumns(t, names)
+			if err != nil {
+				return err
+			}
+			idx.AddAttrs(&IndexInclude{Columns: columns})
+		case p.accept("NULLS", "NOT", "DISTINCT"):
+			idx.AddAttrs(&IndexNullsDistinct{V: false})
+		case p.accept("NULLS", "DISTINCT"):
+		case p.accept("WHERE"):
+			idx.AddAttrs(&IndexPredicate{P: p.rest()})
+		default:
+			opts := p.raw(func() bool { return p.is("WHERE") })
+			if opts == "" {
+				opts = p.next().v
+			}
+			e.warnf("index %q: skipping unsupported options: %s", idx.Name, opts)
+		}
+	}
+	e.names[idx.Name] = true
+	t.AddIndexes(idx)
+	return nil
+}
+
+// alterTable evaluates the ALTER TABLE statement. Only the ADD CONSTRAINT
+// action is supported, as used by tools like pg_dump for foreign keys.
+func (e *ddlEval) alterTable(p *ddlParser) error {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	q, name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !e.managed(q) {
+		e.warnf("skipping changes of table %q in schema %q", name, q)
+		return nil
+	}
+	t, ok := e.s.Table(name)
+	if !ok {
+		return fmt.Errorf("table %q was not found", name)
+	}
+	for !p.eof() {
+		start := p.i
+		if !p.accept("ADD") || !p.is("CONSTRAINT") && !p.is("PRIMARY", "KEY") && !p.is("UNIQUE") && !p.is("FOREIGN", "KEY") && !p.is("CHECK") {
+			p.i = start
+			e.warnf("table %q: skipping unsupported change: %s", name, stmtHead(p.raw(nil)))
+		} else {
+			c, err := e.tableConstraint(p, t)
+			if err != nil {
+				return err
+			}
+			if err := c(); err != nil {
+				return err
+			}
+		}
+		if !p.accept(",") && !p.eof() {
+			return fmt.Errorf("unexpected %q in changes of table %q", p.peek().v, name)
+		}
+	}
+	return nil
+}
+
+// comment evaluates the COMMENT ON statement for tables and columns.
+func (e *ddlEval) comment(p *ddlParser) error {
+	column := p.accept("COLUMN")
+	if !column && !p.accept("TABLE") {
+		e.warnf("skipping unsupported statement: %s", stmtHead(e.stmt.Text))
+		return nil
+	}
+	parts, err := p.path()
+	if err != nil {
+		return err
+	}
+	var cname string
+	if column {
+		if len(parts) < 2 {
+			return fmt.Errorf("missing table name for column %q", parts[0])
+		}
+		parts, cname = parts[:len(parts)-1], parts[len(parts)-1]
+	}
+	if len(parts) > 2 {
+		return fmt.Errorf("unexpected qualified name: %s", strings.Join(parts, "."))
+	}
+	var q string
+	if len(parts) == 2 {
+		q = parts[0]
+	}
+	if err := p.expect("IS"); err != nil {
+		return err
+	}
+	var text string
+	if !p.accept("NULL") {
+		if text, err = p.str(); err != nil {
+			return err
+		}
+	}
+	name := parts[len(parts)-1]
+	if !e.managed(q) {
+		e.warnf("skipping comment of table %q in schema %q", name, q)
+		return nil
+	}
+	t, ok := e.s.Table(name)
+	if !ok {
+		return fmt.Errorf("table %q was not found", name)
+	}
+	if !column {
+		t.SetComment(text)
+		return nil
+	}
+	c, ok := t.Column(cname)
+	if !ok {
+		return fmt.Errorf("column %q was not found in table %q", cname, name)
+	}
+	c.SetComment(text)
+	return nil
+}
+
+// resolve resolves the references between the evaluated objects.
+func (e *ddlEval) resolve() error {
+	for _, t := range e.s.Tables {
+		for _, c := range t.Columns {
+			switch ct := c.Type.Type.(type) {
+			case *UserDefinedType:
+				if enum, ok := e.enum(ct.T); ok {
+					c.Type.Type = enum
+				}
+			case *ArrayType:
+				if ut, ok := ct.Type.(*UserDefinedType); ok {
+					if enum, ok := e.enum(ut.T); ok {
+						ct.Type = enum
+					}
+				}
+			}
+		}
+	}
+	for _, r := range e.refs {
+		var ref *schema.Table
+		switch t, ok := e.s.Table(r.name); {
+		case e.managed(r.schema) && ok:
+			ref = t
+		case e.managed(r.schema):
+			return fmt.Errorf("referenced table %q of foreign key %q was not found", r.name, r.fk.Symbol)
+		default:
+			// Tables of other schemas are referenced as is.
+			ref = schema.NewTable(r.name).SetSchema(schema.New(r.schema))
+			for _, n := range r.columns {
+				ref.AddColumns(schema.NewColumn(n))
+			}
+		}
+		r.fk.SetRefTable(ref)
+		switch {
+		case len(r.columns) > 0:
+			columns, err := tableColumns(ref, r.columns)
+			if err != nil {
+				return err
+			}
+			r.fk.AddRefColumns(columns...)
+		case ref.PrimaryKey != nil:
+			for _, p := range ref.PrimaryKey.Parts {
+				r.fk.AddRefColumns(p.C)
+			}
+		default:
+			return fmt.Errorf("referenced table %q of foreign key %q has no primary key", r.name, r.fk.Symbol)
+		}
+	}
+	return nil
+}
+
+// enum returns the enum type with the given (optionally qualified) name.
+func (e *ddlEval) enum(name string) (*schema.EnumType, bool) {
+	if q, n, ok := strings.Cut(name, "."); ok {
+		if q != e.s.Name {
+			return nil, false
+		}
+		name = n
+	}
+	enum, ok := e.enums[name]
+	return enum, ok
+}
+
+// managed reports if the given schema qualifier refers to the evaluated schema.
+func (e *ddlEval) managed(q string) bool {
+	return q == "" || q == e.s.Name
+}
+
+// name generates a name for an unnamed constraint or index,
+// similar to the ChooseRelationName function of PostgreSQL.
+func (e *ddlEval) name(table string, columns []string, label string) string {
+	base := strings.Join(append(append([]string{table}, columns...), label), "_")
+	name := base
+	for i := 1; e.names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	e.names[name] = true
+	return name
+}
+
+func (e *ddlEval) warnf(format string, args ...any) {
+	e.warnings = append(e.warnings, fmt.Sprintf("statement at position %d: %s", e.stmt.Pos, fmt.Sprintf(format, args...)))
+}
+
+// tableColumns returns the columns of the table with the given names.
+func tableColumns(t *schema.Table, names []string) ([]*schema.Column, error) {
+	columns := make([]*schema.Column, 0, len(names))
+	for _, n := range names {
+		c, ok := t.Column(n)
+		if !ok {
+			return nil, fmt.Errorf("column %q was not found in table %q", n, t.Name)
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+func columnNames(columns []*schema.Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// stmtHead returns the beginning of the statement for reporting.
+func stmtHead(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if i := strings.IndexByte(s, '('); i > 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
+
+// Token types of DDL statements.
+const (
+	tokIdent = iota + 1
+	tokQuoted
+	tokString
+	tokNumber
+	tokParen
+	tokPunct
+)
+
+// ddlToken is a token of a DDL statement.
+type ddlToken struct {
+	t        int
+	v        string
+	pos, end int
+}
+
+// lexDDL splits the given statement into tokens.
+func lexDDL(s string) ([]ddlToken, error) {
+	var toks []ddlToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == ';':
+			i++
+		case strings.HasPrefix(s[i:], "--"):
+			if j := strings.IndexByte(s[i:], '\n'); j != -1 {
+				i += j
+			} else {
+				i = len(s)
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			j := strings.Index(s[i+2:], "*/")
+			if j == -1 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += j + 4
+		case c == '"', c == '\'':
+			j, v, err := lexQuoted(s, i, c)
+			if err != nil {
+				return nil, err
+			}
+			t := tokString
+			if c == '"' {
+				t = tokQuoted
+			}
+			toks = append(toks, ddlToken{t: t, v: v, pos: i, end: j})
+			i = j
+		case (c == 'e' || c == 'E') && i+1 < len(s) && s[i+1] == '\'':
+			j, v, err := lexQuoted(s, i+1, '\'')
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, ddlToken{t: tokString, v: v, pos: i, end: j})
+			i = j
+		case c == '$' && dollarTag(s[i:]) != "":
+			tag := dollarTag(s[i:])
+			j := strings.Index(s[i+len(tag):], tag)
+			if j == -1 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string %s", tag)
+			}
+			end := i + len(tag) + j + len(tag)
+			toks = append(toks, ddlToken{t: tokString, v: s[i+len(tag) : end-len(tag)], pos: i, end: end})
+			i = end
+		case isIdentByte(c) && !isDigit(c):
+			j := i + 1
+			for j < len(s) && isIdentByte(s[j]) {
+				j++
+			}
+			toks = append(toks, ddlToken{t: tokIdent, v: s[i:j], pos: i, end: j})
+			i = j
+		case isDigit(c) || c == '.' && i+1 < len(s) && isDigit(s[i+1]):
+			j := i + 1
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.' || (s[j] == 'e' || s[j] == 'E') && j+1 < len(s) && (isDigit(s[j+1]) || s[j+1] == '-' || s[j+1] == '+')) {
+				if s[j] == 'e' || s[j] == 'E' {
+					j++
+				}
+				j++
+			}
+			toks = append(toks, ddlToken{t: tokNumber, v: s[i:j], pos: i, end: j})
+			i = j
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			toks = append(toks, ddlToken{t: tokParen, v: s[i : i+1], pos: i, end: i + 1})
+			i++
+		case c == ':' && i+1 < len(s) && s[i+1] == ':':
+			toks = append(toks, ddlToken{t: tokPunct, v: "::", pos: i, end: i + 2})
+			i += 2
+		default:
+			toks = append(toks, ddlToken{t: tokPunct, v: s[i : i+1], pos: i, end: i + 1})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// lexQuoted scans the quoted string or identifier at position i,
+// and returns the position after it along with its unquoted value.
+func lexQuoted(s string, i int, q byte) (int, string, error) {
+	var b strings.Builder
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != q {
+			b.WriteByte(s[j])
+			continue
+		}
+		if j+1 < len(s) && s[j+1] == q {
+			b.WriteByte(q)
+			j++
+			continue
+		}
+		return j + 1, b.String(), nil
+	}
+	return 0, "", fmt.Errorf("unterminated quoted string at position %d", i)
+}
+
+// dollarTag returns the dollar-quoting tag at the beginning of s, if exists.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case !isIdentByte(c) || c == '$' || i == 1 && isDigit(c):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// ddlParser parses the tokens of a DDL statement.
+type ddlParser struct {
+	src  string
+	toks []ddlToken
+	i    int
+}
+
+func (p *ddlParser) eof() bool {
+	return p.i >= len(p.toks)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.eof() {
+		return ddlToken{}
+	}
+	return p.toks[p.i]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	if !p.eof() {
+		p.i++
+	}
+	return t
+}
+
+// is reports if the next tokens match the given keywords (case-insensitively)
+// or punctuation marks. Quoted identifiers never match keywords.
+func (p *ddlParser) is(words ...string) bool {
+	if p.i+len(words) > len(p.toks) {
+		return false
+	}
+	for j, w := range words {
+		switch t := p.toks[p.i+j]; t.t {
+		case tokIdent:
+			if !strings.EqualFold(t.v, w) {
+				return false
+			}
+		case tokParen, tokPunct:
+			if t.v != w {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the given words if they are next.
+func (p *ddlParser) accept(words ...string) bool {
+	if !p.is(words...) {
+		return false
+	}
+	p.i += len(words)
+	return true
+}
+
+// expect consumes the given words, or returns an error if they are not next.
+func (p *ddlParser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return fmt.Errorf("expected %q, but got %q", strings.Join(words, " "), p.peek().v)
+	}
+	return nil
+}
+
+// ident returns the next identifier. Unquoted identifiers are folded to lowercase.
+func (p *ddlParser) ident() (string, error) {
+	switch t := p.peek(); t.t {
+	case tokIdent:
+		p.i++
+		return strings.ToLower(t.v), nil
+	case tokQuoted:
+		p.i++
+		return t.v, nil
+	default:
+		return "", fmt.Errorf("expected identifier, but got %q", t.v)
+	}
+}
+
+// path returns the next dot-separated identifiers.
+func (p *ddlParser) path() ([]string, error) {
+	var parts []string
+	for {
+		n, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if parts = append(parts, n); !p.accept(".") {
+			return parts, nil
+		}
+	}
+}
+
+// name returns the next, optionally schema-qualified, object name.
+func (p *ddlParser) name() (string, string, error) {
+	parts, err := p.path()
+	switch {
+	case err != nil:
+		return "", "", err
+	case len(parts) > 2:
+		return "", "", fmt.Errorf("unexpected qualified name: %s", strings.Join(parts, "."))
+	case len(parts) == 2:
+		return parts[0], parts[1], nil
+	default:
+		return "", parts[0], nil
+	}
+}
+
+// str returns the next string literal.
+func (p *ddlParser) str() (string, error) {
+	if t := p.peek(); t.t == tokString {
+		p.i++
+		return t.v, nil
+	}
+	return "", fmt.Errorf("expected string literal, but got %q", p.peek().v)
+}
+
+// int returns the next, optionally signed, integer literal.
+func (p *ddlParser) int() (int64, error) {
+	sign := ""
+	if p.accept("-") {
+		sign = "-"
+	}
+	t := p.next()
+	if t.t != tokNumber {
+		return 0, fmt.Errorf("expected number, but got %q", t.v)
+	}
+	return strconv.ParseInt(sign+t.v, 10, 64)
+}
+
+// action returns the next referential action.
+func (p *ddlParser) action() (schema.ReferenceOption, error) {
+	for _, a := range []schema.ReferenceOption{schema.NoAction, schema.Restrict, schema.Cascade, schema.SetNull, schema.SetDefault} {
+		if p.accept(strings.Fields(string(a))...) {
+			if (a == schema.SetNull || a == schema.SetDefault) && p.is("(") {
+				return "", fmt.Errorf("column lists of referential actions are not supported")
+			}
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unexpected referential action %q", p.peek().v)
+}
+
+// list parses a parenthesized, comma-separated list using f.
+func (p *ddlParser) list(f func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	if p.accept(")") {
+		return nil
+	}
+	for {
+		if err := f(); err != nil {
+			return err
+		}
+		if p.accept(")") {
+			return nil
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+// idents parses a parenthesized list of identifiers.
+func (p *ddlParser) idents() ([]string, error) {
+	var names []string
+	err := p.list(func() error {
+		n, err := p.ident()
+		names = append(names, n)
+		return err
+	})
+	return names, err
+}
+
+// group returns the raw text of the next parenthesized group, without its parentheses.
+func (p *ddlParser) group() (string, error) {
+	if !p.is("(") {
+		return "", fmt.Errorf("expected \"(\", but got %q", p.peek().v)
+	}
+	start, depth := p.i, 0
+	for ; !p.eof(); p.i++ {
+		t := p.toks[p.i]
+		switch {
+		case t.t != tokParen:
+		case t.v == "(" || t.v == "[":
+			depth++
+		default:
+			if depth--; depth == 0 {
+				p.i++
+				return strings.TrimSpace(p.src[p.toks[start].end:t.pos]), nil
+			}
+		}
+	}
+	return "", errors.New("unbalanced parentheses")
+}
+
+// raw returns the raw text of the tokens until the stop function returns true
+// at the top level, or until the end of the current list element.
+func (p *ddlParser) raw(stop func() bool) string {
+	start, end, depth := p.i, p.i, 0
+	for ; !p.eof(); p.i++ {
+		t := p.toks[p.i]
+		if depth == 0 && (t.t == tokParen && (t.v == ")" || t.v == "]") || t.t == tokPunct && t.v == "," || stop != nil && p.i > start && stop()) {
+			break
+		}
+		switch {
+		case t.t != tokParen:
+		case t.v == "(" || t.v == "[":
+			depth++
+		default:
+			depth--
+		}
+		end = p.i + 1
+	}
+	if end == start {
+		return ""
+	}
+	return strings.TrimSpace(p.src[p.toks[start].pos:p.toks[end-1].end])
+}
+
+// rest returns the raw text of the remaining tokens.
+func (p *ddlParser) rest() string {
+	if p.eof() {
+		return ""
+	}
+	s := strings.TrimSpace(p.src[p.toks[p.i].pos:p.toks[len(p.toks)-1].end])
+	p.i = len(p.toks)
+	return s
+}
+
+// isColumnPart reports if the next index part is a column reference.
+func (p *ddlParser) isColumnPart() bool {
+	if t := p.peek(); t.t != tokIdent && t.t != tokQuoted {
+		return false
+	}
+	next := ddlToken{}
+	if p.i+1 < len(p.toks) {
+		next = p.toks[p.i+1]
+	}
+	// Function calls and operators are expressions.
+	return next.t == 0 || next.t == tokIdent || next.t == tokQuoted || next.t == tokParen && next.v == ")" || next.t == tokPunct && next.v == ","
+}
+
+// isPartClause reports if the next token starts a clause of an index part.
+func (p *ddlParser) isPartClause() bool {
+	return p.is("ASC") || p.is("DESC") || p.is("NULLS") || p.is("COLLATE")
+}
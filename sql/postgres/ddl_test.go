@@ -0,0 +1,146 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalSQL(t *testing.T) {
+	s := schema.New("public")
+	warns, err := EvalSQL(`
+SET statement_timeout = 0;
+CREATE TYPE status AS ENUM ('active', 'it''s done');
+CREATE TABLE "users" (
+  id bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 1) PRIMARY KEY,
+  email character varying(255) NOT NULL UNIQUE,
+  status status DEFAULT 'active'::status,
+  tags status[],
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  age int CHECK (age > 0),
+  "Name" text COLLATE "C"
+);
+CREATE TABLE IF NOT EXISTS public.posts (
+  id serial,
+  author_id bigint REFERENCES users ON DELETE CASCADE,
+  reviewer_id bigint,
+  title text,
+  body text,
+  PRIMARY KEY (id),
+  CONSTRAINT reviewer FOREIGN KEY (reviewer_id) REFERENCES users (id) DEFERRABLE INITIALLY DEFERRED,
+  CHECK (title <> body)
+) WITH (fillfactor = 70);
+CREATE UNIQUE INDEX ON posts USING btree (author_id DESC NULLS LAST, title) WHERE title IS NOT NULL;
+CREATE INDEX posts_lower ON posts ((lower(title)) text_pattern_ops);
+COMMENT ON TABLE users IS 'the users';
+COMMENT ON COLUMN public.users."Name" IS 'display name';
+ALTER TABLE ONLY posts ADD CONSTRAINT posts_title_key UNIQUE (title);
+CREATE FUNCTION f() RETURNS int AS 'SELECT 1' LANGUAGE sql;
+`, s)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"statement at position 419: table \"posts\": skipping unsupported options: WITH (fillfactor = 70)",
+		"statement at position 1085: skipping unsupported statement: CREATE FUNCTION f",
+	}, warns)
+
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, "the users", users.Attrs[1].(*schema.Comment).Text)
+	require.Len(t, users.Columns, 7)
+	id := users.Columns[0]
+	require.False(t, id.Type.Null)
+	require.Equal(t, &Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1}}, id.Attrs[0])
+	require.Equal(t, "users_pkey", users.PrimaryKey.Name)
+	require.Equal(t, id, users.PrimaryKey.Parts[0].C)
+	enum := &schema.EnumType{T: "status", Values: []string{"active", "it's done"}, Schema: s}
+	require.Equal(t, enum, users.Columns[2].Type.Type)
+	require.Equal(t, "'active'::status", users.Columns[2].Default.(*schema.RawExpr).X)
+	require.Equal(t, enum, users.Columns[3].Type.Type.(*ArrayType).Type)
+	require.Equal(t, "now()", users.Columns[4].Default.(*schema.RawExpr).X)
+	require.False(t, users.Columns[4].Type.Null)
+	require.Equal(t, "C", users.Columns[6].Attrs[0].(*schema.Collation).V)
+	require.Equal(t, "display name", users.Columns[6].Attrs[1].(*schema.Comment).Text)
+	require.Equal(t, schema.NewCheck().SetName("users_age_check").SetExpr("age > 0"), users.Attrs[0])
+	require.Equal(t, "users_email_key", users.Indexes[0].Name)
+	require.True(t, users.Indexes[0].Unique)
+
+	posts, ok := s.Table("posts")
+	require.True(t, ok)
+	require.IsType(t, &SerialType{}, posts.Columns[0].Type.Type)
+	require.False(t, posts.Columns[0].Type.Null)
+	require.Equal(t, "posts_pkey", posts.PrimaryKey.Name)
+	require.Len(t, posts.ForeignKeys, 2)
+	require.Equal(t, "posts_author_id_fkey", posts.ForeignKeys[0].Symbol)
+	require.Equal(t, schema.Cascade, posts.ForeignKeys[0].OnDelete)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	require.Equal(t, id, posts.ForeignKeys[0].RefColumns[0])
+	require.Equal(t, "reviewer", posts.ForeignKeys[1].Symbol)
+	require.Equal(t, []schema.Attr{&Deferrable{InitiallyDeferred: true}}, posts.ForeignKeys[1].Attrs)
+	require.Equal(t, []schema.Attr{schema.NewCheck().SetName("posts_check").SetExpr("title <> body")}, posts.Attrs)
+	require.Len(t, posts.Indexes, 3)
+	idx := posts.Indexes[0]
+	require.Equal(t, "posts_author_id_title_idx", idx.Name)
+	require.True(t, idx.Unique)
+	require.Equal(t, []schema.Attr{&IndexType{T: "BTREE"}, &IndexPredicate{P: "title IS NOT NULL"}}, idx.Attrs)
+	require.True(t, idx.Parts[0].Desc)
+	require.Equal(t, []schema.Attr{&IndexColumnProperty{NullsLast: true}}, idx.Parts[0].Attrs)
+	idx = posts.Indexes[1]
+	require.Equal(t, "posts_lower", idx.Name)
+	require.Equal(t, "lower(title)", idx.Parts[0].X.(*schema.RawExpr).X)
+	require.Equal(t, []schema.Attr{&IndexOpClass{Name: "text_pattern_ops"}}, idx.Parts[0].Attrs)
+	idx = posts.Indexes[2]
+	require.Equal(t, "posts_title_key", idx.Name)
+	require.Equal(t, []schema.Attr{&Constraint{N: "posts_title_key", T: "u"}}, idx.Attrs)
+
+	// Evaluating the same statements results in no changes.
+	s2 := schema.New("public")
+	_, err = EvalSQL(`
+CREATE TYPE status AS ENUM ('active', 'it''s done');
+CREATE TABLE users (id bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 100) PRIMARY KEY, email varchar(255) NOT NULL UNIQUE, status status DEFAULT 'active'::status, tags status[], created_at timestamptz NOT NULL DEFAULT now(), age integer, "Name" text COLLATE "C");
+ALTER TABLE users ADD CHECK (age > 0);
+COMMENT ON TABLE users IS 'the users';
+COMMENT ON COLUMN users."Name" IS 'display name';
+`, s2)
+	require.NoError(t, err)
+	s.Tables = s.Tables[:1]
+	changes, err := DefaultDiff.SchemaDiff(s, s2)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestEvalSQL_Errors(t *testing.T) {
+	for ddl, msg := range map[string]string{
+		"CREATE TABLE t (a int, a int)":                         `column "a" was already defined in table "t"`,
+		"CREATE TABLE t (a int, PRIMARY KEY (b))":               `column "b" was not found in table "t"`,
+		"CREATE TABLE t (a int REFERENCES u)":                   `referenced table "u" of foreign key "t_a_fkey" was not found`,
+		"CREATE INDEX ON t (a)":                                 `table "t" was not found for index ""`,
+		"CREATE TABLE t (a int CHECK a > 0)":                    `expected "(", but got "a"`,
+		"CREATE TABLE t (a int PRIMARY KEY, b int PRIMARY KEY)": `multiple primary keys for table "t" are not allowed`,
+	} {
+		_, err := EvalSQL(ddl, schema.New("public"))
+		require.ErrorContains(t, err, msg, ddl)
+	}
+}
+
+func TestEvalSQL_AlterTableWarnings(t *testing.T) {
+	s := schema.New("public")
+	warns, err := EvalSQL(`
+CREATE TABLE t (a int);
+ALTER TABLE t ADD COLUMN b int, ADD CONSTRAINT t_a_key UNIQUE (a), OWNER TO admin;
+`, s)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`statement at position 25: table "t": skipping unsupported change: ADD COLUMN b int`,
+		`statement at position 25: table "t": skipping unsupported change: OWNER TO admin`,
+	}, warns)
+	tt, ok := s.Table("t")
+	require.True(t, ok)
+	require.Len(t, tt.Columns, 1)
+	require.Equal(t, "t_a_key", tt.Indexes[0].Name)
+}
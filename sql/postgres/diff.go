@@ -6,9 +6,16 @@ package postgres
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -31,6 +38,76 @@ func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
 	return nil
 }
 
+// SchemaObjectDiff returns a changeset for migrating schema objects from
+// one state to the other.
+func (d *diff) SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify objects.
+	for _, o1 := range from.Objects {
+		o2, ok := objectByName(to.Objects, o1)
+		if !ok {
+			// Type ownership is compared only if it is defined by the desired state.
+			if _, owner := o1.(*TypeOwner); !owner {
+				changes = append(changes, &schema.DropObject{O: o1})
+			}
+			continue
+		}
+		if !objectEqual(o1, o2) {
+			changes = append(changes, &schema.ModifyObject{From: o1, To: o2})
+		}
+	}
+	// Add objects.
+	for _, o1 := range to.Objects {
+		if _, ok := objectByName(from.Objects, o1); !ok {
+			changes = append(changes, &schema.AddObject{O: o1})
+		}
+	}
+	return changes, nil
+}
+
+// MovedObject implements the sqlx.ObjectMover interface. Collations and text search
+// configurations are matched by their names, as they can be moved to other schemas.
+func (d *diff) MovedObject(from, to *schema.Schema, name string) (schema.Object, schema.Object, bool) {
+	for _, o1 := range from.Objects {
+		switch o1.(type) {
+		case *Collation, *TextSearchConfiguration:
+		default:
+			continue
+		}
+		if objectName(o1) != name {
+			continue
+		}
+		if o2, ok := objectByName(to.Objects, o1); ok {
+			return o1, o2, true
+		}
+	}
+	return nil, nil, false
+}
+
+// RealmObjectDiff returns a changeset for migrating realm (database-level)
+// objects, such as event triggers, from one state to the other.
+func (d *diff) RealmObjectDiff(from, to *schema.Realm) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify objects.
+	for _, o1 := range from.Objects {
+		o2, ok := objectByName(to.Objects, o1)
+		if !ok {
+			changes = append(changes, &schema.DropObject{O: o1})
+			continue
+		}
+		if !objectEqual(o1, o2) {
+			changes = append(changes, &schema.ModifyObject{From: o1, To: o2})
+		}
+	}
+	// Add objects.
+	for _, o1 := range to.Objects {
+		if _, ok := objectByName(from.Objects, o1); !ok {
+			changes = append(changes, &schema.AddObject{O: o1})
+		}
+	}
+	return changes, nil
+}
+
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
 func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	var changes []schema.Change
@@ -40,13 +117,149 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if err := d.partitionChanged(from, to); err != nil {
 		return nil, err
 	}
-	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
+	change, err := partitionBoundsChange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if change != nil {
+		changes = append(changes, change)
+	}
+	if change := d.storageParamsChange(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	// Table ownership is managed only if it is defined by the desired state, and was
+	// inspected in the current state (i.e., using the InspectOwners mode). Otherwise,
+	// states that were inspected from databases (e.g. a dev database) would report the
+	// owners of their tables as changes.
+	if o1, o2 := (TableOwner{}), (TableOwner{}); sqlx.Has(from.Attrs, &o1) && sqlx.Has(to.Attrs, &o2) && o1.V != o2.V {
+		changes = append(changes, &schema.ModifyAttr{From: &o1, To: &o2})
+	}
+	if a1, a2 := tableAccessMethod(from.Attrs), tableAccessMethod(to.Attrs); !strings.EqualFold(a1.V, a2.V) {
+		changes = append(changes, &schema.ModifyAttr{From: a1, To: a2})
+	}
+	renames := checkRenames(from, to)
+	for _, c := range equivalentChecks(sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
 		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
-	})...), nil
+	}), renames) {
+		if !inheritedCheck(c) {
+			changes = append(changes, c)
+		}
+	}
+	return append(changes, renames...), nil
+}
+
+// tableAccessMethod returns the access method of the table, or the default one.
+func tableAccessMethod(attrs []schema.Attr) *TableAccessMethod {
+	if a := (&TableAccessMethod{}); sqlx.Has(attrs, a) {
+		return a
+	}
+	return &TableAccessMethod{V: defaultAccessMethod}
+}
+
+// equivalentChecks removes the pairs of dropped and added CHECK constraints that
+// have equivalent expressions (e.g. "x BETWEEN 1 AND 10" and "x >= 1 AND x <= 10"),
+// in case the added one is unnamed, or the pair is renamed.
+func equivalentChecks(changes, renames []schema.Change) []schema.Change {
+	var (
+		drops = make(map[*schema.Check]bool)
+		adds  = make(map[*schema.Check]bool)
+	)
+	for _, c := range renames {
+		r := c.(*schema.RenameCheck)
+		drops[r.From], adds[r.To] = true, true
+	}
+	for _, c1 := range changes {
+		d, ok := c1.(*schema.DropCheck)
+		if !ok || drops[d.C] {
+			continue
+		}
+		for _, c2 := range changes {
+			if a, ok := c2.(*schema.AddCheck); ok && a.C.Name == "" && !adds[a.C] && checkExprEqual(d.C.Expr, a.C.Expr) &&
+				sqlx.Has(d.C.Attrs, &NoInherit{}) == sqlx.Has(a.C.Attrs, &NoInherit{}) {
+				drops[d.C], adds[a.C] = true, true
+				break
+			}
+		}
+	}
+	filtered := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropCheck:
+			if drops[c.C] {
+				continue
+			}
+		case *schema.AddCheck:
+			if adds[c.C] {
+				continue
+			}
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// checkRenames returns the rename changes of named CHECK constraints that
+// have identical definitions in both states, but a different name.
+func checkRenames(from, to *schema.Table) []schema.Change {
+	var (
+		changes []schema.Change
+		renamed = make(map[*schema.Check]bool)
+	)
+	for _, c1 := range checksOf(from) {
+		if c1.Name == "" || sqlx.Has(c1.Attrs, &Inherited{}) || hasCheck(to, c1.Name) {
+			continue
+		}
+		for _, c2 := range checksOf(to) {
+			if c2.Name != "" && !renamed[c2] && checkExprEqual(c1.Expr, c2.Expr) && !hasCheck(from, c2.Name) &&
+				sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{}) {
+				renamed[c2] = true
+				changes = append(changes, &schema.RenameCheck{From: c1, To: c2})
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// checksOf returns the CHECK constraints of the table.
+func checksOf(t *schema.Table) []*schema.Check {
+	var checks []*schema.Check
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+// hasCheck reports if the table has a CHECK constraint with the given name.
+func hasCheck(t *schema.Table, name string) bool {
+	for _, c := range checksOf(t) {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedCheck reports if the given check change involves a constraint
+// that was inherited from a parent table. Such constraints are managed by
+// the parent table and should not be added, dropped or modified on its children.
+func inheritedCheck(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddCheck:
+		return sqlx.Has(c.C.Attrs, &Inherited{})
+	case *schema.DropCheck:
+		return sqlx.Has(c.C.Attrs, &Inherited{})
+	case *schema.ModifyCheck:
+		return sqlx.Has(c.From.Attrs, &Inherited{}) || sqlx.Has(c.To.Attrs, &Inherited{})
+	}
+	return false
 }
 
 // ColumnChange returns the schema changes (if any) for migrating one column to the other.
 func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.ChangeKind, error) {
+	from, to = serialColumn(from), serialColumn(to)
 	change := sqlx.CommentChange(from.Attrs, to.Attrs)
 	if from.Type.Null != to.Type.Null {
 		change |= schema.ChangeNull
@@ -64,7 +277,7 @@ func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.Ch
 	if changed {
 		change |= schema.ChangeDefault
 	}
-	if identityChanged(from.Attrs, to.Attrs) {
+	if identityChanged(from, to) || compressionChanged(from.Attrs, to.Attrs) {
 		change |= schema.ChangeAttr
 	}
 	if changed, err = d.generatedChanged(from, to); err != nil {
@@ -73,19 +286,112 @@ func (d *diff) ColumnChange(_ *schema.Table, from, to *schema.Column) (schema.Ch
 	if changed {
 		change |= schema.ChangeGenerated
 	}
+	if collationChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeCollate
+	}
 	return change, nil
 }
 
+// serialColumn returns the serial form of an integer column whose default
+// value is taken from its sequence, e.g. "integer DEFAULT nextval('t_id_seq')".
+// The two forms are equivalent, and therefore, they are compared as equal.
+func serialColumn(c *schema.Column) *schema.Column {
+	t, ok := c.Type.Type.(*schema.IntegerType)
+	if !ok || c.Default == nil {
+		return c
+	}
+	x, ok := sqlx.DefaultValue(c)
+	if !ok {
+		return c
+	}
+	m := reNextval.FindStringSubmatch(x)
+	if len(m) != 2 {
+		return c
+	}
+	st := &SerialType{SequenceName: m[1]}
+	st.SetType(t)
+	if st.T == "" {
+		return c
+	}
+	c1 := *c
+	c1.Default = nil
+	c1.Type = &schema.ColumnType{Type: st, Raw: st.T, Null: c.Type.Null}
+	return &c1
+}
+
 // defaultChanged reports if the default value of a column was changed.
 func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
-	d1, ok1 := sqlx.DefaultValue(from)
-	d2, ok2 := sqlx.DefaultValue(to)
+	d1, ok1 := defaultValue(from)
+	d2, ok2 := defaultValue(to)
 	if ok1 != ok2 {
 		return true, nil
 	}
+	d1, d2 = unqualifiedCast(from, d1), unqualifiedCast(to, d2)
 	if trimCast(d1) == trimCast(d2) || quote(d1) == quote(d2) {
 		return false, nil
 	}
+	// Current timestamp functions are equivalent, as long as the precision
+	// of their results, rounded to the column precision, is the same.
+	if p1, ok := nowPrecision(d1); ok {
+		if p2, ok := nowPrecision(d2); ok {
+			if t, ok := to.Type.Type.(*schema.TimeType); ok && t.Precision != nil {
+				if p1 > *t.Precision {
+					p1 = *t.Precision
+				}
+				if p2 > *t.Precision {
+					p2 = *t.Precision
+				}
+			}
+			return p1 != p2, nil
+		}
+	}
+	// Money values are formatted according to the lc_monetary setting.
+	if _, ok := to.Type.Type.(*CurrencyType); ok {
+		if m1, ok := moneyValue(d1); ok {
+			if m2, ok := moneyValue(d2); ok {
+				return m1.Cmp(m2) != 0, nil
+			}
+		}
+	}
+	// Special numeric values are compared by their canonical form,
+	// as NaN is not equal to itself in the database comparison.
+	switch to.Type.Type.(type) {
+	case *schema.DecimalType, *schema.FloatType:
+		n1, ok1 := specialNumber(d1)
+		n2, ok2 := specialNumber(d2)
+		if ok1 || ok2 {
+			return !ok1 || !ok2 || n1 != n2, nil
+		}
+	}
+	// Network addresses are compared by their canonical form.
+	if t1, ok := from.Type.Type.(*NetworkType); ok {
+		if t2, ok := to.Type.Type.(*NetworkType); ok {
+			if n1, ok := networkValue(t1, d1); ok {
+				if n2, ok := networkValue(t2, d2); ok {
+					return n1 != n2, nil
+				}
+			}
+		}
+	}
+	// The xml type does not provide an equality operator, and therefore, its
+	// literals are compared structurally, and other expressions are compared
+	// by their text representation in the database.
+	if _, ok := to.Type.Type.(*XMLType); ok {
+		if x1, ok := xmlValue(d1); ok {
+			if x2, ok := xmlValue(d2); ok {
+				return x1 != x2, nil
+			}
+		}
+		d1, d2 = fmt.Sprintf("(%s)::text", d1), fmt.Sprintf("(%s)::text", d2)
+	}
+	// Values of extension types are compared by their canonical form.
+	if n, ok := extensionType(to.Type.Type); ok && extensionTypes[n] != nil {
+		if v1, ok := extensionValue(n, d1); ok {
+			if v2, ok := extensionValue(n, d2); ok {
+				return v1 != v2, nil
+			}
+		}
+	}
 	var (
 		err    error
 		equals bool
@@ -98,155 +404,855 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	return !equals, err
 }
 
-// generatedChanged reports if the generated expression of a column was changed.
-func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
-	var fromX, toX schema.GeneratedExpr
-	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX); {
-	case fromHas && toHas && sqlx.MayWrap(fromX.Expr) != sqlx.MayWrap(toX.Expr):
-		return false, fmt.Errorf("changing the generation expression for a column %q is not supported", from.Name)
-	case !fromHas && toHas:
-		return false, fmt.Errorf("changing column %q to generated column is not supported (drop and add is required)", from.Name)
-	default:
-		// Only DROP EXPRESSION is supported.
-		return fromHas && !toHas, nil
+// defaultValue returns the string represents the DEFAULT of a column, and reports
+// if the column has a default value. A NULL default (e.g. NULL::text) is
+// equivalent to having no default at all.
+func defaultValue(c *schema.Column) (string, bool) {
+	x, ok := sqlx.DefaultValue(c)
+	if ok && strings.EqualFold(strings.TrimSpace(trimCast(x)), "NULL") {
+		return "", false
 	}
+	return x, ok
 }
 
-// partitionChanged checks and returns an error if the partition key of a table was changed.
-func (*diff) partitionChanged(from, to *schema.Table) error {
-	var fromP, toP Partition
-	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromP), sqlx.Has(to.Attrs, &toP); {
-	case fromHas && !toHas:
-		return fmt.Errorf("partition key cannot be dropped from %q (drop and add is required)", from.Name)
-	case !fromHas && toHas:
-		return fmt.Errorf("partition key cannot be added to %q (drop and add is required)", to.Name)
-	case fromHas && toHas:
-		s1, err := formatPartition(fromP)
-		if err != nil {
-			return err
-		}
-		s2, err := formatPartition(toP)
-		if err != nil {
-			return err
-		}
-		if s1 != s2 {
-			return fmt.Errorf("partition key of table %q cannot be changed from %s to %s (drop and add is required)", to.Name, s1, s2)
-		}
+// reQualifiedCast matches a cast to a schema-qualified type at the
+// end of an expression, e.g. 'happy'::public.mood or '1'::"s"."t".
+var reQualifiedCast = regexp.MustCompile(`::\s*("(?:[^"]|"")+"|\w+)\s*\.\s*("(?:[^"]|"")+"|\w+)\s*$`)
+
+// unqualifiedCast removes the schema qualifier from the type cast at the end of
+// the default value of the column, in case it is the schema of the column type
+// (e.g. the enum schema) or pg_catalog, as the database omits it for types that
+// are visible in the search_path.
+func unqualifiedCast(c *schema.Column, x string) string {
+	m := reQualifiedCast.FindStringSubmatchIndex(x)
+	if m == nil {
+		return x
 	}
-	return nil
+	ns := x[m[2]:m[3]]
+	if u, err := sqlx.Unquote(ns); err == nil {
+		ns = u
+	}
+	if e, ok := c.Type.Type.(*schema.EnumType); ns != "pg_catalog" && (!ok || e.Schema == nil || e.Schema.Name != ns) {
+		return x
+	}
+	return x[:m[0]] + "::" + x[m[4]:m[5]]
 }
 
-// IsGeneratedIndexName reports if the index name was generated by the database.
-func (d *diff) IsGeneratedIndexName(t *schema.Table, idx *schema.Index) bool {
-	names := make([]string, len(idx.Parts))
-	for i, p := range idx.Parts {
-		if p.C == nil {
-			return false
-		}
-		names[i] = p.C.Name
+// reNow matches the functions that return the start time of the current
+// transaction, e.g. now(), transaction_timestamp() or CURRENT_TIMESTAMP(3).
+var reNow = regexp.MustCompile(`(?i)^(?:now\(\s*\)|transaction_timestamp\(\s*\)|current_timestamp(?:\s*\(\s*(\d+)\s*\))?)$`)
+
+// nowPrecision reports if the expression returns the start time of the
+// current transaction, and returns the precision of its result.
+func nowPrecision(x string) (int, bool) {
+	x = strings.TrimSpace(trimCast(strings.TrimSpace(x)))
+	for len(x) > 1 && x[0] == '(' && x[len(x)-1] == ')' {
+		x = strings.TrimSpace(x[1 : len(x)-1])
 	}
-	// Auto-generate index names will have the following format: <table>_<c1>_..._key.
-	// In case of conflict, PostgreSQL adds additional index at the end (e.g. "key1").
-	p := fmt.Sprintf("%s_%s_key", t.Name, strings.Join(names, "_"))
-	if idx.Name == p {
-		return true
+	m := reNow.FindStringSubmatch(x)
+	switch {
+	case m == nil:
+		return 0, false
+	case m[1] == "":
+		return defaultTimePrecision, true
 	}
-	i, err := strconv.ParseInt(strings.TrimPrefix(idx.Name, p), 10, 64)
-	return err == nil && i > 0
+	p, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	if p > defaultTimePrecision {
+		p = defaultTimePrecision
+	}
+	return p, true
 }
 
-// IndexAttrChanged reports if the index attributes were changed.
-// The default type is BTREE if no type was specified.
-func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
-	t1 := &IndexType{T: IndexTypeBTree}
-	if sqlx.Has(from, t1) {
-		t1.T = strings.ToUpper(t1.T)
-	}
-	t2 := &IndexType{T: IndexTypeBTree}
-	if sqlx.Has(to, t2) {
-		t2.T = strings.ToUpper(t2.T)
+// extensionTypes holds the comparators of common extension (contrib) types, which
+// are inspected as user-defined types, keyed by their unqualified names. A comparator
+// returns the canonical form of a literal value of the type, as printed by the database.
+// Types without a comparator (e.g. gtrgm, which is used only by indexes) are compared by
+// name only.
+var extensionTypes = map[string]func(string) (string, bool){
+	"citext":    textValue,
+	"gtrgm":     nil,
+	"hstore":    hstoreValue,
+	"lquery":    textValue,
+	"ltree":     textValue,
+	"ltxtquery": textValue,
+}
+
+// extensionType reports if the given type is a known extension
+// type, and returns its unqualified name. e.g. "public"."hstore".
+func extensionType(t schema.Type) (string, bool) {
+	u, ok := t.(*UserDefinedType)
+	if !ok {
+		return "", false
 	}
-	if t1.T != t2.T {
-		return true
+	n := strings.ToLower(u.T)
+	if i := strings.LastIndexByte(n, '.'); i != -1 {
+		n = n[i+1:]
 	}
-	var p1, p2 IndexPredicate
-	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P)) {
-		return true
+	n = strings.Trim(n, `"`)
+	_, ok = extensionTypes[n]
+	return n, ok
+}
+
+// extensionValue returns the canonical form of a
+// literal value of the given extension type.
+func extensionValue(name, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "::"); i != -1 {
+		if n, ok := extensionType(&UserDefinedType{T: strings.TrimSpace(s[i+2:])}); ok && n == name {
+			s = strings.TrimSpace(s[:i])
+		}
 	}
-	if indexIncludeChanged(from, to) {
-		return true
+	u, err := sqlx.Unquote(s)
+	if err != nil {
+		return "", false
 	}
-	s1, ok1 := indexStorageParams(from)
-	s2, ok2 := indexStorageParams(to)
-	return ok1 != ok2 || ok1 && *s1 != *s2
+	return extensionTypes[name](u)
 }
 
-// IndexPartAttrChanged reports if the index-part attributes were changed.
-func (*diff) IndexPartAttrChanged(fromI, toI *schema.Index, i int) bool {
-	from, to := fromI.Parts[i], toI.Parts[i]
-	p1 := &IndexColumnProperty{NullsFirst: from.Desc, NullsLast: !from.Desc}
-	sqlx.Has(from.Attrs, p1)
-	p2 := &IndexColumnProperty{NullsFirst: to.Desc, NullsLast: !to.Desc}
-	sqlx.Has(to.Attrs, p2)
-	if p1.NullsFirst != p2.NullsFirst || p1.NullsLast != p2.NullsLast {
-		return true
+// textValue returns the given value as is, for types
+// that are printed the same as they are written.
+func textValue(s string) (string, bool) {
+	return s, true
+}
+
+// hstoreValue returns the canonical form of an hstore value, as a list of key-value
+// pairs ordered by their keys, with quoted keys and values, e.g. "a"=>"1", "b"=>NULL.
+// Similar to the database, only the first occurrence of duplicate keys is kept.
+func hstoreValue(s string) (string, bool) {
+	const ws = " \t\r\n"
+	var (
+		keys  []string
+		pairs = make(map[string]string)
+	)
+	// next scans the next (possibly quoted) key or value.
+	next := func() (string, bool, bool) {
+		s = strings.TrimLeft(s, ws)
+		if s != "" && s[0] == '"' {
+			var b strings.Builder
+			for i := 1; i < len(s); i++ {
+				switch c := s[i]; {
+				case c == '\\' && i+1 < len(s):
+					i++
+					b.WriteByte(s[i])
+				case c == '"':
+					s = s[i+1:]
+					return b.String(), true, true
+				default:
+					b.WriteByte(c)
+				}
+			}
+			return "", false, false
+		}
+		i := strings.IndexAny(s, ws+",=")
+		if i == -1 {
+			i = len(s)
+		}
+		t := s[:i]
+		s = s[i:]
+		return t, false, t != ""
 	}
-	var fromOp, toOp IndexOpClass
-	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromOp), sqlx.Has(to.Attrs, &toOp); {
-	case fromHas && toHas:
-		return !fromOp.Equal(&toOp)
-	case toHas:
-		// Report a change if a non-default operator class was added.
-		d, err := toOp.DefaultFor(toI, toI.Parts[i])
-		return !d && err == nil
-	case fromHas:
-		// Report a change if a non-default operator class was removed.
-		d, err := fromOp.DefaultFor(fromI, fromI.Parts[i])
-		return !d && err == nil
-	default:
-		return false
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	for strings.TrimLeft(s, ws) != "" {
+		k, _, ok := next()
+		if !ok {
+			return "", false
+		}
+		if s = strings.TrimLeft(s, ws); !strings.HasPrefix(s, "=>") {
+			return "", false
+		}
+		s = s[2:]
+		v, quoted, ok := next()
+		if !ok {
+			return "", false
+		}
+		if _, ok := pairs[k]; !ok {
+			keys = append(keys, k)
+			if pairs[k] = `"` + quote.Replace(v) + `"`; !quoted && strings.EqualFold(v, "NULL") {
+				pairs[k] = "NULL"
+			}
+		}
+		if s = strings.TrimLeft(s, ws); s != "" {
+			if s[0] != ',' {
+				return "", false
+			}
+			s = s[1:]
+		}
 	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = `"` + quote.Replace(k) + `"=>` + pairs[k]
+	}
+	return strings.Join(keys, ", "), true
 }
 
-// ReferenceChanged reports if the foreign key referential action was changed.
-func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
-	// According to PostgreSQL, the NO ACTION rule is set
-	// if no referential action was defined in foreign key.
-	if from == "" {
-		from = schema.NoAction
+// networkValue returns the canonical form of a network address literal, as it is
+// printed by the database for the given type. For example, an inet value omits the
+// netmask of single hosts, while a cidr value always includes it, and MAC addresses
+// are printed in lowercase, colon-separated form, e.g. '08-00-2B-01-02-03'.
+func networkValue(t *NetworkType, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "::"); i != -1 && strings.EqualFold(strings.TrimSpace(s[i+2:]), t.T) {
+		s = strings.TrimSpace(s[:i])
 	}
-	if to == "" {
-		to = schema.NoAction
+	u, err := sqlx.Unquote(s)
+	if err != nil {
+		return "", false
 	}
-	return from != to
+	s = strings.TrimSpace(u)
+	switch strings.ToLower(t.T) {
+	case TypeInet, TypeCIDR:
+		cidr := strings.EqualFold(t.T, TypeCIDR)
+		if !strings.Contains(s, "/") {
+			a, err := netip.ParseAddr(s)
+			if err != nil {
+				return "", false
+			}
+			if !cidr {
+				return a.String(), true
+			}
+			s = fmt.Sprintf("%s/%d", a, a.BitLen())
+		}
+		p, err := netip.ParsePrefix(s)
+		switch {
+		case err != nil:
+			return "", false
+		// CIDR values must not have bits set to the right of the netmask.
+		case cidr && p.Masked() != p:
+			return "", false
+		case !cidr && p.Bits() == p.Addr().BitLen():
+			return p.Addr().String(), true
+		}
+		return p.String(), true
+	case TypeMACAddr, TypeMACAddr8:
+		h := strings.Map(func(r rune) rune {
+			if r == ':' || r == '-' || r == '.' {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, s)
+		if strings.EqualFold(t.T, TypeMACAddr8) && len(h) == 12 {
+			// 6-byte addresses are converted to 8-byte (EUI-64) addresses.
+			h = h[:6] + "fffe" + h[6:]
+		}
+		if len(h) != 12 && (len(h) != 16 || !strings.EqualFold(t.T, TypeMACAddr8)) {
+			return "", false
+		}
+		parts := make([]string, 0, len(h)/2)
+		for i := 0; i < len(h); i += 2 {
+			if _, err := strconv.ParseUint(h[i:i+2], 16, 8); err != nil {
+				return "", false
+			}
+			parts = append(parts, h[i:i+2])
+		}
+		return strings.Join(parts, ":"), true
+	}
+	return "", false
 }
 
-func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
-	fromT, toT := from.Type.Type, to.Type.Type
-	if fromT == nil || toT == nil {
-		return false, fmt.Errorf("postgres: missing type information for column %q", from.Name)
+// reXMLParse matches the XMLPARSE function that is used for creating xml values.
+var reXMLParse = regexp.MustCompile(`(?is)^xmlparse\s*\(\s*(?:content|document)\s+(.+?)\s*\)$`)
+
+// xmlValue returns the canonical form of an xml literal, e.g. '<a> <b/> </a>'::xml
+// or XMLPARSE(CONTENT '<a><b></b></a>'). The canonical form ignores whitespace between
+// elements, the order of their attributes, and the form of empty elements.
+func xmlValue(s string) (string, bool) {
+	s = strings.TrimSpace(trimCast(strings.TrimSpace(s)))
+	if m := reXMLParse.FindStringSubmatch(s); m != nil {
+		s = m[1]
 	}
-	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
-		return true, nil
+	if !sqlx.IsQuoted(s, '\'') {
+		return "", false
 	}
-	var changed bool
-	switch fromT := fromT.(type) {
-	case *schema.BinaryType, *BitType, *schema.BoolType, *schema.DecimalType, *schema.FloatType,
-		*IntervalType, *schema.IntegerType, *schema.JSONType, *RangeType, *SerialType, *schema.SpatialType,
-		*schema.StringType, *schema.TimeType, *TextSearchType, *NetworkType, *UserDefinedType:
-		t1, err := FormatType(toT)
-		if err != nil {
-			return false, err
+	u, err := sqlx.Unquote(s)
+	if err != nil {
+		return "", false
+	}
+	var (
+		b   strings.Builder
+		dec = xml.NewDecoder(strings.NewReader(u))
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return b.String(), true
 		}
-		t2, err := FormatType(fromT)
 		if err != nil {
-			return false, err
+			return "", false
 		}
-		changed = t1 != t2
-	case *schema.EnumType:
-		toT := toT.(*schema.EnumType)
-		// Column type was changed if the underlying enum type was changed or values are not equal.
-		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values) || fromT.T != toT.T ||
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attrs := make([]string, 0, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs = append(attrs, fmt.Sprintf("%s:%s=%q", a.Name.Space, a.Name.Local, a.Value))
+			}
+			sort.Strings(attrs)
+			fmt.Fprintf(&b, "<%s:%s %s>", tok.Name.Space, tok.Name.Local, strings.Join(attrs, " "))
+		case xml.EndElement:
+			fmt.Fprintf(&b, "</%s:%s>", tok.Name.Space, tok.Name.Local)
+		case xml.CharData:
+			if t := strings.TrimSpace(string(tok)); t != "" {
+				fmt.Fprintf(&b, "%q", string(tok))
+			}
+		case xml.Comment:
+			fmt.Fprintf(&b, "<!--%s-->", tok)
+		case xml.ProcInst:
+			fmt.Fprintf(&b, "<?%s %s?>", tok.Target, tok.Inst)
+		}
+	}
+}
+
+// specialNumber returns the canonical form of the special values of numeric
+// and floating-point literals (NaN and infinities). e.g. 'nan'::numeric.
+func specialNumber(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "::"); i != -1 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if !sqlx.IsQuoted(s, '\'') {
+		return "", false
+	}
+	switch strings.ToLower(strings.TrimSpace(s[1 : len(s)-1])) {
+	case "nan":
+		return "NaN", true
+	case "infinity", "+infinity", "inf", "+inf":
+		return "Infinity", true
+	case "-infinity", "-inf":
+		return "-Infinity", true
+	}
+	return "", false
+}
+
+// moneyValue returns the numeric value of a money literal, formatted
+// by any locale. e.g. '$1,000.50', '1.000,50 €' or '($1.00)'.
+func moneyValue(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(trimCast(s))
+	if u, err := sqlx.Unquote(s); err == nil {
+		s = u
+	}
+	s = strings.TrimSpace(s)
+	var neg bool
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg, s = true, s[1:len(s)-1]
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '-':
+			neg = true
+		case unicode.IsDigit(r), r == '.', r == ',':
+			b.WriteRune(r)
+		case unicode.IsSpace(r), unicode.Is(unicode.Sc, r), unicode.IsLetter(r):
+			// Currency symbols, codes and spacing.
+		default:
+			return nil, false
+		}
+	}
+	v := b.String()
+	// The last separator followed by one or two digits
+	// is the decimal one. Others are thousands separators.
+	dec := -1
+	if i := strings.LastIndexAny(v, ".,"); i != -1 && len(v)-i-1 <= 2 {
+		dec = i
+	}
+	var n strings.Builder
+	for i, r := range v {
+		switch {
+		case i == dec:
+			n.WriteRune('.')
+		case r != '.' && r != ',':
+			n.WriteRune(r)
+		}
+	}
+	if n.Len() == 0 {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(n.String())
+	if ok && neg {
+		r.Neg(r)
+	}
+	return r, ok
+}
+
+// generatedChanged reports if the generated expression of a column was changed.
+func (*diff) generatedChanged(from, to *schema.Column) (bool, error) {
+	var fromX, toX schema.GeneratedExpr
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromX), sqlx.Has(to.Attrs, &toX); {
+	case fromHas && toHas:
+		// Changing the expression requires recreating the column.
+		return normalizeGenExpr(fromX.Expr) != normalizeGenExpr(toX.Expr), nil
+	case !fromHas && toHas:
+		return false, fmt.Errorf("changing column %q to generated column is not supported (drop and add is required)", from.Name)
+	default:
+		// Dropping the expression is done using DROP EXPRESSION.
+		return fromHas, nil
+	}
+}
+
+// reLiteralCast matches the casts that are added by the database to string literals in
+// stored expressions. For example, the text search configuration argument of to_tsvector
+// is stored as 'english'::regconfig, and the weight of setweight as 'A'::"char".
+var reLiteralCast = regexp.MustCompile(`('(?:[^']|'')*')::(?:regconfig\b|text\b|character varying\b|"char")`)
+
+// normalizeGenExpr normalizes the given generation expression for comparison. The
+// literal casts added by the database are removed, and the expression is lowercased
+// and stripped from whitespaces, excluding string literals and quoted identifiers.
+func normalizeGenExpr(x string) string {
+	x = reLiteralCast.ReplaceAllString(sqlx.MayWrap(x), "$1")
+	var (
+		b     strings.Builder
+		quote rune
+	)
+	for _, r := range x {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case unicode.IsSpace(r):
+			continue
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// checkExprEqual reports if the two CHECK constraint expressions are equivalent.
+// See normalizeCheckExpr for the forms that are considered equivalent.
+func checkExprEqual(x1, x2 string) bool {
+	if x1 == x2 {
+		return true
+	}
+	n1, err1 := normalizeCheckExpr(x1)
+	n2, err2 := normalizeCheckExpr(x2)
+	return err1 == nil && err2 == nil && n1 == n2
+}
+
+// normalizeCheckExpr returns the canonical form of the given boolean expression.
+// Similar to the way PostgreSQL stores CHECK constraints, BETWEEN predicates are
+// expanded to comparisons (e.g. "x >= 1 AND x <= 10"), and the operands of the
+// AND, OR and NOT operators are parenthesized. Keywords and unquoted identifiers
+// are lowercased, and whitespaces outside of literals are ignored.
+func normalizeCheckExpr(x string) (string, error) {
+	toks, err := lexDDL(x)
+	if err != nil {
+		return "", err
+	}
+	p := &boolParser{toks: toks}
+	e, err := p.or()
+	if err != nil {
+		return "", err
+	}
+	if p.i < len(toks) {
+		return "", fmt.Errorf("unexpected %q in expression", toks[p.i].v)
+	}
+	return e.String(), nil
+}
+
+type (
+	// boolExpr is a node in a parsed boolean expression. The leaves are
+	// predicates (e.g. comparisons) kept in their normalized text form.
+	boolExpr struct {
+		op   string // and, or, not
+		args []*boolExpr
+		pred string
+	}
+
+	// boolParser parses boolean expressions from their tokens.
+	boolParser struct {
+		toks []ddlToken
+		i    int
+	}
+)
+
+// newBoolExpr returns a new AND or OR expression, after flattening
+// the arguments that use the same (associative) operator.
+func newBoolExpr(op string, args ...*boolExpr) *boolExpr {
+	if len(args) == 1 {
+		return args[0]
+	}
+	e := &boolExpr{op: op}
+	for _, a := range args {
+		if a.op == op {
+			e.args = append(e.args, a.args...)
+		} else {
+			e.args = append(e.args, a)
+		}
+	}
+	return e
+}
+
+func (e *boolExpr) String() string {
+	switch e.op {
+	case "":
+		return e.pred
+	case "not":
+		return "(not " + e.args[0].String() + ")"
+	default:
+		args := make([]string, len(e.args))
+		for i := range e.args {
+			args[i] = e.args[i].String()
+		}
+		return "(" + strings.Join(args, " "+e.op+" ") + ")"
+	}
+}
+
+// keyword reports if the token at position i is the given keyword.
+func (p *boolParser) keyword(i int, w string) bool {
+	return i < len(p.toks) && p.toks[i].t == tokIdent && strings.EqualFold(p.toks[i].v, w)
+}
+
+// closing reports if the token at position i closes a group.
+func (p *boolParser) closing(i int) bool {
+	return i < len(p.toks) && p.toks[i].t == tokParen && (p.toks[i].v == ")" || p.toks[i].v == "]")
+}
+
+func (p *boolParser) or() (*boolExpr, error) {
+	return p.binary("or", p.and)
+}
+
+func (p *boolParser) and() (*boolExpr, error) {
+	return p.binary("and", p.not)
+}
+
+func (p *boolParser) binary(op string, operand func() (*boolExpr, error)) (*boolExpr, error) {
+	e, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	args := []*boolExpr{e}
+	for p.keyword(p.i, op) {
+		p.i++
+		if e, err = operand(); err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+	}
+	return newBoolExpr(op, args...), nil
+}
+
+func (p *boolParser) not() (*boolExpr, error) {
+	if !p.keyword(p.i, "not") {
+		return p.pred()
+	}
+	p.i++
+	e, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	return &boolExpr{op: "not", args: []*boolExpr{e}}, nil
+}
+
+// pred parses a predicate. Parenthesized boolean expressions are parsed recursively.
+func (p *boolParser) pred() (*boolExpr, error) {
+	if p.i < len(p.toks) && p.toks[p.i].t == tokParen && p.toks[p.i].v == "(" {
+		end, err := p.group(p.i)
+		if err != nil {
+			return nil, err
+		}
+		if next := end + 1; next == len(p.toks) || p.closing(next) || p.keyword(next, "and") || p.keyword(next, "or") {
+			inner := &boolParser{toks: p.toks[p.i+1 : end]}
+			e, err := inner.or()
+			if err != nil {
+				return nil, err
+			}
+			if inner.i < len(inner.toks) {
+				return nil, fmt.Errorf("unexpected %q in expression", inner.toks[inner.i].v)
+			}
+			p.i = end + 1
+			return e, nil
+		}
+	}
+	operand, err := p.operand(func(i int) bool { return p.keyword(i, "between") })
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword(p.i, "between") {
+		return &boolExpr{pred: renderTokens(operand)}, nil
+	}
+	p.i++
+	negate := len(operand) > 1 && operand[len(operand)-1].t == tokIdent && strings.EqualFold(operand[len(operand)-1].v, "not")
+	if negate {
+		operand = operand[:len(operand)-1]
+	}
+	symmetric := p.keyword(p.i, "symmetric")
+	if symmetric {
+		p.i++
+	}
+	low, err := p.operand(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword(p.i, "and") {
+		return nil, errors.New("missing AND in BETWEEN predicate")
+	}
+	p.i++
+	high, err := p.operand(nil)
+	if err != nil {
+		return nil, err
+	}
+	x, l, h := renderTokens(operand), renderTokens(low), renderTokens(high)
+	cmp := func(op, y string) *boolExpr { return &boolExpr{pred: x + " " + op + " " + y} }
+	// The expansions below follow the transformations
+	// applied by PostgreSQL to the BETWEEN predicates.
+	switch {
+	case negate && symmetric:
+		return newBoolExpr("and", newBoolExpr("or", cmp("<", l), cmp(">", h)), newBoolExpr("or", cmp("<", h), cmp(">", l))), nil
+	case negate:
+		return newBoolExpr("or", cmp("<", l), cmp(">", h)), nil
+	case symmetric:
+		return newBoolExpr("or", newBoolExpr("and", cmp(">=", l), cmp("<=", h)), newBoolExpr("and", cmp(">=", h), cmp("<=", l))), nil
+	default:
+		return newBoolExpr("and", cmp(">=", l), cmp("<=", h)), nil
+	}
+}
+
+// operand returns the tokens until the end of the current operand, that is, an AND or
+// OR keyword or a closing parenthesis at the top level, or a token that matches stop.
+func (p *boolParser) operand(stop func(int) bool) ([]ddlToken, error) {
+	start := p.i
+	for p.i < len(p.toks) && !p.closing(p.i) && !p.keyword(p.i, "and") && !p.keyword(p.i, "or") && (stop == nil || !stop(p.i)) {
+		if t := p.toks[p.i]; t.t == tokParen {
+			end, err := p.group(p.i)
+			if err != nil {
+				return nil, err
+			}
+			p.i = end
+		}
+		p.i++
+	}
+	if p.i == start {
+		return nil, errors.New("missing operand in expression")
+	}
+	return p.toks[start:p.i], nil
+}
+
+// group returns the position of the token that closes the group opened at position i.
+func (p *boolParser) group(i int) (int, error) {
+	depth := 0
+	for ; i < len(p.toks); i++ {
+		switch t := p.toks[i]; {
+		case t.t != tokParen:
+		case t.v == "(" || t.v == "[":
+			depth++
+		default:
+			if depth--; depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.New("unbalanced parentheses in expression")
+}
+
+// renderTokens returns the normalized text form of the given tokens. The regclass
+// casts added by the database to string literals (e.g. nextval('seq'::regclass))
+// are omitted, as they do not change the meaning of the expression.
+func renderTokens(toks []ddlToken) string {
+	parts := make([]string, 0, len(toks))
+	for i, t := range toks {
+		if t.t == tokIdent && strings.EqualFold(t.v, "regclass") && i > 1 && toks[i-1].v == "::" && toks[i-2].t == tokString {
+			parts = parts[:len(parts)-1]
+			continue
+		}
+		switch t.t {
+		case tokIdent:
+			parts = append(parts, strings.ToLower(t.v))
+		case tokQuoted:
+			parts = append(parts, `"`+strings.ReplaceAll(t.v, `"`, `""`)+`"`)
+		case tokString:
+			parts = append(parts, "'"+strings.ReplaceAll(t.v, "'", "''")+"'")
+		case tokPunct:
+			// Multi-character operators (e.g. >=) are scanned char by char.
+			if i > 0 && toks[i-1].t == tokPunct && toks[i-1].end == t.pos {
+				parts[len(parts)-1] += t.v
+				break
+			}
+			parts = append(parts, t.v)
+		default:
+			parts = append(parts, t.v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// partitionChanged checks and returns an error if the partition key of a table was changed.
+func (*diff) partitionChanged(from, to *schema.Table) error {
+	var fromP, toP Partition
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromP), sqlx.Has(to.Attrs, &toP); {
+	case fromHas && !toHas:
+		return fmt.Errorf("partition key cannot be dropped from %q (drop and add is required)", from.Name)
+	case !fromHas && toHas:
+		return fmt.Errorf("partition key cannot be added to %q (drop and add is required)", to.Name)
+	case fromHas && toHas:
+		s1, err := formatPartition(fromP)
+		if err != nil {
+			return err
+		}
+		s2, err := formatPartition(toP)
+		if err != nil {
+			return err
+		}
+		if s1 != s2 {
+			return fmt.Errorf("partition key of table %q cannot be changed from %s to %s (drop and add is required)", to.Name, s1, s2)
+		}
+	}
+	return nil
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database.
+func (d *diff) IsGeneratedIndexName(t *schema.Table, idx *schema.Index) bool {
+	names := make([]string, len(idx.Parts))
+	for i, p := range idx.Parts {
+		if p.C == nil {
+			return false
+		}
+		names[i] = p.C.Name
+	}
+	// Auto-generate index names will have the following format: <table>_<c1>_..._key.
+	// In case of conflict, PostgreSQL adds additional index at the end (e.g. "key1").
+	p := fmt.Sprintf("%s_%s_key", t.Name, strings.Join(names, "_"))
+	if idx.Name == p {
+		return true
+	}
+	i, err := strconv.ParseInt(strings.TrimPrefix(idx.Name, p), 10, 64)
+	return err == nil && i > 0
+}
+
+// SupportsModify reports if the given modify change can be planned without dropping
+// and re-adding the object. The generation expression of a column cannot be changed
+// in place, but it can be dropped (converting the column to a regular one) on servers
+// that support the DROP EXPRESSION clause.
+func (d *diff) SupportsModify(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.ModifyColumn:
+		return !c.Change.Is(schema.ChangeGenerated) || d.supportsDropExpression() && !sqlx.Has(c.To.Attrs, &schema.GeneratedExpr{})
+	case *schema.ModifyIndex, *schema.ModifyForeignKey, *schema.ModifyCheck:
+		return true
+	}
+	return false
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+// The default type is BTREE if no type was specified.
+func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
+	t1 := &IndexType{T: IndexTypeBTree}
+	if sqlx.Has(from, t1) {
+		t1.T = strings.ToUpper(t1.T)
+	}
+	t2 := &IndexType{T: IndexTypeBTree}
+	if sqlx.Has(to, t2) {
+		t2.T = strings.ToUpper(t2.T)
+	}
+	if t1.T != t2.T {
+		return true
+	}
+	// The predicate and the NULLS DISTINCT clause are compared together, as
+	// changing one of them requires rebuilding the index with both of them.
+	var p1, p2 IndexPredicate
+	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || (p1.P != p2.P && p1.P != sqlx.MayWrap(p2.P)) || nullsDistinct(from) != nullsDistinct(to) {
+		return true
+	}
+	if indexIncludeChanged(from, to) {
+		return true
+	}
+	s1, ok1 := indexStorageParams(from)
+	s2, ok2 := indexStorageParams(to)
+	return ok1 != ok2 || ok1 && !s1.equal(s2)
+}
+
+// ForeignKeyAttrChanged reports if the foreign-key attributes were changed.
+// i.e. the constraint deferrability, which can be altered in place.
+func (*diff) ForeignKeyAttrChanged(from, to []schema.Attr) bool {
+	var d1, d2 Deferrable
+	return sqlx.Has(from, &d1) != sqlx.Has(to, &d2) || d1.InitiallyDeferred != d2.InitiallyDeferred
+}
+
+// nullsDistinct reports if NULL values are considered distinct by the index.
+func nullsDistinct(attrs []schema.Attr) bool {
+	n := &IndexNullsDistinct{V: true}
+	sqlx.Has(attrs, n)
+	return n.V
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(fromI, toI *schema.Index, i int) bool {
+	from, to := fromI.Parts[i], toI.Parts[i]
+	p1 := &IndexColumnProperty{NullsFirst: from.Desc, NullsLast: !from.Desc}
+	sqlx.Has(from.Attrs, p1)
+	p2 := &IndexColumnProperty{NullsFirst: to.Desc, NullsLast: !to.Desc}
+	sqlx.Has(to.Attrs, p2)
+	if p1.NullsFirst != p2.NullsFirst || p1.NullsLast != p2.NullsLast {
+		return true
+	}
+	var fromOp, toOp IndexOpClass
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromOp), sqlx.Has(to.Attrs, &toOp); {
+	case fromHas && toHas:
+		return !fromOp.Equal(&toOp)
+	case toHas:
+		// Report a change if a non-default operator class was added.
+		d, err := toOp.DefaultFor(toI, toI.Parts[i])
+		return !d && err == nil
+	case fromHas:
+		// Report a change if a non-default operator class was removed.
+		d, err := fromOp.DefaultFor(fromI, fromI.Parts[i])
+		return !d && err == nil
+	default:
+		return false
+	}
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	from, to = sqlx.NormalizeReference(from), sqlx.NormalizeReference(to)
+	// According to PostgreSQL, the NO ACTION rule is set
+	// if no referential action was defined in foreign key.
+	if from == "" {
+		from = schema.NoAction
+	}
+	if to == "" {
+		to = schema.NoAction
+	}
+	return from != to
+}
+
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	fromT, toT := from.Type.Type, to.Type.Type
+	if fromT == nil || toT == nil {
+		return false, fmt.Errorf("postgres: missing type information for column %q", from.Name)
+	}
+	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
+		return true, nil
+	}
+	// Extension types are installed once per database, and
+	// therefore, they are compared by their unqualified names.
+	if n1, ok := extensionType(fromT); ok {
+		n2, ok := extensionType(toT)
+		return !ok || n1 != n2, nil
+	}
+	var changed bool
+	switch fromT := fromT.(type) {
+	case *schema.BinaryType, *BitType, *schema.BoolType, *schema.DecimalType, *schema.FloatType,
+		*IntervalType, *schema.IntegerType, *schema.JSONType, *RangeType, *SerialType, *schema.SpatialType,
+		*schema.StringType, *schema.TimeType, *TextSearchType, *NetworkType, *UserDefinedType:
+		t1, err := FormatType(toT)
+		if err != nil {
+			return false, err
+		}
+		t2, err := FormatType(fromT)
+		if err != nil {
+			return false, err
+		}
+		changed = t1 != t2
+	case *schema.EnumType:
+		toT := toT.(*schema.EnumType)
+		// Column type was changed if the underlying enum type was changed or values are not equal.
+		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values) || fromT.T != toT.T ||
 			(toT.Schema != nil && fromT.Schema != nil && fromT.Schema.Name != toT.Schema.Name)
 	case *CurrencyType:
 		toT := toT.(*CurrencyType)
@@ -254,132 +1260,1027 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	case *UUIDType:
 		toT := toT.(*UUIDType)
 		changed = fromT.T != toT.T
+	case *OIDType:
+		toT := toT.(*OIDType)
+		changed = !strings.EqualFold(fromT.T, toT.T)
 	case *XMLType:
 		toT := toT.(*XMLType)
 		changed = fromT.T != toT.T
 	case *ArrayType:
 		toT := toT.(*ArrayType)
-		// Same type.
-		if changed = fromT.T != toT.T; !changed {
+		// Same type, or the same integer type spelled differently (e.g. int4[] and integer[]).
+		if changed = fromT.T != toT.T && canonicalArray(fromT.T) != canonicalArray(toT.T); !changed {
 			// In case it is an enum type, compare its values.
 			fromE, ok1 := fromT.Type.(*schema.EnumType)
 			toE, ok2 := toT.Type.(*schema.EnumType)
 			changed = ok1 && ok2 && !sqlx.ValuesEqual(fromE.Values, toE.Values)
 			break
 		}
-		// In case the desired schema is not normalized, the string type can look different even
-		// if the two strings represent the same array type (varchar(1), character varying (1)).
-		// Therefore, we try by comparing the underlying types if they were defined.
-		if fromT.Type != nil && toT.Type != nil {
-			t1, err := FormatType(fromT.Type)
-			if err != nil {
-				return false, err
+		// In case the desired schema is not normalized, the string type can look different even
+		// if the two strings represent the same array type (varchar(1), character varying (1)).
+		// Therefore, we try by comparing the underlying types if they were defined.
+		if fromT.Type != nil && toT.Type != nil {
+			t1, err := FormatType(fromT.Type)
+			if err != nil {
+				return false, err
+			}
+			t2, err := FormatType(toT.Type)
+			if err != nil {
+				return false, err
+			}
+			// Same underlying type.
+			changed = t1 != t2
+		}
+	default:
+		return false, &sqlx.UnsupportedTypeError{Type: fromT}
+	}
+	return changed, nil
+}
+
+// canonicalArray returns the array type name without its declared dimensions,
+// as they are not enforced by the database (e.g. int[3][3] is the same as int[]),
+// and with its element type replaced by its canonical name, in case it is an
+// integer type alias.
+func canonicalArray(t string) string {
+	elem, ok := arrayType(t)
+	if !ok {
+		return t
+	}
+	switch c := canonicalInt(elem); c {
+	case TypeSmallInt, TypeInteger, TypeBigInt:
+		elem = c
+	}
+	return elem + "[]"
+}
+
+// valuesEqual reports if the DEFAULT values x and y
+// equal according to the database engine.
+func (d *diff) valuesEqual(x, y string) (bool, error) {
+	var b bool
+	// The DEFAULT expressions are safe to be inlined in the SELECT
+	// statement same as we inline them in the CREATE TABLE statement.
+	rows, err := d.QueryContext(context.Background(), fmt.Sprintf("SELECT %s = %s", x, y))
+	if err != nil {
+		return false, err
+	}
+	if err := sqlx.ScanOne(rows, &b); err != nil {
+		return false, err
+	}
+	return b, nil
+}
+
+// Default IDENTITY attributes.
+const (
+	defaultIdentityGen  = "BY DEFAULT"
+	defaultSeqStart     = 1
+	defaultSeqIncrement = 1
+)
+
+// identityChanged reports if one of the identity attributes was changed.
+func identityChanged(from, to *schema.Column) bool {
+	i1, ok1 := identity(from.Attrs)
+	i2, ok2 := identity(to.Attrs)
+	if !ok1 && !ok2 || ok1 != ok2 {
+		return ok1 != ok2
+	}
+	return i1.Generation != i2.Generation || i1.Sequence.Start != i2.Sequence.Start || i1.Sequence.Increment != i2.Sequence.Increment || boundsChanged(from, to)
+}
+
+// boundsChanged reports if the bounds of the identity sequences were changed.
+func boundsChanged(from, to *schema.Column) bool {
+	min1, max1 := identityBounds(from)
+	min2, max2 := identityBounds(to)
+	return min1 != min2 || max1 != max2
+}
+
+// identityBounds returns the bounds of the identity sequence of the column. NO MINVALUE
+// and NO MAXVALUE (nil values) resolve to the implicit bounds of ascending sequences,
+// 1 and the maximum value of the column type, or the descending ones, the minimum value
+// of the column type and -1.
+func identityBounds(c *schema.Column) (min, max int64) {
+	i, ok := identity(c.Attrs)
+	if !ok {
+		return 0, 0
+	}
+	min, max = implicitBounds(c, i.Sequence.Increment)
+	if i.Sequence.Min != nil {
+		min = *i.Sequence.Min
+	}
+	if i.Sequence.Max != nil {
+		max = *i.Sequence.Max
+	}
+	return min, max
+}
+
+// implicitBounds returns the bounds of a sequence with the given increment
+// that was declared with NO MINVALUE and NO MAXVALUE for the given column.
+func implicitBounds(c *schema.Column, inc int64) (min, max int64) {
+	min, max = math.MinInt64, math.MaxInt64
+	if t, ok := c.Type.Type.(*schema.IntegerType); ok {
+		switch strings.ToLower(t.T) {
+		case TypeSmallInt, TypeInt2:
+			min, max = math.MinInt16, math.MaxInt16
+		case TypeInteger, TypeInt, TypeInt4:
+			min, max = math.MinInt32, math.MaxInt32
+		}
+	}
+	if inc > 0 {
+		return 1, max
+	}
+	return min, -1
+}
+
+// compressionChanged reports if the compression method of a column was changed.
+func compressionChanged(from, to []schema.Attr) bool {
+	return compression(from) != compression(to)
+}
+
+// collationChanged reports if the collation of a column was changed.
+func collationChanged(from, to []schema.Attr) bool {
+	return collation(from) != collation(to)
+}
+
+// collation returns the collation of a column. The "default" collation
+// is normalized to an empty string, as it is the collation of unset columns.
+func collation(attrs []schema.Attr) string {
+	var c schema.Collation
+	if !sqlx.Has(attrs, &c) || c.V == "default" {
+		return ""
+	}
+	return c.V
+}
+
+// compression returns the compression method of a column. The default method (pglz),
+// is normalized to an empty string, as it is the method used by unset columns.
+func compression(attrs []schema.Attr) string {
+	var c Compression
+	if !sqlx.Has(attrs, &c) {
+		return ""
+	}
+	switch v := strings.ToLower(c.V); v {
+	case "pglz", "default":
+		return ""
+	default:
+		return v
+	}
+}
+
+func identity(attrs []schema.Attr) (*Identity, bool) {
+	i := &Identity{}
+	if !sqlx.Has(attrs, i) {
+		return nil, false
+	}
+	if i.Generation == "" {
+		i.Generation = defaultIdentityGen
+	}
+	if i.Sequence == nil {
+		i.Sequence = &Sequence{Start: defaultSeqStart, Increment: defaultSeqIncrement}
+		return i, true
+	}
+	if i.Sequence.Start == 0 {
+		i.Sequence.Start = defaultSeqStart
+	}
+	if i.Sequence.Increment == 0 {
+		i.Sequence.Increment = defaultSeqIncrement
+	}
+	return i, true
+}
+
+// formatPartition returns the string representation of the
+// partition key according to the PostgreSQL format/grammar.
+func formatPartition(p Partition) (string, error) {
+	b := &sqlx.Builder{QuoteChar: '"'}
+	b.P("PARTITION BY")
+	switch t := strings.ToUpper(p.T); t {
+	case PartitionTypeRange, PartitionTypeList, PartitionTypeHash:
+		b.P(t)
+	default:
+		return "", fmt.Errorf("unknown partition type: %q", t)
+	}
+	if len(p.Parts) == 0 {
+		return "", errors.New("missing parts for partition key")
+	}
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(p.Parts, func(i int, b *sqlx.Builder) {
+			switch k := p.Parts[i]; {
+			case k.C != nil:
+				b.Ident(k.C.Name)
+			case k.X != nil:
+				b.P(sqlx.MayWrap(k.X.(*schema.RawExpr).X))
+			}
+		})
+	})
+	return b.String(), nil
+}
+
+// partitionBoundsChange returns the change of the partitions of a partitioned table.
+// Partitions are managed only if the desired table lists them, and overlapping bounds
+// are rejected, as they fail the creation (or attachment) of the partitions.
+func partitionBoundsChange(from, to *schema.Table) (schema.Change, error) {
+	var fromB, toB PartitionBounds
+	if !sqlx.Has(to.Attrs, &toB) {
+		return nil, nil
+	}
+	if err := checkPartitionBounds(to.Name, toB.Parts); err != nil {
+		return nil, err
+	}
+	switch {
+	case !sqlx.Has(from.Attrs, &fromB) && len(toB.Parts) == 0:
+		return nil, nil
+	case !sqlx.Has(from.Attrs, &fromB):
+		return &schema.AddAttr{A: &toB}, nil
+	case partitionBoundsEqual(fromB.Parts, toB.Parts):
+		return nil, nil
+	}
+	return &schema.ModifyAttr{From: &fromB, To: &toB}, nil
+}
+
+// partitionBoundsEqual reports if both lists define the same partitions and bounds.
+func partitionBoundsEqual(from, to []*PartitionBound) bool {
+	if len(from) != len(to) {
+		return false
+	}
+	for _, p1 := range from {
+		p2, ok := partitionBound(to, p1.Name)
+		if !ok || !boundEqual(p1.Bound, p2.Bound) {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionBound returns the partition bound with the given name.
+func partitionBound(parts []*PartitionBound, name string) (*PartitionBound, bool) {
+	for _, p := range parts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// boundEqual reports if the two bound specifications are equal, ignoring
+// the case of keywords and the spacing between the tokens.
+func boundEqual(b1, b2 string) bool {
+	t1, err1 := boundTokens(b1)
+	t2, err2 := boundTokens(b2)
+	if err1 != nil || err2 != nil {
+		return b1 == b2
+	}
+	return reflect.DeepEqual(t1, t2)
+}
+
+// boundToken is a token of a partition bound specification.
+type boundToken struct {
+	V      string // Uppercase keyword, punctuation or the unquoted literal.
+	Quoted bool
+}
+
+// boundTokens splits the partition bound specification into tokens. Casts
+// (e.g. '2024-01-01'::date) are skipped, as bounds are implicitly converted
+// to the types of their partition keys.
+func boundTokens(b string) ([]boundToken, error) {
+	var tokens []boundToken
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, boundToken{V: string(c)})
+			i++
+		case c == '\'':
+			var v strings.Builder
+			for i++; ; i++ {
+				if i >= len(b) {
+					return nil, fmt.Errorf("unterminated literal in partition bound %q", b)
+				}
+				if b[i] == '\'' {
+					if i+1 < len(b) && b[i+1] == '\'' {
+						v.WriteByte('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				v.WriteByte(b[i])
+			}
+			tokens = append(tokens, boundToken{V: v.String(), Quoted: true})
+		case strings.HasPrefix(b[i:], "::"):
+			// Skip the type name, which may contain spaces and modifiers.
+			for depth := 0; i < len(b) && (depth > 0 || b[i] != ',' && b[i] != ')'); i++ {
+				switch b[i] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+		default:
+			j := strings.IndexAny(b[i:], " \t\n\r(),':")
+			if j == -1 {
+				j = len(b) - i
+			}
+			if j == 0 {
+				return nil, fmt.Errorf("unexpected character %q in partition bound %q", b[i], b)
+			}
+			tokens = append(tokens, boundToken{V: strings.ToUpper(b[i : i+j])})
+			i += j
+		}
+	}
+	return tokens, nil
+}
+
+// boundSpec is a parsed partition bound specification.
+type boundSpec struct {
+	kind     string       // DEFAULT, RANGE, LIST or HASH.
+	from, to []boundToken // RANGE bounds.
+	values   []boundToken // LIST values.
+	mod, rem int64        // HASH modulus and remainder.
+}
+
+// parseBound parses the partition bound specification. It reports
+// false if the bound cannot be parsed, e.g. it contains expressions.
+func parseBound(b string) (*boundSpec, bool) {
+	tokens, err := boundTokens(b)
+	if err != nil {
+		return nil, false
+	}
+	// list parses a parenthesized list of single-token values.
+	list := func() ([]boundToken, bool) {
+		if len(tokens) == 0 || tokens[0].V != "(" {
+			return nil, false
+		}
+		var values []boundToken
+		for i := 1; i < len(tokens); i += 2 {
+			if t := tokens[i]; !t.Quoted && (t.V == "(" || t.V == ")") || i+1 >= len(tokens) {
+				return nil, false
+			}
+			values = append(values, tokens[i])
+			switch sep := tokens[i+1]; {
+			case sep.Quoted:
+				return nil, false
+			case sep.V == ")":
+				tokens = tokens[i+2:]
+				return values, true
+			case sep.V != ",":
+				return nil, false
+			}
+		}
+		return nil, false
+	}
+	keyword := func(words ...string) bool {
+		if len(tokens) < len(words) {
+			return false
+		}
+		for i, w := range words {
+			if tokens[i].Quoted || tokens[i].V != w {
+				return false
+			}
+		}
+		tokens = tokens[len(words):]
+		return true
+	}
+	spec := &boundSpec{}
+	switch {
+	case keyword("DEFAULT"):
+		spec.kind = "DEFAULT"
+	case keyword("FOR", "VALUES", "FROM"):
+		from, ok := list()
+		if !ok || !keyword("TO") {
+			return nil, false
+		}
+		to, ok := list()
+		if !ok {
+			return nil, false
+		}
+		spec.kind, spec.from, spec.to = PartitionTypeRange, from, to
+	case keyword("FOR", "VALUES", "IN"):
+		values, ok := list()
+		if !ok {
+			return nil, false
+		}
+		spec.kind, spec.values = PartitionTypeList, values
+	case keyword("FOR", "VALUES", "WITH", "(", "MODULUS"):
+		// Hash bounds are written as (MODULUS m, REMAINDER r).
+		if len(tokens) != 5 || tokens[1].V != "," || tokens[2].V != "REMAINDER" || tokens[4].V != ")" {
+			return nil, false
+		}
+		mod, err1 := strconv.ParseInt(tokens[0].V, 10, 64)
+		rem, err2 := strconv.ParseInt(tokens[3].V, 10, 64)
+		if err1 != nil || err2 != nil || mod <= 0 {
+			return nil, false
+		}
+		spec.kind, spec.mod, spec.rem, tokens = PartitionTypeHash, mod, rem, nil
+	default:
+		return nil, false
+	}
+	return spec, len(tokens) == 0
+}
+
+// checkPartitionBounds returns an error if the bounds of two partitions overlap.
+// Bounds that cannot be parsed (e.g. bounds with expressions) are not checked.
+func checkPartitionBounds(table string, parts []*PartitionBound) error {
+	specs := make([]*boundSpec, len(parts))
+	for i, p := range parts {
+		specs[i], _ = parseBound(p.Bound)
+	}
+	for i := range parts {
+		for j := i + 1; j < len(parts); j++ {
+			if specs[i] != nil && specs[j] != nil && specs[i].overlaps(specs[j]) {
+				return fmt.Errorf("postgres: partition %q of table %q overlaps partition %q", parts[j].Name, table, parts[i].Name)
+			}
+		}
+	}
+	return nil
+}
+
+// overlaps reports if the two partition bounds overlap.
+func (b *boundSpec) overlaps(o *boundSpec) bool {
+	if b.kind != o.kind {
+		return false
+	}
+	switch b.kind {
+	case "DEFAULT":
+		return true
+	case PartitionTypeRange:
+		// Ranges include their lower bound and exclude their upper bound.
+		return compareBounds(b.from, o.to) < 0 && compareBounds(o.from, b.to) < 0
+	case PartitionTypeList:
+		for _, v1 := range b.values {
+			for _, v2 := range o.values {
+				if compareBoundValues(v1, v2) == 0 {
+					return true
+				}
+			}
+		}
+	case PartitionTypeHash:
+		g := b.mod
+		for m := o.mod; m != 0; {
+			g, m = m, g%m
+		}
+		return b.rem%g == o.rem%g
+	}
+	return false
+}
+
+// compareBounds compares two range bounds (of multi-column keys) lexicographically.
+func compareBounds(b1, b2 []boundToken) int {
+	for i := 0; i < len(b1) && i < len(b2); i++ {
+		if c := compareBoundValues(b1[i], b2[i]); c != 0 {
+			return c
+		}
+	}
+	return len(b1) - len(b2)
+}
+
+// compareBoundValues compares two bound values. Numbers are compared by their
+// values, and other literals (e.g. dates in ISO format) are compared textually.
+func compareBoundValues(v1, v2 boundToken) int {
+	rank := func(v boundToken) int {
+		switch {
+		case v.Quoted:
+			return 0
+		case v.V == "MINVALUE":
+			return -1
+		case v.V == "MAXVALUE":
+			return 1
+		}
+		return 0
+	}
+	if r1, r2 := rank(v1), rank(v2); r1 != 0 || r2 != 0 {
+		return r1 - r2
+	}
+	f1, err1 := strconv.ParseFloat(v1.V, 64)
+	f2, err2 := strconv.ParseFloat(v2.V, 64)
+	switch {
+	case err1 != nil || err2 != nil:
+		return strings.Compare(v1.V, v2.V)
+	case f1 < f2:
+		return -1
+	case f1 > f2:
+		return 1
+	}
+	return 0
+}
+
+// indexStorageParams returns the index storage parameters from the attributes
+// in case it is there, and it is not the default.
+func indexStorageParams(attrs []schema.Attr) (*IndexStorageParams, bool) {
+	s := &IndexStorageParams{}
+	if !sqlx.Has(attrs, s) {
+		return nil, false
+	}
+	// An explicit fillfactor that equals the default of the
+	// index method is normalized to the zero value (default).
+	t := &IndexType{T: IndexTypeBTree}
+	sqlx.Has(attrs, t)
+	if d, ok := indexFillFactors[strings.ToUpper(t.T)]; ok && s.FillFactor == d {
+		s.FillFactor = 0
+	}
+	if s.FillFactor == 0 && !s.AutoSummarize && (s.PagesPerRange == 0 || s.PagesPerRange == defaultPagePerRange) && s.fastUpdate() && s.PendingListLimit == 0 {
+		return nil, false
+	}
+	return s, true
+}
+
+// fastUpdate reports if the fastupdate storage parameter is enabled.
+func (s *IndexStorageParams) fastUpdate() bool {
+	return s.FastUpdate == nil || *s.FastUpdate
+}
+
+// pagesPerRange returns the pages_per_range storage parameter.
+func (s *IndexStorageParams) pagesPerRange() int64 {
+	if s.PagesPerRange == 0 {
+		return defaultPagePerRange
+	}
+	return s.PagesPerRange
+}
+
+// equal reports if the two storage parameters are equal after normalization.
+func (s *IndexStorageParams) equal(o *IndexStorageParams) bool {
+	return s.AutoSummarize == o.AutoSummarize && s.pagesPerRange() == o.pagesPerRange() && s.FillFactor == o.FillFactor && s.ginEqual(o)
+}
+
+// ginEqual reports if the GIN storage parameters of the two are equal.
+func (s *IndexStorageParams) ginEqual(o *IndexStorageParams) bool {
+	return s.fastUpdate() == o.fastUpdate() && s.PendingListLimit == o.PendingListLimit
+}
+
+// tableStorageParams returns the table storage parameters, if exist.
+// The legacy "oids=false" parameter is the default, and therefore, omitted.
+func tableStorageParams(attrs []schema.Attr) (*TableStorageParams, bool) {
+	p := &TableStorageParams{}
+	if !sqlx.Has(attrs, p) {
+		return nil, false
+	}
+	if !p.oids() {
+		p = p.withoutOIDs()
+	}
+	if len(p.Params) == 0 {
+		return nil, false
+	}
+	return p, true
+}
+
+// storageParamsChange returns the schema change for migrating
+// the table storage parameters, or nil if they were not changed.
+func (d *diff) storageParamsChange(from, to []schema.Attr) schema.Change {
+	fromP, ok1 := tableStorageParams(from)
+	toP, ok2 := tableStorageParams(to)
+	// Tables cannot be defined WITH OIDS on servers that do not support them.
+	// Hence, the option is ignored instead of producing endless changes.
+	if !d.supportsOIDs() {
+		if ok1 {
+			fromP = fromP.withoutOIDs()
+			ok1 = len(fromP.Params) > 0
+		}
+		if ok2 {
+			toP = toP.withoutOIDs()
+			ok2 = len(toP.Params) > 0
+		}
+	}
+	switch {
+	case !ok1 && !ok2:
+		return nil
+	case !ok1 && d.storageParamsEqual(&TableStorageParams{}, toP):
+		return nil
+	case !ok1:
+		return &schema.AddAttr{A: toP}
+	case !ok2 && d.storageParamsEqual(fromP, &TableStorageParams{}):
+		return nil
+	case !ok2:
+		return &schema.DropAttr{A: fromP}
+	case !d.storageParamsEqual(fromP, toP):
+		return &schema.ModifyAttr{From: fromP, To: toP}
+	}
+	return nil
+}
+
+// storageParamsEqual reports if the two storage parameters are equal after normalization.
+func (c *conn) storageParamsEqual(from, to *TableStorageParams) bool {
+	for _, ps := range [][]struct{ N, V string }{from.Params, to.Params} {
+		for _, p := range ps {
+			if !c.storageParamEqual(from, to, p.N) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// storageParamEqual reports if the storage parameter has the same value in both, where
+// absent autovacuum parameters are equal to their defaults, and values are compared by
+// their numeric or boolean values, if possible. e.g. 0.20 and 0.2, or on and true.
+func (c *conn) storageParamEqual(from, to *TableStorageParams, name string) bool {
+	v1, ok1 := from.Value(name)
+	v2, ok2 := to.Value(name)
+	if d, ok := c.storageParamDefault(name); ok {
+		if !ok1 {
+			v1, ok1 = d, true
+		}
+		if !ok2 {
+			v2, ok2 = d, true
+		}
+	}
+	return ok1 == ok2 && storageValueEqual(v1, v2)
+}
+
+// storageValueEqual reports if the two storage parameter values are equal.
+func storageValueEqual(v1, v2 string) bool {
+	if strings.EqualFold(v1, v2) {
+		return true
+	}
+	if f1, err := strconv.ParseFloat(v1, 64); err == nil {
+		f2, err := strconv.ParseFloat(v2, 64)
+		return err == nil && f1 == f2
+	}
+	b1, err1 := parseBool(v1)
+	b2, err2 := parseBool(v2)
+	return err1 == nil && err2 == nil && b1 == b2
+}
+
+// autovacuumDefaults holds the default values of the per-table autovacuum storage
+// parameters. The parameters of the TOAST table share the same defaults.
+// https://www.postgresql.org/docs/current/runtime-config-autovacuum.html
+var autovacuumDefaults = map[string]string{
+	"autovacuum_enabled":                    "true",
+	"autovacuum_vacuum_threshold":           "50",
+	"autovacuum_vacuum_scale_factor":        "0.2",
+	"autovacuum_vacuum_insert_threshold":    "1000",
+	"autovacuum_vacuum_insert_scale_factor": "0.2",
+	"autovacuum_analyze_threshold":          "50",
+	"autovacuum_analyze_scale_factor":       "0.1",
+	"autovacuum_vacuum_cost_limit":          "-1",
+	"autovacuum_freeze_min_age":             "50000000",
+	"autovacuum_freeze_max_age":             "200000000",
+	"autovacuum_freeze_table_age":           "150000000",
+	"autovacuum_multixact_freeze_min_age":   "5000000",
+	"autovacuum_multixact_freeze_max_age":   "400000000",
+	"autovacuum_multixact_freeze_table_age": "150000000",
+	"log_autovacuum_min_duration":           "-1",
+}
+
+// storageParamDefault returns the default value of the given table storage parameter.
+func (c *conn) storageParamDefault(name string) (string, bool) {
+	name = strings.ToLower(name)
+	switch strings.TrimPrefix(name, "toast.") {
+	// Unlike indexes, tables are fully packed by default.
+	// TOAST tables do not support the fillfactor parameter.
+	case "fillfactor":
+		return "100", name == "fillfactor"
+	// The default was lowered from 20ms to 2ms in PostgreSQL 12.
+	case "autovacuum_vacuum_cost_delay":
+		if c.version != 0 && c.version < 12_00_00 {
+			return "20", true
+		}
+		return "2", true
+	}
+	name = strings.TrimPrefix(name, "toast.")
+	v, ok := autovacuumDefaults[name]
+	return v, ok
+}
+
+// objectName returns the name of the given object.
+func objectName(o schema.Object) string {
+	switch o := o.(type) {
+	case *Collation:
+		return o.Name
+	case *TextSearchConfiguration:
+		return o.Name
+	case *DefaultPrivilege:
+		// Default privileges are identified by the role that creates
+		// the objects, their type and the role that receives them.
+		return strings.Join([]string{o.Owner, strings.ToUpper(o.ObjType), o.Grantee}, ":")
+	case *TypeOwner:
+		return o.Name
+	case *EventTrigger:
+		return o.Name
+	case *Statistics:
+		return o.Name
+	case *Rule:
+		// Rule names are unique per table.
+		return o.Table.Name + "." + o.Name
+	case *Publication:
+		return o.Name
+	case *Subscription:
+		return o.Name
+	case *Cast:
+		// Casts are identified by their source and target types.
+		return castType(o.Source) + " AS " + castType(o.Target)
+	case *AccessMethod:
+		return o.Name
+	}
+	return ""
+}
+
+// objectByName returns the object from the list with the
+// same type and name as the given object.
+func objectByName(objs []schema.Object, o schema.Object) (schema.Object, bool) {
+	for _, o1 := range objs {
+		if reflect.TypeOf(o1) == reflect.TypeOf(o) && objectName(o1) == objectName(o) {
+			return o1, true
+		}
+	}
+	return nil, false
+}
+
+// objectEqual reports if the two objects (of the same type) are defined the same.
+func objectEqual(o1, o2 schema.Object) bool {
+	switch o1 := o1.(type) {
+	case *Collation:
+		return collationEqual(o1, o2.(*Collation))
+	case *TextSearchConfiguration:
+		o2 := o2.(*TextSearchConfiguration)
+		return o1.parser() == o2.parser() && len(tsMappingsDiff(o1, o2)) == 0
+	case *DefaultPrivilege:
+		grant, revoke := privilegesDiff(o1, o2.(*DefaultPrivilege))
+		return len(grant) == 0 && len(revoke) == 0
+	case *TypeOwner:
+		return o1.Owner == o2.(*TypeOwner).Owner
+	case *EventTrigger:
+		o2 := o2.(*EventTrigger)
+		return eventTriggerDefEqual(o1, o2) && o1.state() == o2.state()
+	case *Statistics:
+		return statisticsEqual(o1, o2.(*Statistics))
+	case *Rule:
+		return ruleEqual(o1, o2.(*Rule))
+	case *Publication:
+		o2 := o2.(*Publication)
+		return o1.AllTables == o2.AllTables && publicationParamsEqual(o1, o2) && len(publicationTablesDiff(o1, o2)) == 0 && len(publicationTablesDiff(o2, o1)) == 0
+	case *Cast:
+		o2 := o2.(*Cast)
+		return castFunc(o1.Func) == castFunc(o2.Func) && o1.InOut == o2.InOut && o1.context() == o2.context()
+	case *AccessMethod:
+		o2 := o2.(*AccessMethod)
+		return strings.EqualFold(o1.Type, o2.Type) && castFunc(o1.Handler) == castFunc(o2.Handler)
+	case *Subscription:
+		o2 := o2.(*Subscription)
+		return !subscriptionConnChanged(o1, o2) && sqlx.ValuesEqual(sortedCopy(o1.Publications), sortedCopy(o2.Publications)) &&
+			o1.Disabled == o2.Disabled && len(subscriptionOptionsDiff(o1, o2)) == 0
+	}
+	return true
+}
+
+// publishOps returns the given publication operations in their canonical order.
+// Publishing all operations is the default, and therefore, normalized to nil.
+func publishOps(ops []string) []string {
+	var canonical []string
+	for _, op := range []string{"insert", "update", "delete", "truncate"} {
+		for _, o := range ops {
+			if strings.EqualFold(o, op) {
+				canonical = append(canonical, op)
+				break
 			}
-			t2, err := FormatType(toT.Type)
-			if err != nil {
-				return false, err
+		}
+	}
+	if len(canonical) == 4 {
+		return nil
+	}
+	return canonical
+}
+
+// publicationParamsEqual reports if the two publications are defined with the same parameters.
+func publicationParamsEqual(p1, p2 *Publication) bool {
+	return p1.ViaRoot == p2.ViaRoot && sqlx.ValuesEqual(publishOps(p1.Publish), publishOps(p2.Publish))
+}
+
+// publicationTablesDiff returns the tables of the first publication that are not included in the second.
+func publicationTablesDiff(p1, p2 *Publication) []*schema.Table {
+	var diff []*schema.Table
+Tables:
+	for _, t1 := range p1.Tables {
+		for _, t2 := range p2.Tables {
+			if sameTable(t1, t2) {
+				continue Tables
 			}
-			// Same underlying type.
-			changed = t1 != t2
 		}
-	default:
-		return false, &sqlx.UnsupportedTypeError{Type: fromT}
+		diff = append(diff, t1)
 	}
-	return changed, nil
+	return diff
 }
 
-// valuesEqual reports if the DEFAULT values x and y
-// equal according to the database engine.
-func (d *diff) valuesEqual(x, y string) (bool, error) {
-	var b bool
-	// The DEFAULT expressions are safe to be inlined in the SELECT
-	// statement same as we inline them in the CREATE TABLE statement.
-	rows, err := d.QueryContext(context.Background(), fmt.Sprintf("SELECT %s = %s", x, y))
-	if err != nil {
-		return false, err
+// sameTable reports if the two tables have the same name and schema (if known).
+func sameTable(t1, t2 *schema.Table) bool {
+	return t1.Name == t2.Name && (t1.Schema == nil || t2.Schema == nil || t1.Schema.Name == t2.Schema.Name)
+}
+
+// subscriptionConnChanged reports if the connection string of the subscription was changed.
+// The inspected state does not hold connection strings, and therefore, they are compared
+// only if both states define them.
+func subscriptionConnChanged(from, to *Subscription) bool {
+	return from.Conn != "" && to.Conn != "" && from.Conn != to.Conn
+}
+
+// subscriptionOptionsDiff returns the options that are defined by the desired
+// subscription and differ from the current ones. The rest are left unchanged.
+func subscriptionOptionsDiff(from, to *Subscription) []struct{ N, V string } {
+	var diff []struct{ N, V string }
+	for _, o2 := range to.Options {
+		if v, ok := subscriptionOption(from, o2.N); !ok || !strings.EqualFold(v, o2.V) {
+			diff = append(diff, o2)
+		}
 	}
-	if err := sqlx.ScanOne(rows, &b); err != nil {
-		return false, err
+	return diff
+}
+
+// subscriptionOption returns the value of the given subscription option.
+func subscriptionOption(s *Subscription, name string) (string, bool) {
+	for _, o := range s.Options {
+		if strings.EqualFold(o.N, name) {
+			return o.V, true
+		}
 	}
-	return b, nil
+	return "", false
 }
 
-// Default IDENTITY attributes.
-const (
-	defaultIdentityGen  = "BY DEFAULT"
-	defaultSeqStart     = 1
-	defaultSeqIncrement = 1
-)
+// sortedCopy returns a sorted copy of the given strings.
+func sortedCopy(s []string) []string {
+	s = append([]string(nil), s...)
+	sort.Strings(s)
+	return s
+}
 
-// identityChanged reports if one of the identity attributes was changed.
-func identityChanged(from, to []schema.Attr) bool {
-	i1, ok1 := identity(from)
-	i2, ok2 := identity(to)
-	if !ok1 && !ok2 || ok1 != ok2 {
-		return ok1 != ok2
+// statisticsEqual reports if the two extended statistics are defined the same.
+// The order of the columns is ignored, as PostgreSQL stores them by their position.
+func statisticsEqual(s1, s2 *Statistics) bool {
+	if s1.Table.Name != s2.Table.Name || !sqlx.ValuesEqual(s1.kinds(), s2.kinds()) {
+		return false
 	}
-	return i1.Generation != i2.Generation || i1.Sequence.Start != i2.Sequence.Start || i1.Sequence.Increment != i2.Sequence.Increment
+	c1, c2 := columnNames(s1.Columns), columnNames(s2.Columns)
+	sort.Strings(c1)
+	sort.Strings(c2)
+	return sqlx.ValuesEqual(c1, c2)
 }
 
-func identity(attrs []schema.Attr) (*Identity, bool) {
-	i := &Identity{}
-	if !sqlx.Has(attrs, i) {
-		return nil, false
+// ruleEqual reports if the two rewrite rules are defined the same. The condition and
+// the actions are compared textually, ignoring whitespace and the trailing semicolon.
+func ruleEqual(r1, r2 *Rule) bool {
+	return r1.Table.Name == r2.Table.Name && strings.EqualFold(r1.Event, r2.Event) && r1.Instead == r2.Instead &&
+		ruleText(r1.Where) == ruleText(r2.Where) && ruleText(r1.Actions) == ruleText(r2.Actions)
+}
+
+// ruleText returns the normalized text of a rule condition or actions.
+func ruleText(s string) string {
+	return strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimSpace(s), ";")), " ")
+}
+
+// kinds returns the statistics kinds, sorted by name. An empty
+// list is expanded to all kinds that are computed by default.
+func (s *Statistics) kinds() []string {
+	if len(s.Kinds) == 0 {
+		return []string{"dependencies", "mcv", "ndistinct"}
 	}
-	if i.Generation == "" {
-		i.Generation = defaultIdentityGen
+	ks := make([]string, len(s.Kinds))
+	for i := range s.Kinds {
+		ks[i] = strings.ToLower(s.Kinds[i])
 	}
-	if i.Sequence == nil {
-		i.Sequence = &Sequence{Start: defaultSeqStart, Increment: defaultSeqIncrement}
-		return i, true
+	sort.Strings(ks)
+	return ks
+}
+
+// castType returns the canonical form of the source or target type
+// of a cast, as it is printed by the database. e.g. int4 is integer.
+func castType(t string) string {
+	t = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), "pg_catalog.")
+	if typ, err := ParseType(t); err == nil {
+		if f, err := FormatType(typ); err == nil {
+			return f
+		}
 	}
-	if i.Sequence.Start == 0 {
-		i.Sequence.Start = defaultSeqStart
+	return t
+}
+
+// castFunc returns the canonical form of the function signature of a cast.
+// i.e. without whitespaces and with the argument types in their canonical form.
+func castFunc(f string) string {
+	name, args, ok := strings.Cut(strings.ToLower(f), "(")
+	if !ok {
+		return strings.TrimSpace(name)
 	}
-	if i.Sequence.Increment == 0 {
-		i.Sequence.Increment = defaultSeqIncrement
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSpace(args), ")"), ",")
+	for i := range parts {
+		parts[i] = castType(parts[i])
 	}
-	return i, true
+	return strings.TrimSpace(name) + "(" + strings.Join(parts, ",") + ")"
 }
 
-// formatPartition returns the string representation of the
-// partition key according to the PostgreSQL format/grammar.
-func formatPartition(p Partition) (string, error) {
-	b := &sqlx.Builder{QuoteChar: '"'}
-	b.P("PARTITION BY")
-	switch t := strings.ToUpper(p.T); t {
-	case PartitionTypeRange, PartitionTypeList, PartitionTypeHash:
-		b.P(t)
-	default:
-		return "", fmt.Errorf("unknown partition type: %q", t)
+// eventTriggerDefEqual reports if the two event triggers have the same definition
+// (event, filter tags and function), ignoring their firing state that can be altered.
+func eventTriggerDefEqual(e1, e2 *EventTrigger) bool {
+	if !strings.EqualFold(e1.Event, e2.Event) || e1.Func != e2.Func || len(e1.Tags) != len(e2.Tags) {
+		return false
 	}
-	if len(p.Parts) == 0 {
-		return "", errors.New("missing parts for partition key")
+	// The schema of the function may be omitted by the desired state.
+	if e1.FuncSchema != "" && e2.FuncSchema != "" && e1.FuncSchema != e2.FuncSchema {
+		return false
 	}
-	b.Wrap(func(b *sqlx.Builder) {
-		b.MapComma(p.Parts, func(i int, b *sqlx.Builder) {
-			switch k := p.Parts[i]; {
-			case k.C != nil:
-				b.Ident(k.C.Name)
-			case k.X != nil:
-				b.P(sqlx.MayWrap(k.X.(*schema.RawExpr).X))
+	tags := make(map[string]bool, len(e1.Tags))
+	for _, t := range e1.Tags {
+		tags[strings.ToUpper(t)] = true
+	}
+	for _, t := range e2.Tags {
+		if !tags[strings.ToUpper(t)] {
+			return false
+		}
+	}
+	return true
+}
+
+// privilegesDiff returns the privileges that should be granted and revoked
+// for migrating the default privileges from one state to the other.
+func privilegesDiff(from, to *DefaultPrivilege) (grant, revoke []string) {
+	has := func(ps []string, p string) bool {
+		for i := range ps {
+			if strings.EqualFold(ps[i], p) {
+				return true
 			}
-		})
-	})
-	return b.String(), nil
+		}
+		return false
+	}
+	for _, p := range to.Privileges {
+		if !has(from.Privileges, p) {
+			grant = append(grant, strings.ToUpper(p))
+		}
+	}
+	for _, p := range from.Privileges {
+		if !has(to.Privileges, p) {
+			revoke = append(revoke, strings.ToUpper(p))
+		}
+	}
+	return grant, revoke
 }
 
-// indexStorageParams returns the index storage parameters from the attributes
-// in case it is there, and it is not the default.
-func indexStorageParams(attrs []schema.Attr) (*IndexStorageParams, bool) {
-	s := &IndexStorageParams{}
-	if !sqlx.Has(attrs, s) {
-		return nil, false
+// tsMappingsDiff returns the token mappings that were added, changed or dropped between
+// the two configurations. Dropped mappings are returned with an empty dictionaries list.
+func tsMappingsDiff(from, to *TextSearchConfiguration) []*TextSearchMapping {
+	var (
+		changed []*TextSearchMapping
+		fromM   = make(map[string]*TextSearchMapping, len(from.Mappings))
+		toM     = make(map[string]*TextSearchMapping, len(to.Mappings))
+	)
+	for _, m := range from.Mappings {
+		fromM[m.Token] = m
 	}
-	if !s.AutoSummarize && (s.PagesPerRange == 0 || s.PagesPerRange == defaultPagePerRange) {
-		return nil, false
+	for _, m := range to.Mappings {
+		toM[m.Token] = m
+		if m1, ok := fromM[m.Token]; !ok || !sqlx.ValuesEqual(m1.Dicts, m.Dicts) {
+			changed = append(changed, m)
+		}
 	}
-	return s, true
+	for _, m := range from.Mappings {
+		if _, ok := toM[m.Token]; !ok {
+			changed = append(changed, &TextSearchMapping{Token: m.Token})
+		}
+	}
+	return changed
+}
+
+// parser returns the parser name of the configuration,
+// without the default schema qualifier.
+func (c *TextSearchConfiguration) parser() string {
+	if c.Parser == "" {
+		return "default"
+	}
+	return strings.TrimPrefix(c.Parser, "pg_catalog.")
+}
+
+// collations returns the collation objects from the given list.
+func collations(objs []schema.Object) []*Collation {
+	var cs []*Collation
+	for _, o := range objs {
+		if c, ok := o.(*Collation); ok {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// collationEqual reports if the two collations are defined the same.
+func collationEqual(c1, c2 *Collation) bool {
+	p1, p2 := c1.Provider, c2.Provider
+	if p1 == "" {
+		p1 = "libc"
+	}
+	if p2 == "" {
+		p2 = "libc"
+	}
+	return p1 == p2 && c1.Nondeterministic == c2.Nondeterministic &&
+		c1.lcCollate() == c2.lcCollate() && c1.lcCtype() == c2.lcCtype()
+}
+
+func (c *Collation) lcCollate() string {
+	if c.LCCollate != "" {
+		return c.LCCollate
+	}
+	return c.Locale
+}
+
+func (c *Collation) lcCtype() string {
+	if c.LCCtype != "" {
+		return c.LCCtype
+	}
+	return c.Locale
 }
 
 // indexIncludeChanged reports if the INCLUDE attribute clause was changed.
@@ -408,3 +2309,297 @@ func trimCast(s string) string {
 	}
 	return s[:i]
 }
+
+// Risk describes the risk level of applying a schema change on existing data.
+type Risk uint8
+
+// List of risk levels returned by ClassifyColumnChange.
+const (
+	// RiskNone indicates the change is lossless.
+	RiskNone Risk = iota
+	// RiskLossy indicates the change may lose data or fail on existing
+	// data. For example, narrowing a column type or dropping its default.
+	RiskLossy
+	// RiskDestructive indicates the change destroys existing data. For
+	// example, removing enum values or converting to an unrelated type.
+	RiskDestructive
+)
+
+// String implements the fmt.Stringer interface.
+func (r Risk) String() string {
+	switch r {
+	case RiskNone:
+		return "lossless"
+	case RiskLossy:
+		return "lossy"
+	case RiskDestructive:
+		return "destructive"
+	default:
+		return fmt.Sprintf("Risk(%d)", r)
+	}
+}
+
+// ClassifyColumnChange reports the risk of modifying a column from one
+// definition to the other, where kind holds the changes that were detected
+// by the differ (see schema.ModifyColumn).
+func ClassifyColumnChange(from, to *schema.Column, kind schema.ChangeKind) Risk {
+	var (
+		r    = RiskNone
+		mark = func(nr Risk) {
+			if nr > r {
+				r = nr
+			}
+		}
+	)
+	// Only dropping the generation expression keeps the existing
+	// data. Other changes require recreating the column.
+	if kind.Is(schema.ChangeGenerated) && sqlx.Has(to.Attrs, &schema.GeneratedExpr{}) {
+		mark(RiskDestructive)
+	}
+	if kind.Is(schema.ChangeType) {
+		mark(typeChangeRisk(from, to))
+	}
+	if kind.Is(schema.ChangeNull) && from.Type.Null && !to.Type.Null {
+		mark(RiskLossy)
+	}
+	if kind.Is(schema.ChangeDefault) && from.Default != nil && to.Default == nil {
+		mark(RiskLossy)
+	}
+	return r
+}
+
+// typeChangeRisk reports the risk of changing the column type.
+func typeChangeRisk(from, to *schema.Column) Risk {
+	switch changed, err := (&diff{}).typeChanged(from, to); {
+	case err != nil:
+		return RiskLossy
+	case !changed:
+		return RiskNone
+	}
+	fromT, toT := from.Type.Type, to.Type.Type
+	if n1, ok := intSize(fromT); ok {
+		if n2, ok := intSize(toT); ok {
+			return widened(n1 <= n2)
+		}
+		if d, ok := toT.(*schema.DecimalType); ok {
+			return widened(d.Precision == 0 || intDigits[n1] <= d.Precision-d.Scale)
+		}
+	}
+	switch fromT := fromT.(type) {
+	case *schema.StringType:
+		if toT, ok := toT.(*schema.StringType); ok && !isTextType(toT) {
+			f1, err1 := FormatType(fromT)
+			f2, err2 := FormatType(toT)
+			if err1 != nil || err2 != nil || isTextType(fromT) {
+				return RiskLossy
+			}
+			// Both types are bounded. Converting CHARACTER to CHARACTER VARYING trims
+			// trailing spaces, which are insignificant for CHARACTER types, but the
+			// other way around pads the values.
+			var (
+				n1, n2   = fromT.Size, toT.Size
+				fromChar = strings.HasPrefix(f1, TypeCharacter+"(")
+				toChar   = strings.HasPrefix(f2, TypeCharacter+"(")
+			)
+			if n1 == 0 && fromChar {
+				n1 = 1
+			}
+			if n2 == 0 && toChar {
+				n2 = 1
+			}
+			return widened(n1 <= n2 && (fromChar || !toChar))
+		}
+	case *schema.DecimalType:
+		if toT, ok := toT.(*schema.DecimalType); ok {
+			return widened(toT.Precision == 0 || fromT.Precision != 0 && toT.Scale >= fromT.Scale && toT.Precision-toT.Scale >= fromT.Precision-fromT.Scale)
+		}
+	case *schema.FloatType:
+		if toT, ok := toT.(*schema.FloatType); ok {
+			f, err := FormatType(toT)
+			return widened(err == nil && f == TypeDouble)
+		}
+	case *schema.TimeType:
+		if toT, ok := toT.(*schema.TimeType); ok && strings.EqualFold(fromT.T, toT.T) {
+			return widened(toT.Precision == nil || fromT.Precision != nil && *fromT.Precision <= *toT.Precision)
+		}
+	case *schema.EnumType:
+		if toT, ok := toT.(*schema.EnumType); ok {
+			for _, v := range fromT.Values {
+				if !contains(toT.Values, v) {
+					return RiskDestructive
+				}
+			}
+			return RiskNone
+		}
+	}
+	// Converting to an unbounded string type keeps the textual representation.
+	if t, ok := toT.(*schema.StringType); ok && isTextType(t) {
+		return RiskNone
+	}
+	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
+		return RiskDestructive
+	}
+	return RiskLossy
+}
+
+// RequiresRewrite reports if changing the type of a column from one definition
+// to the other rewrites the table (and rebuilds its indexes). Changes between
+// binary-coercible types, or that only relax the type modifiers (e.g. increasing
+// the length of a varchar), are applied without rewriting the table.
+func RequiresRewrite(from, to *schema.Column) bool {
+	switch changed, err := (&diff{}).typeChanged(from, to); {
+	case err != nil:
+		return true
+	case !changed:
+		return false
+	}
+	switch fromT := from.Type.Type.(type) {
+	case *schema.StringType:
+		toT, ok := to.Type.Type.(*schema.StringType)
+		if !ok || !isVarCharType(fromT) || !isVarCharType(toT) {
+			return true
+		}
+		// Unbounded types (text or varchar) accept values of any length.
+		return !isTextType(toT) && (isTextType(fromT) || toT.Size < fromT.Size)
+	case *schema.DecimalType:
+		toT, ok := to.Type.Type.(*schema.DecimalType)
+		return !ok || toT.Precision != 0 && (fromT.Precision == 0 || toT.Scale != fromT.Scale || toT.Precision < fromT.Precision)
+	case *schema.TimeType:
+		toT, ok := to.Type.Type.(*schema.TimeType)
+		return !ok || !strings.EqualFold(fromT.T, toT.T) || !relaxedPrecision(fromT.Precision, toT.Precision)
+	case *IntervalType:
+		// Removing the field restriction does not truncate existing values.
+		toT, ok := to.Type.Type.(*IntervalType)
+		return !ok || toT.F != "" && !strings.EqualFold(fromT.F, toT.F) || !relaxedPrecision(fromT.Precision, toT.Precision)
+	case *BitType:
+		toT, ok := to.Type.Type.(*BitType)
+		return !ok || !strings.EqualFold(fromT.T, TypeBitVar) || !strings.EqualFold(toT.T, TypeBitVar) || toT.Len != 0 && (fromT.Len == 0 || toT.Len < fromT.Len)
+	case *NetworkType:
+		// CIDR values are valid INET values.
+		toT, ok := to.Type.Type.(*NetworkType)
+		return !ok || fromT.T != TypeCIDR || toT.T != TypeInet
+	case *XMLType:
+		// XML values are stored as text.
+		toT, ok := to.Type.Type.(*schema.StringType)
+		return !ok || !isVarCharType(toT) || !isTextType(toT)
+	}
+	return true
+}
+
+// TypeCompatible reports if PostgreSQL can change the type of a column from one
+// definition to the other without a USING clause, and without failing on existing
+// data. That is, the types are binary-coercible, or the new type safely widens the
+// old one and an implicit or assignment cast exists between them.
+func TypeCompatible(from, to *schema.Column) (bool, error) {
+	changed, err := (&diff{}).typeChanged(from, to)
+	switch {
+	case err != nil:
+		return false, err
+	case !changed, !RequiresRewrite(from, to):
+		return true, nil
+	}
+	switch fromT := from.Type.Type.(type) {
+	case *BitType:
+		toT, ok := to.Type.Type.(*BitType)
+		return ok && !bitCastRequired(fromT, toT), nil
+	case *schema.EnumType:
+		// Values of one enum type cannot be cast to another enum type.
+		if toT, ok := to.Type.Type.(*schema.EnumType); ok && !strings.EqualFold(fromT.T, toT.T) {
+			return false, nil
+		}
+	}
+	return typeChangeRisk(from, to) == RiskNone, nil
+}
+
+// castRequired reports if changing a column type from one definition to the
+// other should be done with an explicit USING cast.
+func castRequired(from, to schema.Type) bool {
+	switch fromT := from.(type) {
+	case *BitType:
+		// Bit strings are padded or truncated only by explicit casts.
+		toT, ok := to.(*BitType)
+		return ok && bitCastRequired(fromT, toT)
+	case *schema.JSONType:
+		// Switching between the textual and binary JSON representations
+		// requires the stored values to be re-parsed.
+		toT, ok := to.(*schema.JSONType)
+		return ok && !strings.EqualFold(fromT.T, toT.T)
+	}
+	return false
+}
+
+// bitCastRequired reports if changing a bit string type from one definition to the
+// other requires an explicit cast. Unlike explicit casts, which pad or truncate the
+// values, the implicit conversion fails on values that do not fit the new length.
+func bitCastRequired(from, to *BitType) bool {
+	fromL, toL := bitLen(from), bitLen(to)
+	if !strings.EqualFold(to.T, TypeBitVar) {
+		return fromL != toL || strings.EqualFold(from.T, TypeBitVar)
+	}
+	return toL != 0 && (fromL == 0 || fromL > toL)
+}
+
+// bitLen returns the length of the bit string type, where
+// 0 stands for the unlimited length of BIT VARYING.
+func bitLen(t *BitType) int64 {
+	if t.Len == 0 && !strings.EqualFold(t.T, TypeBitVar) {
+		return 1
+	}
+	return t.Len
+}
+
+// isVarCharType reports if the string type is stored as text (i.e. text or
+// character varying), and therefore binary-coercible to the other ones.
+func isVarCharType(t *schema.StringType) bool {
+	f, err := FormatType(t)
+	return err == nil && (f == TypeText || f == TypeCharVar || strings.HasPrefix(f, TypeCharVar+"("))
+}
+
+// relaxedPrecision reports if the precision was kept or increased. A missing
+// precision stands for the maximum precision allowed by the type.
+func relaxedPrecision(from, to *int) bool {
+	return to == nil || from != nil && *from <= *to
+}
+
+// intDigits holds the maximum number of decimal digits the integer types can hold.
+var intDigits = map[int]int{2: 5, 4: 10, 8: 19}
+
+// intSize returns the storage size (in bytes) of integer and serial types.
+func intSize(t schema.Type) (int, bool) {
+	f, err := FormatType(t)
+	if err != nil {
+		return 0, false
+	}
+	switch f {
+	case TypeSmallInt, TypeSmallSerial:
+		return 2, true
+	case TypeInteger, TypeSerial:
+		return 4, true
+	case TypeBigInt, TypeBigSerial:
+		return 8, true
+	}
+	return 0, false
+}
+
+// isTextType reports if the string type accepts strings of any size.
+func isTextType(t *schema.StringType) bool {
+	f, err := FormatType(t)
+	return err == nil && (f == TypeText || f == TypeCharVar)
+}
+
+// widened returns the risk of a type change based on if its range was widened.
+func widened(b bool) Risk {
+	if b {
+		return RiskNone
+	}
+	return RiskLossy
+}
+
+func contains(vs []string, v string) bool {
+	for i := range vs {
+		if vs[i] == v {
+			return true
+		}
+	}
+	return false
+}
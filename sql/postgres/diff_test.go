@@ -5,16 +5,19 @@
 package postgres
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 
+	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/stretchr/testify/require"
 )
 
 func TestDiff_TableDiff(t *testing.T) {
+	p := func(i int) *int { return &i }
 	type testcase struct {
 		name        string
 		from, to    *schema.Table
@@ -39,6 +42,323 @@ func TestDiff_TableDiff(t *testing.T) {
 			to:      &schema.Table{Name: "users"},
 			wantErr: true,
 		},
+		{
+			name: "null default",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewNullStringColumn("a", "text"),
+					schema.NewNullStringColumn("b", "text").SetDefault(&schema.RawExpr{X: "'b'::text"}),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewNullStringColumn("a", "text").SetDefault(&schema.RawExpr{X: "NULL::text"}),
+					schema.NewNullStringColumn("b", "text").SetDefault(&schema.RawExpr{X: "NULL"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewNullStringColumn("b", "text").SetDefault(&schema.RawExpr{X: "'b'::text"}),
+					To:     schema.NewNullStringColumn("b", "text").SetDefault(&schema.RawExpr{X: "NULL"}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "interval fields",
+			from: schema.NewTable("users").
+				AddColumns(
+					&schema.Column{Name: "a", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "HOUR TO SECOND", Precision: p(3)}}},
+					&schema.Column{Name: "b", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", Precision: p(6)}}},
+					&schema.Column{Name: "c", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "YEAR TO MONTH", Precision: p(6)}}},
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					&schema.Column{Name: "a", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "hour to second", Precision: p(3)}}},
+					&schema.Column{Name: "b", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "day to second"}}},
+					&schema.Column{Name: "c", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "year to month"}}},
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   &schema.Column{Name: "b", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", Precision: p(6)}}},
+					To:     &schema.Column{Name: "b", Type: &schema.ColumnType{Type: &IntervalType{T: "interval", F: "day to second"}}},
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "money defaults",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewColumn("a").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$0.00'::money"}),
+					schema.NewColumn("b").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'($1,234.50)'::money"}),
+					schema.NewColumn("c").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'1.234,50 €'::money"}),
+					schema.NewColumn("d").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$1.00'::money"}),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewColumn("a").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'0'"}),
+					schema.NewColumn("b").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'-1234.5'::money"}),
+					schema.NewColumn("c").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$1,234.50'"}),
+					schema.NewColumn("d").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$2.00'"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("d").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$1.00'::money"}),
+					To:     schema.NewColumn("d").SetType(&CurrencyType{T: "money"}).SetDefault(&schema.RawExpr{X: "'$2.00'"}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "special numeric defaults",
+			from: schema.NewTable("metrics").
+				AddColumns(
+					schema.NewDecimalColumn("a", "numeric").SetDefault(&schema.RawExpr{X: "'NaN'::numeric"}),
+					schema.NewFloatColumn("b", "double precision").SetDefault(&schema.RawExpr{X: "'Infinity'::double precision"}),
+					schema.NewFloatColumn("c", "real").SetDefault(&schema.RawExpr{X: "'-Infinity'::real"}),
+					schema.NewDecimalColumn("d", "numeric").SetDefault(&schema.RawExpr{X: "'NaN'::numeric"}),
+				),
+			to: schema.NewTable("metrics").
+				AddColumns(
+					schema.NewDecimalColumn("a", "numeric").SetDefault(&schema.RawExpr{X: "'nan'"}),
+					schema.NewFloatColumn("b", "double precision").SetDefault(&schema.RawExpr{X: "'inf'::float8"}),
+					schema.NewFloatColumn("c", "real").SetDefault(&schema.RawExpr{X: "'-inf'"}),
+					schema.NewDecimalColumn("d", "numeric").SetDefault(&schema.RawExpr{X: "0"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewDecimalColumn("d", "numeric").SetDefault(&schema.RawExpr{X: "'NaN'::numeric"}),
+					To:     schema.NewDecimalColumn("d", "numeric").SetDefault(&schema.RawExpr{X: "0"}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "network defaults",
+			from: schema.NewTable("hosts").
+				AddColumns(
+					schema.NewColumn("a").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.5'::inet"}),
+					schema.NewColumn("b").SetType(&NetworkType{T: TypeCIDR}).SetDefault(&schema.RawExpr{X: "'10.0.0.0/32'::cidr"}),
+					schema.NewColumn("c").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'2001:db8::1/64'::inet"}),
+					schema.NewColumn("d").SetType(&NetworkType{T: TypeMACAddr}).SetDefault(&schema.RawExpr{X: "'08:00:2b:01:02:03'::macaddr"}),
+					schema.NewColumn("e").SetType(&NetworkType{T: TypeMACAddr8}).SetDefault(&schema.RawExpr{X: "'08:00:2b:ff:fe:01:02:03'::macaddr8"}),
+					schema.NewColumn("f").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.0/24'::inet"}),
+				),
+			to: schema.NewTable("hosts").
+				AddColumns(
+					schema.NewColumn("a").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.5/32'"}),
+					schema.NewColumn("b").SetType(&NetworkType{T: TypeCIDR}).SetDefault(&schema.RawExpr{X: "'10.0.0.0'"}),
+					schema.NewColumn("c").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'2001:0db8:0:0::1/64'"}),
+					schema.NewColumn("d").SetType(&NetworkType{T: TypeMACAddr}).SetDefault(&schema.RawExpr{X: "'08-00-2B-01-02-03'"}),
+					schema.NewColumn("e").SetType(&NetworkType{T: TypeMACAddr8}).SetDefault(&schema.RawExpr{X: "'08002b010203'"}),
+					schema.NewColumn("f").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.5/24'"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("f").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.0/24'::inet"}),
+					To:     schema.NewColumn("f").SetType(&NetworkType{T: TypeInet}).SetDefault(&schema.RawExpr{X: "'192.168.1.5/24'"}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "xml defaults",
+			from: schema.NewTable("docs").
+				AddColumns(
+					schema.NewColumn("a").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a x="1" y="2"><b></b></a>'::xml`}),
+					schema.NewColumn("b").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>text</a>'::xml`}),
+					schema.NewColumn("c").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>text</a>'::xml`}),
+				),
+			to: schema.NewTable("docs").
+				AddColumns(
+					schema.NewColumn("a").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: "XMLPARSE(CONTENT '<a y=\"2\" x=\"1\">\n  <b/>\n</a>')"}),
+					schema.NewColumn("b").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>text</a>'`}),
+					schema.NewColumn("c").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>other</a>'`}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("c").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>text</a>'::xml`}),
+					To:     schema.NewColumn("c").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>other</a>'`}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "now defaults",
+			from: schema.NewTable("events").
+				AddColumns(
+					schema.NewColumn("a").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+					schema.NewColumn("b").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "now()"}),
+					schema.NewColumn("c").SetType(&schema.TimeType{T: TypeTimestampWTZ, Precision: p(3)}).SetDefault(&schema.RawExpr{X: "now()"}),
+					schema.NewColumn("d").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "now()"}),
+				),
+			to: schema.NewTable("events").
+				AddColumns(
+					schema.NewColumn("a").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "now()"}),
+					schema.NewColumn("b").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "transaction_timestamp()"}),
+					schema.NewColumn("c").SetType(&schema.TimeType{T: TypeTimestampWTZ, Precision: p(3)}).SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP(3)"}),
+					schema.NewColumn("d").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP(3)"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("d").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "now()"}),
+					To:     schema.NewColumn("d").SetType(&schema.TimeType{T: TypeTimestampWTZ}).SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP(3)"}),
+					Change: schema.ChangeDefault,
+				},
+			},
+		},
+		{
+			name: "large object columns",
+			from: schema.NewTable("files").
+				AddColumns(
+					schema.NewColumn("a").SetType(&OIDType{T: TypeOID}).SetDefault(&schema.RawExpr{X: "0"}),
+					schema.NewColumn("b").SetType(&OIDType{T: TypeLO}),
+				),
+			to: schema.NewTable("files").
+				AddColumns(
+					schema.NewColumn("a").SetType(func() schema.Type { t, _ := ParseType("OID"); return t }()).SetDefault(&schema.RawExpr{X: "'0'"}),
+					schema.NewColumn("b").SetType(func() schema.Type { t, _ := ParseType("lo"); return t }()),
+				),
+		},
+		{
+			name: "array dimensions",
+			from: schema.NewTable("grids").
+				AddColumns(
+					schema.NewColumn("a").SetType(&ArrayType{T: "integer[]", Type: &schema.IntegerType{T: TypeInteger}}),
+					schema.NewColumn("b").SetType(&ArrayType{T: "integer[]", Type: &schema.IntegerType{T: TypeInteger}}),
+					schema.NewColumn("c").SetType(&ArrayType{T: "timestamp with time zone[]", Type: &schema.TimeType{T: TypeTimestampWTZ, Precision: p(6)}}),
+					schema.NewColumn("d").SetType(&ArrayType{T: "text[]", Type: &schema.StringType{T: TypeText}}),
+				),
+			to: schema.NewTable("grids").
+				AddColumns(
+					schema.NewColumn("a").SetType(func() schema.Type { t, _ := ParseType("int[3][3]"); return t }()),
+					schema.NewColumn("b").SetType(&ArrayType{T: "int4[3]"}),
+					schema.NewColumn("c").SetType(func() schema.Type { t, _ := ParseType("_timestamptz"); return t }()),
+					schema.NewColumn("d").SetType(func() schema.Type { t, _ := ParseType("_int4"); return t }()),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("d").SetType(&ArrayType{T: "text[]", Type: &schema.StringType{T: TypeText}}),
+					To:     schema.NewColumn("d").SetType(&ArrayType{T: "int4[]", Type: &schema.IntegerType{T: TypeInt4}}),
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "serial and explicit sequence default",
+			from: schema.NewTable("t").
+				AddColumns(
+					schema.NewColumn("id").SetType(&SerialType{T: TypeSerial}),
+					schema.NewIntColumn("a", TypeBigInt).SetDefault(&schema.RawExpr{X: "nextval('t_a_seq'::regclass)"}),
+					schema.NewColumn("b").SetType(&SerialType{T: TypeSmallSerial}),
+				),
+			to: schema.NewTable("t").
+				AddColumns(
+					schema.NewIntColumn("id", TypeInteger).SetDefault(&schema.RawExpr{X: "nextval('t_id_seq')"}),
+					schema.NewColumn("a").SetType(&SerialType{T: TypeSerial8}),
+					schema.NewIntColumn("b", TypeInteger).SetDefault(&schema.RawExpr{X: "nextval('t_b_seq')"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("b").SetType(&SerialType{T: TypeSmallSerial}),
+					To:     schema.NewIntColumn("b", TypeInteger).SetDefault(&schema.RawExpr{X: "nextval('t_b_seq')"}),
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "bpchar alias",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewStringColumn("a", "bpchar", schema.StringSize(10)),
+					schema.NewStringColumn("b", "bpchar"),
+					schema.NewStringColumn("c", "bpchar", schema.StringSize(2)),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewStringColumn("a", "character", schema.StringSize(10)),
+					schema.NewStringColumn("b", "char", schema.StringSize(1)),
+					schema.NewStringColumn("c", "char", schema.StringSize(3)),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewStringColumn("c", "bpchar", schema.StringSize(2)),
+					To:     schema.NewStringColumn("c", "char", schema.StringSize(3)),
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "varchar alias",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewStringColumn("a", "character varying", schema.StringSize(255)),
+					schema.NewStringColumn("b", "character varying"),
+					schema.NewStringColumn("c", "varchar"),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewStringColumn("a", "varchar", schema.StringSize(255)),
+					schema.NewStringColumn("b", "varchar"),
+					schema.NewStringColumn("c", "text"),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewStringColumn("c", "varchar"),
+					To:     schema.NewStringColumn("c", "text"),
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "integer aliases",
+			from: schema.NewTable("users").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					&schema.Column{Name: "a", Type: &schema.ColumnType{Raw: "int4", Type: &schema.IntegerType{T: "int4"}}},
+					&schema.Column{Name: "b", Type: &schema.ColumnType{Raw: "int8", Type: &schema.IntegerType{T: "int8"}}},
+					&schema.Column{Name: "c", Type: &schema.ColumnType{Raw: "int2[]", Type: &ArrayType{T: "int2[]"}}},
+					&schema.Column{Name: "d", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}},
+				),
+			to: schema.NewTable("users").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					&schema.Column{Name: "a", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}},
+					&schema.Column{Name: "b", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "BIGINT"}}},
+					&schema.Column{Name: "c", Type: &schema.ColumnType{Type: &ArrayType{T: "smallint[]"}}},
+					&schema.Column{Name: "d", Type: &schema.ColumnType{Type: &SerialType{T: "serial"}}},
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   &schema.Column{Name: "d", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}},
+					To:     &schema.Column{Name: "d", Type: &schema.ColumnType{Type: &SerialType{T: "serial"}}},
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		{
+			name: "numeric precision",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewDecimalColumn("a", "numeric"),
+					schema.NewDecimalColumn("b", "numeric", schema.DecimalPrecision(10)),
+					schema.NewDecimalColumn("c", "numeric", schema.DecimalPrecision(2), schema.DecimalScale(-3)),
+					schema.NewDecimalColumn("d", "numeric"),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewDecimalColumn("a", "decimal"),
+					schema.NewDecimalColumn("b", "decimal", schema.DecimalPrecision(10), schema.DecimalScale(0)),
+					schema.NewDecimalColumn("c", "decimal", schema.DecimalPrecision(2), schema.DecimalScale(-3)),
+					schema.NewDecimalColumn("d", "numeric", schema.DecimalPrecision(10), schema.DecimalScale(2)),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewDecimalColumn("d", "numeric"),
+					To:     schema.NewDecimalColumn("d", "numeric", schema.DecimalPrecision(10), schema.DecimalScale(2)),
+					Change: schema.ChangeType,
+				},
+			},
+		},
 		{
 			name: "change identity attributes",
 			from: func() *schema.Table {
@@ -69,6 +389,26 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "identity sequence drift",
+			from: schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("a", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 5, Last: 500}}),
+					schema.NewIntColumn("b", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 1, Last: 900}}),
+				),
+			to: schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("a", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways}),
+					schema.NewIntColumn("b", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewIntColumn("a", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 5, Last: 500}}),
+					To:     schema.NewIntColumn("a", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways}),
+					Change: schema.ChangeAttr,
+				},
+			},
+		},
 		{
 			name: "drop partition key",
 			from: schema.NewTable("logs").
@@ -117,6 +457,132 @@ func TestDiff_TableDiff(t *testing.T) {
 				}),
 			wantErr: true,
 		},
+		{
+			name: "same storage params in different order",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"toast.autovacuum_enabled", "false"}}}),
+			to:   schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"toast.autovacuum_enabled", "false"}, {"parallel_workers", "4"}}}),
+		},
+		{
+			name: "change toast storage params",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"toast.autovacuum_enabled", "false"}}}),
+			to:   schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"toast.autovacuum_enabled", "true"}}}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &TableStorageParams{Params: []struct{ N, V string }{{"toast.autovacuum_enabled", "false"}}},
+					To:   &TableStorageParams{Params: []struct{ N, V string }{{"toast.autovacuum_enabled", "true"}}},
+				},
+			},
+		},
+		{
+			name: "legacy oids storage params",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"oids", "true"}}}),
+			to:   schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"oids", "false"}, {"parallel_workers", "4"}}}),
+		},
+		{
+			name: "default autovacuum storage params",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_vacuum_scale_factor", "0.20"}, {"toast.autovacuum_enabled", "on"}}}),
+			to:   schema.NewTable("t1"),
+		},
+		{
+			name: "default table access method",
+			from: schema.NewTable("t1"),
+			to:   schema.NewTable("t1").AddAttrs(&TableAccessMethod{V: "HEAP"}),
+		},
+		{
+			name: "table access method",
+			from: schema.NewTable("t1"),
+			to:   schema.NewTable("t1").AddAttrs(&TableAccessMethod{V: "columnar"}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{From: &TableAccessMethod{V: "heap"}, To: &TableAccessMethod{V: "columnar"}},
+			},
+		},
+		{
+			name: "default table fillfactor",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "100"}}}),
+			to:   schema.NewTable("t1"),
+		},
+		func() testcase {
+			var (
+				c    = schema.NewIntColumn("c", "int")
+				from = schema.NewTable("t1").AddColumns(c)
+				to   = schema.NewTable("t1").AddColumns(c)
+			)
+			from.AddIndexes(
+				// Explicit defaults of the index methods.
+				schema.NewIndex("i1").AddColumns(c).AddAttrs(&IndexStorageParams{FillFactor: 90}),
+				schema.NewIndex("i2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeHash}, &IndexStorageParams{FillFactor: 75}),
+				// The table default does not apply to indexes.
+				schema.NewIndex("i3").AddColumns(c).AddAttrs(&IndexStorageParams{FillFactor: 100}),
+				schema.NewIndex("i4").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeHash}, &IndexStorageParams{FillFactor: 90}),
+			)
+			to.AddIndexes(
+				schema.NewIndex("i1").AddColumns(c),
+				schema.NewIndex("i2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeHash}),
+				schema.NewIndex("i3").AddColumns(c),
+				schema.NewIndex("i4").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeHash}),
+			)
+			return testcase{
+				name: "default index fillfactor",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[2], To: to.Indexes[2], Change: schema.ChangeAttr},
+					&schema.ModifyIndex{From: from.Indexes[3], To: to.Indexes[3], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+		{
+			name: "enable autovacuum",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_enabled", "false"}}}),
+			to:   schema.NewTable("t1"),
+			wantChanges: []schema.Change{
+				&schema.DropAttr{
+					A: &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_enabled", "false"}}},
+				},
+			},
+		},
+		{
+			name: "tune autovacuum storage params",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_analyze_threshold", "50"}}}),
+			to:   schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_vacuum_scale_factor", "0.05"}}}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_analyze_threshold", "50"}}},
+					To:   &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_vacuum_scale_factor", "0.05"}}},
+				},
+			},
+		},
+		{
+			name: "drop storage params",
+			from: schema.NewTable("t1").AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}}}),
+			to:   schema.NewTable("t1"),
+			wantChanges: []schema.Change{
+				&schema.DropAttr{
+					A: &TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}}},
+				},
+			},
+		},
+		func() testcase {
+			var (
+				on   = true
+				off  = false
+				c    = schema.NewColumn("c").SetType(&schema.JSONType{T: "jsonb"})
+				from = schema.NewTable("t1").AddColumns(c)
+				to   = schema.NewTable("t1").AddColumns(c)
+			)
+			from.AddIndexes(schema.NewIndex("i1").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}))
+			to.AddIndexes(schema.NewIndex("i1").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}, &IndexStorageParams{FastUpdate: &on}))
+			from.AddIndexes(schema.NewIndex("i2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}))
+			to.AddIndexes(schema.NewIndex("i2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}, &IndexStorageParams{FastUpdate: &off}))
+			return testcase{
+				name: "gin storage params",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[1], To: to.Indexes[1], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
 		{
 			name: "add check",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -137,6 +603,70 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		func() testcase {
+			var (
+				from = schema.NewTable("users").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewStringColumn("email", "text"))
+				to = schema.NewTable("users").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewStringColumn("email", "text"))
+			)
+			from.AddIndexes(schema.NewUniqueIndex("users_email_key").AddColumns(from.Columns[0]).AddAttrs(&IndexType{T: "btree"}, &Constraint{N: "users_email_key", T: "u"}))
+			to.AddIndexes(schema.NewUniqueIndex("users_email_key").AddColumns(to.Columns[0]))
+			return testcase{
+				name: "unique constraint index",
+				from: from,
+				to:   to,
+			}
+		}(),
+		{
+			name: "inherited check",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&Inherited{}}}}},
+			to:   &schema.Table{Name: "t1"},
+		},
+		{
+			name: "inherited check modified",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&Inherited{}}}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NoInherit{}}}}},
+		},
+		{
+			name: "between check",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Name: "t1_c1_check", Expr: "((c1 >= 1) AND (c1 <= 10))"},
+				&schema.Check{Name: "t1_c2_check", Expr: "((c2 < 1) OR (c2 > 10))"},
+				&schema.Check{Name: "t1_c3_check", Expr: "(((c3 >= 1) AND (c3 <= 10)) OR ((c3 >= 10) AND (c3 <= 1)))"},
+				&schema.Check{Name: "t1_c4_check", Expr: "(((c4 < 1) OR (c4 > 10)) AND ((c4 < 10) OR (c4 > 1)))"},
+				&schema.Check{Name: "t1_check", Expr: "((c1 >= 0) AND (c1 <= 5) AND (c2 IS NOT NULL))"},
+			}},
+			to: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Expr: "c1 BETWEEN 1 AND 10"},
+				&schema.Check{Expr: "c2 NOT BETWEEN 1 AND 10"},
+				&schema.Check{Expr: "c3 between symmetric 1 and 10"},
+				&schema.Check{Expr: "c4 NOT BETWEEN SYMMETRIC 1 AND 10"},
+				&schema.Check{Expr: "C1 BETWEEN 0 AND 5 AND c2 IS NOT NULL"},
+			}},
+		},
+		{
+			name: "checks with functions are compared textually",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Name: "t1_id_check", Expr: "(id < nextval('seq'::regclass))"},
+				&schema.Check{Name: "t1_c1_check", Expr: "((length(c1) > 0) AND (c1 <> lower(c1)))"},
+			}},
+			to: &schema.Table{Name: "t1", Attrs: []schema.Attr{
+				&schema.Check{Expr: "id < nextval('seq')"},
+				&schema.Check{Expr: "length(c1) > 0 AND c1 <> LOWER(c1)"},
+			}},
+		},
+		{
+			name: "between check changed",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "((c1 >= 1) AND (c1 <= 10))"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Expr: "c1 NOT BETWEEN 1 AND 10"}}},
+			wantChanges: []schema.Change{
+				&schema.DropCheck{C: &schema.Check{Name: "t1_c1_check", Expr: "((c1 >= 1) AND (c1 <= 10))"}},
+				&schema.AddCheck{C: &schema.Check{Expr: "c1 NOT BETWEEN 1 AND 10"}},
+			},
+		},
 		{
 			name: "add comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -207,26 +737,93 @@ func TestDiff_TableDiff(t *testing.T) {
 					schema.NewIntColumn("c1", "int").
 						SetGeneratedExpr(&schema.GeneratedExpr{Expr: "2", Type: "STORED"}),
 				),
-			wantErr: true,
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewIntColumn("c1", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "1", Type: "STORED"}),
+					To:     schema.NewIntColumn("c1", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "2", Type: "STORED"}),
+					Change: schema.ChangeGenerated,
+				},
+			},
 		},
-		func() testcase {
-			var (
-				from = &schema.Table{
-					Name: "t1",
-					Schema: &schema.Schema{
-						Name: "public",
-					},
-					Columns: []*schema.Column{
-						{Name: "c1", Type: &schema.ColumnType{Raw: "json", Type: &schema.JSONType{T: "json"}}},
-						{Name: "c2", Type: &schema.ColumnType{Raw: "int8", Type: &schema.IntegerType{T: "int8"}}},
-					},
-				}
-				to = &schema.Table{
-					Name: "t1",
-					Columns: []*schema.Column{
-						{
-							Name:    "c1",
-							Type:    &schema.ColumnType{Raw: "json", Type: &schema.JSONType{T: "json"}, Null: true},
+		{
+			name: "tsvector generation expression",
+			from: schema.NewTable("posts").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `(setweight(to_tsvector('english'::regconfig, COALESCE(title, ''::text)), 'A'::"char") || to_tsvector('english'::regconfig, COALESCE("Body", ''::text)))`, Type: "STORED"}),
+				),
+			to: schema.NewTable("posts").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `setweight(to_tsvector('english', coalesce(title, '')), 'A') || to_tsvector('english', coalesce("Body", ''))`, Type: "STORED"}),
+				),
+		},
+		{
+			name: "change tsvector configuration",
+			from: schema.NewTable("posts").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `to_tsvector('english'::regconfig, body)`, Type: "STORED"}),
+				),
+			to: schema.NewTable("posts").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `to_tsvector('simple', body)`, Type: "STORED"}),
+				),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From: schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `to_tsvector('english'::regconfig, body)`, Type: "STORED"}),
+					To: schema.NewColumn("search").
+						SetType(&TextSearchType{T: TypeTSVector}).
+						SetGeneratedExpr(&schema.GeneratedExpr{Expr: `to_tsvector('simple', body)`, Type: "STORED"}),
+					Change: schema.ChangeGenerated,
+				},
+			},
+		},
+		{
+			name: "json to jsonb",
+			from: schema.NewTable("t1").
+				SetSchema(schema.New("public")).
+				AddColumns(schema.NewColumn("c1").SetType(&schema.JSONType{T: TypeJSON})),
+			to: schema.NewTable("t1").
+				SetSchema(schema.New("public")).
+				AddColumns(schema.NewColumn("c1").SetType(&schema.JSONType{T: TypeJSONB})),
+			wantChanges: []schema.Change{
+				&schema.ModifyColumn{
+					From:   schema.NewColumn("c1").SetType(&schema.JSONType{T: TypeJSON}),
+					To:     schema.NewColumn("c1").SetType(&schema.JSONType{T: TypeJSONB}),
+					Change: schema.ChangeType,
+				},
+			},
+		},
+		func() testcase {
+			var (
+				from = &schema.Table{
+					Name: "t1",
+					Schema: &schema.Schema{
+						Name: "public",
+					},
+					Columns: []*schema.Column{
+						{Name: "c1", Type: &schema.ColumnType{Raw: "json", Type: &schema.JSONType{T: "json"}}},
+						{Name: "c2", Type: &schema.ColumnType{Raw: "int8", Type: &schema.IntegerType{T: "int8"}}},
+					},
+				}
+				to = &schema.Table{
+					Name: "t1",
+					Columns: []*schema.Column{
+						{
+							Name:    "c1",
+							Type:    &schema.ColumnType{Raw: "json", Type: &schema.JSONType{T: "json"}, Null: true},
 							Default: &schema.RawExpr{X: "{}"},
 							Attrs:   []schema.Attr{&schema.Comment{Text: "json comment"}},
 						},
@@ -375,6 +972,35 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewNullIntColumn("c1", "int8"), schema.NewNullIntColumn("c2", "int8"))
+				to = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewNullIntColumn("c1", "int8"), schema.NewNullIntColumn("c2", "int8"))
+			)
+			from.AddIndexes(
+				schema.NewUniqueIndex("partial_no_change").AddColumns(from.Columns[0]).AddAttrs(&IndexPredicate{P: "(c2 > 0)"}, &IndexNullsDistinct{}),
+				schema.NewUniqueIndex("partial_nulls_changed").AddColumns(from.Columns[0]).AddAttrs(&IndexPredicate{P: "(c2 > 0)"}),
+				schema.NewUniqueIndex("partial_both_changed").AddColumns(from.Columns[0]).AddAttrs(&IndexPredicate{P: "(c2 > 0)"}, &IndexNullsDistinct{}),
+			)
+			to.AddIndexes(
+				schema.NewUniqueIndex("partial_no_change").AddColumns(to.Columns[0]).AddAttrs(&IndexPredicate{P: "c2 > 0"}, &IndexNullsDistinct{}),
+				schema.NewUniqueIndex("partial_nulls_changed").AddColumns(to.Columns[0]).AddAttrs(&IndexPredicate{P: "c2 > 0"}, &IndexNullsDistinct{}),
+				schema.NewUniqueIndex("partial_both_changed").AddColumns(to.Columns[0]).AddAttrs(&IndexPredicate{P: "c2 < 0"}, &IndexNullsDistinct{V: true}),
+			)
+			return testcase{
+				name: "nulls not distinct with predicates",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[1], To: to.Indexes[1], Change: schema.ChangeAttr},
+					&schema.ModifyIndex{From: from.Indexes[2], To: to.Indexes[2], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
 		func() testcase {
 			var (
 				from = schema.NewTable("t1").
@@ -511,6 +1137,922 @@ func TestDiff_SchemaDiff(t *testing.T) {
 	}, changes)
 }
 
+func TestDiff_SchemaObjectDiff(t *testing.T) {
+	var (
+		german  = &Collation{Name: "german", Provider: "libc", Locale: "de_DE"}
+		french  = &Collation{Name: "french", Provider: "libc", Locale: "fr_FR"}
+		nocase1 = &Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-l2"}
+		nocase2 = &Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-l2", Nondeterministic: true}
+		from    = schema.New("public").AddObjects(german, nocase1, &Collation{Name: "libc", LCCollate: "C", LCCtype: "C"})
+		to      = schema.New("public").AddObjects(french, nocase2, &Collation{Name: "libc", Provider: "libc", Locale: "C"})
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropObject{O: german},
+		&schema.ModifyObject{From: nocase1, To: nocase2},
+		&schema.AddObject{O: french},
+	}, changes)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_TextSearchConfigurations(t *testing.T) {
+	var (
+		english = func(m ...*TextSearchMapping) *TextSearchConfiguration {
+			return &TextSearchConfiguration{Name: "english", Parser: "default", Mappings: m}
+		}
+		from = schema.New("public").AddObjects(
+			english(&TextSearchMapping{Token: "asciiword", Dicts: []string{"english_stem"}}),
+			&TextSearchConfiguration{Name: "custom", Parser: "pg_catalog.default"},
+		)
+		to = schema.New("public").AddObjects(
+			english(&TextSearchMapping{Token: "asciiword", Dicts: []string{"unaccent", "english_stem"}}),
+			&TextSearchConfiguration{Name: "custom", Parser: "default"},
+		)
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+	}, changes)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_DefaultPrivileges(t *testing.T) {
+	var (
+		from = schema.New("public").AddObjects(
+			&DefaultPrivilege{Owner: "admin", ObjType: "TABLES", Grantee: "reader", Privileges: []string{"INSERT", "SELECT"}},
+			&DefaultPrivilege{Owner: "admin", ObjType: "SEQUENCES", Grantee: "PUBLIC", Privileges: []string{"USAGE"}},
+		)
+		to = schema.New("public").AddObjects(
+			&DefaultPrivilege{Owner: "admin", ObjType: "TABLES", Grantee: "reader", Privileges: []string{"select"}},
+			&DefaultPrivilege{Owner: "admin", ObjType: "TABLES", Grantee: "writer", Privileges: []string{"INSERT"}},
+		)
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.DropObject{O: from.Objects[1]},
+		&schema.AddObject{O: to.Objects[1]},
+	}, changes)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_TypeOwners(t *testing.T) {
+	var (
+		from = schema.New("public").AddObjects(
+			&TypeOwner{Name: "mood", Owner: "admin"},
+			&TypeOwner{Name: "status", Owner: "admin"},
+			&TypeOwner{Name: "unmanaged", Owner: "admin"},
+		)
+		to = schema.New("public").AddObjects(
+			&TypeOwner{Name: "mood", Owner: "app"},
+			&TypeOwner{Name: "status", Owner: "admin"},
+			&TypeOwner{Name: "state", Owner: "app"},
+		)
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+}
+
+func TestDiff_EventTriggers(t *testing.T) {
+	var (
+		from = schema.NewRealm(schema.New("public")).AddObjects(
+			&EventTrigger{Name: "audit", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, FuncSchema: "public", Func: "log_ddl", State: "ENABLE"},
+			&EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "public", Func: "abort_drop", State: "ENABLE"},
+			&EventTrigger{Name: "legacy", Event: "ddl_command_start", FuncSchema: "public", Func: "legacy", State: "ENABLE"},
+		)
+		to = schema.NewRealm(schema.New("public")).AddObjects(
+			// Tags are compared regardless of their order, and the
+			// function schema is compared only if it is defined.
+			&EventTrigger{Name: "audit", Event: "ddl_command_end", Tags: []string{"alter table", "create table"}, Func: "log_ddl"},
+			&EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "public", Func: "abort_drop", State: "DISABLE"},
+			&EventTrigger{Name: "rewrites", Event: "table_rewrite", FuncSchema: "public", Func: "log_rewrite"},
+		)
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.DropObject{O: from.Objects[2]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+}
+
+func TestDiff_QualifiedDefaultCast(t *testing.T) {
+	var (
+		public = schema.New("public")
+		from   = schema.NewTable("users").SetSchema(public).AddColumns(
+			schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy", "sad"), schema.EnumSchema(public)).
+				SetDefault(&schema.RawExpr{X: "'happy'::mood"}),
+			schema.NewStringColumn("name", "text").SetDefault(&schema.RawExpr{X: "'unknown'::text"}),
+		)
+		to = schema.NewTable("users").SetSchema(public).AddColumns(
+			schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy", "sad"), schema.EnumSchema(public)).
+				SetDefault(&schema.RawExpr{X: `'happy'::"public".mood`}),
+			schema.NewStringColumn("name", "text").SetDefault(&schema.RawExpr{X: "'unknown'::pg_catalog.text"}),
+		)
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Qualifiers of other schemas are not stripped.
+	to.Columns[0].SetDefault(&schema.RawExpr{X: "'happy'::other.mood"})
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
+func TestDiff_ExtensionTypes(t *testing.T) {
+	from := schema.NewTable("users").AddColumns(
+		schema.NewColumn("attrs").SetType(&UserDefinedType{T: "public.hstore"}).SetDefault(&schema.RawExpr{X: `'"a"=>"1", "b"=>NULL, "c d"=>"x\"y"'::public.hstore`}),
+		schema.NewColumn("path").SetType(&UserDefinedType{T: "ltree"}).SetDefault(&schema.RawExpr{X: `'top.science'::ltree`}),
+		schema.NewColumn("email").SetType(&UserDefinedType{T: "citext"}).SetDefault(&schema.RawExpr{X: `'ABC'::citext`}),
+		schema.NewColumn("trgm").SetType(&UserDefinedType{T: "gtrgm"}),
+	)
+	to := schema.NewTable("users").AddColumns(
+		schema.NewColumn("attrs").SetType(&UserDefinedType{T: "hstore"}).SetDefault(&schema.RawExpr{X: `'"c d" => "x\"y", b=>null,a=>1, a=>2'`}),
+		schema.NewColumn("path").SetType(&UserDefinedType{T: `"public"."ltree"`}).SetDefault(&schema.RawExpr{X: `'top.science'`}),
+		schema.NewColumn("email").SetType(&UserDefinedType{T: "citext"}).SetDefault(&schema.RawExpr{X: `'ABC'`}),
+		schema.NewColumn("trgm").SetType(&UserDefinedType{T: "public.gtrgm"}),
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Values are compared by their canonical form, but citext values are case-sensitive.
+	to.Columns[0].SetDefault(&schema.RawExpr{X: `'a=>1, b=>"NULL"'`})
+	to.Columns[2].SetDefault(&schema.RawExpr{X: `'abc'::citext`})
+	to.Columns[3].SetType(&UserDefinedType{T: "hstore"})
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, schema.ChangeDefault, changes[0].(*schema.ModifyColumn).Change)
+	require.Equal(t, schema.ChangeDefault, changes[1].(*schema.ModifyColumn).Change)
+	require.Equal(t, schema.ChangeType, changes[2].(*schema.ModifyColumn).Change)
+}
+
+func TestDiff_Casts(t *testing.T) {
+	from := schema.NewRealm().AddObjects(
+		&Cast{Source: "mood", Target: "text", Func: "mood_text(mood)", Context: "ASSIGNMENT"},
+		&Cast{Source: "text", Target: "mood", InOut: true, Context: "EXPLICIT"},
+		&Cast{Source: "integer", Target: "mood", Func: "int_mood(integer)", Context: "EXPLICIT"},
+	)
+	to := schema.NewRealm().AddObjects(
+		&Cast{Source: "mood", Target: "text", Func: "mood_text( mood )", Context: "assignment"},
+		&Cast{Source: "text", Target: "mood", InOut: true},
+		&Cast{Source: "int4", Target: "mood", Func: "int_mood(int4)", Context: "IMPLICIT"},
+		&Cast{Source: "mood", Target: "varchar", InOut: true},
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[2], To: to.Objects[2]},
+		&schema.AddObject{O: to.Objects[3]},
+	}, changes)
+}
+
+func TestDiff_AccessMethods(t *testing.T) {
+	from := schema.NewRealm().AddObjects(
+		&AccessMethod{Name: "columnar", Type: "TABLE", Handler: "columnar_handler"},
+		&AccessMethod{Name: "bloom", Type: "INDEX", Handler: "blhandler"},
+		&AccessMethod{Name: "heap2", Type: "TABLE", Handler: "heap_tableam_handler"},
+	)
+	to := schema.NewRealm().AddObjects(
+		&AccessMethod{Name: "columnar", Type: "table", Handler: "columnar_handler"},
+		&AccessMethod{Name: "bloom", Type: "INDEX", Handler: "bloom_handler"},
+		&AccessMethod{Name: "rum", Type: "INDEX", Handler: "rumhandler"},
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.DropObject{O: from.Objects[2]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+}
+
+func TestDiff_Replication(t *testing.T) {
+	var (
+		public = schema.New("public").AddTables(schema.NewTable("users"), schema.NewTable("orders"), schema.NewTable("items"))
+		from   = schema.NewRealm(public).AddObjects(
+			&Publication{Name: "app", Tables: []*schema.Table{public.Tables[0], public.Tables[1]}},
+			&Publication{Name: "audit", AllTables: true, Publish: []string{"insert"}},
+			&Subscription{Name: "replica", Publications: []string{"a", "b"}, Options: []struct{ N, V string }{{N: "slot_name", V: "replica"}, {N: "synchronous_commit", V: "off"}}},
+			&Subscription{Name: "legacy", Publications: []string{"a"}},
+		)
+		to = schema.NewRealm(public).AddObjects(
+			// Tables are compared regardless of their order.
+			&Publication{Name: "app", Tables: []*schema.Table{public.Tables[1], public.Tables[0]}, Publish: []string{"insert", "update", "delete", "truncate"}},
+			&Publication{Name: "audit", AllTables: true, Publish: []string{"insert", "delete"}},
+			// Connections are not inspected, and only the defined options are compared.
+			&Subscription{Name: "replica", Conn: "host=primary", Publications: []string{"b", "a"}, Options: []struct{ N, V string }{{N: "synchronous_commit", V: "OFF"}}},
+			&Subscription{Name: "legacy", Conn: "host=primary", Publications: []string{"a"}, Disabled: true},
+		)
+	)
+	changes, err := DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.ModifyObject{From: from.Objects[3], To: to.Objects[3]},
+	}, changes)
+
+	to.Objects[0].(*Publication).Tables = []*schema.Table{public.Tables[0], public.Tables[2]}
+	changes, err = DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, &schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]}, changes[0])
+}
+
+func TestDiff_Statistics(t *testing.T) {
+	var (
+		from  = schema.New("public")
+		to    = schema.New("public")
+		users = func(s *schema.Schema) *schema.Table {
+			t := schema.NewTable("users").AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("c", "int"))
+			s.AddTables(t)
+			return t
+		}
+		t1, t2 = users(from), users(to)
+	)
+	from.AddObjects(
+		&Statistics{Name: "s1", Schema: from, Table: t1, Kinds: []string{"dependencies", "mcv", "ndistinct"}, Columns: []*schema.Column{t1.Columns[0], t1.Columns[1]}},
+		&Statistics{Name: "s2", Schema: from, Table: t1, Kinds: []string{"ndistinct"}, Columns: []*schema.Column{t1.Columns[0], t1.Columns[1]}},
+		&Statistics{Name: "s3", Schema: from, Table: t1, Kinds: []string{"mcv"}, Columns: []*schema.Column{t1.Columns[1], t1.Columns[2]}},
+	)
+	to.AddObjects(
+		// An empty list of kinds and the order of the columns are ignored.
+		&Statistics{Name: "s1", Schema: to, Table: t2, Columns: []*schema.Column{t2.Columns[1], t2.Columns[0]}},
+		&Statistics{Name: "s2", Schema: to, Table: t2, Kinds: []string{"ndistinct"}, Columns: []*schema.Column{t2.Columns[0], t2.Columns[2]}},
+		&Statistics{Name: "s4", Schema: to, Table: t2, Kinds: []string{"dependencies"}, Columns: []*schema.Column{t2.Columns[0], t2.Columns[2]}},
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.DropObject{O: from.Objects[2]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_Rules(t *testing.T) {
+	var (
+		from  = schema.New("public")
+		to    = schema.New("public")
+		users = func(s *schema.Schema) *schema.Table {
+			t := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+			s.AddTables(t)
+			return t
+		}
+		t1, t2 = users(from), users(to)
+	)
+	from.AddObjects(
+		&Rule{Name: "r1", Table: t1, Event: "DELETE", Instead: true, Actions: "NOTHING"},
+		&Rule{Name: "r2", Table: t1, Event: "UPDATE", Where: "old.id <> new.id", Actions: "INSERT INTO logs (id) VALUES (new.id)"},
+		&Rule{Name: "r3", Table: t1, Event: "INSERT", Instead: true, Actions: "NOTHING"},
+	)
+	to.AddObjects(
+		// Whitespace and trailing semicolons are ignored.
+		&Rule{Name: "r1", Table: t2, Event: "delete", Instead: true, Actions: "NOTHING;"},
+		&Rule{Name: "r2", Table: t2, Event: "UPDATE", Where: "old.id <> new.id", Actions: "INSERT INTO logs (id) VALUES (old.id)"},
+		&Rule{Name: "r4", Table: t2, Event: "INSERT", Instead: true, Actions: "NOTHING"},
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.DropObject{O: from.Objects[2]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+
+	changes, err = DefaultDiff.SchemaDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_FoldIdentifiers(t *testing.T) {
+	var (
+		from = schema.New("public").AddTables(
+			schema.NewTable("mytable").AddColumns(schema.NewIntColumn("userid", "int")),
+			schema.NewTable("Quoted").AddColumns(schema.NewIntColumn("id", "int")),
+		)
+		to = schema.New("public").AddTables(
+			schema.NewTable("MyTable").AddColumns(schema.NewIntColumn("UserID", "int")),
+			schema.NewTable("quoted").AddColumns(schema.NewIntColumn("id", "int")),
+		)
+	)
+	// Mixed-case identifiers that are stored as-is (i.e. quoted) remain case-sensitive.
+	changes, err := diffWith(schema.WithFoldIdentifiers()).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, &schema.DropTable{T: from.Tables[1]}, changes[0])
+	require.Equal(t, "quoted", changes[1].(*schema.AddTable).T.Name)
+
+	// Changes of folded elements reference their current identifiers.
+	to.Tables[0].Columns[0].SetType(&schema.IntegerType{T: "bigint"})
+	changes, err = diffWith(schema.WithFoldIdentifiers()).SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	modify := changes[0].(*schema.ModifyTable)
+	require.Equal(t, "mytable", modify.T.Name)
+	require.Equal(t, "userid", modify.Changes[0].(*schema.ModifyColumn).To.Name)
+	require.Same(t, modify.T.Columns[0], modify.Changes[0].(*schema.ModifyColumn).To)
+
+	// The desired state is not modified by the Differ.
+	require.Equal(t, "MyTable", to.Tables[0].Name)
+	require.Equal(t, "UserID", to.Tables[0].Columns[0].Name)
+	changes, err = DefaultDiff.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+}
+
+func TestDiff_ColumnCompression(t *testing.T) {
+	from := schema.NewTable("logs").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("body", "text"))
+	to := schema.NewTable("logs").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("body", "text").AddAttrs(&Compression{V: "lz4"}))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr},
+	}, changes)
+
+	// The default method is equal to an unset one.
+	to.Columns[0].Attrs = []schema.Attr{&Compression{V: "pglz"}}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	to.Columns[0].Attrs = []schema.Attr{&Compression{V: "DEFAULT"}}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_RenameCheck(t *testing.T) {
+	from := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("age", "int")).
+		AddChecks(schema.NewCheck().SetName("users_age_check").SetExpr("(age > 0)"))
+	to := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("age", "int")).
+		AddChecks(schema.NewCheck().SetName("age_positive").SetExpr("(age > 0)"))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.RenameCheck{From: from.Attrs[0].(*schema.Check), To: to.Attrs[0].(*schema.Check)},
+	}, changes)
+
+	// Unnamed constraints in the desired state match any name.
+	to.Attrs[0].(*schema.Check).Name = ""
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_RealmQualifier(t *testing.T) {
+	var (
+		events = schema.NewTable("events").AddColumns(schema.NewIntColumn("id", "int"))
+		from   = schema.NewRealm(
+			schema.New("app").AddTables(
+				schema.NewTable("users").AddColumns(schema.NewIntColumn("event_id", "int")),
+			),
+			schema.New("analytics").AddTables(events),
+		)
+		to = schema.NewRealm(
+			schema.New("app").AddTables(
+				schema.NewTable("users").AddColumns(schema.NewIntColumn("event_id", "int")),
+				schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int")),
+			),
+		)
+	)
+	// A reference to a table in an unmanaged schema.
+	for _, r := range []*schema.Realm{from, to} {
+		users, _ := r.Schemas[0].Table("users")
+		users.AddForeignKeys(schema.NewForeignKey("users_event_id_fkey").AddColumns(users.Columns[0]).SetRefTable(events).AddRefColumns(events.Columns[0]))
+	}
+	changes, err := diffWith(schema.WithRealmQualifier("app")).RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, &schema.AddTable{T: to.Schemas[0].Tables[1]}, changes[0])
+
+	changes, err = DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.IsType(t, &schema.DropSchema{}, changes[1])
+}
+
+func TestDiff_SchemaMoves(t *testing.T) {
+	var (
+		from = schema.NewRealm(
+			schema.New("legacy").
+				AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))).
+				AddObjects(&Collation{Name: "natural", Provider: "icu", Locale: "und-u-kn-true"}),
+			schema.New("public"),
+		)
+		to = schema.NewRealm(
+			schema.New("public").
+				AddTables(schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))).
+				AddObjects(&Collation{Name: "natural", Provider: "icu", Locale: "und-u-kn-true"}),
+		)
+		moves = []schema.DiffOption{schema.WithSchemaMove("legacy.users", "public"), schema.WithSchemaMove("legacy.natural", "public")}
+	)
+	from.Schemas[0].Objects[0].(*Collation).Schema = from.Schemas[0]
+	to.Schemas[0].Objects[0].(*Collation).Schema = to.Schemas[0]
+	users1, users2 := from.Schemas[0].Tables[0], to.Schemas[0].Tables[0]
+	changes, err := diffWith(moves...).RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.MoveObject{From: from.Schemas[0].Objects[0], To: to.Schemas[0].Objects[0]},
+		&schema.MoveTable{From: users1, To: users2},
+		&schema.ModifyTable{T: users2, Changes: []schema.Change{&schema.AddColumn{C: users2.Columns[1]}}},
+		&schema.DropSchema{S: from.Schemas[0]},
+	}, changes)
+
+	// Without hints, tables are dropped and created.
+	changes, err = DefaultDiff.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropSchema{S: from.Schemas[0]},
+		&schema.AddObject{O: to.Schemas[0].Objects[0]},
+		&schema.AddTable{T: users2},
+	}, changes)
+
+	// Hints that were already applied are ignored.
+	changes, err = diffWith(moves...).RealmDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = diffWith(schema.WithSchemaMove("users", "public")).RealmDiff(from, to)
+	require.EqualError(t, err, `invalid schema move "users": expect a qualified name (schema.name)`)
+}
+
+func TestDiff_ForeignKeyDeferrable(t *testing.T) {
+	from := schema.NewTable("posts").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	from.AddForeignKeys(schema.NewForeignKey("author_fk").AddColumns(from.Columns[0]).SetRefTable(from).AddRefColumns(from.Columns[0]))
+	to := schema.NewTable("posts").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	to.AddForeignKeys(schema.NewForeignKey("author_fk").AddColumns(to.Columns[0]).SetRefTable(to).AddRefColumns(to.Columns[0]).AddAttrs(&Deferrable{}))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyForeignKey{From: from.ForeignKeys[0], To: to.ForeignKeys[0], Change: schema.ChangeAttr},
+	}, changes)
+
+	from.ForeignKeys[0].AddAttrs(&Deferrable{})
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_ForeignKeyActions(t *testing.T) {
+	from := schema.NewTable("posts").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	from.AddForeignKeys(schema.NewForeignKey("author_fk").AddColumns(from.Columns[0]).SetRefTable(from).AddRefColumns(from.Columns[0]).SetOnDelete(schema.Cascade))
+	to := schema.NewTable("posts").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("author_id", "int"))
+	to.AddForeignKeys(schema.NewForeignKey("author_fk").AddColumns(to.Columns[0]).SetRefTable(to).AddRefColumns(to.Columns[0]).SetOnDelete("cascade").SetOnUpdate("NOACTION"))
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	to.ForeignKeys[0].SetOnUpdate("set_null")
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyForeignKey{From: from.ForeignKeys[0], To: to.ForeignKeys[0], Change: schema.ChangeUpdateAction},
+	}, changes)
+}
+
+type auditAttr struct {
+	schema.Attr
+	Level string
+}
+
+func TestDiff_AttrDiffer(t *testing.T) {
+	table := func(level string) *schema.Table {
+		c := schema.NewIntColumn("id", "int")
+		if level != "" {
+			c.AddAttrs(&auditAttr{Level: level})
+		}
+		return schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(c).
+			AddAttrs(&auditAttr{Level: "all"})
+	}
+	from, to := table(""), table("")
+	to.Attrs[0].(*auditAttr).Level = "ddl"
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes, "custom attributes are ignored without a differ")
+
+	var compared [][2]schema.Attr
+	differ := schema.WithAttrDiffer(&auditAttr{}, func(from, to schema.Attr) (bool, string) {
+		compared = append(compared, [2]schema.Attr{from, to})
+		switch {
+		case from == nil || to == nil:
+			return true, ""
+		case from.(*auditAttr).Level == to.(*auditAttr).Level:
+			return false, ""
+		default:
+			return true, fmt.Sprintf("SELECT audit_table('users', '%s')", to.(*auditAttr).Level)
+		}
+	})
+	changes, err = diffWith(differ).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, compared, 1)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{
+			From:  from.Attrs[0],
+			To:    to.Attrs[0],
+			Extra: []schema.Clause{&schema.AttrStmt{Cmd: "SELECT audit_table('users', 'ddl')"}},
+		},
+	}, changes)
+
+	// Column attributes are reported as table changes, along with their columns.
+	from, to = table(""), table("all")
+	changes, err = diffWith(differ).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.AddAttr{
+			A:     to.Columns[0].Attrs[0],
+			Extra: []schema.Clause{&schema.AttrStmt{C: to.Columns[0]}},
+		},
+	}, changes)
+	changes, err = diffWith(differ, schema.WithIgnoreAttrs(&auditAttr{})).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_PartitionBounds(t *testing.T) {
+	table := func(parts ...*PartitionBound) *schema.Table {
+		c := schema.NewTimeColumn("day", "date")
+		return schema.NewTable("logs").
+			SetSchema(schema.New("public")).
+			AddColumns(c).
+			AddAttrs(
+				&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}},
+				&PartitionBounds{Parts: parts},
+			)
+	}
+	from := table(
+		&PartitionBound{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+		&PartitionBound{Name: "logs_default", Bound: "DEFAULT"},
+	)
+	changes, err := DefaultDiff.TableDiff(from, table(
+		&PartitionBound{Name: "logs_default", Bound: "default"},
+		&PartitionBound{Name: "logs_2024_01", Bound: "for values from ('2024-01-01'::date)  to ('2024-02-01')"},
+	))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Partitions are not managed if they are not listed.
+	to := table()
+	to.Attrs = to.Attrs[:1]
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	to = table(
+		&PartitionBound{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-01-15')"},
+		&PartitionBound{Name: "logs_default", Bound: "DEFAULT"},
+	)
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: from.Attrs[1], To: to.Attrs[1]},
+	}, changes)
+
+	for _, parts := range [][]*PartitionBound{
+		{
+			{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+			{Name: "logs_2024_01b", Bound: "FOR VALUES FROM ('2024-01-15') TO ('2024-03-01')"},
+		},
+		{
+			{Name: "logs_2024_01", Bound: "FOR VALUES FROM (MINVALUE) TO ('2024-02-01')"},
+			{Name: "logs_2024_01b", Bound: "FOR VALUES FROM ('2023-01-01') TO (MAXVALUE)"},
+		},
+		{
+			{Name: "logs_2024_01", Bound: "FOR VALUES IN (1, 2)"},
+			{Name: "logs_2024_01b", Bound: "FOR VALUES IN (3, 2)"},
+		},
+		{
+			{Name: "logs_2024_01", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+			{Name: "logs_2024_01b", Bound: "FOR VALUES WITH (MODULUS 4, REMAINDER 3)"},
+		},
+	} {
+		_, err = DefaultDiff.TableDiff(from, table(parts...))
+		require.EqualError(t, err, `postgres: partition "logs_2024_01b" of table "logs" overlaps partition "logs_2024_01"`)
+	}
+	for _, parts := range [][]*PartitionBound{
+		{
+			{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+			{Name: "logs_2024_02", Bound: "FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')"},
+		},
+		{
+			{Name: "logs_1", Bound: "FOR VALUES FROM (1, MINVALUE) TO (1, 100)"},
+			{Name: "logs_2", Bound: "FOR VALUES FROM (1, 100) TO (2, 0)"},
+		},
+		{
+			{Name: "logs_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+			{Name: "logs_2", Bound: "FOR VALUES WITH (MODULUS 4, REMAINDER 3)"},
+		},
+	} {
+		_, err = DefaultDiff.TableDiff(from, table(parts...))
+		require.NoError(t, err)
+	}
+}
+
+func TestDiff_TableOwner(t *testing.T) {
+	var (
+		from = schema.NewTable("users").SetSchema(schema.New("public")).AddAttrs(&TableOwner{V: "admin"})
+		to   = schema.NewTable("users").SetSchema(schema.New("public"))
+	)
+	// Ownership is not managed by the desired state.
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Ownership was not inspected in the current state (disabled by default).
+	to.AddAttrs(&TableOwner{V: "app"})
+	changes, err = DefaultDiff.TableDiff(schema.NewTable("users").SetSchema(schema.New("public")), to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyAttr{From: &TableOwner{V: "admin"}, To: &TableOwner{V: "app"}}}, changes)
+
+	changes, err = DefaultDiff.TableDiff(to, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_ColumnCollation(t *testing.T) {
+	var (
+		from = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewStringColumn("name", "text"))
+		to   = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewStringColumn("name", "text").SetCollation("default"))
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	to.Columns[0].SetCollation("C")
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeCollate}}, changes)
+}
+
+func TestDiff_IdentityBounds(t *testing.T) {
+	var (
+		from = schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "int").AddAttrs(&Identity{Sequence: &Sequence{Start: 1, Increment: 1, Min: i64(1), Max: i64(2147483647)}}))
+		to = schema.NewTable("users").SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "int").AddAttrs(&Identity{}))
+	)
+	// NO MINVALUE and NO MAXVALUE resolve to the implicit bounds of the type.
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	to.Columns[0].Attrs = []schema.Attr{&Identity{Sequence: &Sequence{Max: i64(1000)}}}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr}}, changes)
+
+	// Descending sequences are bounded by the minimum value of the type.
+	from.Columns[0].Attrs = []schema.Attr{&Identity{Sequence: &Sequence{Start: -1, Increment: -1, Min: i64(-32768), Max: i64(-1)}}}
+	from.Columns[0].Type.Type = &schema.IntegerType{T: "smallint"}
+	to = schema.NewTable("users").SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "smallint").AddAttrs(&Identity{Sequence: &Sequence{Start: -1, Increment: -1}}))
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Zero is a valid bound, and it is not treated as NO MAXVALUE.
+	to.Columns[0].Attrs = []schema.Attr{&Identity{Sequence: &Sequence{Start: -1, Increment: -1, Max: i64(0)}}}
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr}}, changes)
+}
+
+func i64(v int64) *int64 { return &v }
+
+func TestDiff_ColumnOrder(t *testing.T) {
+	var (
+		warns []string
+		from  = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("c", "int"))
+		to = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("c", "int"), schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"))
+	)
+	changes, err := diffWith(schema.WithWarnings(func(w string) { warns = append(warns, w) })).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, []string{`table "users": columns are not reordered, the resulting column order is (a, b, c) instead of (c, a, b)`}, warns)
+
+	// New columns are added at the end of the table.
+	warns = nil
+	to = schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("d", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("c", "int"))
+	changes, err = diffWith(schema.WithWarnings(func(w string) { warns = append(warns, w) })).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddColumn{C: to.Columns[1]}}, changes)
+	require.Equal(t, []string{`table "users": columns are not reordered, the resulting column order is (a, b, c, d) instead of (a, d, b, c)`}, warns)
+
+	warns = nil
+	to = schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("c", "int"), schema.NewIntColumn("d", "int"))
+	_, err = diffWith(schema.WithWarnings(func(w string) { warns = append(warns, w) })).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, warns)
+}
+
+func TestDiff_AdditiveOnly(t *testing.T) {
+	public := schema.New("public")
+	from := schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text")),
+		schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	public.AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("age", "int")),
+		schema.NewTable("posts").AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	changes, err := DefaultDiff.SchemaDiff(from, public)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	changes, err = diffWith(schema.WithAdditiveOnly()).SchemaDiff(from, public)
+	require.NoError(t, err)
+	users, _ := public.Table("users")
+	posts, _ := public.Table("posts")
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: users.Columns[1]}}},
+		&schema.AddTable{T: posts},
+	}, changes)
+
+	// Adding a foreign key to a column whose type is changed.
+	posts.AddColumns(schema.NewIntColumn("author_id", "bigint"))
+	posts.AddForeignKeys(schema.NewForeignKey("author_id").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	_, err = diffWith(schema.WithAdditiveOnly()).SchemaDiff(from, public)
+	require.EqualError(t, err, `sql/schema: foreign key "author_id" depends on skipped change of column "id"`)
+}
+
+func TestDiff_MergeDropAdd(t *testing.T) {
+	from := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("name", "text"))
+	from.AddIndexes(schema.NewUniqueIndex("users_name_key").AddColumns(from.Columns[0]))
+	to := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("name", "text"))
+	to.AddIndexes(
+		schema.NewIndex("").
+			AddColumns(to.Columns[0]).
+			AddAttrs(&IndexPredicate{P: "name <> ''"}),
+	)
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropIndex{I: from.Indexes[0]},
+		&schema.AddIndex{I: to.Indexes[0]},
+	}, changes)
+
+	changes, err = diffWith(schema.WithMergeDropAdd()).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyIndex{From: from.Indexes[0], To: to.Indexes[0], Change: schema.ChangeUnique | schema.ChangeAttr},
+	}, changes)
+
+	// Generation expressions cannot be modified in place,
+	// but can be dropped on servers that support it.
+	require.False(t, (&diff{}).SupportsModify(&schema.ModifyColumn{Change: schema.ChangeGenerated}))
+	gen := schema.NewIntColumn("c", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "a+1", Type: "STORED"})
+	d13 := &diff{conn{version: 13_00_00}}
+	require.True(t, d13.SupportsModify(&schema.ModifyColumn{From: gen, To: schema.NewIntColumn("c", "int"), Change: schema.ChangeGenerated}))
+	require.False(t, d13.SupportsModify(&schema.ModifyColumn{From: schema.NewIntColumn("c", "int"), To: gen, Change: schema.ChangeGenerated}))
+}
+
+func TestDiff_IgnoreAttrs(t *testing.T) {
+	from := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		SetComment("users").
+		AddColumns(
+			schema.NewIntColumn("id", "int").SetComment("id"),
+			schema.NewStringColumn("name", "text"),
+		).
+		AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "70"}}})
+	from.AddIndexes(schema.NewIndex("users_name").AddColumns(from.Columns[1]))
+	to := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		SetComment("all users").
+		AddColumns(
+			schema.NewIntColumn("id", "int").SetComment("user id"),
+			schema.NewStringColumn("name", "text").SetComment("user name"),
+		)
+	to.AddIndexes(schema.NewIndex("users_name").AddColumns(to.Columns[1]).SetComment("name index"))
+
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	changes, err = diffWith(schema.WithIgnoreAttrs(&schema.Comment{})).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.DropAttr{A: from.Attrs[1]},
+	}, changes)
+
+	// Options are applied on top of the existing ones.
+	ignoreComments := diffWith(schema.WithIgnoreAttrs(&schema.Comment{}))
+	changes, err = ignoreComments.(schema.DifferWithOptions).WithOptions(schema.WithIgnoreAttrs(&TableStorageParams{})).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	changes, err = ignoreComments.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	// Column type changes are not affected.
+	to.Columns[0].SetType(&schema.IntegerType{T: "bigint"})
+	changes, err = diffWith(schema.WithIgnoreAttrs(&schema.Comment{}, &TableStorageParams{})).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeType},
+	}, changes)
+}
+
+func TestDiff_StructuralOnly(t *testing.T) {
+	from := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		SetComment("users").
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("name", "text").AddAttrs(&Compression{V: "pglz"}),
+		).
+		AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "70"}}})
+	from.AddIndexes(schema.NewIndex("users_name").AddColumns(from.Columns[1]))
+	to := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		SetComment("all users").
+		AddColumns(
+			schema.NewIntColumn("id", "int").SetComment("user id"),
+			schema.NewStringColumn("name", "text").AddAttrs(&Compression{V: "lz4"}),
+		).
+		AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "90"}}})
+	to.AddIndexes(
+		schema.NewIndex("users_name").
+			AddColumns(to.Columns[1]).
+			AddAttrs(&IndexStorageParams{PagesPerRange: 64}),
+	)
+
+	changes, err := DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	changes, err = diffWith(schema.WithStructuralOnly()).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	// Structural changes are still reported.
+	to.Columns[1].SetType(&schema.StringType{T: "varchar", Size: 255})
+	to.AddChecks(schema.NewCheck().SetName("name_len").SetExpr("length(name) > 0"))
+	changes, err = diffWith(schema.WithStructuralOnly()).TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.AddCheck{C: to.Attrs[2].(*schema.Check)},
+		&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[1], Change: schema.ChangeType},
+	}, changes)
+}
+
 func TestDefaultDiff(t *testing.T) {
 	changes, err := DefaultDiff.SchemaDiff(
 		schema.New("public").
@@ -523,3 +2065,301 @@ func TestDefaultDiff(t *testing.T) {
 	require.Len(t, changes, 1)
 	require.IsType(t, &schema.DropTable{}, changes[0])
 }
+
+func TestClassifyColumnChange(t *testing.T) {
+	col := func(typ schema.Type) *schema.Column {
+		return &schema.Column{Name: "c", Type: &schema.ColumnType{Type: typ}}
+	}
+	tests := []struct {
+		from, to *schema.Column
+		kind     schema.ChangeKind
+		want     Risk
+	}{
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.IntegerType{T: "bigint"}),
+			kind: schema.ChangeType,
+			want: RiskNone,
+		},
+		{
+			from: col(&schema.IntegerType{T: "bigint"}),
+			to:   col(&schema.IntegerType{T: "int4"}),
+			kind: schema.ChangeType,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.DecimalType{T: "numeric", Precision: 12}),
+			kind: schema.ChangeType,
+			want: RiskNone,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 255}),
+			to:   col(&schema.StringType{T: "character varying", Size: 50}),
+			kind: schema.ChangeType,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 50}),
+			to:   col(&schema.StringType{T: "varchar", Size: 255}),
+			kind: schema.ChangeType,
+			want: RiskNone,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 50}),
+			to:   col(&schema.StringType{T: "char", Size: 50}),
+			kind: schema.ChangeType,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.StringType{T: "text"}),
+			to:   col(&schema.StringType{T: "varchar", Size: 10}),
+			kind: schema.ChangeType,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.StringType{T: "text"}),
+			kind: schema.ChangeType,
+			want: RiskNone,
+		},
+		{
+			from: col(&schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}),
+			to:   col(&schema.DecimalType{T: "numeric", Precision: 8, Scale: 2}),
+			kind: schema.ChangeType,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.EnumType{T: "status", Values: []string{"a", "b"}}),
+			to:   col(&schema.EnumType{T: "status", Values: []string{"a"}}),
+			kind: schema.ChangeType,
+			want: RiskDestructive,
+		},
+		{
+			from: col(&schema.StringType{T: "text"}),
+			to:   col(&schema.IntegerType{T: "int"}),
+			kind: schema.ChangeType,
+			want: RiskDestructive,
+		},
+		{
+			from: &schema.Column{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}, Default: &schema.Literal{V: "1"}},
+			to:   col(&schema.IntegerType{T: "int"}),
+			kind: schema.ChangeDefault,
+			want: RiskLossy,
+		},
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.IntegerType{T: "int"}).SetGeneratedExpr(&schema.GeneratedExpr{Expr: "1"}),
+			kind: schema.ChangeGenerated,
+			want: RiskDestructive,
+		},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, ClassifyColumnChange(tt.from, tt.to, tt.kind))
+	}
+}
+
+func TestRequiresRewrite(t *testing.T) {
+	col := func(typ schema.Type) *schema.Column {
+		return &schema.Column{Name: "c", Type: &schema.ColumnType{Type: typ}}
+	}
+	p := func(i int) *int { return &i }
+	tests := []struct {
+		from, to *schema.Column
+		want     bool
+	}{
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 50}),
+			to:   col(&schema.StringType{T: "varchar", Size: 100}),
+			want: false,
+		},
+		{
+			from: col(&IntervalType{T: "interval", F: "DAY TO SECOND", Precision: p(3)}),
+			to:   col(&IntervalType{T: "interval", Precision: p(6)}),
+			want: false,
+		},
+		{
+			from: col(&IntervalType{T: "interval", Precision: p(6)}),
+			to:   col(&IntervalType{T: "interval", F: "DAY TO SECOND", Precision: p(6)}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 100}),
+			to:   col(&schema.StringType{T: "varchar", Size: 50}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 100}),
+			to:   col(&schema.StringType{T: "text"}),
+			want: false,
+		},
+		{
+			from: col(&schema.StringType{T: "text"}),
+			to:   col(&schema.StringType{T: "varchar", Size: 100}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: "char", Size: 10}),
+			to:   col(&schema.StringType{T: "text"}),
+			want: true,
+		},
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.IntegerType{T: "bigint"}),
+			want: true,
+		},
+		{
+			from: col(&schema.IntegerType{T: "int"}),
+			to:   col(&schema.StringType{T: "text"}),
+			want: true,
+		},
+		{
+			from: col(&schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}),
+			to:   col(&schema.DecimalType{T: "numeric", Precision: 12, Scale: 2}),
+			want: false,
+		},
+		{
+			from: col(&schema.DecimalType{T: "numeric", Precision: 10, Scale: 2}),
+			to:   col(&schema.DecimalType{T: "numeric", Precision: 12, Scale: 4}),
+			want: true,
+		},
+		{
+			from: col(&schema.TimeType{T: "timestamp", Precision: p(3)}),
+			to:   col(&schema.TimeType{T: "timestamp"}),
+			want: false,
+		},
+		{
+			from: col(&schema.TimeType{T: "timestamp"}),
+			to:   col(&schema.TimeType{T: "timestamp with time zone"}),
+			want: true,
+		},
+		{
+			from: col(&BitType{T: TypeBitVar, Len: 8}),
+			to:   col(&BitType{T: TypeBitVar, Len: 16}),
+			want: false,
+		},
+		{
+			from: col(&BitType{T: TypeBit, Len: 8}),
+			to:   col(&BitType{T: TypeBit, Len: 16}),
+			want: true,
+		},
+		{
+			from: col(&BitType{T: TypeBit}),
+			to:   col(&BitType{T: TypeBit, Len: 1}),
+			want: false,
+		},
+		{
+			from: col(&NetworkType{T: TypeCIDR}),
+			to:   col(&NetworkType{T: TypeInet}),
+			want: false,
+		},
+		{
+			from: col(&XMLType{T: TypeXML}),
+			to:   col(&schema.StringType{T: "text"}),
+			want: false,
+		},
+	}
+	for i, tt := range tests {
+		require.Equal(t, tt.want, RequiresRewrite(tt.from, tt.to), i)
+	}
+}
+
+func TestTypeCompatible(t *testing.T) {
+	col := func(typ schema.Type) *schema.Column {
+		return &schema.Column{Name: "c", Type: &schema.ColumnType{Type: typ}}
+	}
+	tests := []struct {
+		from, to *schema.Column
+		want     bool
+	}{
+		{
+			from: col(&schema.IntegerType{T: TypeInteger}),
+			to:   col(&schema.IntegerType{T: TypeInteger}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 50}),
+			to:   col(&schema.StringType{T: "varchar", Size: 100}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: "varchar", Size: 100}),
+			to:   col(&schema.StringType{T: "varchar", Size: 50}),
+			want: false,
+		},
+		{
+			from: col(&schema.IntegerType{T: TypeInteger}),
+			to:   col(&schema.IntegerType{T: TypeBigInt}),
+			want: true,
+		},
+		{
+			from: col(&schema.IntegerType{T: TypeBigInt}),
+			to:   col(&schema.IntegerType{T: TypeInteger}),
+			want: false,
+		},
+		{
+			from: col(&schema.IntegerType{T: TypeInteger}),
+			to:   col(&schema.StringType{T: TypeText}),
+			want: true,
+		},
+		{
+			from: col(&schema.StringType{T: TypeText}),
+			to:   col(&schema.IntegerType{T: TypeInteger}),
+			want: false,
+		},
+		{
+			from: col(&BitType{T: TypeBit, Len: 8}),
+			to:   col(&BitType{T: TypeBit, Len: 16}),
+			want: false,
+		},
+		{
+			from: col(&BitType{T: TypeBit, Len: 8}),
+			to:   col(&BitType{T: TypeBitVar, Len: 16}),
+			want: true,
+		},
+		{
+			from: col(&schema.EnumType{T: "status", Values: []string{"a"}}),
+			to:   col(&schema.EnumType{T: "state", Values: []string{"a", "b"}}),
+			want: false,
+		},
+		{
+			from: col(&NetworkType{T: TypeCIDR}),
+			to:   col(&NetworkType{T: TypeInet}),
+			want: true,
+		},
+	}
+	for i, tt := range tests {
+		ok, err := TypeCompatible(tt.from, tt.to)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, ok, i)
+	}
+}
+
+func TestDiff_XMLDefaultExpr(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(`SELECT (xmlelement(name a, 'text'))::text = ('<a>text</a>')::text`)).
+		WillReturnRows(sqlmock.NewRows([]string{"equal"}).AddRow(true))
+	from := schema.NewTable("docs").AddColumns(
+		schema.NewColumn("a").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `xmlelement(name a, 'text')`}),
+	)
+	to := schema.NewTable("docs").AddColumns(
+		schema.NewColumn("a").SetType(&XMLType{T: TypeXML}).SetDefault(&schema.RawExpr{X: `'<a>text</a>'`}),
+	)
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Without a database connection, the expressions are compared as-is.
+	changes, err = DefaultDiff.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
+
+func diffWith(opts ...schema.DiffOption) schema.Differ {
+	return DefaultDiff.(schema.DifferWithOptions).WithOptions(opts...)
+}
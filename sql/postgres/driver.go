@@ -45,6 +45,9 @@ type (
 // DriverName holds the name used for registration.
 const DriverName = "postgres"
 
+// maxIdentLen is the maximum length of identifiers (NAMEDATALEN - 1).
+const maxIdentLen = 63
+
 func init() {
 	sqlclient.Register(
 		DriverName,
@@ -119,7 +122,7 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 func (d *Driver) dev() *sqlx.DevDriver {
 	return &sqlx.DevDriver{
 		Driver:     d,
-		MaxNameLen: 63,
+		MaxNameLen: maxIdentLen,
 		PatchColumn: func(s *schema.Schema, c *schema.Column) {
 			if e, ok := hasEnumType(c); ok {
 				e.Schema = s
@@ -290,6 +293,38 @@ func acquire(ctx context.Context, conn schema.ExecQuerier, id uint32, timeout ti
 	}
 }
 
+// supportsOIDs reports if the server supports tables WITH OIDS.
+// The option was removed in PostgreSQL 12.
+func (c *conn) supportsOIDs() bool {
+	return c.version < 12_00_00
+}
+
+// supportsNullsDistinct reports if the server supports the NULLS [NOT] DISTINCT clause.
+func (c *conn) supportsNullsDistinct() bool {
+	return c.version >= 15_00_00
+}
+
+// supportsDropExpression reports if the server supports the DROP EXPRESSION clause.
+func (c *conn) supportsDropExpression() bool {
+	return c.version >= 13_00_00
+}
+
+// supportsCompression reports if the server supports setting the compression method of columns.
+func (c *conn) supportsCompression() bool {
+	return c.version >= 14_00_00
+}
+
+// supportsNotNullCheck reports if the server skips the table scan of SET NOT NULL
+// in case a validated CHECK (c IS NOT NULL) constraint exists on the column.
+func (c *conn) supportsNotNullCheck() bool {
+	return c.version >= 12_00_00
+}
+
+// supportsSetAccessMethod reports if the server supports changing the access method of tables.
+func (c *conn) supportsSetAccessMethod() bool {
+	return c.version >= 15_00_00
+}
+
 // supportsIndexInclude reports if the server supports the INCLUDE clause.
 func (c *conn) supportsIndexInclude() bool {
 	return c.version >= 11_00_00
@@ -321,7 +356,8 @@ const (
 	TypeBytea   = "bytea"
 
 	TypeCharacter = "character"
-	TypeChar      = "char" // character
+	TypeChar      = "char"   // character
+	TypeBPChar    = "bpchar" // character
 	TypeCharVar   = "character varying"
 	TypeVarChar   = "varchar" // character varying
 	TypeText      = "text"
@@ -378,6 +414,8 @@ const (
 	TypeJSON        = "json"
 	TypeJSONB       = "jsonb"
 	TypeUUID        = "uuid"
+	TypeOID         = "oid"
+	TypeLO          = "lo"
 	TypeMoney       = "money"
 	TypeInterval    = "interval"
 	TypeTSQuery     = "tsquery"
@@ -409,6 +447,18 @@ const (
 	defaultPagePerRange = 128
 )
 
+// indexFillFactors holds the default fillfactor of the index methods that support it.
+// https://www.postgresql.org/docs/current/sql-createindex.html#INDEX-RELOPTION-FILLFACTOR
+var indexFillFactors = map[string]int64{
+	IndexTypeBTree:  90,
+	IndexTypeHash:   75,
+	IndexTypeGiST:   90,
+	IndexTypeSPGiST: 80,
+}
+
+// defaultAccessMethod is the built-in (and default) table access method.
+const defaultAccessMethod = "heap"
+
 // List of "GENERATED" types.
 const (
 	GeneratedTypeAlways    = "ALWAYS"
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,13 +37,40 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
-	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+	if len(schemas) == 0 {
 		return sqlx.ExcludeRealm(r, opts.Exclude)
 	}
-	if err := i.inspectTables(ctx, r, nil); err != nil {
-		return nil, err
+	mode := sqlx.ModeInspectRealm(opts)
+	if mode.Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, nil, mode); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	if mode.Is(schema.InspectObjects) {
+		if err := i.inspectObjects(ctx, r, mode); err != nil {
+			return nil, err
+		}
+		// Database-level objects are inspected only
+		// in case the entire realm was requested.
+		if len(opts.Schemas) == 0 && !i.crdb {
+			if err := i.eventTriggers(ctx, r); err != nil {
+				return nil, err
+			}
+			if err := i.publications(ctx, r); err != nil {
+				return nil, err
+			}
+			if err := i.subscriptions(ctx, r); err != nil {
+				return nil, err
+			}
+			if err := i.casts(ctx, r); err != nil {
+				return nil, err
+			}
+			if err := i.accessMethods(ctx, r); err != nil {
+				return nil, err
+			}
+		}
 	}
-	sqlx.LinkSchemaTables(schemas)
 	return sqlx.ExcludeRealm(r, opts.Exclude)
 }
 
@@ -64,17 +92,23 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
-	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
-		if err := i.inspectTables(ctx, r, opts); err != nil {
+	mode := sqlx.ModeInspectSchema(opts)
+	if mode.Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, opts, mode); err != nil {
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
 	}
+	if mode.Is(schema.InspectObjects) {
+		if err := i.inspectObjects(ctx, r, mode); err != nil {
+			return nil, err
+		}
+	}
 	return sqlx.ExcludeSchema(r.Schemas[0], opts.Exclude)
 }
 
-func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
-	if err := i.tables(ctx, r, opts); err != nil {
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions, mode schema.InspectMode) error {
+	if err := i.tables(ctx, r, opts, mode); err != nil {
 		return err
 	}
 	for _, s := range r.Schemas {
@@ -87,7 +121,7 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		if err := i.indexes(ctx, s); err != nil {
 			return err
 		}
-		if err := i.partitions(s); err != nil {
+		if err := i.partitions(ctx, s); err != nil {
 			return err
 		}
 		if err := i.fks(ctx, s); err != nil {
@@ -100,8 +134,451 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 	return nil
 }
 
+// inspectObjects inspects the schema objects that are not tables.
+func (i *inspect) inspectObjects(ctx context.Context, r *schema.Realm, mode schema.InspectMode) error {
+	// CockroachDB does not support creating these objects.
+	if i.crdb {
+		return nil
+	}
+	if err := i.collations(ctx, r); err != nil {
+		return err
+	}
+	if err := i.textSearchConfigs(ctx, r); err != nil {
+		return err
+	}
+	if err := i.defaultPrivileges(ctx, r); err != nil {
+		return err
+	}
+	if err := i.statistics(ctx, r); err != nil {
+		return err
+	}
+	if err := i.rules(ctx, r); err != nil {
+		return err
+	}
+	// Similar to tables, ownership is inspected only if it was requested explicitly.
+	if mode.Is(schema.InspectOwners) {
+		if err := i.typeOwners(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collations queries and appends the collations defined in the realm schemas.
+func (i *inspect) collations(ctx context.Context, r *schema.Realm) error {
+	var (
+		args  []any
+		query = collationsQuery
+	)
+	switch {
+	case i.version >= 17_00_00:
+		query = collationsQuery17
+	case i.version >= 15_00_00:
+		query = collationsQuery15
+	case i.version < 12_00_00:
+		query = collationsQuery11
+	}
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(query, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying collations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			deterministic      bool
+			ns, name, provider string
+			lcCollate, lcCtype sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &provider, &lcCollate, &lcCtype, &deterministic); err != nil {
+			return fmt.Errorf("postgres: scan collation information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		c := &Collation{
+			Name:             name,
+			Schema:           s,
+			Provider:         collationProviders[provider],
+			Nondeterministic: !deterministic,
+		}
+		if lcCollate.String == lcCtype.String || !lcCtype.Valid {
+			c.Locale = lcCollate.String
+		} else {
+			c.LCCollate, c.LCCtype = lcCollate.String, lcCtype.String
+		}
+		s.AddObjects(c)
+	}
+	return rows.Close()
+}
+
+// textSearchConfigs queries and appends the text search configurations defined in the realm schemas.
+func (i *inspect) textSearchConfigs(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(tsConfigsQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying text search configurations: %w", err)
+	}
+	defer rows.Close()
+	var last *TextSearchConfiguration
+	for rows.Next() {
+		var (
+			ns, name, parser string
+			token, dicts     sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &parser, &token, &dicts); err != nil {
+			return fmt.Errorf("postgres: scan text search configuration information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		if last == nil || last.Schema != s || last.Name != name {
+			last = &TextSearchConfiguration{Name: name, Schema: s, Parser: parser}
+			s.AddObjects(last)
+		}
+		if sqlx.ValidString(token) {
+			last.Mappings = append(last.Mappings, &TextSearchMapping{
+				Token: token.String,
+				Dicts: strings.Split(dicts.String, ","),
+			})
+		}
+	}
+	return rows.Close()
+}
+
+// statistics queries and appends the extended statistics defined in the realm schemas.
+// Statistics that are defined on tables that were not inspected are skipped.
+func (i *inspect) statistics(ctx context.Context, r *schema.Realm) error {
+	// Extended statistics were added in PostgreSQL 10.
+	if i.version < 10_00_00 {
+		return nil
+	}
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(statisticsQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying statistics: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			columns                    sql.NullString
+			ns, name, tns, tname, kind string
+		)
+		if err := rows.Scan(&ns, &name, &tns, &tname, &kind, &columns); err != nil {
+			return fmt.Errorf("postgres: scan statistics information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		ts, ok := r.Schema(tns)
+		if !ok {
+			continue
+		}
+		t, ok := ts.Table(tname)
+		if !ok {
+			continue
+		}
+		st := &Statistics{Name: name, Schema: s, Table: t}
+		for _, k := range strings.Split(kind, ",") {
+			if k1, ok := statisticsKinds[k]; ok {
+				st.Kinds = append(st.Kinds, k1)
+			} else if k == "e" {
+				// Statistics on expressions are not supported.
+				st = nil
+				break
+			}
+		}
+		if st == nil || !sqlx.ValidString(columns) {
+			continue
+		}
+		sort.Strings(st.Kinds)
+		if st.Columns, err = tableColumns(t, strings.Split(columns.String, ",")); err != nil {
+			return fmt.Errorf("postgres: statistics %q: %w", name, err)
+		}
+		s.AddObjects(st)
+	}
+	return rows.Close()
+}
+
+// rules queries and appends the rewrite rules defined on the tables of the realm schemas.
+// The implicit _RETURN rules of views and rules on tables that were not inspected are skipped.
+func (i *inspect) rules(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(rulesQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying rules: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			instead                  bool
+			ns, tname, name, ev, def string
+		)
+		if err := rows.Scan(&ns, &tname, &name, &ev, &instead, &def); err != nil {
+			return fmt.Errorf("postgres: scan rules information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		t, ok := s.Table(tname)
+		if !ok {
+			continue
+		}
+		matches := reRuleDef.FindStringSubmatch(def)
+		if len(matches) != 3 {
+			return fmt.Errorf("postgres: unexpected definition for rule %q: %q", name, def)
+		}
+		s.AddObjects(&Rule{
+			Name:    name,
+			Table:   t,
+			Event:   ruleEvents[ev],
+			Instead: instead,
+			Where:   matches[1],
+			Actions: matches[2],
+		})
+	}
+	return rows.Close()
+}
+
+// reRuleDef extracts the condition and the actions from the rule definition that
+// is returned by the pg_get_ruledef function. Names may be quoted and contain spaces.
+var reRuleDef = regexp.MustCompile(`(?s)^CREATE RULE (?:"(?:[^"]|"")+"|\S+) AS\s+ON (?:SELECT|INSERT|UPDATE|DELETE) TO (?:(?:"(?:[^"]|"")+"|[^\s".]+)\.)?(?:"(?:[^"]|"")+"|[^\s".]+)(?:\s+WHERE (.+?))?\s+DO\s+(?:INSTEAD\s+)?(.+?);?$`)
+
+// defaultPrivileges queries and appends the default privileges defined in the realm schemas.
+func (i *inspect) defaultPrivileges(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(defaultPrivilegesQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying default privileges: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			owner                            sql.NullString
+			ns, objType, grantee, privileges string
+		)
+		if err := rows.Scan(&ns, &owner, &objType, &grantee, &privileges); err != nil {
+			return fmt.Errorf("postgres: scan default privileges information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		t, ok := defaultPrivilegeTypes[objType]
+		if !ok {
+			return fmt.Errorf("postgres: unexpected default privileges object type %q", objType)
+		}
+		// Rules of the current role are reported without an owner,
+		// as they are defined without the FOR ROLE clause.
+		s.AddObjects(&DefaultPrivilege{
+			Schema:     s,
+			Owner:      owner.String,
+			ObjType:    t,
+			Grantee:    grantee,
+			Privileges: strings.Split(privileges, ","),
+		})
+	}
+	return rows.Close()
+}
+
+// typeOwners queries and appends the owners of the user-defined types (enums,
+// composite types and domains) defined in the realm schemas.
+func (i *inspect) typeOwners(ctx context.Context, r *schema.Realm) error {
+	args := make([]any, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(typeOwnersQuery, nArgs(0, len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying type owners: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ns, name, owner string
+		if err := rows.Scan(&ns, &name, &owner); err != nil {
+			return fmt.Errorf("postgres: scan type owner information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		s.AddObjects(&TypeOwner{Schema: s, Name: name, Owner: owner})
+	}
+	return rows.Close()
+}
+
+// eventTriggers queries and appends the event triggers defined in the database.
+func (i *inspect) eventTriggers(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, eventTriggersQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying event triggers: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			tags                             sql.NullString
+			name, event, fnSchema, fn, state string
+		)
+		if err := rows.Scan(&name, &event, &tags, &fnSchema, &fn, &state); err != nil {
+			return fmt.Errorf("postgres: scan event trigger information: %w", err)
+		}
+		e := &EventTrigger{Name: name, Event: event, FuncSchema: fnSchema, Func: fn, State: state}
+		if tags.String != "" {
+			e.Tags = strings.Split(tags.String, ",")
+		}
+		r.AddObjects(e)
+	}
+	return rows.Close()
+}
+
+// publications queries and appends the logical replication publications defined in the
+// database. Tables are resolved from the realm, in case their schemas were inspected.
+func (i *inspect) publications(ctx context.Context, r *schema.Realm) error {
+	query := publicationsQuery
+	switch {
+	case i.version < 11_00_00:
+		query = publicationsQuery10
+	case i.version < 13_00_00:
+		query = publicationsQuery12
+	}
+	rows, err := i.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("postgres: querying publications: %w", err)
+	}
+	defer rows.Close()
+	var last *Publication
+	for rows.Next() {
+		var (
+			name                                        string
+			all, insert, update, del, truncate, viaRoot bool
+			ns, table                                   sql.NullString
+		)
+		if err := rows.Scan(&name, &all, &insert, &update, &del, &truncate, &viaRoot, &ns, &table); err != nil {
+			return fmt.Errorf("postgres: scan publication information: %w", err)
+		}
+		if last == nil || last.Name != name {
+			last = &Publication{Name: name, AllTables: all, ViaRoot: viaRoot}
+			for op, ok := range map[string]bool{"insert": insert, "update": update, "delete": del, "truncate": truncate} {
+				if ok {
+					last.Publish = append(last.Publish, op)
+				}
+			}
+			last.Publish = publishOps(last.Publish)
+			r.AddObjects(last)
+		}
+		if table.Valid {
+			t, ok := realmTable(r, ns.String, table.String)
+			if !ok {
+				t = schema.NewTable(table.String).SetSchema(schema.New(ns.String))
+			}
+			last.Tables = append(last.Tables, t)
+		}
+	}
+	return rows.Close()
+}
+
+// subscriptions queries and appends the logical replication subscriptions of the current database.
+// Their connection strings are not inspected, as they are readable only by superusers.
+func (i *inspect) subscriptions(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, subscriptionsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			enabled                  bool
+			name, pubs, slot, commit sql.NullString
+		)
+		if err := rows.Scan(&name, &enabled, &pubs, &slot, &commit); err != nil {
+			return fmt.Errorf("postgres: scan subscription information: %w", err)
+		}
+		s := &Subscription{
+			Name:     name.String,
+			Disabled: !enabled,
+			Options: []struct{ N, V string }{
+				{N: "slot_name", V: slot.String},
+				{N: "synchronous_commit", V: commit.String},
+			},
+		}
+		// A subscription without a replication slot is created with slot_name = NONE.
+		if !slot.Valid {
+			s.Options[0].V = "NONE"
+		}
+		if pubs.String != "" {
+			s.Publications = strings.Split(pubs.String, ",")
+		}
+		r.AddObjects(s)
+	}
+	return rows.Close()
+}
+
+// casts queries and appends the user-defined casts defined in the database.
+func (i *inspect) casts(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, castsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying casts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			fn                              sql.NullString
+			source, target, method, context string
+		)
+		if err := rows.Scan(&source, &target, &method, &fn, &context); err != nil {
+			return fmt.Errorf("postgres: scan cast information: %w", err)
+		}
+		r.AddObjects(&Cast{Source: source, Target: target, Func: fn.String, InOut: method == "i", Context: context})
+	}
+	return rows.Close()
+}
+
+// accessMethods queries and appends the custom access methods defined in the database.
+func (i *inspect) accessMethods(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, accessMethodsQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying access methods: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, typ, handler string
+		if err := rows.Scan(&name, &typ, &handler); err != nil {
+			return fmt.Errorf("postgres: scan access method information: %w", err)
+		}
+		r.AddObjects(&AccessMethod{Name: name, Type: typ, Handler: handler})
+	}
+	return rows.Close()
+}
+
+// realmTable returns the table with the given schema and name from the realm.
+func realmTable(r *schema.Realm, ns, name string) (*schema.Table, bool) {
+	s, ok := r.Schema(ns)
+	if !ok {
+		return nil, false
+	}
+	return s.Table(name)
+}
+
 // table returns the table from the database, or a NotExistError if the table was not found.
-func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
+func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions, mode schema.InspectMode) error {
 	var (
 		args  []any
 		query = fmt.Sprintf(tablesQuery, nArgs(0, len(realm.Schemas)))
@@ -115,14 +592,19 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 		}
 		query = fmt.Sprintf(tablesQueryArgs, nArgs(0, len(realm.Schemas)), nArgs(len(realm.Schemas), len(opts.Tables)))
 	}
+	// On servers that support the legacy WITH OIDS option,
+	// it is reported as part of the table storage parameters.
+	if !i.crdb && i.supportsOIDs() {
+		query = strings.Replace(query, "t3.reloptions AS storage_params", tableOIDsParams, 1)
+	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var tSchema, name, comment, partattrs, partstart, partexprs sql.NullString
-		if err := rows.Scan(&tSchema, &name, &comment, &partattrs, &partstart, &partexprs); err != nil {
+		var tSchema, name, comment, partattrs, partstart, partexprs, params, toast, owner, am sql.NullString
+		if err := rows.Scan(&tSchema, &name, &comment, &partattrs, &partstart, &partexprs, &params, &toast, &owner, &am); err != nil {
 			return fmt.Errorf("scan table information: %w", err)
 		}
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
@@ -144,6 +626,17 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				exprs: partexprs.String,
 			})
 		}
+		if p := newTableStorage(params.String, toast.String); len(p.Params) > 0 {
+			t.AddAttrs(p)
+		}
+		// Ownership is inspected only if it was requested explicitly.
+		if sqlx.ValidString(owner) && mode.Is(schema.InspectOwners) {
+			t.AddAttrs(&TableOwner{V: owner.String})
+		}
+		// The default access method is not stored.
+		if sqlx.ValidString(am) && am.String != defaultAccessMethod {
+			t.AddAttrs(&TableAccessMethod{V: am.String})
+		}
 	}
 	return rows.Close()
 }
@@ -151,8 +644,11 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 // columns queries and appends the columns of the given table.
 func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
 	query := columnsQuery
-	if i.crdb {
+	switch {
+	case i.crdb:
 		query = crdbColumnsQuery
+	case i.supportsCompression():
+		query = columnsQuery14
 	}
 	rows, err := i.querySchema(ctx, query, s)
 	if err != nil {
@@ -176,13 +672,21 @@ func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
 // addColumn scans the current row and adds a new column from it to the table.
 func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 	var (
-		typid, typelem, maxlen, precision, timeprecision, scale, seqstart, seqinc, seqlast                                                  sql.NullInt64
-		table, name, typ, fmtype, nullable, defaults, identity, genidentity, genexpr, charset, collate, comment, typtype, elemtyp, interval sql.NullString
+		typid, typelem, maxlen, precision, timeprecision, scale, seqstart, seqinc, seqlast, seqmin, seqmax                                               sql.NullInt64
+		table, name, typ, fmtype, nullable, defaults, identity, genidentity, genexpr, charset, collate, comment, typtype, elemtyp, interval, compression sql.NullString
+		dest                                                                                                                                             = []any{
+			&table, &name, &typ, &fmtype, &nullable, &defaults, &maxlen, &precision, &timeprecision, &scale, &interval, &charset,
+			&collate, &identity, &seqstart, &seqinc, &seqlast, &genidentity, &genexpr, &comment, &typtype, &typelem, &elemtyp, &typid,
+		}
 	)
-	if err = rows.Scan(
-		&table, &name, &typ, &fmtype, &nullable, &defaults, &maxlen, &precision, &timeprecision, &scale, &interval, &charset,
-		&collate, &identity, &seqstart, &seqinc, &seqlast, &genidentity, &genexpr, &comment, &typtype, &typelem, &elemtyp, &typid,
-	); err != nil {
+	if !i.crdb {
+		dest = append(dest, &seqmin, &seqmax)
+	}
+	// The compression method is reported only by servers that support it.
+	if !i.crdb && i.supportsCompression() {
+		dest = append(dest, &compression)
+	}
+	if err = rows.Scan(dest...); err != nil {
 		return err
 	}
 	t, ok := s.Table(table.String)
@@ -219,6 +723,8 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 				Last:      seqlast.Int64,
 				Start:     seqstart.Int64,
 				Increment: seqinc.Int64,
+				Min:       &seqmin.Int64,
+				Max:       &seqmax.Int64,
 			},
 		})
 	}
@@ -236,6 +742,9 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) (err error) {
 	if sqlx.ValidString(collate) {
 		c.SetCollation(collate.String)
 	}
+	if sqlx.ValidString(compression) {
+		c.Attrs = append(c.Attrs, &Compression{V: compression.String})
+	}
 	t.Columns = append(t.Columns, c)
 	return nil
 }
@@ -305,6 +814,8 @@ func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
 		return i.crdbIndexes(ctx, s)
 	case !i.conn.supportsIndexInclude():
 		query = indexesQueryNoInclude
+	case i.conn.supportsNullsDistinct():
+		query = indexesQuery15
 	}
 	rows, err := i.querySchema(ctx, query, s)
 	if err != nil {
@@ -324,12 +835,12 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 		var (
 			uniq, primary, included                                               bool
 			table, name, typ                                                      string
-			desc, nullsfirst, nullslast, opcdefault                               sql.NullBool
+			desc, nullsfirst, nullslast, opcdefault, nullsnotdistinct             sql.NullBool
 			column, constraints, pred, expr, comment, options, opcname, opcparams sql.NullString
 		)
 		if err := rows.Scan(
 			&table, &name, &typ, &column, &included, &primary, &uniq, &constraints, &pred, &expr,
-			&desc, &nullsfirst, &nullslast, &comment, &options, &opcname, &opcdefault, &opcparams,
+			&desc, &nullsfirst, &nullslast, &comment, &options, &opcname, &opcdefault, &opcparams, &nullsnotdistinct,
 		); err != nil {
 			return fmt.Errorf("postgres: scanning indexes for schema %q: %w", s.Name, err)
 		}
@@ -362,6 +873,9 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 			if sqlx.ValidString(pred) {
 				idx.Attrs = append(idx.Attrs, &IndexPredicate{P: pred.String})
 			}
+			if nullsnotdistinct.Bool {
+				idx.Attrs = append(idx.Attrs, &IndexNullsDistinct{V: false})
+			}
 			if sqlx.ValidString(options) {
 				p, err := newIndexStorage(options.String)
 				if err != nil {
@@ -429,7 +943,8 @@ func mayAppendOps(part *schema.IndexPart, name string, params string, defaults b
 }
 
 // partitions builds the partition each table in the schema.
-func (i *inspect) partitions(s *schema.Schema) error {
+func (i *inspect) partitions(ctx context.Context, s *schema.Schema) error {
+	var parents []*schema.Table
 	for _, t := range s.Tables {
 		var d Partition
 		if !sqlx.Has(t.Attrs, &d) {
@@ -474,8 +989,44 @@ func (i *inspect) partitions(s *schema.Schema) error {
 			}
 		}
 		schema.ReplaceOrAppend(&t.Attrs, &d)
+		parents = append(parents, t)
 	}
-	return nil
+	if len(parents) == 0 || i.crdb {
+		return nil
+	}
+	return i.partitionBounds(ctx, s, parents)
+}
+
+// partitionBounds queries and appends the partition bounds of the given tables.
+func (i *inspect) partitionBounds(ctx context.Context, s *schema.Schema, parents []*schema.Table) error {
+	args := []any{s.Name}
+	for _, t := range parents {
+		args = append(args, t.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(partitionBoundsQuery, nArgs(1, len(parents))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q partition bounds: %w", s.Name, err)
+	}
+	defer rows.Close()
+	bounds := make(map[string]*PartitionBounds)
+	for rows.Next() {
+		var parent, name, bound string
+		if err := rows.Scan(&parent, &name, &bound); err != nil {
+			return fmt.Errorf("postgres: scanning partition bounds: %w", err)
+		}
+		b, ok := bounds[parent]
+		if !ok {
+			t, ok := s.Table(parent)
+			if !ok {
+				return fmt.Errorf("table %q was not found in schema", parent)
+			}
+			b = &PartitionBounds{}
+			t.AddAttrs(b)
+			bounds[parent] = b
+		}
+		b.Parts = append(b.Parts, &PartitionBound{Name: name, Bound: bound})
+	}
+	return rows.Err()
 }
 
 // fks queries and appends the foreign keys of the given table.
@@ -485,7 +1036,12 @@ func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 		return fmt.Errorf("postgres: querying schema %q foreign keys: %w", s.Name, err)
 	}
 	defer rows.Close()
-	if err := sqlx.SchemaFKs(s, rows); err != nil {
+	var deferrable, deferred bool
+	if err := sqlx.SchemaFKsFunc(s, rows, []any{&deferrable, &deferred}, func(fk *schema.ForeignKey) {
+		if deferrable && !sqlx.Has(fk.Attrs, &Deferrable{}) {
+			fk.AddAttrs(&Deferrable{InitiallyDeferred: deferred})
+		}
+	}); err != nil {
 		return fmt.Errorf("postgres: %w", err)
 	}
 	return rows.Err()
@@ -509,10 +1065,10 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 	names := make(map[string]*schema.Check)
 	for rows.Next() {
 		var (
-			noInherit                            bool
+			noInherit, isLocal                   bool
 			table, name, column, clause, indexes string
 		)
-		if err := rows.Scan(&table, &name, &clause, &column, &indexes, &noInherit); err != nil {
+		if err := rows.Scan(&table, &name, &clause, &column, &indexes, &noInherit, &isLocal); err != nil {
 			return fmt.Errorf("postgres: scanning check: %w", err)
 		}
 		t, ok := s.Table(table)
@@ -528,6 +1084,9 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 			if noInherit {
 				check.Attrs = append(check.Attrs, &NoInherit{})
 			}
+			if !isLocal {
+				check.Attrs = append(check.Attrs, &Inherited{})
+			}
 			names[name] = check
 			t.Attrs = append(t.Attrs, check)
 		}
@@ -640,7 +1199,7 @@ func canConvert(t *schema.ColumnType, x string) (string, bool) {
 		if sqlx.IsLiteralBool(x) {
 			return x, true
 		}
-	case *schema.DecimalType, *schema.IntegerType, *schema.FloatType:
+	case *schema.DecimalType, *schema.IntegerType, *schema.FloatType, *OIDType:
 		if sqlx.IsLiteralNumber(x) {
 			return x, true
 		}
@@ -743,6 +1302,13 @@ type (
 		T string
 	}
 
+	// An OIDType defines an object identifier type. For example,
+	// columns that reference large objects (pg_largeobject).
+	OIDType struct {
+		schema.Type
+		T string
+	}
+
 	// A XMLType defines an XML type.
 	XMLType struct {
 		schema.Type
@@ -764,6 +1330,10 @@ type (
 		// Last sequence value written to disk.
 		// https://postgresql.org/docs/current/view-pg-sequences.html.
 		Last int64
+		// Min and Max hold the bounds of the sequence. Nil values stand for
+		// NO MINVALUE and NO MAXVALUE, which resolve to the implicit bounds of
+		// the column type and the direction of the sequence. See identityBounds.
+		Min, Max *int64
 	}
 
 	// Identity defines an identity column.
@@ -787,6 +1357,23 @@ type (
 		P string
 	}
 
+	// Compression describes the compression method of a column (e.g. pglz or lz4).
+	// Columns without an explicit method use the default_toast_compression setting.
+	// https://www.postgresql.org/docs/current/sql-altertable.html#SQL-ALTERTABLE-DESC-SET-COMPRESSION
+	Compression struct {
+		schema.Attr
+		V string
+	}
+
+	// IndexNullsDistinct describes the NULLS [NOT] DISTINCT clause of a unique index.
+	// NULL values are considered distinct by default, and therefore, the attribute
+	// is reported only if the index was defined with NULLS NOT DISTINCT.
+	// https://www.postgresql.org/docs/current/sql-createindex.html
+	IndexNullsDistinct struct {
+		schema.Attr
+		V bool
+	}
+
 	// IndexColumnProperty describes an index column property.
 	// https://postgresql.org/docs/current/functions-info.html#FUNCTIONS-INFO-INDEX-COLUMN-PROPS
 	IndexColumnProperty struct {
@@ -806,6 +1393,16 @@ type (
 		// PagesPerRange defines pages_per_range storage
 		// parameter for BRIN indexes. Defaults to 128.
 		PagesPerRange int64
+		// FastUpdate defines the fastupdate storage parameter
+		// for GIN indexes. A nil value means the default (on).
+		FastUpdate *bool
+		// PendingListLimit defines the gin_pending_list_limit storage
+		// parameter for GIN indexes (in kilobytes). A zero value means
+		// the value of the gin_pending_list_limit server parameter.
+		PendingListLimit int64
+		// FillFactor defines the fillfactor storage parameter. A zero
+		// value means the default of the index method (e.g. 90 for BTREE).
+		FillFactor int64
 	}
 
 	// IndexInclude describes the INCLUDE clause allows specifying
@@ -832,12 +1429,51 @@ type (
 		schema.Attr
 	}
 
+	// NotValid is a clause for FOREIGN KEY and CHECK constraint additions that
+	// creates the constraint without scanning the table to validate existing rows.
+	// If Validate is set, the constraint is validated (VALIDATE CONSTRAINT) at the
+	// end of the plan, after all other changes were applied.
+	// https://www.postgresql.org/docs/current/sql-altertable.html#SQL-ALTERTABLE-NOTES
+	NotValid struct {
+		schema.Clause
+		Validate bool
+	}
+
+	// Cascade is a clause for DROP changes that drops the objects that depend on the
+	// dropped object, and overrides the DropBehavior set by the planner options.
+	// https://www.postgresql.org/docs/current/ddl-depend.html
+	Cascade struct {
+		schema.Clause
+	}
+
+	// Restrict is a clause for DROP changes that refuses to drop the object in case
+	// other objects depend on it, and overrides the DropBehavior set by the planner
+	// options. It is the default behavior of PostgreSQL for dropping tables and columns.
+	Restrict struct {
+		schema.Clause
+	}
+
+	// Deferrable attribute defines a DEFERRABLE constraint, and whether its
+	// checking is deferred by default to the end of the transaction.
+	// https://postgresql.org/docs/current/sql-set-constraints.html
+	Deferrable struct {
+		schema.Attr
+		InitiallyDeferred bool
+	}
+
 	// NoInherit attribute defines the NO INHERIT flag for CHECK constraint.
 	// https://postgresql.org/docs/current/catalog-pg-constraint.html
 	NoInherit struct {
 		schema.Attr
 	}
 
+	// Inherited attribute marks a CHECK constraint that was inherited from a
+	// parent table (conislocal=false) and is not defined locally on the table.
+	// https://postgresql.org/docs/current/catalog-pg-constraint.html
+	Inherited struct {
+		schema.Attr
+	}
+
 	// CheckColumns attribute hold the column named used by the CHECK constraints.
 	// This attribute is added on inspection for internal usage and has no meaning
 	// on migration.
@@ -846,6 +1482,210 @@ type (
 		Columns []string
 	}
 
+	// Collation describes a collation object that was created using CREATE COLLATION.
+	// https://www.postgresql.org/docs/current/sql-createcollation.html
+	Collation struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Provider of the collation. Can be one of: libc, icu.
+		Provider string
+		// Locale sets both LC_COLLATE and LC_CTYPE. In case
+		// they are different, LCCollate and LCCtype are used.
+		Locale             string
+		LCCollate, LCCtype string
+		// Nondeterministic indicates the collation is not deterministic.
+		// This attribute cannot be changed once the collation is created.
+		Nondeterministic bool
+	}
+
+	// TextSearchConfiguration describes a text search configuration object that
+	// was created using CREATE TEXT SEARCH CONFIGURATION.
+	// https://www.postgresql.org/docs/current/sql-createtsconfig.html
+	TextSearchConfiguration struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Parser of the configuration. Parsers that are not
+		// defined in the pg_catalog schema are qualified.
+		Parser string
+		// Mappings of token types to dictionaries, sorted by token type.
+		Mappings []*TextSearchMapping
+	}
+
+	// TextSearchMapping maps a token type to the list of dictionaries
+	// to be consulted, in order, for tokens of this type.
+	TextSearchMapping struct {
+		Token string
+		Dicts []string
+	}
+
+	// Statistics describes an extended statistics object that was created
+	// using CREATE STATISTICS on the columns of a table.
+	// https://www.postgresql.org/docs/current/sql-createstatistics.html
+	Statistics struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		Table  *schema.Table
+		// Kinds of the statistics, sorted by name. Can be: dependencies, mcv, ndistinct.
+		// An empty list indicates all supported kinds are computed.
+		Kinds   []string
+		Columns []*schema.Column
+	}
+
+	// Rule describes a query rewrite rule that was created using CREATE RULE on a table.
+	// Rules are placed in the schema of their table, and their names are unique per table.
+	// https://www.postgresql.org/docs/current/sql-createrule.html
+	Rule struct {
+		schema.Object
+		Name  string
+		Table *schema.Table
+		// Event is one of: SELECT, INSERT, UPDATE or DELETE.
+		Event string
+		// Instead indicates if the actions replace the original
+		// query (DO INSTEAD), or run in addition to it (DO ALSO).
+		Instead bool
+		// Where holds the optional condition of the rule.
+		Where string
+		// Actions hold the commands of the rule, NOTHING, or multiple
+		// commands separated by semicolons and wrapped with parentheses.
+		Actions string
+	}
+
+	// DefaultPrivilege describes the privileges that are granted to a role on objects
+	// created in the schema (by the owner role) in the future. Defined using ALTER
+	// DEFAULT PRIVILEGES ... IN SCHEMA.
+	// https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	DefaultPrivilege struct {
+		schema.Object
+		Schema *schema.Schema
+		// Owner is the role that creates the objects (FOR ROLE).
+		// An empty string indicates the current role.
+		Owner string
+		// ObjType is the type of the objects the privileges apply to.
+		// Can be one of: TABLES, SEQUENCES, FUNCTIONS, TYPES.
+		ObjType string
+		// Grantee is the role that receives the privileges, or PUBLIC.
+		Grantee string
+		// Privileges that are granted, sorted by name (e.g. INSERT, SELECT).
+		Privileges []string
+	}
+
+	// TypeOwner describes the role that owns a user-defined type (enum,
+	// composite type or domain). Changed using ALTER TYPE ... OWNER TO.
+	// https://www.postgresql.org/docs/current/sql-altertype.html
+	TypeOwner struct {
+		schema.Object
+		Schema *schema.Schema
+		Name   string // Type name.
+		Owner  string // Owner role.
+	}
+
+	// EventTrigger describes a database-level trigger that fires on DDL events.
+	// Defined using CREATE EVENT TRIGGER and attached to the realm objects.
+	// https://www.postgresql.org/docs/current/sql-createeventtrigger.html
+	EventTrigger struct {
+		schema.Object
+		Name  string
+		Event string // e.g. ddl_command_start, ddl_command_end.
+		// Tags filter the command tags the trigger fires
+		// for (e.g. CREATE TABLE). Empty means all commands.
+		Tags []string
+		// The schema and name of the function to execute.
+		FuncSchema, Func string
+		// State holds the firing state of the trigger. Can be one of:
+		// ENABLE (the default), ENABLE REPLICA, ENABLE ALWAYS or DISABLE.
+		State string
+	}
+
+	// Publication describes a logical replication publication. Defined using
+	// CREATE PUBLICATION and attached to the realm objects.
+	// https://www.postgresql.org/docs/current/sql-createpublication.html
+	Publication struct {
+		schema.Object
+		Name string
+		// AllTables indicates the publication was created FOR ALL TABLES,
+		// including tables created in the future. Tables is empty in this case.
+		AllTables bool
+		Tables    []*schema.Table
+		// Publish holds the published operations (insert, update, delete,
+		// truncate). Empty means all operations, which is the default.
+		Publish []string
+		// ViaRoot defines the publish_via_partition_root parameter.
+		ViaRoot bool
+	}
+
+	// Subscription describes a logical replication subscription. Defined using
+	// CREATE SUBSCRIPTION and attached to the realm objects.
+	// https://www.postgresql.org/docs/current/sql-createsubscription.html
+	Subscription struct {
+		schema.Object
+		Name string
+		// Conn holds the connection string to the publisher. It is not inspected
+		// (readable only by superusers), and compared only if both states define it.
+		Conn         string
+		Publications []string
+		// Disabled indicates the subscription is not replicating.
+		Disabled bool
+		// Options holds the subscription parameters (e.g. slot_name). Only the
+		// options that are defined by the desired state are compared.
+		Options []struct{ N, V string }
+	}
+
+	// Cast describes a user-defined cast between two types. Defined
+	// using CREATE CAST and attached to the realm objects.
+	// https://www.postgresql.org/docs/current/sql-createcast.html
+	Cast struct {
+		schema.Object
+		Source, Target string // Formatted types, e.g. integer or public.mood.
+		// Func holds the signature of the function that performs the cast,
+		// e.g. public.mood_text(public.mood). Empty for casts that are created
+		// WITH INOUT or WITHOUT FUNCTION (binary coercible types).
+		Func  string
+		InOut bool
+		// Context holds the contexts in which the cast can be invoked
+		// implicitly: EXPLICIT (the default), ASSIGNMENT or IMPLICIT.
+		Context string
+	}
+
+	// AccessMethod describes a custom table or index access method. Defined
+	// using CREATE ACCESS METHOD and attached to the realm objects.
+	// https://www.postgresql.org/docs/current/sql-create-access-method.html
+	AccessMethod struct {
+		schema.Object
+		Name    string
+		Type    string // TABLE or INDEX.
+		Handler string // The handler function, e.g. columnar_handler.
+	}
+
+	// TableOwner describes the role that owns a table. Changed using ALTER TABLE
+	// ... OWNER TO. Table owners are inspected only with the InspectOwners mode,
+	// and compared only if they are defined by both the current and desired states.
+	// https://www.postgresql.org/docs/current/sql-altertable.html
+	TableOwner struct {
+		schema.Attr
+		V string
+	}
+
+	// TableAccessMethod describes the access method of a table. Defined using the
+	// USING clause of CREATE TABLE, and changed using ALTER TABLE ... SET ACCESS METHOD.
+	// https://www.postgresql.org/docs/current/tableam.html
+	TableAccessMethod struct {
+		schema.Attr
+		V string
+	}
+
+	// TableStorageParams describes the table storage parameters that were set
+	// with the WITH clause or changed using ALTER TABLE SET. Parameters of the
+	// TOAST table are prefixed with "toast.", and unknown parameters are kept
+	// as-is to support parameters added in future versions.
+	// https://www.postgresql.org/docs/current/sql-createtable.html#SQL-CREATETABLE-STORAGE-PARAMETERS
+	TableStorageParams struct {
+		schema.Attr
+		Params []struct{ N, V string }
+	}
+
 	// Partition defines the spec of a partitioned table.
 	Partition struct {
 		schema.Attr
@@ -860,6 +1700,22 @@ type (
 		start, attrs, exprs string
 	}
 
+	// PartitionBounds lists the partitions of a partitioned table along with their
+	// bound specifications. Partitions are expected to reside in the schema of their
+	// parent table, and partitions that are not listed are not managed by Atlas.
+	// https://www.postgresql.org/docs/current/ddl-partitioning.html
+	PartitionBounds struct {
+		schema.Attr
+		Parts []*PartitionBound
+	}
+
+	// PartitionBound describes the bound specification of a partition. For example,
+	// FOR VALUES FROM ('2024-01-01') TO ('2024-02-01'), FOR VALUES IN (1, 2) or DEFAULT.
+	PartitionBound struct {
+		Name  string // Partition (table) name.
+		Bound string // Partition bound specification.
+	}
+
 	// An PartitionPart represents an index part that
 	// can be either an expression or a column.
 	PartitionPart struct {
@@ -1032,11 +1888,151 @@ func newIndexStorage(opts string) (*IndexStorageParams, error) {
 				return nil, fmt.Errorf("failed parsing pages_per_range %q: %w", kv[1], err)
 			}
 			params.PagesPerRange = i
+		case "fastupdate":
+			b, err := parseBool(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing fastupdate %q: %w", kv[1], err)
+			}
+			params.FastUpdate = &b
+		case "gin_pending_list_limit":
+			i, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing gin_pending_list_limit %q: %w", kv[1], err)
+			}
+			params.PendingListLimit = i
+		case "fillfactor":
+			i, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing fillfactor %q: %w", kv[1], err)
+			}
+			params.FillFactor = i
 		}
 	}
 	return params, nil
 }
 
+// parseBool parses the boolean values accepted by PostgreSQL for storage parameters.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	default:
+		return strconv.ParseBool(s)
+	}
+}
+
+// newTableStorage returns the table storage parameters from
+// the reloptions of the table and its TOAST table.
+func newTableStorage(opts, toast string) *TableStorageParams {
+	params := &TableStorageParams{}
+	for _, o := range []struct{ opts, prefix string }{{opts, ""}, {toast, "toast."}} {
+		if o.opts = strings.Trim(o.opts, "{}"); o.opts == "" {
+			continue
+		}
+		for _, p := range strings.Split(o.opts, ",") {
+			n, v, _ := strings.Cut(strings.Trim(p, `"`), "=")
+			params.Params = append(params.Params, struct{ N, V string }{N: o.prefix + n, V: v})
+		}
+	}
+	return params
+}
+
+// oids reports if the table was defined WITH OIDS. The option
+// is supported only by servers older than PostgreSQL 12.
+func (p *TableStorageParams) oids() bool {
+	v, ok := p.Value("oids")
+	if !ok {
+		return false
+	}
+	b, err := parseBool(v)
+	return err == nil && b
+}
+
+// withoutOIDs returns a copy of the storage parameters without the legacy oids option.
+func (p *TableStorageParams) withoutOIDs() *TableStorageParams {
+	p1 := &TableStorageParams{Params: make([]struct{ N, V string }, 0, len(p.Params))}
+	for _, kv := range p.Params {
+		if !strings.EqualFold(kv.N, "oids") {
+			p1.Params = append(p1.Params, kv)
+		}
+	}
+	return p1
+}
+
+// Value returns the value of the given storage parameter, if it exists.
+func (p *TableStorageParams) Value(name string) (string, bool) {
+	for _, kv := range p.Params {
+		if strings.EqualFold(kv.N, name) {
+			return kv.V, true
+		}
+	}
+	return "", false
+}
+
+// Physical implements the schema.PhysicalAttr interface.
+func (*TableStorageParams) Physical() {}
+
+// Physical implements the schema.PhysicalAttr interface.
+func (*IndexStorageParams) Physical() {}
+
+// Physical implements the schema.PhysicalAttr interface.
+func (*Compression) Physical() {}
+
+// TableDependent implements the migrate.TableDependent interface.
+func (*Statistics) TableDependent() {}
+
+// TableDependent implements the migrate.TableDependent interface.
+func (*Rule) TableDependent() {}
+
+// TableDependent implements the migrate.TableDependent interface.
+func (*Publication) TableDependent() {}
+
+// state returns the firing state of the event trigger.
+func (e *EventTrigger) state() string {
+	if e.State == "" {
+		return "ENABLE"
+	}
+	return strings.ToUpper(e.State)
+}
+
+// context returns the context of the cast, defaults to EXPLICIT.
+func (c *Cast) context() string {
+	if c.Context == "" {
+		return "EXPLICIT"
+	}
+	return strings.ToUpper(c.Context)
+}
+
+// name returns the name of the cast, as it is referenced by the
+// DROP CAST and COMMENT ON CAST statements, e.g. "(text AS mood)".
+func (c *Cast) name() string {
+	return fmt.Sprintf("(%s AS %s)", c.Source, c.Target)
+}
+
+var (
+	// Collations query on PostgreSQL 11 that does not support nondeterministic collations.
+	collationsQuery11 = strings.ReplaceAll(collationsQuery, "c.collisdeterministic AS deterministic", "true AS deterministic")
+	// Collations query on PostgreSQL 15 and 16, where the ICU locale is stored separately.
+	collationsQuery15 = strings.ReplaceAll(collationsQuery, "c.collcollate AS lc_collate", "COALESCE(c.collcollate, c.colliculocale) AS lc_collate")
+	// Collations query on PostgreSQL 17 and above, where the ICU locale column was renamed.
+	collationsQuery17 = strings.ReplaceAll(collationsQuery, "c.collcollate AS lc_collate", "COALESCE(c.collcollate, c.colllocale) AS lc_collate")
+	// Publications query on PostgreSQL 11 and 12 that do not support publish_via_partition_root.
+	publicationsQuery12 = strings.ReplaceAll(publicationsQuery, "p.pubviaroot AS via_root", "false AS via_root")
+	// Publications query on PostgreSQL 10 that does not support publishing truncates.
+	publicationsQuery10 = strings.ReplaceAll(publicationsQuery12, "p.pubtruncate AS publish_truncate", "false AS publish_truncate")
+	// collationProviders maps the pg_collation.collprovider codes to their names.
+	collationProviders = map[string]string{"c": "libc", "i": "icu", "d": "default", "b": "builtin"}
+	// defaultPrivilegeTypes maps the pg_default_acl.defaclobjtype codes to their names.
+	defaultPrivilegeTypes = map[string]string{"r": "TABLES", "S": "SEQUENCES", "f": "FUNCTIONS", "T": "TYPES"}
+	// ruleEvents maps the pg_rewrite.ev_type codes to their names.
+	ruleEvents = map[string]string{"1": "SELECT", "2": "UPDATE", "3": "INSERT", "4": "DELETE"}
+
+	// statisticsKinds maps the pg_statistic_ext.stxkind codes to their names.
+	statisticsKinds = map[string]string{"d": "dependencies", "f": "ndistinct", "m": "mcv"}
+)
+
 // reEnumType extracts the enum type and an option schema qualifier.
 var reEnumType = regexp.MustCompile(`^(?:(".+"|\w+)\.)?(".+"|\w+)$`)
 
@@ -1071,6 +2067,235 @@ const (
 	// Query to list specific database schemas.
 	schemasQueryArgs = "SELECT schema_name FROM information_schema.schemata WHERE schema_name %s ORDER BY schema_name"
 
+	// Query to list the collations defined in the schemas, excluding extension collations.
+	collationsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	c.collname AS collation_name,
+	c.collprovider AS provider,
+	c.collcollate AS lc_collate,
+	c.collctype AS lc_ctype,
+	c.collisdeterministic AS deterministic
+FROM
+	pg_catalog.pg_collation AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.collnamespace
+WHERE
+	n.nspname IN (%s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend AS d WHERE d.classid = 'pg_catalog.pg_collation'::regclass AND d.objid = c.oid AND d.deptype = 'e')
+ORDER BY
+	n.nspname, c.collname
+`
+
+	// Query to list the text search configurations defined in the schemas, along with their token mappings.
+	tsConfigsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	c.cfgname AS config_name,
+	CASE WHEN pn.nspname = 'pg_catalog' THEN p.prsname ELSE pn.nspname || '.' || p.prsname END AS parser,
+	t.alias AS token,
+	string_agg(m.mapdict::regdictionary::text, ',' ORDER BY m.mapseqno) AS dictionaries
+FROM
+	pg_catalog.pg_ts_config AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.cfgnamespace
+	JOIN pg_catalog.pg_ts_parser AS p ON p.oid = c.cfgparser
+	JOIN pg_catalog.pg_namespace AS pn ON pn.oid = p.prsnamespace
+	LEFT JOIN pg_catalog.pg_ts_config_map AS m ON m.mapcfg = c.oid
+	LEFT JOIN LATERAL pg_catalog.ts_token_type(c.cfgparser) AS t ON t.tokid = m.maptokentype
+WHERE
+	n.nspname IN (%s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend AS d WHERE d.classid = 'pg_catalog.pg_ts_config'::regclass AND d.objid = c.oid AND d.deptype = 'e')
+GROUP BY
+	n.nspname, c.cfgname, pn.nspname, p.prsname, t.alias
+ORDER BY
+	n.nspname, c.cfgname, t.alias
+`
+
+	// Query to list the default privileges that were set in the given schemas.
+	// The owner of rules that are defined by the current role is reported as NULL.
+	defaultPrivilegesQuery = `
+SELECT
+	n.nspname AS schema_name,
+	CASE WHEN d.defaclrole <> (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = current_user) THEN pg_catalog.pg_get_userbyid(d.defaclrole) END AS owner,
+	d.defaclobjtype AS object_type,
+	CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(a.grantee) END AS grantee,
+	string_agg(a.privilege_type, ',' ORDER BY a.privilege_type) AS privileges
+FROM
+	pg_catalog.pg_default_acl AS d
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = d.defaclnamespace
+	CROSS JOIN LATERAL pg_catalog.aclexplode(d.defaclacl) AS a
+WHERE
+	n.nspname IN (%s)
+GROUP BY
+	n.nspname, d.defaclrole, d.defaclobjtype, a.grantee
+ORDER BY
+	schema_name, owner, object_type, grantee
+`
+
+	// Query to list the owners of the user-defined types (enums, composite types and domains).
+	typeOwnersQuery = `
+SELECT
+	n.nspname AS schema_name,
+	t.typname AS type_name,
+	pg_catalog.pg_get_userbyid(t.typowner) AS owner
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace
+	LEFT JOIN pg_catalog.pg_class AS c ON c.oid = t.typrelid
+WHERE
+	n.nspname IN (%s)
+	AND (t.typtype IN ('e', 'd') OR (t.typtype = 'c' AND c.relkind = 'c'))
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend AS d WHERE d.classid = 'pg_catalog.pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e')
+ORDER BY
+	schema_name, type_name
+`
+
+	// Query to list the extended statistics defined in the schemas, along with their column names.
+	statisticsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	s.stxname AS statistics_name,
+	tn.nspname AS table_schema,
+	c.relname AS table_name,
+	array_to_string(s.stxkind, ',') AS kinds,
+	(
+		SELECT string_agg(a.attname, ',' ORDER BY k.n)
+		FROM unnest(s.stxkeys::int2[]) WITH ORDINALITY AS k(attnum, n)
+		JOIN pg_catalog.pg_attribute AS a ON a.attrelid = s.stxrelid AND a.attnum = k.attnum
+	) AS columns
+FROM
+	pg_catalog.pg_statistic_ext AS s
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = s.stxnamespace
+	JOIN pg_catalog.pg_class AS c ON c.oid = s.stxrelid
+	JOIN pg_catalog.pg_namespace AS tn ON tn.oid = c.relnamespace
+WHERE
+	n.nspname IN (%s)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend AS d WHERE d.classid = 'pg_catalog.pg_statistic_ext'::regclass AND d.objid = s.oid AND d.deptype = 'e')
+ORDER BY
+	schema_name, statistics_name
+`
+
+	// Query to list the rewrite rules of the tables in the schemas, excluding the implicit view rules.
+	rulesQuery = `
+SELECT
+	n.nspname AS schema_name,
+	c.relname AS table_name,
+	r.rulename AS rule_name,
+	r.ev_type AS event,
+	r.is_instead AS instead,
+	pg_catalog.pg_get_ruledef(r.oid) AS definition
+FROM
+	pg_catalog.pg_rewrite AS r
+	JOIN pg_catalog.pg_class AS c ON c.oid = r.ev_class
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	n.nspname IN (%s)
+	AND c.relkind IN ('r', 'p')
+	AND r.rulename <> '_RETURN'
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend AS d WHERE d.classid = 'pg_catalog.pg_rewrite'::regclass AND d.objid = r.oid AND d.deptype = 'e')
+ORDER BY
+	schema_name, table_name, rule_name
+`
+
+	// Query to list the event triggers of the database, excluding the ones created by extensions.
+	eventTriggersQuery = `
+SELECT
+	e.evtname AS trigger_name,
+	e.evtevent AS event,
+	array_to_string(e.evttags, ',') AS tags,
+	n.nspname AS function_schema,
+	p.proname AS function_name,
+	CASE e.evtenabled WHEN 'D' THEN 'DISABLE' WHEN 'R' THEN 'ENABLE REPLICA' WHEN 'A' THEN 'ENABLE ALWAYS' ELSE 'ENABLE' END AS state
+FROM
+	pg_catalog.pg_event_trigger AS e
+	JOIN pg_catalog.pg_proc AS p ON p.oid = e.evtfoid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = p.pronamespace
+WHERE
+	NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend AS d
+		WHERE d.classid = 'pg_catalog.pg_event_trigger'::regclass AND d.objid = e.oid AND d.deptype = 'e'
+	)
+ORDER BY
+	trigger_name
+`
+
+	// Query to list the logical replication publications of the database, along with their tables.
+	publicationsQuery = `
+SELECT
+	p.pubname AS publication_name,
+	p.puballtables AS all_tables,
+	p.pubinsert AS publish_insert,
+	p.pubupdate AS publish_update,
+	p.pubdelete AS publish_delete,
+	p.pubtruncate AS publish_truncate,
+	p.pubviaroot AS via_root,
+	n.nspname AS table_schema,
+	c.relname AS table_name
+FROM
+	pg_catalog.pg_publication AS p
+	LEFT JOIN pg_catalog.pg_publication_rel AS r ON r.prpubid = p.oid
+	LEFT JOIN pg_catalog.pg_class AS c ON c.oid = r.prrelid
+	LEFT JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+ORDER BY
+	publication_name, table_schema, table_name
+`
+
+	// Query to list the logical replication subscriptions of the current database.
+	subscriptionsQuery = `
+SELECT
+	s.subname AS subscription_name,
+	s.subenabled AS enabled,
+	array_to_string(s.subpublications, ',') AS publications,
+	s.subslotname AS slot_name,
+	s.subsynccommit AS synchronous_commit
+FROM
+	pg_catalog.pg_subscription AS s
+WHERE
+	s.subdbid = (SELECT d.oid FROM pg_catalog.pg_database AS d WHERE d.datname = current_database())
+ORDER BY
+	subscription_name
+`
+
+	// Query to list the user-defined casts, excluding the ones created by extensions.
+	castsQuery = `
+SELECT
+	pg_catalog.format_type(c.castsource, NULL) AS source,
+	pg_catalog.format_type(c.casttarget, NULL) AS target,
+	c.castmethod AS method,
+	CASE WHEN c.castfunc = 0 THEN NULL ELSE c.castfunc::regprocedure::text END AS function,
+	CASE c.castcontext WHEN 'a' THEN 'ASSIGNMENT' WHEN 'i' THEN 'IMPLICIT' ELSE 'EXPLICIT' END AS context
+FROM
+	pg_catalog.pg_cast AS c
+WHERE
+	c.oid >= 16384
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend AS d
+		WHERE d.classid = 'pg_catalog.pg_cast'::regclass AND d.objid = c.oid AND d.deptype = 'e'
+	)
+ORDER BY
+	source, target
+`
+
+	// Query to list the custom access methods, excluding the ones created by extensions.
+	accessMethodsQuery = `
+SELECT
+	a.amname AS name,
+	CASE a.amtype WHEN 't' THEN 'TABLE' ELSE 'INDEX' END AS type,
+	a.amhandler::text AS handler
+FROM
+	pg_catalog.pg_am AS a
+WHERE
+	a.oid >= 16384
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend AS d
+		WHERE d.classid = 'pg_catalog.pg_am'::regclass AND d.objid = a.oid AND d.deptype = 'e'
+	)
+ORDER BY
+	name
+`
+
+	// Table storage parameters, including the legacy WITH OIDS option.
+	tableOIDsParams = "CASE WHEN t3.relhasoids THEN array_append(t3.reloptions, 'oids=true') ELSE t3.reloptions END AS storage_params"
+
 	// Query to list table information.
 	tablesQuery = `
 SELECT
@@ -1079,12 +2304,18 @@ SELECT
 	pg_catalog.obj_description(t3.oid, 'pg_class') AS comment,
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
-	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs
+	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
+	t3.reloptions AS storage_params,
+	t5.reloptions AS toast_params,
+	pg_catalog.pg_get_userbyid(t3.relowner) AS owner,
+	t6.amname AS access_method
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
 	JOIN pg_catalog.pg_class AS t3 ON t3.relnamespace = t2.oid AND t3.relname = t1.table_name
 	LEFT JOIN pg_catalog.pg_partitioned_table AS t4 ON t4.partrelid = t3.oid
+	LEFT JOIN pg_catalog.pg_class AS t5 ON t5.oid = t3.reltoastrelid
+	LEFT JOIN pg_catalog.pg_am AS t6 ON t6.oid = t3.relam
 WHERE
 	t1.table_type = 'BASE TABLE'
 	AND NOT COALESCE(t3.relispartition, false)
@@ -1099,12 +2330,18 @@ SELECT
 	pg_catalog.obj_description(t3.oid, 'pg_class') AS comment,
 	t4.partattrs AS partition_attrs,
 	t4.partstrat AS partition_strategy,
-	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs
+	pg_get_expr(t4.partexprs, t4.partrelid) AS partition_exprs,
+	t3.reloptions AS storage_params,
+	t5.reloptions AS toast_params,
+	pg_catalog.pg_get_userbyid(t3.relowner) AS owner,
+	t6.amname AS access_method
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
 	JOIN pg_catalog.pg_class AS t3 ON t3.relnamespace = t2.oid AND t3.relname = t1.table_name
 	LEFT JOIN pg_catalog.pg_partitioned_table AS t4 ON t4.partrelid = t3.oid
+	LEFT JOIN pg_catalog.pg_class AS t5 ON t5.oid = t3.reltoastrelid
+	LEFT JOIN pg_catalog.pg_am AS t6 ON t6.oid = t3.relam
 WHERE
 	t1.table_type = 'BASE TABLE'
 	AND NOT COALESCE(t3.relispartition, false)
@@ -1113,8 +2350,9 @@ WHERE
 ORDER BY
 	t1.table_schema, t1.table_name
 `
-	// Query to list table columns.
-	columnsQuery = `
+	// Query to list table columns. The first argument holds the optional columns
+	// that are supported only by newer versions, and the second the table names.
+	columnsQueryTmpl = `
 SELECT
 	t1.table_name,
 	t1.column_name,
@@ -1130,28 +2368,48 @@ SELECT
 	t1.character_set_name,
 	t1.collation_name,
 	t1.is_identity,
-	t1.identity_start,
-	t1.identity_increment,
-	(CASE WHEN t1.is_identity = 'YES' THEN (SELECT last_value FROM pg_sequences WHERE quote_ident(schemaname) || '.' || quote_ident(sequencename) = pg_get_serial_sequence(quote_ident(t1.table_schema) || '.' || quote_ident(t1.table_name), t1.column_name)) END) AS identity_last,
+	t5.start_value AS identity_start,
+	t5.increment_by AS identity_increment,
+	t5.last_value AS identity_last,
 	t1.identity_generation,
 	t1.generation_expression,
 	col_description(t3.oid, "ordinal_position") AS comment,
 	t4.typtype,
 	t4.typelem,
 	(CASE WHEN t4.typcategory = 'A' AND t4.typelem <> 0 THEN (SELECT t.typtype FROM pg_catalog.pg_type t WHERE t.oid = t4.typelem) END) AS elemtyp,
-	t4.oid
+	t4.oid,
+	t5.min_value AS identity_min,
+	t5.max_value AS identity_max%s
 FROM
 	"information_schema"."columns" AS t1
 	JOIN pg_catalog.pg_namespace AS t2 ON t2.nspname = t1.table_schema
 	JOIN pg_catalog.pg_class AS t3 ON t3.relnamespace = t2.oid AND t3.relname = t1.table_name
 	JOIN pg_catalog.pg_attribute AS a ON a.attrelid = t3.oid AND a.attname = t1.column_name
 	LEFT JOIN pg_catalog.pg_type AS t4 ON t4.oid = a.atttypid
+	LEFT JOIN pg_catalog.pg_sequences AS t5 ON t1.is_identity = 'YES' AND quote_ident(t5.schemaname) || '.' || quote_ident(t5.sequencename) = pg_get_serial_sequence(quote_ident(t1.table_schema) || '.' || quote_ident(t1.table_name), t1.column_name)
 WHERE
 	t1.table_schema = $1 AND t1.table_name IN (%s)
 ORDER BY
 	t1.table_name, t1.ordinal_position
 `
 
+	// Query to list the partitions of partitioned tables and their bounds.
+	partitionBoundsQuery = `
+SELECT
+	t2.relname AS parent_name,
+	t3.relname AS partition_name,
+	pg_catalog.pg_get_expr(t3.relpartbound, t3.oid) AS partition_bound
+FROM
+	pg_catalog.pg_inherits AS t1
+	JOIN pg_catalog.pg_class AS t2 ON t2.oid = t1.inhparent
+	JOIN pg_catalog.pg_class AS t3 ON t3.oid = t1.inhrelid AND t3.relnamespace = t2.relnamespace
+	JOIN pg_catalog.pg_namespace AS t4 ON t4.oid = t2.relnamespace
+WHERE
+	t4.nspname = $1 AND t2.relname IN (%s) AND t3.relispartition
+ORDER BY
+	t2.relname, t3.relname
+`
+
 	fksQuery = `
 SELECT 
     fk.constraint_name,
@@ -1162,11 +2420,15 @@ SELECT
     a2.attname AS referenced_column_name,
     fk.referenced_schema_name,
     rc.update_rule,
-    rc.delete_rule
+    rc.delete_rule,
+    fk.condeferrable,
+    fk.condeferred
 	FROM 
 	    (
 	    	SELECT
 	      		con.conname AS constraint_name,
+	      		con.condeferrable,
+	      		con.condeferred,
 	      		con.conrelid,
 	      		con.confrelid,
 	      		t1.relname AS table_name,
@@ -1199,7 +2461,8 @@ SELECT
 	pg_get_expr(t1.conbin, t1.conrelid) as expression,
 	t2.attname as column_name,
 	t1.conkey as column_indexes,
-	t1.connoinherit as no_inherit
+	t1.connoinherit as no_inherit,
+	t1.conislocal as is_local
 FROM
 	pg_constraint t1
 	JOIN pg_attribute t2
@@ -1219,8 +2482,14 @@ ORDER BY
 )
 
 var (
-	indexesQuery          = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "%s")
-	indexesQueryNoInclude = fmt.Sprintf(indexesQueryTmpl, "false", "%s")
+	// Query to list table columns.
+	columnsQuery = fmt.Sprintf(columnsQueryTmpl, "", "%s")
+	// Query to list table columns, including their compression method (PostgreSQL 14 and above).
+	columnsQuery14 = fmt.Sprintf(columnsQueryTmpl, ",\n\t(CASE a.attcompression WHEN 'p' THEN 'pglz' WHEN 'l' THEN 'lz4' END) AS compression", "%s")
+
+	indexesQuery          = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "false", "%s")
+	indexesQuery15        = fmt.Sprintf(indexesQueryTmpl, "(a.attname <> '' AND idx.indnatts > idx.indnkeyatts AND idx.ord > idx.indnkeyatts)", "idx.indnullsnotdistinct", "%s")
+	indexesQueryNoInclude = fmt.Sprintf(indexesQueryTmpl, "false", "false", "%s")
 	indexesQueryTmpl      = `
 SELECT
 	t.relname AS table_name,
@@ -1240,7 +2509,8 @@ SELECT
 	i.reloptions AS options,
 	op.opcname AS opclass_name,
 	op.opcdefault AS opclass_default,
-	a2.attoptions AS opclass_params
+	a2.attoptions AS opclass_params,
+	%s AS nulls_not_distinct
 FROM
 	(
 		select
@@ -6,6 +6,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
 	"testing"
 
@@ -41,9 +42,9 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- table_name  |  column_name |          data_type          |  formatted          | is_nullable |         column_default                 | character_maximum_length | numeric_precision | datetime_precision | numeric_scale |    interval_type    | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid
+ table_name  |  column_name |          data_type          |  formatted          | is_nullable |         column_default                 | character_maximum_length | numeric_precision | datetime_precision | numeric_scale |    interval_type    | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid | identity_min | identity_max
 -------------+--------------+-----------------------------+---------------------|-------------+----------------------------------------+--------------------------+-------------------+--------------------+---------------+---------------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-------
- users       |  id          | bigint                      | int8                | NO          |                                        |                          |                64 |                    |             0 |                     |                    |                | YES         |      100       |          1         |          1       |    BY DEFAULT       |                       |         | b       |         |         |    20
+ users       |  id          | bigint                      | int8                | NO          |                                        |                          |                64 |                    |             0 |                     |                    |                | YES         |      100       |          1         |          1       |    BY DEFAULT       |                       |         | b       |         |         |    20 | 1            | 9223372036854775807
  users       |  rank        | integer                     | int4                | YES         |                                        |                          |                32 |                    |             0 |                     |                    |                | NO          |                |                    |                  |                     |                       | rank    | b       |         |         |    23
  users       |  c1          | smallint                    | int2                | NO          |           1000                         |                          |                16 |                    |             0 |                     |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    21
  users       |  c2          | bit                         | bit                 | NO          |                                        |                        1 |                   |                    |               |                     |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  1560
@@ -103,7 +104,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				require.NoError(err)
 				require.Equal("users", t.Name)
 				require.EqualValues([]*schema.Column{
-					{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1, Last: 1}}}},
+					{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1, Last: 1, Min: i64(1), Max: i64(9223372036854775807)}}}},
 					{Name: "rank", Type: &schema.ColumnType{Raw: "integer", Null: true, Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&schema.Comment{Text: "rank"}}},
 					{Name: "c1", Type: &schema.ColumnType{Raw: "smallint", Type: &schema.IntegerType{T: "smallint"}}, Default: &schema.Literal{V: "1000"}},
 					{Name: "c2", Type: &schema.ColumnType{Raw: "bit", Type: &BitType{T: "bit", Len: 1}}},
@@ -154,7 +155,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid
+table_name | column_name |      data_type      | formatted |  is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid | identity_min | identity_max
 -----------+-------------+---------------------+-----------+--------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-------
 users      | id          | bigint              | int8      |  NO          |                                 |                          |                64 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    20
 users      | c1          | smallint            | int2      |  NO          |                                 |                          |                16 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    21
@@ -164,23 +165,23 @@ users      | ts          | tsvector            | tsvector  |  NO          |
 				m.ExpectQuery(queryIndexes).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-   table_name   |    index_name   | index_type  | column_name | included | primary | unique |   constraints   | predicate             |   expression              | desc | nulls_first | nulls_last | comment   |                 options               |   opclass_name    | opclass_default | opclass_params 
-----------------+-----------------+-------------+-------------+----------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+---------------------------------------+-------------------+-----------------+----------------
-users           | idx             | hash        |             | f        | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |                                       |     int4_ops      |        t        |
-users           | idx1            | btree       |             | f        | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |                                       |     int4_ops      |        t        |
-users           | t1_c1_key       | btree       | c1          | f        | f       | t      | {"name": "u"}   |                       | c1                        | t    | t           | f          |           |                                       |     int4_ops      |        t        |
-users           | t1_pkey         | btree       | id          | f        | t       | t      | {"t_pkey": "p"} |                       | id                        | t    | f           | f          |           |                                       |     int4_ops      |        t        |
-users           | idx4            | btree       | c1          | f        | f       | t      |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |        t        |
-users           | idx4            | btree       | id          | f        | f       | t      |                 |                       | id                        | f    | f           | t          |           |                                       |     int4_ops      |        t        |
-users           | idx5            | btree       | c1          | f        | f       | t      |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |        t        |
-users           | idx5            | btree       |             | f        | f       | t      |                 |                       | coalesce(parent_id, 0)    | f    | f           | f          |           |                                       |     int4_ops      |        t        |
-users           | idx6            | brin        | c1          | f        | f       | t      |                 |                       |                           | f    | f           | f          |           | {autosummarize=true,pages_per_range=2}|     int4_ops      |        t        |
-users           | idx2            | btree       |             | f        | f       | f      |                 |                       | ((c * 2))                 | f    | f           | t          |           |                                       |     int4_ops      |        t        |
-users           | idx2            | btree       | c1          | f        | f       | f      |                 |                       | c                         | f    | f           | t          |           |                                       |     int4_ops      |        t        |
-users           | idx2            | btree       | id          | f        | f       | f      |                 |                       | d                         | f    | f           | t          |           |                                       |     int4_ops      |        t        |
-users           | idx2            | btree       | c1          | t        | f       | f      |                 |                       | c                         |      |             |            |           |                                       |     int4_ops      |        t        |
-users           | idx2            | btree       | parent_id   | t        | f       | f      |                 |                       | d                         |      |             |            |           |                                       |     int4_ops      |        t        |
-users           | tsx             | gist        | ts          | f        | f       | f      |                 |                       | ts                        |      |             |            |           |                                       |     tsvector_ops  |        f        | {siglen=1}
+   table_name   |    index_name   | index_type  | column_name | included | primary | unique |   constraints   | predicate             |   expression              | desc | nulls_first | nulls_last | comment   |                 options               |   opclass_name    | opclass_default | opclass_params | nulls_not_distinct
+----------------+-----------------+-------------+-------------+----------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+---------------------------------------+-------------------+-----------------+----------------+-------------------
+users           | idx             | hash        |             | f        | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |                                       |     int4_ops      |        t        |                | f
+users           | idx1            | btree       |             | f        | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |                                       |     int4_ops      |        t        |                | f
+users           | t1_c1_key       | btree       | c1          | f        | f       | t      | {"name": "u"}   |                       | c1                        | t    | t           | f          |           |                                       |     int4_ops      |        t        |                | f
+users           | t1_pkey         | btree       | id          | f        | t       | t      | {"t_pkey": "p"} |                       | id                        | t    | f           | f          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx4            | btree       | c1          | f        | f       | t      |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |        t        |                | t
+users           | idx4            | btree       | id          | f        | f       | t      |                 |                       | id                        | f    | f           | t          |           |                                       |     int4_ops      |        t        |                | t
+users           | idx5            | btree       | c1          | f        | f       | t      |                 |                       | c1                        | f    | f           | f          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx5            | btree       |             | f        | f       | t      |                 |                       | coalesce(parent_id, 0)    | f    | f           | f          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx6            | brin        | c1          | f        | f       | t      |                 |                       |                           | f    | f           | f          |           | {autosummarize=true,pages_per_range=2}|     int4_ops      |        t        |                | f
+users           | idx2            | btree       |             | f        | f       | f      |                 |                       | ((c * 2))                 | f    | f           | t          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx2            | btree       | c1          | f        | f       | f      |                 |                       | c                         | f    | f           | t          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx2            | btree       | id          | f        | f       | f      |                 |                       | d                         | f    | f           | t          |           |                                       |     int4_ops      |        t        |                | f
+users           | idx2            | btree       | c1          | t        | f       | f      |                 |                       | c                         |      |             |            |           |                                       |     int4_ops      |        t        |                | f
+users           | idx2            | btree       | parent_id   | t        | f       | f      |                 |                       | d                         |      |             |            |           |                                       |     int4_ops      |        t        |                | f
+users           | tsx             | gist        | ts          | f        | f       | f      |                 |                       | ts                        |      |             |            |           |                                       |     tsvector_ops  |        f        | {siglen=1}     | f
 `))
 				m.noFKs()
 				m.noChecks()
@@ -198,7 +199,7 @@ users           | tsx             | gist        | ts          | f        | f
 					{Name: "idx", Table: t, Attrs: []schema.Attr{&IndexType{T: "hash"}, &schema.Comment{Text: "boring"}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "idx1", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `(id <> NULL::integer)`}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &Constraint{N: "name", T: "u"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
+					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexNullsDistinct{}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
 					{Name: "idx5", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, X: &schema.RawExpr{X: `coalesce(parent_id, 0)`}}}},
 					{Name: "idx6", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "brin"}, &IndexStorageParams{AutoSummarize: true, PagesPerRange: 2}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}}},
 					{Name: "idx2", Unique: false, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexInclude{Columns: columns[1:3]}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `((c * 2))`}, Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}, {SeqNo: 2, C: columns[1], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}, {SeqNo: 3, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
@@ -226,7 +227,7 @@ users           | tsx             | gist        | ts          | f        | f
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name | column_name |      data_type      | formatted | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid
+table_name | column_name |      data_type      | formatted | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp |  oid | identity_min | identity_max
 -----------+-------------+---------------------+-----------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-------
 users      | id          | integer             | int       | NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    20
 users      | oid         | integer             | int       | NO          |                                 |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |    21
@@ -236,13 +237,13 @@ users      | uid         | integer             | int       | NO          |
 				m.ExpectQuery(queryFKs).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule
------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------
-multi_column    | users      | id          | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE
-multi_column    | users      | id          | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE
-multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE
-multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE
-self_reference  | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE
+constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule | condeferrable | condeferred
+-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+---------------+-------------
+multi_column    | users      | id          | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE     | t             | t
+multi_column    | users      | id          | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE     | t             | t
+multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE     | t             | t
+multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE     | t             | t
+self_reference  | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE     | f             | f
 `))
 				m.noChecks()
 			},
@@ -251,7 +252,7 @@ self_reference  | users      | uid         | public       | users
 				require.Equal("users", t.Name)
 				require.Equal("public", t.Schema.Name)
 				fks := []*schema.ForeignKey{
-					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
+					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}, Attrs: []schema.Attr{&Deferrable{InitiallyDeferred: true}}},
 					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t},
 				}
 				columns := []*schema.Column{
@@ -273,7 +274,7 @@ self_reference  | users      | uid         | public       | users
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid | identity_min | identity_max
 -----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
 users      | c1         | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
 users      | c2         | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
@@ -284,18 +285,18 @@ users      | c3         | integer   | int4      | NO          |                |
 				m.ExpectQuery(queryChecks).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name   | constraint_name    |       expression        | column_name | column_indexes | no_inherit
--------------+--------------------+-------------------------+-------------+----------------+----------------
-users        | boring             | (c1 > 1)                | c1          | {1}            | t
-users        | users_c2_check     | (c2 > 0)                | c2          | {2}            | f
-users        | users_c2_check1    | (c2 > 0)                | c2          | {2}            | f
-users        | users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f
-users        | users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f
+table_name   | constraint_name    |       expression        | column_name | column_indexes | no_inherit | is_local
+-------------+--------------------+-------------------------+-------------+----------------+------------+----------
+users        | boring             | (c1 > 1)                | c1          | {1}            | t          | t
+users        | users_c2_check     | (c2 > 0)                | c2          | {2}            | f          | t
+users        | users_c2_check1    | (c2 > 0)                | c2          | {2}            | f          | t
+users        | users_c3_check     | (c3 > 0)                | c3          | {3}            | f          | f
+users        | users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f          | t
+users        | users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f          | t
 `))
-				m.noChecks()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -310,6 +311,7 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 					&schema.Check{Name: "boring", Expr: "(c1 > 1)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c1"}}, &NoInherit{}}},
 					&schema.Check{Name: "users_c2_check", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
 					&schema.Check{Name: "users_c2_check1", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
+					&schema.Check{Name: "users_c3_check", Expr: "(c3 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c3"}}, &Inherited{}}},
 					&schema.Check{Name: "users_check", Expr: "((c2 + c1) > 2)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1"}}}},
 					&schema.Check{Name: "users_check1", Expr: "(((c2 + c1) + c3) > 10)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1", "c3"}}}},
 				}, t.Attrs)
@@ -333,6 +335,7 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
  public
 `))
 			tt.before(mk)
+			mk.noObjects("public")
 			s, err := drv.InspectSchema(context.Background(), "public", nil)
 			require.NoError(t, err)
 			tt.expect(require.New(t), s.Tables[0], err)
@@ -340,6 +343,54 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 	}
 }
 
+func TestDriver_InspectTableOwner(t *testing.T) {
+	for _, mode := range []schema.InspectMode{0, schema.InspectTables | schema.InspectOwners} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mk := mock{m}
+		mk.version("130000")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+			WithArgs("public").
+			WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
+			WithArgs("public").
+			WillReturnRows(sqltest.Rows(`
+ table_schema | table_name | comment | partition_attrs | partition_strategy | partition_exprs | storage_params | toast_params | owner | access_method
+--------------+------------+---------+-----------------+--------------------+-----------------+----------------+--------------+-------+---------------
+ public       | users      |         |                 |                    |                 |                |              | admin |
+`))
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))).
+			WithArgs("public", "users").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "formatted", "is_nullable", "column_default", "character_maximum_length", "numeric_precision", "datetime_precision", "numeric_scale", "interval_type", "character_set_name", "collation_name", "is_identity", "identity_start", "identity_increment", "identity_last", "identity_generation", "generation_expression", "comment", "typtype", "typelem", "elemtyp", "oid", "identity_min", "identity_max"}))
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2"))).
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression"}))
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))).
+			WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))).
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+		if mode == 0 {
+			mk.noObjects("public")
+		}
+		s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{Mode: mode})
+		require.NoError(t, err)
+		require.NoError(t, m.ExpectationsWereMet())
+		users, ok := s.Table("users")
+		require.True(t, ok)
+		// Owners are not inspected by default.
+		if mode == 0 {
+			require.Empty(t, users.Attrs)
+		} else {
+			require.Equal(t, []schema.Attr{&TableOwner{V: "admin"}}, users.Attrs)
+		}
+	}
+}
+
 func TestDriver_InspectPartitionedTable(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -356,17 +407,17 @@ public
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("public").
 		WillReturnRows(sqltest.Rows(`
- table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   
---------------+-------------+---------+-----------------+--------------------+----------------------------------------------------
- public       | logs1       |         |                 |                    | 
- public       | logs2       |         | 1               | r                  | 
- public       | logs3       |         | 2 0 0           | l                  | (a + b), (a + (b * 2))
+ table_schema | table_name  | comment | partition_attrs | partition_strategy |                  partition_exprs                   |           storage_params            |        toast_params        | owner | access_method
+--------------+-------------+---------+-----------------+--------------------+----------------------------------------------------+-------------------------------------+----------------------------+-------+---------------
+ public       | logs1       |         |                 |                    |                                                    | {parallel_workers=4,fillfactor=70}  | {autovacuum_enabled=false} | admin | columnar
+ public       | logs2       |         | 1               | r                  |                                                    |                                     |                            |       |
+ public       | logs3       |         | 2 0 0           | l                  | (a + b), (a + (b * 2))                             |                                     |                            |       | heap
 
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "$2, $3, $4"))).
 		WithArgs("public", "logs1", "logs2", "logs3").
 		WillReturnRows(sqltest.Rows(`
-table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid
+table_name |column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment |   identity_last  | identity_generation | generation_expression | comment | typtype | typelem | elemtyp | oid | identity_min | identity_max
 -----------+------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+------------------+---------------------+-----------------------+---------+---------+---------+---------+-----
 logs1      | c1         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
 logs2      | c2         | integer   | integer   | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |                  |                     |                       |         | b       |         |         |  23
@@ -376,25 +427,49 @@ logs3      | c5         | integer   | integer   | NO          |                |
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(partitionBoundsQuery, "$2, $3"))).
+		WithArgs("public", "logs2", "logs3").
+		WillReturnRows(sqltest.Rows(`
+ parent_name | partition_name |                  partition_bound
+-------------+----------------+----------------------------------------------------
+ logs2       | logs2_2024_01  | FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')
+ logs2       | logs2_default  | DEFAULT
+`))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
-	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{})
+	mk.noObjects("public")
+	mk.noTypeOwners("public")
+	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{
+		Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectObjects | schema.InspectOwners,
+	})
 	require.NoError(t, err)
 
 	t1, ok := s.Table("logs1")
 	require.True(t, ok)
-	require.Empty(t, t1.Attrs)
+	require.Equal(t, []schema.Attr{
+		&TableStorageParams{
+			Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"fillfactor", "70"}, {"toast.autovacuum_enabled", "false"}},
+		},
+		&TableOwner{V: "admin"},
+		&TableAccessMethod{V: "columnar"},
+	}, t1.Attrs)
 
 	t2, ok := s.Table("logs2")
 	require.True(t, ok)
-	require.Len(t, t2.Attrs, 1)
+	require.Len(t, t2.Attrs, 2)
 	key := t2.Attrs[0].(*Partition)
 	require.Equal(t, PartitionTypeRange, key.T)
 	require.Equal(t, []*PartitionPart{
 		{C: &schema.Column{Name: "c2", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}}},
 	}, key.Parts)
+	require.Equal(t, &PartitionBounds{
+		Parts: []*PartitionBound{
+			{Name: "logs2_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+			{Name: "logs2_default", Bound: "DEFAULT"},
+		},
+	}, t2.Attrs[1])
 
 	t3, ok := s.Table("logs3")
 	require.True(t, ok)
@@ -496,7 +571,44 @@ test
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "storage_params", "toast_params", "owner", "access_method"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, "$1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | collation_name | provider | lc_collate  | lc_ctype    | deterministic
+-------------+----------------+----------+-------------+-------------+---------------
+ test        | german         | c        | de_DE       | de_DE       | true
+ test        | mixed          | c        | en_US       | C           | true
+ test        | nocase         | i        | und-u-ks-l2 | und-u-ks-l2 | false
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsConfigsQuery, "$1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | config_name | parser      | token     | dictionaries
+-------------+-------------+-------------+-----------+-------------------
+ test        | empty       | test.parser |           |
+ test        | english     | default     | asciiword | english_stem
+ test        | english     | default     | word      | unaccent,english_stem
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(defaultPrivilegesQuery, "$1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | owner | object_type | grantee | privileges
+-------------+-------+-------------+---------+---------------
+ test        | admin | S           | PUBLIC  | USAGE
+ test        | admin | r           | reader  | INSERT,SELECT
+ test        |       | r           | writer  | INSERT
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statisticsQuery, "$1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | statistics_name | table_schema | table_name | kinds | columns
+-------------+-----------------+--------------+------------+-------+---------
+ test        | users_stats     | other        | users      | d,f   | a,b
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, "$1"))).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "rule_name", "event", "instead", "definition"}))
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Schema {
@@ -517,10 +629,67 @@ test
 			},
 		}
 		r.Schemas[0].Realm = r
+		r.Schemas[0].Objects = []schema.Object{
+			&Collation{Name: "german", Schema: r.Schemas[0], Provider: "libc", Locale: "de_DE"},
+			&Collation{Name: "mixed", Schema: r.Schemas[0], Provider: "libc", LCCollate: "en_US", LCCtype: "C"},
+			&Collation{Name: "nocase", Schema: r.Schemas[0], Provider: "icu", Locale: "und-u-ks-l2", Nondeterministic: true},
+			&TextSearchConfiguration{Name: "empty", Schema: r.Schemas[0], Parser: "test.parser"},
+			&TextSearchConfiguration{
+				Name:   "english",
+				Schema: r.Schemas[0],
+				Parser: "default",
+				Mappings: []*TextSearchMapping{
+					{Token: "asciiword", Dicts: []string{"english_stem"}},
+					{Token: "word", Dicts: []string{"unaccent", "english_stem"}},
+				},
+			},
+			&DefaultPrivilege{Schema: r.Schemas[0], Owner: "admin", ObjType: "SEQUENCES", Grantee: "PUBLIC", Privileges: []string{"USAGE"}},
+			&DefaultPrivilege{Schema: r.Schemas[0], Owner: "admin", ObjType: "TABLES", Grantee: "reader", Privileges: []string{"INSERT", "SELECT"}},
+			&DefaultPrivilege{Schema: r.Schemas[0], ObjType: "TABLES", Grantee: "writer", Privileges: []string{"INSERT"}},
+		}
 		return r.Schemas[0]
 	}(), s)
 }
 
+func TestDriver_InspectTypeOwners(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	for _, mode := range []schema.InspectMode{schema.InspectObjects, schema.InspectObjects | schema.InspectOwners} {
+		mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()"))).
+			WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+test
+`))
+		mk.noObjects("test")
+		if mode.Is(schema.InspectOwners) {
+			m.ExpectQuery(sqltest.Escape(fmt.Sprintf(typeOwnersQuery, "$1"))).
+				WithArgs("test").
+				WillReturnRows(sqltest.Rows(`
+ schema_name | type_name | owner
+-------------+-----------+-------
+ test        | address   | admin
+ test        | mood      | app
+`))
+		}
+		s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{Mode: mode})
+		require.NoError(t, err)
+		if !mode.Is(schema.InspectOwners) {
+			require.Empty(t, s.Objects, "type owners are inspected only with the InspectOwners mode")
+			continue
+		}
+		require.Equal(t, []schema.Object{
+			&TypeOwner{Schema: s, Name: "address", Owner: "admin"},
+			&TypeOwner{Schema: s, Name: "mood", Owner: "app"},
+		}, s.Objects)
+	}
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestDriver_Realm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -537,7 +706,42 @@ public
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "storage_params", "toast_params", "owner", "access_method"}))
+	mk.noObjects("test", "public")
+	m.ExpectQuery(sqltest.Escape(eventTriggersQuery)).
+		WillReturnRows(sqltest.Rows(`
+ trigger_name |      event      |           tags           | function_schema | function_name |  state
+--------------+-----------------+--------------------------+-----------------+---------------+---------
+ audit_ddl    | ddl_command_end | CREATE TABLE,ALTER TABLE | public          | log_ddl       | ENABLE
+ no_drops     | sql_drop        |                          | admin           | abort_drop    | DISABLE
+`))
+	m.ExpectQuery(sqltest.Escape(publicationsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ publication_name | all_tables | publish_insert | publish_update | publish_delete | publish_truncate | via_root | table_schema | table_name
+------------------+------------+----------------+----------------+----------------+------------------+----------+--------------+------------
+ all_changes      | true       | true           | true           | true           | true             | false    |              |
+ orders           | false      | true           | true           | false          | false            | true     | sales        | orders
+ orders           | false      | true           | true           | false          | false            | true     | sales        | returns
+`))
+	m.ExpectQuery(sqltest.Escape(subscriptionsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ subscription_name | enabled | publications      | slot_name | synchronous_commit
+-------------------+---------+-------------------+-----------+--------------------
+ replica           | false   | orders,customers  | replica   | off
+`))
+	m.ExpectQuery(sqltest.Escape(castsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ source | target | method |     function     |  context
+--------+--------+--------+------------------+------------
+ mood   | text   | f      | mood_text(mood)  | ASSIGNMENT
+ text   | mood   | i      |                  | EXPLICIT
+`))
+	m.ExpectQuery(sqltest.Escape(accessMethodsQuery)).
+		WillReturnRows(sqltest.Rows(`
+   name   | type  |     handler
+----------+-------+------------------
+ columnar | TABLE | columnar_handler
+`))
 	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -559,6 +763,26 @@ public
 					V: "en_US.utf8",
 				},
 			},
+			Objects: []schema.Object{
+				&EventTrigger{Name: "audit_ddl", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, FuncSchema: "public", Func: "log_ddl", State: "ENABLE"},
+				&EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "admin", Func: "abort_drop", State: "DISABLE"},
+				&Publication{Name: "all_changes", AllTables: true},
+				&Publication{
+					Name:    "orders",
+					Tables:  []*schema.Table{schema.NewTable("orders").SetSchema(schema.New("sales")), schema.NewTable("returns").SetSchema(schema.New("sales"))},
+					Publish: []string{"insert", "update"},
+					ViaRoot: true,
+				},
+				&Subscription{
+					Name:         "replica",
+					Publications: []string{"orders", "customers"},
+					Disabled:     true,
+					Options:      []struct{ N, V string }{{N: "slot_name", V: "replica"}, {N: "synchronous_commit", V: "off"}},
+				},
+				&Cast{Source: "mood", Target: "text", Func: "mood_text(mood)", Context: "ASSIGNMENT"},
+				&Cast{Source: "text", Target: "mood", InOut: true, Context: "EXPLICIT"},
+				&AccessMethod{Name: "columnar", Type: "TABLE", Handler: "columnar_handler"},
+			},
 		}
 		r.Schemas[0].Realm = r
 		r.Schemas[1].Realm = r
@@ -575,7 +799,8 @@ public
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "storage_params", "toast_params", "owner", "access_method"}))
+	mk.noObjects("test", "public")
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"test", "public"}})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -612,7 +837,8 @@ public
 `))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
-		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs", "storage_params", "toast_params", "owner", "access_method"}))
+	mk.noObjects("test")
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"test"}})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -637,6 +863,38 @@ public
 	}(), realm)
 }
 
+func TestInspect_Rules(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	r := schema.NewRealm(schema.New("public").AddTables(schema.NewTable("users"), schema.NewTable("logs"), schema.NewTable("audit logs")))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "rule_name", "event", "instead", "definition"}).
+			AddRow("public", "logs", "logs_protect", "4", true, "CREATE RULE logs_protect AS ON DELETE TO public.logs DO INSTEAD NOTHING;").
+			AddRow("public", "other", "other_rule", "3", true, "CREATE RULE other_rule AS ON INSERT TO public.other DO INSTEAD NOTHING;").
+			AddRow("public", "users", "users_audit", "2", false, "CREATE RULE users_audit AS ON UPDATE TO public.users WHERE (old.name <> new.name) DO  INSERT INTO logs (id) VALUES (new.id);").
+			AddRow("public", "audit logs", "keep logs", "4", true, `CREATE RULE "keep logs" AS ON DELETE TO public."audit logs" WHERE (old.id > 0) DO INSTEAD NOTHING;`))
+	i := &inspect{conn{ExecQuerier: db, version: 13_00_00}}
+	require.NoError(t, i.rules(context.Background(), r))
+	s := r.Schemas[0]
+	require.Equal(t, []schema.Object{
+		&Rule{Name: "logs_protect", Table: s.Tables[1], Event: "DELETE", Instead: true, Actions: "NOTHING"},
+		&Rule{Name: "users_audit", Table: s.Tables[0], Event: "UPDATE", Where: "(old.name <> new.name)", Actions: "INSERT INTO logs (id) VALUES (new.id)"},
+		&Rule{Name: "keep logs", Table: s.Tables[2], Event: "DELETE", Instead: true, Where: "(old.id > 0)", Actions: "NOTHING"},
+	}, s.Objects)
+}
+
+func TestColumnType_LargeObject(t *testing.T) {
+	typ, err := columnType(&columnDesc{typ: "oid", fmtype: "lo", typtype: "d"})
+	require.NoError(t, err)
+	require.Equal(t, &OIDType{T: TypeLO}, typ)
+
+	// Other domains are inspected as user-defined types.
+	typ, err = columnType(&columnDesc{typ: "oid", fmtype: "handle", typtype: "d"})
+	require.NoError(t, err)
+	require.Equal(t, &UserDefinedType{T: "handle"}, typ)
+}
+
 func TestInspectMode_InspectRealm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -707,15 +965,53 @@ func (m mock) version(version string) {
 }
 
 func (m mock) tableExists(schema, table string, exists bool) {
-	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs"})
+	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_comment", "partition_attrs", "partition_strategy", "partition_exprs", "storage_params", "toast_params", "owner", "access_method"})
 	if exists {
-		rows.AddRow(schema, table, nil, nil, nil, nil)
+		rows.AddRow(schema, table, nil, nil, nil, nil, nil, nil, nil, nil)
 	}
 	m.ExpectQuery(queryTables).
 		WithArgs(schema).
 		WillReturnRows(rows)
 }
 
+func (m mock) noObjects(schemas ...string) {
+	args := make([]driver.Value, len(schemas))
+	for i := range schemas {
+		args[i] = schemas[i]
+	}
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(collationsQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "collation_name", "provider", "lc_collate", "lc_ctype", "deterministic"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tsConfigsQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "config_name", "parser", "token", "dictionaries"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(defaultPrivilegesQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "owner", "object_type", "grantee", "privileges"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statisticsQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "statistics_name", "table_schema", "table_name", "kinds", "columns"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(rulesQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "rule_name", "event", "instead", "definition"}))
+}
+
+func (m mock) noTypeOwners(schemas ...string) {
+	args := make([]driver.Value, len(schemas))
+	for i := range schemas {
+		args[i] = schemas[i]
+	}
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(typeOwnersQuery, nArgs(0, len(schemas))))).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "type_name", "owner"}))
+}
+
+func (m mock) noDependents(schema, table string) {
+	m.ExpectQuery(sqltest.Escape(dependentsQuery)).
+		WithArgs(schema, table).
+		WillReturnRows(sqlmock.NewRows([]string{"object"}))
+}
+
 func (m mock) noIndexes() {
 	m.ExpectQuery(queryIndexes).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "primary", "unique", "constraint_type", "predicate", "expression", "options"}))
@@ -6,10 +6,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
@@ -21,8 +26,13 @@ type planApply struct{ conn }
 
 // PlanChanges returns a migration plan for the given schema changes.
 func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	return newState(p.conn, name, opts...).planChanges(ctx, changes)
+}
+
+// newState returns a new planning state for the given connection.
+func newState(c conn, name string, opts ...migrate.PlanOption) *state {
 	s := &state{
-		conn: p.conn,
+		conn: c,
 		Plan: migrate.Plan{
 			Name:          name,
 			Reversible:    true,
@@ -32,105 +42,1568 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 		altered: make(map[string]*schema.EnumType),
 		dropped: make(map[string]*schema.EnumType),
 	}
-	for _, o := range opts {
-		o(&s.PlanOptions)
+	for _, o := range opts {
+		o(&s.PlanOptions)
+	}
+	return s
+}
+
+// planChanges plans the changes and returns the resulting migration plan.
+func (s *state) planChanges(ctx context.Context, changes []schema.Change) (*migrate.Plan, error) {
+	if err := s.plan(ctx, changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+		}
+	}
+	if s.Provenance {
+		for _, c := range s.Changes {
+			if c.Source != nil {
+				c.Provenance = schema.Describe(c.Source)
+			}
+		}
+	}
+	if s.Coalesce {
+		s.Changes = groupEnumValues(s.Changes)
+	}
+	if s.IdempotentDDL {
+		for _, c := range s.Changes {
+			if unguarded(c) {
+				c.Cmd = ignoreExisting(c.Cmd)
+			}
+		}
+	}
+	s.Changes = s.timeouts(s.Changes)
+	return &s.Plan, nil
+}
+
+// timeouts prepends the configured timeouts to the transaction blocks of the planned
+// changes, using SET LOCAL, and to the statements that are executed outside of them,
+// using SET, as SET LOCAL has no effect outside a transaction block.
+func (s *state) timeouts(changes []*migrate.Change) []*migrate.Change {
+	if s.LockTimeout <= 0 && s.StatementTimeout <= 0 || len(changes) == 0 {
+		return changes
+	}
+	var (
+		inTx    bool
+		planned = make([]*migrate.Change, 0, len(changes))
+	)
+	set := func(tx bool) {
+		cmd := "SET "
+		if tx {
+			cmd += "LOCAL "
+		}
+		for _, t := range []struct {
+			name string
+			d    time.Duration
+		}{
+			{"lock_timeout", s.LockTimeout},
+			{"statement_timeout", s.StatementTimeout},
+		} {
+			if t.d > 0 {
+				planned = append(planned, &migrate.Change{
+					Cmd:     fmt.Sprintf("%s%s = '%s'", cmd, t.name, timeoutValue(t.d)),
+					Comment: fmt.Sprintf("set the %s", strings.ReplaceAll(t.name, "_", " ")),
+				})
+			}
+		}
+	}
+	for _, c := range changes {
+		switch tx := transactional(c); {
+		case !tx:
+			set(false)
+		case !inTx:
+			set(true)
+		}
+		inTx = transactional(c)
+		planned = append(planned, c)
+	}
+	return planned
+}
+
+// timeoutValue formats the given duration as a PostgreSQL time value.
+func timeoutValue(d time.Duration) string {
+	switch {
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dmin", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		// Round up, as a zero value disables the timeout.
+		return fmt.Sprintf("%dms", (d+time.Millisecond-1)/time.Millisecond)
+	}
+}
+
+// unguarded reports if the statement creates an object (or a constraint), and does not
+// support the IF NOT EXISTS clause. Unnamed constraints are not reported, as they are
+// named by the database, and therefore, are added again when the statement is re-run.
+// Statements that cannot be executed in a transaction block cannot be wrapped either.
+//
+// ALTER TABLE statements are reported only if adding the constraint is their single
+// action, as the other actions would be skipped along with it (see modifyTable).
+func unguarded(c *migrate.Change) bool {
+	cmd := strings.ToUpper(c.Cmd)
+	switch {
+	case !transactional(c):
+		return false
+	case strings.HasPrefix(cmd, "ALTER TABLE"):
+		if m, ok := c.Source.(*schema.ModifyTable); ok {
+			return len(m.Changes) == 1 && addsConstraint(m.Changes[0])
+		}
+		return strings.Contains(cmd, " ADD CONSTRAINT ")
+	}
+	for _, p := range []string{"CREATE TYPE", "CREATE CAST", "CREATE EVENT TRIGGER", "CREATE PUBLICATION", "CREATE TEXT SEARCH CONFIGURATION"} {
+		if strings.HasPrefix(cmd, p+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// addsConstraint reports if the table change adds a named constraint.
+func addsConstraint(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddForeignKey:
+		return c.F.Symbol != ""
+	case *schema.AddCheck:
+		return c.C.Name != ""
+	case *schema.AddIndex:
+		return true
+	}
+	return false
+}
+
+// ignoreExisting wraps the statement in a DO block that ignores the errors raised
+// for objects that already exist. Note, unique constraints raise duplicate_table
+// errors, as their indexes are relations.
+func ignoreExisting(cmd string) string {
+	tag := "$$"
+	if strings.Contains(cmd, tag) {
+		tag = "$atlas$"
+	}
+	return fmt.Sprintf("DO %s BEGIN %s; EXCEPTION WHEN duplicate_object OR duplicate_table THEN NULL; END %s", tag, cmd, tag)
+}
+
+// baselineVersion is the server version that is assumed when planning changes
+// without a database connection (i.e., against a baseline schema).
+const baselineVersion = 15_00_00
+
+// DiffBaseline returns the changes for migrating the baseline schema (e.g. the state
+// that was stored after the latest migration file was generated) to the desired one.
+// Both schemas are usually loaded from their serialized form, using EvalHCLBytes, and
+// the database is not accessed.
+func DiffBaseline(baseline, desired *schema.Schema, opts ...schema.DiffOption) ([]schema.Change, error) {
+	return (&sqlx.Diff{DiffDriver: &diff{conn{version: baselineVersion}}}).WithOptions(opts...).SchemaDiff(baseline, desired)
+}
+
+// PlanBaseline returns the migration plan for migrating the baseline schema to the
+// desired one, without accessing the database. Lookups that are made by the planner
+// on the database (e.g. the existence of enum types and schemas) are resolved using
+// the baseline schema. For example, generating an incremental migration file in CI:
+//
+//	plan, err := postgres.PlanBaseline(ctx, "add_users", baseline, desired)
+//	if err != nil {
+//		return err
+//	}
+//	files, err := migrate.DefaultFormatter.Format(plan)
+func PlanBaseline(ctx context.Context, name string, baseline, desired *schema.Schema, opts ...migrate.PlanOption) (*migrate.Plan, error) {
+	changes, err := DiffBaseline(baseline, desired)
+	if err != nil {
+		return nil, err
+	}
+	s := newState(conn{version: baselineVersion}, name, opts...)
+	s.baseline = baseline
+	return s.planChanges(ctx, changes)
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to do so, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
+	return sqlx.ApplyChanges(ctx, changes, p, opts...)
+}
+
+type (
+	// Result describes the outcome of applying a changeset using Apply.
+	Result struct {
+		// Applied holds the statements that were executed and committed
+		// (or executed outside a transaction) successfully.
+		Applied []*StmtResult
+		// RolledBack holds the statements that were executed successfully, but
+		// were rolled back because a later statement in their transaction failed.
+		RolledBack []*StmtResult
+		// Failed holds the statement that failed, if any.
+		Failed *StmtResult
+		// Pending holds the statements that were not executed, because
+		// a previous statement failed.
+		Pending []*migrate.Change
+	}
+
+	// StmtResult describes the execution of a single statement.
+	StmtResult struct {
+		Change  *migrate.Change
+		Elapsed time.Duration
+		// Err is set in case the statement failed.
+		Err error
+	}
+)
+
+// Apply plans the given changeset and executes its statements on the database.
+// Consecutive statements that can be executed inside a transaction block are
+// grouped into one transaction, and statements that cannot (e.g. CREATE INDEX
+// CONCURRENTLY) are executed on their own, outside a transaction.
+//
+// In case a statement fails, its transaction is rolled back, and the returned
+// Result reports the statements that were applied before the failure (e.g. in
+// previous transactions, or non-transactional ones), along with the error.
+func Apply(ctx context.Context, db *sql.DB, changes []schema.Change, opts ...migrate.PlanOption) (*Result, error) {
+	drv, err := Open(db)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := drv.PlanChanges(ctx, "apply", changes, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+	for i := 0; i < len(plan.Changes); {
+		j := i + 1
+		if transactional(plan.Changes[i]) {
+			for j < len(plan.Changes) && transactional(plan.Changes[j]) {
+				j++
+			}
+		}
+		if err := r.exec(ctx, db, plan.Changes[i:j], transactional(plan.Changes[i])); err != nil {
+			r.Pending = append(r.Pending, plan.Changes[j:]...)
+			return r, err
+		}
+		i = j
+	}
+	return r, nil
+}
+
+// exec executes the given group of statements, optionally in a transaction,
+// and records their results.
+func (r *Result) exec(ctx context.Context, db *sql.DB, changes []*migrate.Change, inTx bool) error {
+	var (
+		e    schema.ExecQuerier = db
+		tx   *sql.Tx
+		done []*StmtResult
+		err  error
+	)
+	if inTx {
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			r.Pending = append(r.Pending, changes...)
+			return fmt.Errorf("postgres: starting transaction: %w", err)
+		}
+		e = tx
+	}
+	for i, c := range changes {
+		start := time.Now()
+		if _, err := e.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
+			if c.Comment != "" {
+				err = fmt.Errorf("%s: %w", c.Comment, err)
+			}
+			r.Failed = &StmtResult{Change: c, Elapsed: time.Since(start), Err: err}
+			r.Pending = append(r.Pending, changes[i+1:]...)
+			if tx != nil {
+				r.RolledBack = done
+				if rerr := tx.Rollback(); rerr != nil {
+					err = fmt.Errorf("%w: %v", err, rerr)
+				}
+			} else {
+				r.Applied = append(r.Applied, done...)
+			}
+			return err
+		}
+		done = append(done, &StmtResult{Change: c, Elapsed: time.Since(start)})
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			r.RolledBack = done
+			return fmt.Errorf("postgres: committing transaction: %w", err)
+		}
+	}
+	r.Applied = append(r.Applied, done...)
+	return nil
+}
+
+// TxFormatter is a migrate.Formatter that renders the plan as a single file in which
+// the statements are wrapped with explicit BEGIN and COMMIT statements. Statements that
+// cannot be executed inside a transaction block (e.g. CREATE INDEX CONCURRENTLY) are placed
+// outside the transaction blocks, and are marked with a comment.
+//
+//	migrate.NewPlanner(drv, dir, migrate.PlanFormat(postgres.TxFormatter))
+var TxFormatter migrate.Formatter = txFormatter{}
+
+// txFormatter implements the TxFormatter.
+type txFormatter struct{}
+
+// Format implements the migrate.Formatter interface.
+func (txFormatter) Format(p *migrate.Plan) ([]migrate.File, error) {
+	files, err := migrate.DefaultFormatter.Format(p)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		b    strings.Builder
+		inTx bool
+	)
+	for _, c := range p.Changes {
+		switch tx := transactional(c); {
+		case tx && !inTx:
+			b.WriteString("BEGIN;\n")
+		case !tx && inTx:
+			b.WriteString("COMMIT;\n")
+		}
+		if inTx = transactional(c); !inTx {
+			b.WriteString("-- atlas:nontransactional\n")
+		}
+		if c.Comment != "" {
+			b.WriteString("-- " + c.Comment + "\n")
+		}
+		for _, d := range c.Provenance {
+			b.WriteString("-- " + d + "\n")
+		}
+		b.WriteString(c.Cmd + ";\n")
+	}
+	if inTx {
+		b.WriteString("COMMIT;\n")
+	}
+	return []migrate.File{migrate.NewLocalFile(files[0].Name(), []byte(b.String()))}, nil
+}
+
+// transactional reports if the change can be executed inside a transaction block.
+func transactional(c *migrate.Change) bool {
+	cmd := strings.ToUpper(c.Cmd)
+	switch {
+	case strings.HasPrefix(cmd, "VACUUM"):
+		return false
+	case strings.HasPrefix(cmd, "CREATE INDEX"), strings.HasPrefix(cmd, "CREATE UNIQUE INDEX"),
+		strings.HasPrefix(cmd, "DROP INDEX"), strings.HasPrefix(cmd, "REINDEX"):
+		return !strings.Contains(cmd, " CONCURRENTLY ")
+	// Subscriptions that create, drop or refresh replication
+	// slots cannot be executed inside a transaction block.
+	case strings.HasPrefix(cmd, "CREATE SUBSCRIPTION"), strings.HasPrefix(cmd, "DROP SUBSCRIPTION"):
+		return false
+	case strings.HasPrefix(cmd, "ALTER SUBSCRIPTION"):
+		return !strings.Contains(cmd, " PUBLICATION ")
+	case addEnumValue(c):
+		// Before PostgreSQL 12, values cannot be added to enums inside a transaction
+		// block, and since then, new values cannot be used before they are committed.
+		return false
+	}
+	return true
+}
+
+// addEnumValue reports if the change adds a value to an enum type.
+func addEnumValue(c *migrate.Change) bool {
+	cmd := strings.ToUpper(c.Cmd)
+	return strings.HasPrefix(cmd, "ALTER TYPE") && strings.Contains(cmd, " ADD VALUE ")
+}
+
+// groupEnumValues moves the statements that add values to enum types to the
+// start of the plan, in their original order, so they are executed (outside
+// a transaction) once, and before the statements that may use these values.
+func groupEnumValues(changes []*migrate.Change) []*migrate.Change {
+	grouped := make([]*migrate.Change, 0, len(changes))
+	for _, c := range changes {
+		if addEnumValue(c) {
+			grouped = append(grouped, c)
+		}
+	}
+	for _, c := range changes {
+		if !addEnumValue(c) {
+			grouped = append(grouped, c)
+		}
+	}
+	return grouped
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+	migrate.PlanOptions
+	// Track the enums that were created, altered and
+	// dropped, in this phase to avoid duplicate updates.
+	created, altered, dropped map[string]*schema.EnumType
+	// Constraint validations that are executed at the end of the plan.
+	validate []*migrate.Change
+	// The baseline schema that is used instead of the
+	// database in case the plan is created offline.
+	baseline *schema.Schema
+}
+
+// Exec executes the changes on the database. An error is returned
+// if one of the operations fail, or a change is not supported.
+func (s *state) plan(ctx context.Context, changes []schema.Change) error {
+	if s.SchemaQualifier != nil {
+		if err := sqlx.CheckChangesScope(changes); err != nil {
+			return err
+		}
+	}
+	if s.CreateSchemas && s.SchemaQualifier == nil {
+		if err := s.createSchemas(ctx, changes); err != nil {
+			return err
+		}
+	}
+	changes, err := s.inheritedChecks(ctx, changes)
+	if err != nil {
+		return err
+	}
+	planned, deferred := s.topLevel(changes)
+	if planned, err = sqlx.DetachCycles(planned); err != nil {
+		return err
+	}
+	if s.Coalesce {
+		planned = coalesce(planned)
+	}
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(ctx, c)
+		case *schema.DropTable:
+			err = s.dropTable(ctx, c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(ctx, c)
+		case *schema.RenameTable:
+			s.renameTable(c)
+		case *schema.MoveTable:
+			s.moveTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// Objects are dropped after the tables (and columns) that may use them.
+	for _, c := range deferred {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			err = s.addObject(c)
+		case *schema.DropObject:
+			err = s.dropObject(c)
+		case *schema.ModifyObject:
+			switch to := c.To.(type) {
+			// The recreation of objects that were dropped by topLevel.
+			case *Statistics:
+				s.addStatistics(c, to)
+			case *Cast:
+				s.addCast(c, to)
+			case *Rule:
+				s.replaceRule(c, c.From.(*Rule), to)
+			// Tables are added to publications after they were created.
+			case *Publication:
+				err = s.modifyObject(c)
+			}
+		case *schema.DropSchema:
+			s.dropSchema(c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.append(s.validate...)
+	return nil
+}
+
+// createSchemas creates the schemas that are referenced by the given changes, but do not
+// exist in the database and are not created by the changes themselves. The statements are
+// planned before all other changes, as tables, types and objects may be placed in them.
+func (s *state) createSchemas(ctx context.Context, changes []schema.Change) error {
+	var (
+		names []string
+		seen  = make(map[string]bool)
+		use   = func(ns *schema.Schema) {
+			if ns != nil && ns.Name != "" && !seen[ns.Name] {
+				seen[ns.Name] = true
+				names = append(names, ns.Name)
+			}
+		}
+		columns = func(cs ...*schema.Column) {
+			for _, c := range cs {
+				if e, ok := hasEnumType(c); ok && e.Schema != nil {
+					use(e.Schema)
+				}
+			}
+		}
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			// Created explicitly.
+			seen[c.S.Name] = true
+		case *schema.AddObject:
+			use(objectSchema(c.O))
+		case *schema.AddTable:
+			use(c.T.Schema)
+			columns(c.T.Columns...)
+			for _, fk := range c.T.ForeignKeys {
+				use(fk.RefTable.Schema)
+			}
+		case *schema.ModifyTable:
+			use(c.T.Schema)
+			for _, c1 := range c.Changes {
+				if add, ok := c1.(*schema.AddColumn); ok {
+					columns(add.C)
+				}
+			}
+		}
+	}
+	for _, n := range names {
+		exists, err := s.schemaExists(ctx, n)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("CREATE SCHEMA IF NOT EXISTS").Ident(n).String(),
+			Reverse: s.Build("DROP SCHEMA").Ident(n).P("CASCADE").String(),
+			Comment: fmt.Sprintf("Add missing schema named %q", n),
+		})
+	}
+	return nil
+}
+
+// objectSchema returns the schema of the given object, if it is known.
+func objectSchema(o schema.Object) *schema.Schema {
+	switch o := o.(type) {
+	case *Collation:
+		return o.Schema
+	case *TextSearchConfiguration:
+		return o.Schema
+	case *DefaultPrivilege:
+		return o.Schema
+	}
+	return nil
+}
+
+// coalesce merges consecutive modifications of the same table into one change in
+// case all their sub-changes are executed as part of the ALTER TABLE statement.
+// Non-consecutive modifications are not merged, as the changes planned between
+// them may depend on their order (e.g. a table that references a new column).
+func coalesce(changes []schema.Change) []schema.Change {
+	merged := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || !alterOnly(m) || len(merged) == 0 {
+			merged = append(merged, c)
+			continue
+		}
+		last, ok := merged[len(merged)-1].(*schema.ModifyTable)
+		if !ok || last.T != m.T || !alterOnly(last) {
+			merged = append(merged, c)
+			continue
+		}
+		merged[len(merged)-1] = &schema.ModifyTable{
+			T:       m.T,
+			Changes: append(append(make([]schema.Change, 0, len(last.Changes)+len(m.Changes)), last.Changes...), m.Changes...),
+		}
+	}
+	return merged
+}
+
+// alterOnly reports if all changes of the table modification are
+// executed by one ALTER TABLE statement. i.e. column or constraint
+// changes, which are processed by the database in the right order.
+func alterOnly(m *schema.ModifyTable) bool {
+	for _, c := range m.Changes {
+		switch c := c.(type) {
+		case *schema.AddColumn, *schema.DropColumn, *schema.AddForeignKey, *schema.DropForeignKey,
+			*schema.AddCheck, *schema.DropCheck, *schema.ModifyCheck:
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangeComment) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// topLevel executes first the changes for creating or dropping schemas (top-level schema elements),
+// and creating or modifying schema objects. Object drops and type owners are returned separately, as
+// they are planned after the table changes.
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, []schema.Change) {
+	var (
+		deferred []schema.Change
+		planned  = make([]schema.Change, 0, len(changes))
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			// Types (e.g. enums) are created along with the tables that use them.
+			// Event triggers are created last, after the objects they may rely on,
+			// statistics, rules and publications after the tables they are defined
+			// on, and casts after the types they convert.
+			switch c.O.(type) {
+			case *TypeOwner, *EventTrigger, *Statistics, *Rule, *Publication, *Subscription, *Cast:
+				deferred = append(deferred, c)
+				continue
+			}
+			if err := s.addObject(c); err != nil {
+				// Unsupported objects are reported by the main loop.
+				planned = append(planned, c)
+			}
+		case *schema.ModifyObject:
+			// Statistics cannot be altered (besides their name, schema and target).
+			// Therefore, they are dropped before the table changes (as their columns
+			// may be dropped), and recreated after them (as they may be added).
+			if from, ok := c.From.(*Statistics); ok {
+				if _, ok := c.To.(*Statistics); ok {
+					s.dropStatistics(c, from)
+					deferred = append(deferred, c)
+					continue
+				}
+			}
+			// Rules are replaced after the table changes, as
+			// their actions may refer to the added columns.
+			switch c.From.(type) {
+			case *Publication, *Rule:
+				deferred = append(deferred, c)
+				continue
+			}
+			// Casts cannot be altered, and similar to statistics,
+			// they are recreated after the types they convert.
+			if from, ok := c.From.(*Cast); ok {
+				if _, ok := c.To.(*Cast); ok {
+					s.dropCast(c, from)
+					deferred = append(deferred, c)
+					continue
+				}
+			}
+			if err := s.modifyObject(c); err != nil {
+				planned = append(planned, c)
+			}
+		case *schema.MoveObject:
+			if err := s.moveObject(c); err != nil {
+				planned = append(planned, c)
+			}
+		case *schema.DropObject:
+			if o, ok := c.O.(*Statistics); ok {
+				s.dropStatistics(c, o)
+				continue
+			}
+			// Casts are dropped before the types they convert.
+			if o, ok := c.O.(*Cast); ok {
+				s.dropCast(c, o)
+				continue
+			}
+			// Rules are dropped before the tables they are defined on.
+			if o, ok := c.O.(*Rule); ok {
+				s.dropRule(c, o)
+				continue
+			}
+			deferred = append(deferred, c)
+		case *schema.AddSchema:
+			b := s.Build("CREATE SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+				b.P("IF NOT EXISTS")
+			}
+			b.Ident(c.S.Name)
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  c,
+				Reverse: s.Build("DROP SCHEMA").Ident(c.S.Name).P("CASCADE").String(),
+				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
+			})
+		case *schema.DropSchema:
+			// Schemas are dropped after the tables
+			// and objects that are moved out of them.
+			if movedFrom(changes, c.S) {
+				deferred = append(deferred, c)
+				continue
+			}
+			s.dropSchema(c)
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, deferred
+}
+
+// dropSchema builds the statement for dropping a schema.
+func (s *state) dropSchema(c *schema.DropSchema) {
+	b := s.Build("DROP SCHEMA")
+	if sqlx.Has(c.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	// Unlike tables and columns, schemas are dropped along with their objects by default.
+	b.Ident(c.S.Name)
+	if s.dropBehavior(c.Extra) == migrate.DropRestrict {
+		b.P("RESTRICT")
+	} else {
+		b.P("CASCADE")
+	}
+	change := &migrate.Change{
+		Cmd:     b.String(),
+		Source:  c,
+		Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
+	}
+	if s.SafetyWarnings && s.dropBehavior(c.Extra) != migrate.DropRestrict {
+		change.Warnings = append(change.Warnings, fmt.Sprintf("drops schema %q along with all of its objects and data", c.S.Name))
+	}
+	s.append(change)
+}
+
+// movedFrom reports if tables or objects are moved out of the given schema.
+func movedFrom(changes []schema.Change, s *schema.Schema) bool {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.MoveTable:
+			if c.From.Schema == s {
+				return true
+			}
+		case *schema.MoveObject:
+			if objectSchema(c.From) == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addObject builds the statement for creating a schema object.
+func (s *state) addObject(add *schema.AddObject) error {
+	switch o := add.O.(type) {
+	case *Collation:
+		s.append(&migrate.Change{
+			Cmd:     s.collationCreate(o),
+			Source:  add,
+			Comment: fmt.Sprintf("create %q collation", o.Name),
+			Reverse: s.collationDrop(o),
+		})
+	case *TextSearchConfiguration:
+		s.addTSConfig(add, o)
+	case *DefaultPrivilege:
+		s.append(&migrate.Change{
+			Cmd:     s.defaultPrivileges(o, "GRANT", o.Privileges),
+			Source:  add,
+			Comment: fmt.Sprintf("grant default privileges on %s in schema %q to %q", strings.ToLower(o.ObjType), o.Schema.Name, o.Grantee),
+			Reverse: s.defaultPrivileges(o, "REVOKE", o.Privileges),
+		})
+	case *TypeOwner:
+		// The previous owner is unknown (the type may be
+		// created by this plan), therefore, not reversible.
+		s.append(&migrate.Change{
+			Cmd:     s.typeOwner(o),
+			Source:  add,
+			Comment: fmt.Sprintf("set the owner of type %q to %q", o.Name, o.Owner),
+		})
+	case *EventTrigger:
+		s.addEventTrigger(add, o)
+	case *Statistics:
+		s.addStatistics(add, o)
+	case *Rule:
+		s.addRule(add, o)
+	case *Publication:
+		s.addPublication(add, o)
+	case *Subscription:
+		return s.addSubscription(add, o)
+	case *Cast:
+		s.addCast(add, o)
+	case *AccessMethod:
+		s.addAccessMethod(add, o)
+	default:
+		return fmt.Errorf("unsupported object %T", add.O)
+	}
+	return nil
+}
+
+// dropObject builds the statement for dropping a schema object.
+func (s *state) dropObject(drop *schema.DropObject) error {
+	switch o := drop.O.(type) {
+	case *Collation:
+		s.append(&migrate.Change{
+			Cmd:     s.collationDrop(o),
+			Source:  drop,
+			Comment: fmt.Sprintf("drop %q collation", o.Name),
+			Reverse: s.collationCreate(o),
+		})
+	case *TextSearchConfiguration:
+		s.dropTSConfig(drop, o)
+	case *DefaultPrivilege:
+		s.append(&migrate.Change{
+			Cmd:     s.defaultPrivileges(o, "REVOKE", o.Privileges),
+			Source:  drop,
+			Comment: fmt.Sprintf("revoke default privileges on %s in schema %q from %q", strings.ToLower(o.ObjType), o.Schema.Name, o.Grantee),
+			Reverse: s.defaultPrivileges(o, "GRANT", o.Privileges),
+		})
+	case *TypeOwner:
+		// Either the type was dropped, or its ownership is not managed
+		// by the desired state. Similar to the differ, which does not
+		// report such changes, there is nothing to plan.
+	case *EventTrigger:
+		s.dropEventTrigger(drop, o)
+	case *Statistics:
+		s.dropStatistics(drop, o)
+	case *Rule:
+		s.dropRule(drop, o)
+	case *Publication:
+		s.dropPublication(drop, o)
+	case *Subscription:
+		s.dropSubscription(drop, o)
+	case *Cast:
+		s.dropCast(drop, o)
+	case *AccessMethod:
+		s.dropAccessMethod(drop, o)
+	default:
+		return fmt.Errorf("unsupported object %T", drop.O)
+	}
+	return nil
+}
+
+// moveObject builds the statement for moving a schema object to another schema.
+func (s *state) moveObject(move *schema.MoveObject) error {
+	var (
+		kind     string
+		from, to *schema.Schema
+	)
+	switch o := move.From.(type) {
+	case *Collation:
+		to1, ok := move.To.(*Collation)
+		if !ok {
+			return fmt.Errorf("mismatched moved objects %T and %T", move.From, move.To)
+		}
+		kind, from, to = "COLLATION", o.Schema, to1.Schema
+	case *TextSearchConfiguration:
+		to1, ok := move.To.(*TextSearchConfiguration)
+		if !ok {
+			return fmt.Errorf("mismatched moved objects %T and %T", move.From, move.To)
+		}
+		kind, from, to = "TEXT SEARCH CONFIGURATION", o.Schema, to1.Schema
+	default:
+		return fmt.Errorf("unsupported moved object %T", move.From)
+	}
+	name := objectName(move.From)
+	s.append(&migrate.Change{
+		Cmd:     s.Build("ALTER", kind).P(s.schemaPrefix(from) + s.ident(name)).P("SET SCHEMA").Ident(to.Name).String(),
+		Source:  move,
+		Comment: fmt.Sprintf("move %s %q from schema %q to %q", strings.ToLower(kind), name, from.Name, to.Name),
+		Reverse: s.Build("ALTER", kind).P(s.schemaPrefix(to) + s.ident(name)).P("SET SCHEMA").Ident(from.Name).String(),
+	})
+	return nil
+}
+
+// modifyObject builds the statements for modifying a schema object.
+func (s *state) modifyObject(modify *schema.ModifyObject) error {
+	switch from := modify.From.(type) {
+	case *Collation:
+		to, ok := modify.To.(*Collation)
+		if !ok {
+			break
+		}
+		// Collations cannot be altered (besides their version
+		// and name). Therefore, they are dropped and recreated.
+		s.append(&migrate.Change{
+			Cmd:     s.collationDrop(from),
+			Source:  modify,
+			Comment: fmt.Sprintf("drop %q collation for recreating it", from.Name),
+			Reverse: s.collationCreate(from),
+		})
+		s.append(&migrate.Change{
+			Cmd:     s.collationCreate(to),
+			Source:  modify,
+			Comment: fmt.Sprintf("create %q collation", to.Name),
+			Reverse: s.collationDrop(to),
+		})
+		return nil
+	case *AccessMethod:
+		to, ok := modify.To.(*AccessMethod)
+		if !ok {
+			break
+		}
+		// Access methods cannot be altered. Therefore, they are dropped and
+		// recreated, which fails in case they are used by tables or indexes.
+		s.dropAccessMethod(modify, from)
+		s.addAccessMethod(modify, to)
+		return nil
+	case *TextSearchConfiguration:
+		to, ok := modify.To.(*TextSearchConfiguration)
+		if !ok {
+			break
+		}
+		// The parser of a configuration cannot be altered.
+		if from.parser() != to.parser() {
+			s.dropTSConfig(modify, from)
+			s.addTSConfig(modify, to)
+			return nil
+		}
+		s.alterTSMappings(modify, from, to)
+		return nil
+	case *DefaultPrivilege:
+		to, ok := modify.To.(*DefaultPrivilege)
+		if !ok {
+			break
+		}
+		grant, revoke := privilegesDiff(from, to)
+		if len(revoke) > 0 {
+			s.append(&migrate.Change{
+				Cmd:     s.defaultPrivileges(to, "REVOKE", revoke),
+				Source:  modify,
+				Comment: fmt.Sprintf("revoke default privileges on %s in schema %q from %q", strings.ToLower(to.ObjType), to.Schema.Name, to.Grantee),
+				Reverse: s.defaultPrivileges(to, "GRANT", revoke),
+			})
+		}
+		if len(grant) > 0 {
+			s.append(&migrate.Change{
+				Cmd:     s.defaultPrivileges(to, "GRANT", grant),
+				Source:  modify,
+				Comment: fmt.Sprintf("grant default privileges on %s in schema %q to %q", strings.ToLower(to.ObjType), to.Schema.Name, to.Grantee),
+				Reverse: s.defaultPrivileges(to, "REVOKE", grant),
+			})
+		}
+		return nil
+	case *TypeOwner:
+		to, ok := modify.To.(*TypeOwner)
+		if !ok {
+			break
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.typeOwner(to),
+			Source:  modify,
+			Comment: fmt.Sprintf("change the owner of type %q from %q to %q", to.Name, from.Owner, to.Owner),
+			Reverse: s.typeOwner(from),
+		})
+		return nil
+	case *EventTrigger:
+		to, ok := modify.To.(*EventTrigger)
+		if !ok {
+			break
+		}
+		// Besides their state (and name), event triggers
+		// cannot be altered. Therefore, they are recreated.
+		if !eventTriggerDefEqual(from, to) {
+			s.dropEventTrigger(modify, from)
+			s.addEventTrigger(modify, to)
+			return nil
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.eventTriggerState(to),
+			Source:  modify,
+			Comment: fmt.Sprintf("change the state of %q event trigger to %s", to.Name, to.state()),
+			Reverse: s.eventTriggerState(from),
+		})
+		return nil
+	case *Publication:
+		to, ok := modify.To.(*Publication)
+		if !ok {
+			break
+		}
+		// Publications cannot be switched from or to FOR ALL TABLES.
+		if from.AllTables != to.AllTables {
+			s.dropPublication(modify, from)
+			s.addPublication(modify, to)
+			return nil
+		}
+		s.alterPublication(modify, from, to)
+		return nil
+	case *Subscription:
+		to, ok := modify.To.(*Subscription)
+		if !ok {
+			break
+		}
+		s.alterSubscription(modify, from, to)
+		return nil
+	}
+	return fmt.Errorf("unsupported object modification %T -> %T", modify.From, modify.To)
+}
+
+// addPublication builds the statement for creating a publication.
+func (s *state) addPublication(src schema.Change, p *Publication) {
+	s.append(&migrate.Change{
+		Cmd:     s.publicationCreate(p),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q publication", p.Name),
+		Reverse: s.Build("DROP PUBLICATION").Ident(p.Name).String(),
+	})
+}
+
+// dropPublication builds the statement for dropping a publication.
+func (s *state) dropPublication(src schema.Change, p *Publication) {
+	s.append(&migrate.Change{
+		Cmd:     s.Build("DROP PUBLICATION").Ident(p.Name).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q publication", p.Name),
+		Reverse: s.publicationCreate(p),
+	})
+}
+
+// publicationCreate returns the CREATE PUBLICATION statement of the publication.
+func (s *state) publicationCreate(p *Publication) string {
+	b := s.Build("CREATE PUBLICATION").Ident(p.Name)
+	switch {
+	case p.AllTables:
+		b.P("FOR ALL TABLES")
+	case len(p.Tables) > 0:
+		b.P("FOR TABLE").MapComma(p.Tables, func(i int, b *sqlx.Builder) {
+			b.Table(p.Tables[i])
+		})
+	}
+	if ops := publishOps(p.Publish); len(ops) > 0 || p.ViaRoot {
+		b.P("WITH").Wrap(func(b *sqlx.Builder) {
+			b.WriteString(publicationParams(p))
+		})
+	}
+	return b.String()
+}
+
+// publicationParams returns the parameters of the publication, as used in the WITH clause.
+func publicationParams(p *Publication) string {
+	ops := publishOps(p.Publish)
+	if ops == nil {
+		ops = []string{"insert", "update", "delete", "truncate"}
+	}
+	return fmt.Sprintf("publish = %s, publish_via_partition_root = %t", quote(strings.Join(ops, ", ")), p.ViaRoot)
+}
+
+// alterPublication builds the statements for altering the tables and parameters of a publication.
+func (s *state) alterPublication(src schema.Change, from, to *Publication) {
+	alter := func(op string, ts []*schema.Table) string {
+		return s.Build("ALTER PUBLICATION").Ident(to.Name).P(op, "TABLE").MapComma(ts, func(i int, b *sqlx.Builder) {
+			b.Table(ts[i])
+		}).String()
+	}
+	if add := publicationTablesDiff(to, from); len(add) > 0 {
+		s.append(&migrate.Change{
+			Cmd:     alter("ADD", add),
+			Source:  src,
+			Comment: fmt.Sprintf("add tables to %q publication", to.Name),
+			Reverse: alter("DROP", add),
+		})
+	}
+	if drop := publicationTablesDiff(from, to); len(drop) > 0 {
+		s.append(&migrate.Change{
+			Cmd:     alter("DROP", drop),
+			Source:  src,
+			Comment: fmt.Sprintf("drop tables from %q publication", to.Name),
+			Reverse: alter("ADD", drop),
+		})
+	}
+	if !publicationParamsEqual(from, to) {
+		s.append(&migrate.Change{
+			Cmd:     s.Build("ALTER PUBLICATION").Ident(to.Name).P("SET").Wrap(func(b *sqlx.Builder) { b.WriteString(publicationParams(to)) }).String(),
+			Source:  src,
+			Comment: fmt.Sprintf("change the parameters of %q publication", to.Name),
+			Reverse: s.Build("ALTER PUBLICATION").Ident(to.Name).P("SET").Wrap(func(b *sqlx.Builder) { b.WriteString(publicationParams(from)) }).String(),
+		})
+	}
+}
+
+// addSubscription builds the statement for creating a subscription.
+func (s *state) addSubscription(src schema.Change, sub *Subscription) error {
+	if sub.Conn == "" {
+		return fmt.Errorf("missing connection string for subscription %q", sub.Name)
+	}
+	s.append(&migrate.Change{
+		Cmd:     s.subscriptionCreate(sub),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q subscription", sub.Name),
+		Reverse: s.Build("DROP SUBSCRIPTION").Ident(sub.Name).String(),
+	})
+	return nil
+}
+
+// dropSubscription builds the statement for dropping a subscription. The drop is
+// reversible only if the connection string of the subscription is known.
+func (s *state) dropSubscription(src schema.Change, sub *Subscription) {
+	change := &migrate.Change{
+		Cmd:     s.Build("DROP SUBSCRIPTION").Ident(sub.Name).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q subscription", sub.Name),
+	}
+	if sub.Conn != "" {
+		change.Reverse = s.subscriptionCreate(sub)
+	}
+	s.append(change)
+}
+
+// subscriptionCreate returns the CREATE SUBSCRIPTION statement of the subscription.
+func (s *state) subscriptionCreate(sub *Subscription) string {
+	b := s.Build("CREATE SUBSCRIPTION").Ident(sub.Name).P("CONNECTION", s.connString(sub.Conn), "PUBLICATION").
+		MapComma(sub.Publications, func(i int, b *sqlx.Builder) {
+			b.Ident(sub.Publications[i])
+		})
+	opts := sub.Options
+	if sub.Disabled {
+		opts = append([]struct{ N, V string }{{N: "enabled", V: "false"}}, opts...)
+	}
+	if len(opts) > 0 {
+		b.P("WITH").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(opts, func(i int, b *sqlx.Builder) {
+				b.P(opts[i].N, "=", subscriptionValue(opts[i].V))
+			})
+		})
+	}
+	return b.String()
+}
+
+// alterSubscription builds the statements for altering the connection,
+// publications, options and the state of a subscription.
+func (s *state) alterSubscription(src schema.Change, from, to *Subscription) {
+	b := func() *sqlx.Builder { return s.Build("ALTER SUBSCRIPTION").Ident(to.Name) }
+	if subscriptionConnChanged(from, to) {
+		s.append(&migrate.Change{
+			Cmd:     b().P("CONNECTION", s.connString(to.Conn)).String(),
+			Source:  src,
+			Comment: fmt.Sprintf("change the connection of %q subscription", to.Name),
+			Reverse: b().P("CONNECTION", s.connString(from.Conn)).String(),
+		})
+	}
+	if !sqlx.ValuesEqual(sortedCopy(from.Publications), sortedCopy(to.Publications)) {
+		set := func(pubs []string) string {
+			return b().P("SET PUBLICATION").MapComma(pubs, func(i int, b *sqlx.Builder) {
+				b.Ident(pubs[i])
+			}).String()
+		}
+		s.append(&migrate.Change{
+			Cmd:     set(to.Publications),
+			Source:  src,
+			Comment: fmt.Sprintf("change the publications of %q subscription", to.Name),
+			Reverse: set(from.Publications),
+		})
+	}
+	if opts := subscriptionOptionsDiff(from, to); len(opts) > 0 {
+		set := func(opts []struct{ N, V string }) string {
+			return b().P("SET").Wrap(func(b *sqlx.Builder) {
+				b.MapComma(opts, func(i int, b *sqlx.Builder) {
+					b.P(opts[i].N, "=", subscriptionValue(opts[i].V))
+				})
+			}).String()
+		}
+		change := &migrate.Change{
+			Cmd:     set(opts),
+			Source:  src,
+			Comment: fmt.Sprintf("change the options of %q subscription", to.Name),
+		}
+		// Reversible only if the previous values are known.
+		prev := make([]struct{ N, V string }, 0, len(opts))
+		for _, o := range opts {
+			if v, ok := subscriptionOption(from, o.N); ok {
+				prev = append(prev, struct{ N, V string }{N: o.N, V: v})
+			}
+		}
+		if len(prev) == len(opts) {
+			change.Reverse = set(prev)
+		}
+		s.append(change)
+	}
+	if from.Disabled != to.Disabled {
+		state := func(disabled bool) string {
+			if disabled {
+				return b().P("DISABLE").String()
+			}
+			return b().P("ENABLE").String()
+		}
+		s.append(&migrate.Change{
+			Cmd:     state(to.Disabled),
+			Source:  src,
+			Comment: fmt.Sprintf("change the state of %q subscription", to.Name),
+			Reverse: state(from.Disabled),
+		})
+	}
+}
+
+// reSecret matches the passwords in connection strings, either
+// as a key/value pair (password=secret) or as URI user info.
+var reSecret = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)|(://[^:/@\s]+:)[^@\s]+(@)`)
+
+// connString returns the quoted connection string of a subscription.
+// Passwords are masked in case secrets are redacted from the plan.
+func (s *state) connString(conn string) string {
+	if s.RedactSecrets {
+		conn = reSecret.ReplaceAllString(conn, "${1}${3}********${4}")
+	}
+	return quote(conn)
+}
+
+// subscriptionValue returns the representation of a subscription option value.
+// Booleans, numbers and NONE are written as-is, and the rest as string literals.
+func subscriptionValue(v string) string {
+	switch strings.ToLower(v) {
+	case "true", "false", "on", "off", "none":
+		return v
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	return quote(v)
+}
+
+// addStatistics builds the statement for creating extended statistics.
+func (s *state) addStatistics(src schema.Change, st *Statistics) {
+	s.append(&migrate.Change{
+		Cmd:     s.statisticsCreate(st),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q statistics on table %q", st.Name, st.Table.Name),
+		Reverse: s.statisticsDrop(st),
+	})
+}
+
+// dropStatistics builds the statement for dropping extended statistics.
+func (s *state) dropStatistics(src schema.Change, st *Statistics) {
+	s.append(&migrate.Change{
+		Cmd:     s.statisticsDrop(st),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q statistics from table %q", st.Name, st.Table.Name),
+		Reverse: s.statisticsCreate(st),
+	})
+}
+
+// statisticsCreate returns the CREATE STATISTICS statement of the extended statistics.
+func (s *state) statisticsCreate(st *Statistics) string {
+	b := s.Build("CREATE STATISTICS").P(s.schemaPrefix(st.Schema) + s.ident(st.Name))
+	if len(st.Kinds) > 0 {
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(st.Kinds, func(i int, b *sqlx.Builder) {
+				b.WriteString(strings.ToLower(st.Kinds[i]))
+			})
+		})
+	}
+	return b.P("ON").MapComma(st.Columns, func(i int, b *sqlx.Builder) {
+		b.Ident(st.Columns[i].Name)
+	}).P("FROM").Table(st.Table).String()
+}
+
+// statisticsDrop returns the DROP STATISTICS statement of the extended statistics.
+func (s *state) statisticsDrop(st *Statistics) string {
+	return s.Build("DROP STATISTICS").P(s.schemaPrefix(st.Schema) + s.ident(st.Name)).String()
+}
+
+// addRule builds the statement for creating a rewrite rule.
+func (s *state) addRule(src schema.Change, r *Rule) {
+	s.append(&migrate.Change{
+		Cmd:     s.ruleCreate(r, false),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q rule on table %q", r.Name, r.Table.Name),
+		Reverse: s.ruleDrop(r),
+	})
+}
+
+// dropRule builds the statement for dropping a rewrite rule.
+func (s *state) dropRule(src schema.Change, r *Rule) {
+	s.append(&migrate.Change{
+		Cmd:     s.ruleDrop(r),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q rule from table %q", r.Name, r.Table.Name),
+		Reverse: s.ruleCreate(r, false),
+	})
+}
+
+// replaceRule builds the statement for replacing the definition of a rewrite rule.
+func (s *state) replaceRule(src schema.Change, from, to *Rule) {
+	s.append(&migrate.Change{
+		Cmd:     s.ruleCreate(to, true),
+		Source:  src,
+		Comment: fmt.Sprintf("replace %q rule on table %q", to.Name, to.Table.Name),
+		Reverse: s.ruleCreate(from, true),
+	})
+}
+
+// ruleCreate returns the CREATE RULE statement of the rewrite rule.
+func (s *state) ruleCreate(r *Rule, replace bool) string {
+	b := s.Build("CREATE")
+	if replace {
+		b.P("OR REPLACE")
+	}
+	b.P("RULE").Ident(r.Name).P("AS ON", strings.ToUpper(r.Event), "TO").Table(r.Table)
+	if r.Where != "" {
+		b.P("WHERE", r.Where)
+	}
+	b.P("DO")
+	if r.Instead {
+		b.P("INSTEAD")
+	}
+	actions := strings.TrimSuffix(strings.TrimSpace(r.Actions), ";")
+	if actions == "" {
+		actions = "NOTHING"
+	}
+	return b.P(actions).String()
+}
+
+// ruleDrop returns the DROP RULE statement of the rewrite rule.
+func (s *state) ruleDrop(r *Rule) string {
+	return s.Build("DROP RULE").Ident(r.Name).P("ON").Table(r.Table).String()
+}
+
+// addCast builds the statement for creating a cast.
+func (s *state) addCast(src schema.Change, c *Cast) {
+	s.append(&migrate.Change{
+		Cmd:     s.castCreate(c),
+		Source:  src,
+		Comment: fmt.Sprintf("create cast %s", c.name()),
+		Reverse: s.Build("DROP CAST").P(c.name()).String(),
+	})
+}
+
+// dropCast builds the statement for dropping a cast.
+func (s *state) dropCast(src schema.Change, c *Cast) {
+	s.append(&migrate.Change{
+		Cmd:     s.Build("DROP CAST").P(c.name()).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop cast %s", c.name()),
+		Reverse: s.castCreate(c),
+	})
+}
+
+// castCreate returns the CREATE CAST statement of the cast.
+func (s *state) castCreate(c *Cast) string {
+	b := s.Build("CREATE CAST").P(c.name())
+	switch {
+	case c.Func != "":
+		b.P("WITH FUNCTION", c.Func)
+	case c.InOut:
+		b.P("WITH INOUT")
+	default:
+		b.P("WITHOUT FUNCTION")
+	}
+	if ctx := c.context(); ctx != "EXPLICIT" {
+		b.P("AS", ctx)
+	}
+	return b.String()
+}
+
+// addAccessMethod builds the statement for creating an access method.
+func (s *state) addAccessMethod(src schema.Change, a *AccessMethod) {
+	s.append(&migrate.Change{
+		Cmd:     s.accessMethodCreate(a),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q access method", a.Name),
+		Reverse: s.Build("DROP ACCESS METHOD").Ident(a.Name).String(),
+	})
+}
+
+// dropAccessMethod builds the statement for dropping an access method.
+func (s *state) dropAccessMethod(src schema.Change, a *AccessMethod) {
+	s.append(&migrate.Change{
+		Cmd:     s.Build("DROP ACCESS METHOD").Ident(a.Name).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q access method", a.Name),
+		Reverse: s.accessMethodCreate(a),
+	})
+}
+
+// accessMethodCreate returns the CREATE ACCESS METHOD statement of the access method.
+func (s *state) accessMethodCreate(a *AccessMethod) string {
+	return s.Build("CREATE ACCESS METHOD").Ident(a.Name).P("TYPE", strings.ToUpper(a.Type), "HANDLER", a.Handler).String()
+}
+
+// addEventTrigger builds the statements for creating an event trigger and setting its state.
+func (s *state) addEventTrigger(src schema.Change, e *EventTrigger) {
+	s.append(&migrate.Change{
+		Cmd:     s.eventTriggerCreate(e),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q event trigger", e.Name),
+		Reverse: s.Build("DROP EVENT TRIGGER").Ident(e.Name).String(),
+	})
+	// Event triggers are created enabled.
+	if e.state() != "ENABLE" {
+		s.append(&migrate.Change{
+			Cmd:     s.eventTriggerState(e),
+			Source:  src,
+			Comment: fmt.Sprintf("change the state of %q event trigger to %s", e.Name, e.state()),
+			Reverse: s.eventTriggerState(&EventTrigger{Name: e.Name}),
+		})
+	}
+}
+
+// dropEventTrigger builds the statement for dropping an event trigger.
+func (s *state) dropEventTrigger(src schema.Change, e *EventTrigger) {
+	change := &migrate.Change{
+		Cmd:     s.Build("DROP EVENT TRIGGER").Ident(e.Name).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q event trigger", e.Name),
+	}
+	// Disabled triggers cannot be recreated using one statement.
+	if e.state() == "ENABLE" {
+		change.Reverse = s.eventTriggerCreate(e)
+	}
+	s.append(change)
+}
+
+// eventTriggerCreate returns the CREATE EVENT TRIGGER statement of the event trigger.
+func (s *state) eventTriggerCreate(e *EventTrigger) string {
+	b := s.Build("CREATE EVENT TRIGGER").Ident(e.Name).P("ON", e.Event)
+	if len(e.Tags) > 0 {
+		b.P("WHEN TAG IN").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(e.Tags, func(i int, b *sqlx.Builder) {
+				b.WriteString(quote(e.Tags[i]))
+			})
+		})
+	}
+	// EXECUTE FUNCTION was added in PostgreSQL 11 as a replacement of EXECUTE PROCEDURE.
+	if s.version < 11_00_00 {
+		b.P("EXECUTE PROCEDURE")
+	} else {
+		b.P("EXECUTE FUNCTION")
+	}
+	fn := s.ident(e.Func)
+	if e.FuncSchema != "" {
+		fn = s.ident(e.FuncSchema) + "." + fn
+	}
+	return b.P(fn + "()").String()
+}
+
+// eventTriggerState returns the ALTER EVENT TRIGGER statement for setting the state of the event trigger.
+func (s *state) eventTriggerState(e *EventTrigger) string {
+	return s.Build("ALTER EVENT TRIGGER").Ident(e.Name).P(e.state()).String()
+}
+
+// addTSConfig builds the statements for creating a text search configuration and its mappings.
+func (s *state) addTSConfig(src schema.Change, c *TextSearchConfiguration) {
+	name := s.tsConfigName(c)
+	s.append(&migrate.Change{
+		Cmd:     s.Build("CREATE TEXT SEARCH CONFIGURATION").P(name).Wrap(func(b *sqlx.Builder) { b.P("PARSER =", s.tsParser(c)) }).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("create %q text search configuration", c.Name),
+		Reverse: s.Build("DROP TEXT SEARCH CONFIGURATION").P(name).String(),
+	})
+	s.alterTSMappings(src, &TextSearchConfiguration{Name: c.Name, Schema: c.Schema}, c)
+}
+
+// dropTSConfig builds the statement for dropping a text search configuration.
+func (s *state) dropTSConfig(src schema.Change, c *TextSearchConfiguration) {
+	name := s.tsConfigName(c)
+	change := &migrate.Change{
+		Cmd:     s.Build("DROP TEXT SEARCH CONFIGURATION").P(name).String(),
+		Source:  src,
+		Comment: fmt.Sprintf("drop %q text search configuration", c.Name),
+	}
+	// Configurations with mappings cannot be recreated using one statement.
+	if len(c.Mappings) == 0 {
+		change.Reverse = s.Build("CREATE TEXT SEARCH CONFIGURATION").P(name).Wrap(func(b *sqlx.Builder) { b.P("PARSER =", s.tsParser(c)) }).String()
+	}
+	s.append(change)
+}
+
+// alterTSMappings builds the statements for changing the token mappings of a text search configuration.
+func (s *state) alterTSMappings(src schema.Change, from, to *TextSearchConfiguration) {
+	var (
+		name  = s.tsConfigName(to)
+		fromM = make(map[string]*TextSearchMapping, len(from.Mappings))
+		alter = func(action string, m *TextSearchMapping) string {
+			b := s.Build("ALTER TEXT SEARCH CONFIGURATION").P(name, action, "MAPPING FOR", m.Token)
+			if action != "DROP" {
+				b.P("WITH", strings.Join(m.Dicts, ", "))
+			}
+			return b.String()
+		}
+	)
+	for _, m := range from.Mappings {
+		fromM[m.Token] = m
+	}
+	for _, m := range tsMappingsDiff(from, to) {
+		m1, ok := fromM[m.Token]
+		switch {
+		case !ok:
+			s.append(&migrate.Change{
+				Cmd:     alter("ADD", m),
+				Source:  src,
+				Comment: fmt.Sprintf("add %q mapping to %q text search configuration", m.Token, to.Name),
+				Reverse: alter("DROP", m),
+			})
+		case len(m.Dicts) == 0:
+			s.append(&migrate.Change{
+				Cmd:     alter("DROP", m),
+				Source:  src,
+				Comment: fmt.Sprintf("drop %q mapping from %q text search configuration", m.Token, to.Name),
+				Reverse: alter("ADD", m1),
+			})
+		default:
+			s.append(&migrate.Change{
+				Cmd:     alter("ALTER", m),
+				Source:  src,
+				Comment: fmt.Sprintf("modify %q mapping of %q text search configuration", m.Token, to.Name),
+				Reverse: alter("ALTER", m1),
+			})
+		}
+	}
+}
+
+// defaultPrivileges returns the ALTER DEFAULT PRIVILEGES statement for granting
+// or revoking the given privileges of the default privileges object.
+func (s *state) defaultPrivileges(p *DefaultPrivilege, action string, privileges []string) string {
+	b := s.Build("ALTER DEFAULT PRIVILEGES")
+	if p.Owner != "" {
+		b.P("FOR ROLE").Ident(p.Owner)
+	}
+	// Unlike other objects, default privileges are always scoped
+	// to a schema, even if the schema qualifier is omitted.
+	ns := p.Schema.Name
+	if s.SchemaQualifier != nil && *s.SchemaQualifier != "" {
+		ns = *s.SchemaQualifier
 	}
-	if err := s.plan(ctx, changes); err != nil {
-		return nil, err
+	b.P("IN SCHEMA").Ident(ns).P(action, strings.Join(privileges, ", "), "ON", strings.ToUpper(p.ObjType))
+	if action == "GRANT" {
+		b.P("TO")
+	} else {
+		b.P("FROM")
 	}
-	for _, c := range s.Changes {
-		if c.Reverse == "" {
-			s.Reversible = false
-		}
+	if strings.EqualFold(p.Grantee, "PUBLIC") {
+		b.P("PUBLIC")
+	} else {
+		b.Ident(p.Grantee)
 	}
-	return &s.Plan, nil
+	return b.String()
 }
 
-// ApplyChanges applies the changes on the database. An error is returned
-// if the driver is unable to produce a plan to do so, or one of the statements
-// is failed or unsupported.
-func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change, opts ...migrate.PlanOption) error {
-	return sqlx.ApplyChanges(ctx, changes, p, opts...)
+// typeOwner returns the ALTER TYPE statement for changing the owner of the type.
+func (s *state) typeOwner(o *TypeOwner) string {
+	return s.Build("ALTER TYPE").P(s.schemaPrefix(o.Schema) + s.ident(o.Name)).P("OWNER TO").Ident(o.Owner).String()
 }
 
-// state represents the state of a planning. It is not part of
-// planApply so that multiple planning/applying can be called
-// in parallel.
-type state struct {
-	conn
-	migrate.Plan
-	migrate.PlanOptions
-	// Track the enums that were created, altered and
-	// dropped, in this phase to avoid duplicate updates.
-	created, altered, dropped map[string]*schema.EnumType
+// tsConfigName returns the (qualified) name of the text search configuration.
+func (s *state) tsConfigName(c *TextSearchConfiguration) string {
+	return s.schemaPrefix(c.Schema) + s.ident(c.Name)
 }
 
-// Exec executes the changes on the database. An error is returned
-// if one of the operations fail, or a change is not supported.
-func (s *state) plan(ctx context.Context, changes []schema.Change) error {
-	if s.SchemaQualifier != nil {
-		if err := sqlx.CheckChangesScope(changes); err != nil {
-			return err
-		}
+// tsParser returns the qualified parser name of the text search configuration.
+func (s *state) tsParser(c *TextSearchConfiguration) string {
+	ns, name, ok := strings.Cut(c.Parser, ".")
+	if !ok {
+		ns, name = "pg_catalog", c.parser()
 	}
-	planned := s.topLevel(changes)
-	planned, err := sqlx.DetachCycles(planned)
-	if err != nil {
-		return err
-	}
-	for _, c := range planned {
-		switch c := c.(type) {
-		case *schema.AddTable:
-			err = s.addTable(ctx, c)
-		case *schema.DropTable:
-			s.dropTable(c)
-		case *schema.ModifyTable:
-			err = s.modifyTable(ctx, c)
-		case *schema.RenameTable:
-			s.renameTable(c)
-		default:
-			err = fmt.Errorf("unsupported change %T", c)
+	return s.ident(ns) + "." + s.ident(name)
+}
+
+// collationCreate returns the CREATE COLLATION statement of the collation.
+func (s *state) collationCreate(c *Collation) string {
+	b := s.Build("CREATE COLLATION").P(s.collationName(c))
+	b.Wrap(func(b *sqlx.Builder) {
+		var opts []string
+		if c.Provider != "" && c.Provider != "default" {
+			opts = append(opts, "provider = "+c.Provider)
 		}
-		if err != nil {
-			return err
+		if c.LCCollate != "" || c.LCCtype != "" {
+			opts = append(opts, "lc_collate = "+quote(c.LCCollate), "lc_ctype = "+quote(c.LCCtype))
+		} else {
+			opts = append(opts, "locale = "+quote(c.Locale))
 		}
-	}
-	return nil
+		if c.Nondeterministic {
+			opts = append(opts, "deterministic = false")
+		}
+		b.P(strings.Join(opts, ", "))
+	})
+	return b.String()
 }
 
-// topLevel executes first the changes for creating or dropping schemas (top-level schema elements).
-func (s *state) topLevel(changes []schema.Change) []schema.Change {
-	planned := make([]schema.Change, 0, len(changes))
-	for _, c := range changes {
-		switch c := c.(type) {
-		case *schema.AddSchema:
-			b := s.Build("CREATE SCHEMA")
-			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
-				b.P("IF NOT EXISTS")
-			}
-			b.Ident(c.S.Name)
-			s.append(&migrate.Change{
-				Cmd:     b.String(),
-				Source:  c,
-				Reverse: s.Build("DROP SCHEMA").Ident(c.S.Name).P("CASCADE").String(),
-				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
-			})
-		case *schema.DropSchema:
-			b := s.Build("DROP SCHEMA")
-			if sqlx.Has(c.Extra, &schema.IfExists{}) {
-				b.P("IF EXISTS")
-			}
-			b.Ident(c.S.Name).P("CASCADE")
-			s.append(&migrate.Change{
-				Cmd:     b.String(),
-				Source:  c,
-				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
-			})
-		default:
-			planned = append(planned, c)
-		}
-	}
-	return planned
+// collationDrop returns the DROP COLLATION statement of the collation.
+func (s *state) collationDrop(c *Collation) string {
+	return s.Build("DROP COLLATION").P(s.collationName(c)).String()
+}
+
+// collationName returns the (qualified) name of the collation.
+func (s *state) collationName(c *Collation) string {
+	return s.schemaPrefix(c.Schema) + s.ident(c.Name)
 }
 
 // addTable builds and executes the query for creating a table in a schema.
@@ -177,34 +1650,153 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 		}
 		b.P(s)
 	}
+	if a := (TableAccessMethod{}); sqlx.Has(add.T.Attrs, &a) {
+		b.P("USING").Ident(a.V)
+	}
+	var warnings []string
+	if p, ok := tableStorageParams(add.T.Attrs); ok {
+		if p.oids() {
+			warnings = append(warnings, oidsWarning)
+			// Skip the option, as it fails the statement otherwise.
+			if !s.supportsOIDs() {
+				p = p.withoutOIDs()
+			}
+		}
+		if len(p.Params) > 0 {
+			b.P("WITH").Wrap(func(b *sqlx.Builder) {
+				b.MapComma(p.Params, func(i int, b *sqlx.Builder) {
+					b.P(p.Params[i].N, "=", p.Params[i].V)
+				})
+			})
+		}
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
+	if w := setDefaultWarning(add.T.ForeignKeys); w != "" {
+		warnings = append(warnings, w)
+	}
 	s.append(&migrate.Change{
-		Cmd:     b.String(),
-		Source:  add,
-		Comment: fmt.Sprintf("create %q table", add.T.Name),
-		Reverse: s.Build("DROP TABLE").Table(add.T).String(),
+		Cmd:      b.String(),
+		Source:   add,
+		Comment:  fmt.Sprintf("create %q table", add.T.Name),
+		Reverse:  s.Build("DROP TABLE").Table(add.T).String(),
+		Warnings: warnings,
 	})
 	if err := s.addIndexes(add.T, add.T.Indexes...); err != nil {
 		return err
 	}
+	if p := (PartitionBounds{}); sqlx.Has(add.T.Attrs, &p) {
+		for _, b := range p.Parts {
+			s.append(s.createPartition(add.T, add, b))
+		}
+	}
 	s.addComments(add.T)
+	if o := (TableOwner{}); sqlx.Has(add.T.Attrs, &o) {
+		c := s.tableOwner(add.T, o.V)
+		// Tables are owned by the role that created them.
+		c.Source, c.Reverse = add, s.Build("ALTER TABLE").Table(add.T).P("OWNER TO CURRENT_USER").String()
+		s.append(c)
+	}
 	return nil
 }
 
 // dropTable builds and executes the query for dropping a table from a schema.
-func (s *state) dropTable(drop *schema.DropTable) {
+func (s *state) dropTable(ctx context.Context, drop *schema.DropTable) error {
 	b := s.Build("DROP TABLE")
 	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
 		b.P("IF EXISTS")
 	}
 	b.Table(drop.T)
-	s.append(&migrate.Change{
+	// Tables are dropped restricted, unless cascading was requested explicitly.
+	bh := s.dropBehavior(drop.Extra)
+	if bh == migrate.DropDefault {
+		bh = migrate.DropRestrict
+	}
+	dropClause(b, bh)
+	c := &migrate.Change{
 		Cmd:     b.String(),
 		Source:  drop,
 		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
-	})
+	}
+	// Restricted drops fail in case other objects depend on the
+	// dropped table. Therefore, these are reported as warnings.
+	if bh == migrate.DropRestrict && s.baseline == nil && s.ExecQuerier != nil && !s.crdb {
+		deps, err := s.dependents(ctx, drop.T)
+		if err != nil {
+			return err
+		}
+		if len(deps) > 0 {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("the drop fails unless the objects that depend on table %q are dropped first: %s", drop.T.Name, strings.Join(deps, ", ")))
+		}
+	}
+	if s.SafetyWarnings {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("drops table %q along with all of its rows", drop.T.Name))
+	}
+	s.append(c)
+	return nil
+}
+
+// dependentsQuery lists the objects that depend on a table, excluding
+// its own objects, such as its indexes, constraints and owned sequences.
+const dependentsQuery = `
+SELECT DISTINCT
+	pg_catalog.pg_describe_object(d.classid, d.objid, 0) AS object
+FROM pg_catalog.pg_depend d
+	JOIN pg_catalog.pg_class c ON c.oid = d.refobjid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE
+	d.refclassid = 'pg_catalog.pg_class'::regclass
+	AND d.deptype = 'n'
+	AND n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+	AND c.relname = $2
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_constraint k WHERE d.classid = 'pg_catalog.pg_constraint'::regclass AND k.oid = d.objid AND k.conrelid = c.oid)
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_rewrite r WHERE d.classid = 'pg_catalog.pg_rewrite'::regclass AND r.oid = d.objid AND r.ev_class = c.oid)
+ORDER BY
+	object
+`
+
+// dependents returns the descriptions of the objects that depend on the given table.
+func (s *state) dependents(ctx context.Context, t *schema.Table) ([]string, error) {
+	var ns string
+	switch {
+	case s.SchemaQualifier != nil:
+		ns = *s.SchemaQualifier
+	case t.Schema != nil:
+		ns = t.Schema.Name
+	}
+	rows, err := s.QueryContext(ctx, dependentsQuery, ns, t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying dependents of table %q: %w", t.Name, err)
+	}
+	deps, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: scanning dependents of table %q: %w", t.Name, err)
+	}
+	return deps, nil
+}
+
+// dropBehavior returns the drop behavior of a change. The Cascade and
+// Restrict clauses of the change override the one set by the plan options.
+func (s *state) dropBehavior(extra []schema.Clause) migrate.DropBehavior {
+	switch {
+	case sqlx.Has(extra, &Cascade{}):
+		return migrate.DropCascade
+	case sqlx.Has(extra, &Restrict{}):
+		return migrate.DropRestrict
+	default:
+		return s.DropBehavior
+	}
+}
+
+// dropClause appends the clause of the drop behavior, if it was set explicitly.
+func dropClause(b *sqlx.Builder, bh migrate.DropBehavior) {
+	switch bh {
+	case migrate.DropCascade:
+		b.P("CASCADE")
+	case migrate.DropRestrict:
+		b.P("RESTRICT")
+	}
 }
 
 // modifyTable builds the statements that bring the table into its modified state.
@@ -213,26 +1805,90 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 		alter       []schema.Change
 		addI, dropI []*schema.Index
 		changes     []*migrate.Change
+		reindex     []*migrate.Change
+		notes       []string
+		warnings    []string
+		backfill    []*schema.ModifyColumn
+		promote     []*schema.Index
 	)
 	for _, change := range skipAutoChanges(modify.Changes) {
 		switch change := change.(type) {
-		case *schema.AddAttr, *schema.ModifyAttr:
+		case *schema.AddAttr, *schema.ModifyAttr, *schema.DropAttr:
+			// Custom attribute changes are applied by their statements,
+			// and changes without statements are skipped (reported only).
+			if stmt, ok := sqlx.AttrStmt(change); ok {
+				if stmt != "" {
+					changes = append(changes, &migrate.Change{
+						Cmd:     stmt,
+						Source:  modify,
+						Comment: fmt.Sprintf("modify %q table attribute", modify.T.Name),
+					})
+				}
+				continue
+			}
+			if from, to, ok := storageParams(change); ok {
+				if c := s.alterStorageParams(modify.T, change, from, to); c != nil {
+					changes = append(changes, c)
+				}
+				continue
+			}
+			if from, to, ok := partitionBounds(change); ok {
+				changes = append(changes, s.alterPartitions(modify.T, change, from, to)...)
+				continue
+			}
+			if from, to, ok := accessMethod(change); ok {
+				if !s.supportsSetAccessMethod() {
+					return fmt.Errorf("changing the access method of table %q requires PostgreSQL 15 or above", modify.T.Name)
+				}
+				b := s.Build("ALTER TABLE").Table(modify.T).P("SET ACCESS METHOD")
+				c := &migrate.Change{
+					Cmd:      b.Clone().Ident(to.V).String(),
+					Source:   modify,
+					Comment:  fmt.Sprintf("set the access method of table %q to %q", modify.T.Name, to.V),
+					Reverse:  b.Clone().Ident(from.V).String(),
+					Warnings: []string{"changing the access method rewrites the table"},
+				}
+				if s.CostEstimate && s.baseline == nil {
+					if err := s.annotateCost(ctx, modify.T, []*migrate.Change{c}); err != nil {
+						return err
+					}
+				}
+				changes = append(changes, c)
+				continue
+			}
+			if from, to, ok := tableOwner(change); ok {
+				c := s.tableOwner(modify.T, to.V)
+				// The previous owner is unknown in case
+				// it was not inspected (or defined).
+				if from != nil {
+					c.Reverse = s.tableOwner(modify.T, from.V).Cmd
+				}
+				changes = append(changes, c)
+				continue
+			}
+			if _, ok := change.(*schema.DropAttr); ok {
+				return fmt.Errorf("unsupported change type: %T", change)
+			}
 			from, to, err := commentChange(change)
 			if err != nil {
 				return err
 			}
 			changes = append(changes, s.tableComment(modify.T, to, from))
-		case *schema.DropAttr:
-			return fmt.Errorf("unsupported change type: %T", change)
 		case *schema.AddIndex:
 			if c := (schema.Comment{}); sqlx.Has(change.I.Attrs, &c) {
 				changes = append(changes, s.indexComment(modify.T, change.I, c.Text, ""))
 			}
+			// UNIQUE constraints that were requested to be built concurrently are
+			// created as unique indexes first, and then promoted to constraints.
+			if isUniqueConstraint(change.I) && concurrently(modify.T, change.I) {
+				promote = append(promote, change.I)
+			}
 			addI = append(addI, change.I)
 		case *schema.DropIndex:
 			// Unlike DROP INDEX statements that are executed separately,
 			// DROP CONSTRAINT are added to the ALTER TABLE statement below.
-			if isUniqueConstraint(change.I) {
+			// Indexes that back a constraint cannot be dropped directly.
+			if _, ok := indexConstraint(change.I); ok {
 				alter = append(alter, change)
 			} else {
 				dropI = append(dropI, change.I)
@@ -250,7 +1906,22 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					continue
 				}
 			}
-			// Index modification requires rebuilding the index.
+			// GIN storage parameters can be changed without rebuilding the index.
+			if k == schema.ChangeAttr && ginParamsChanged(change.From, change.To) {
+				changes = append(changes, s.alterIndexParams(modify.T, change))
+				continue
+			}
+			// Index modification requires rebuilding the index. Indexes that
+			// back a constraint are rebuilt by dropping their constraint.
+			if _, ok := indexConstraint(change.From); ok {
+				alter = append(alter, &schema.DropIndex{I: change.From})
+				if isUniqueConstraint(change.To) {
+					alter = append(alter, &schema.AddIndex{I: change.To})
+				} else {
+					addI = append(addI, change.To)
+				}
+				continue
+			}
 			addI = append(addI, change.To)
 			dropI = append(dropI, change.From)
 		case *schema.RenameIndex:
@@ -261,6 +1932,11 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 				Reverse: s.Build("ALTER INDEX").Ident(change.To.Name).P("RENAME TO").Ident(change.From.Name).String(),
 			})
 		case *schema.ModifyForeignKey:
+			// The deferrability of a foreign key can be altered in place.
+			if change.Change == schema.ChangeAttr && change.From.Symbol == change.To.Symbol {
+				alter = append(alter, change)
+				continue
+			}
 			// Foreign-key modification is translated into 2 steps.
 			// Dropping the current foreign key and creating a new one.
 			alter = append(alter, &schema.DropForeignKey{
@@ -275,6 +1951,19 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) {
 				changes = append(changes, s.columnComment(modify.T, change.C, c.Text, ""))
 			}
+			if volatileDefault(change.C) {
+				// Unlike constant defaults, which are stored in the catalog (PostgreSQL 11+),
+				// volatile defaults are computed for each existing row, and rewrite the table.
+				if s.BackfillDefaults {
+					nc := *change.C
+					nc.Default, nc.Type = nil, &schema.ColumnType{Type: change.C.Type.Type, Raw: change.C.Type.Raw, Null: true}
+					alter = append(alter, &schema.AddColumn{C: &nc, Extra: change.Extra})
+					backfill = append(backfill, &schema.ModifyColumn{From: &nc, To: change.C})
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("adding column %q with a volatile default rewrites the table. "+
+					"To avoid it, add the column without a default, backfill the existing rows in batches, and then set its default and NOT NULL", change.C.Name))
+			}
 			alter = append(alter, change)
 		case *schema.ModifyColumn:
 			k := change.Change
@@ -289,6 +1978,42 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					continue
 				}
 			}
+			// The generation expression of a stored column cannot be altered in place.
+			// Hence, the column is dropped and added with its new expression, along
+			// with the indexes and foreign keys that are dropped with it.
+			if k.Is(schema.ChangeGenerated) && sqlx.Has(change.To.Attrs, &schema.GeneratedExpr{}) {
+				if err := s.mayAddEnums(ctx, modify.T, change.To); err != nil {
+					return err
+				}
+				notes = append(notes, fmt.Sprintf("the values of column %q are recomputed using its new generation expression", change.To.Name))
+				idx, fks := columnDependents(modify, change.To)
+				for _, i := range idx {
+					switch _, ok := indexConstraint(i); {
+					case !ok:
+						dropI, addI = append(dropI, i), append(addI, i)
+					case !isUniqueConstraint(i):
+						alter, addI = append(alter, &schema.DropIndex{I: i}), append(addI, i)
+					default:
+						alter = append(alter, &schema.DropIndex{I: i})
+					}
+				}
+				for _, fk := range fks {
+					alter = append(alter, &schema.DropForeignKey{F: fk})
+				}
+				alter = append(alter, &schema.DropColumn{C: change.From}, &schema.AddColumn{C: change.To})
+				for _, i := range idx {
+					if _, ok := indexConstraint(i); ok && isUniqueConstraint(i) {
+						alter = append(alter, &schema.AddIndex{I: i})
+					}
+				}
+				for _, fk := range fks {
+					alter = append(alter, &schema.AddForeignKey{F: fk})
+				}
+				if refs := columnReferences(modify.T, change.To); len(refs) > 0 {
+					warnings = append(warnings, fmt.Sprintf("the foreign keys %s that reference column %q must be dropped first", strings.Join(refs, ", "), change.To.Name))
+				}
+				continue
+			}
 			from, ok1 := hasEnumType(change.From)
 			to, ok2 := hasEnumType(change.To)
 			switch {
@@ -309,32 +2034,343 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					return err
 				}
 			}
-			alter = append(alter, &schema.ModifyColumn{To: change.To, From: change.From, Change: k})
-		case *schema.RenameColumn:
-			// "RENAME COLUMN" cannot be combined with other alterations.
-			b := s.Build("ALTER TABLE").Table(modify.T).P("RENAME COLUMN")
-			r := b.Clone()
-			changes = append(changes, &migrate.Change{
-				Source:  change,
-				Comment: fmt.Sprintf("rename a column from %q to %q", change.From.Name, change.To.Name),
-				Cmd:     b.Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
-				Reverse: r.Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
-			})
-		default:
-			alter = append(alter, change)
+			alter = append(alter, &schema.ModifyColumn{To: change.To, From: change.From, Change: k})
+			// Indexes that include a column whose collation was
+			// changed are rebuilt, as their sort order may differ.
+			if k.Is(schema.ChangeCollate) {
+				idx, _ := columnDependents(modify, change.To)
+				for _, i := range idx {
+					reindex = append(reindex, s.reindex(modify.T, change.To, i))
+				}
+			}
+		case *schema.RenameColumn:
+			// "RENAME COLUMN" cannot be combined with other alterations.
+			b := s.Build("ALTER TABLE").Table(modify.T).P("RENAME COLUMN")
+			r := b.Clone()
+			changes = append(changes, &migrate.Change{
+				Source:  change,
+				Comment: fmt.Sprintf("rename a column from %q to %q", change.From.Name, change.To.Name),
+				Cmd:     b.Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Reverse: r.Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+			})
+		case *schema.RenameCheck:
+			b := s.Build("ALTER TABLE").Table(modify.T).P("RENAME CONSTRAINT")
+			r := b.Clone()
+			changes = append(changes, &migrate.Change{
+				Source:  change,
+				Comment: fmt.Sprintf("rename a constraint from %q to %q", change.From.Name, change.To.Name),
+				Cmd:     b.Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Reverse: r.Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+			})
+		default:
+			alter = append(alter, change)
+		}
+	}
+	s.dropIndexes(modify.T, dropI...)
+	// Constraints that are added idempotently are planned in their own statements,
+	// as the DO blocks that wrap them would skip the other actions as well.
+	var addC []schema.Change
+	if s.IdempotentDDL {
+		for i := 0; i < len(alter); i++ {
+			if addsConstraint(alter[i]) {
+				addC = append(addC, alter[i])
+				alter = append(alter[:i], alter[i+1:]...)
+				i--
+			}
+		}
+	}
+	if len(alter) > 0 {
+		n := len(s.Changes)
+		if err := s.alterTable(modify.T, alter); err != nil {
+			return err
+		}
+		for _, note := range notes {
+			s.Changes[n].Comment += ". " + note
+		}
+		s.Changes[n].Warnings = append(s.Changes[n].Warnings, warnings...)
+		if s.CostEstimate && s.baseline == nil && rewritesTable(alter) {
+			if err := s.annotateCost(ctx, modify.T, s.Changes[n:]); err != nil {
+				return err
+			}
+		}
+		s.append(reindex...)
+	}
+	for _, c := range addC {
+		if err := s.alterTable(modify.T, []schema.Change{c}); err != nil {
+			return err
+		}
+	}
+	if err := s.backfillColumns(modify.T, backfill); err != nil {
+		return err
+	}
+	// Indexes on existing partitioned tables that were requested to
+	// be built concurrently are created and attached per partition.
+	if sqlx.Has(modify.T.Attrs, &Partition{}) {
+		var concurrentI []*schema.Index
+		for i := 0; i < len(addI); i++ {
+			if sqlx.Has(addI[i].Attrs, &Concurrently{}) {
+				concurrentI = append(concurrentI, addI[i])
+				addI = append(addI[:i], addI[i+1:]...)
+				i--
+			}
+		}
+		if err := s.addPartitionedIndexes(ctx, modify.T, concurrentI...); err != nil {
+			return err
+		}
+	}
+	for _, idx := range addI {
+		c, err := s.addIndex(modify.T, idx)
+		if err != nil {
+			return err
+		}
+		if s.UniquePreflight {
+			if q, ok := s.duplicatesQuery(modify.T, idx); ok {
+				c.Warnings = append(c.Warnings, fmt.Sprintf("check for duplicates before applying: %s", q))
+			}
+		}
+		s.append(c)
+	}
+	s.promoteIndexes(modify.T, promote...)
+	s.append(changes...)
+	return nil
+}
+
+// backfillColumns plans the steps that follow the addition of columns with
+// volatile defaults without them: backfilling the existing rows, and then
+// setting the default values and NOT NULL constraints of the columns.
+func (s *state) backfillColumns(t *schema.Table, columns []*schema.ModifyColumn) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	alter := make([]schema.Change, 0, len(columns))
+	for _, c := range columns {
+		x := c.To.Default.(*schema.RawExpr)
+		s.append(&migrate.Change{
+			Cmd:     s.Build("UPDATE").Table(t).P("SET").Ident(c.To.Name).P("=", x.X, "WHERE").Ident(c.To.Name).P("IS NULL").String(),
+			Source:  c,
+			Comment: fmt.Sprintf("backfill column %q of table %q. On large tables, consider running it in batches", c.To.Name, t.Name),
+		})
+		c.Change = schema.ChangeDefault
+		if !c.To.Type.Null {
+			c.Change |= schema.ChangeNull
+		}
+		alter = append(alter, c)
+	}
+	return s.alterTable(t, alter)
+}
+
+// volatileDefault reports if the default value of the column is
+// a call to a volatile function, which is evaluated for each row.
+func volatileDefault(c *schema.Column) bool {
+	if _, ok := c.Type.Type.(*SerialType); ok {
+		return false
+	}
+	x, ok := c.Default.(*schema.RawExpr)
+	return ok && reVolatile.MatchString(x.X)
+}
+
+// reVolatile matches calls to builtin volatile functions.
+var reVolatile = regexp.MustCompile(`(?i)\b(gen_random_uuid|uuid_generate_v1|uuid_generate_v1mc|uuid_generate_v4|random|clock_timestamp|timeofday|nextval|txid_current)\s*\(`)
+
+// reindex returns the maintenance step for rebuilding an index
+// after the collation of one of its columns was changed.
+func (s *state) reindex(t *schema.Table, c *schema.Column, idx *schema.Index) *migrate.Change {
+	b := s.Build("REINDEX INDEX")
+	b.WriteString(s.schemaPrefix(t.Schema))
+	cmd := b.Ident(idx.Name).String()
+	return &migrate.Change{
+		Cmd:     cmd,
+		Comment: fmt.Sprintf("rebuild index %q after changing the collation of column %q", idx.Name, c.Name),
+		// Reverting the collation requires rebuilding the index as well.
+		Reverse: cmd,
+	}
+}
+
+// columnDependents returns the indexes and foreign keys of the table that
+// include the given column, and are not changed by the table modification.
+func columnDependents(modify *schema.ModifyTable, c *schema.Column) ([]*schema.Index, []*schema.ForeignKey) {
+	var (
+		idx []*schema.Index
+		fks []*schema.ForeignKey
+	)
+	changed := func(f func(schema.Change) bool) bool {
+		for _, ch := range modify.Changes {
+			if f(ch) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, i := range modify.T.Indexes {
+		for _, p := range i.Parts {
+			if p.C == nil || p.C.Name != c.Name {
+				continue
+			}
+			if !changed(func(ch schema.Change) bool {
+				switch ch := ch.(type) {
+				case *schema.AddIndex:
+					return ch.I.Name == i.Name
+				case *schema.DropIndex:
+					return ch.I.Name == i.Name
+				case *schema.ModifyIndex:
+					return ch.To.Name == i.Name
+				case *schema.RenameIndex:
+					return ch.To.Name == i.Name
+				}
+				return false
+			}) {
+				idx = append(idx, i)
+			}
+			break
+		}
+	}
+	for _, fk := range modify.T.ForeignKeys {
+		for _, fc := range fk.Columns {
+			if fc.Name != c.Name {
+				continue
+			}
+			if !changed(func(ch schema.Change) bool {
+				switch ch := ch.(type) {
+				case *schema.AddForeignKey:
+					return ch.F.Symbol == fk.Symbol
+				case *schema.DropForeignKey:
+					return ch.F.Symbol == fk.Symbol
+				case *schema.ModifyForeignKey:
+					return ch.To.Symbol == fk.Symbol
+				}
+				return false
+			}) {
+				fks = append(fks, fk)
+			}
+			break
+		}
+	}
+	return idx, fks
+}
+
+// columnReferences returns the foreign keys of other tables in
+// the schema of table t that reference the given column.
+func columnReferences(t *schema.Table, c *schema.Column) []string {
+	if t.Schema == nil {
+		return nil
+	}
+	var refs []string
+	for _, t2 := range t.Schema.Tables {
+		if t2 == t {
+			continue
+		}
+		for _, fk := range t2.ForeignKeys {
+			if fk.RefTable == nil || fk.RefTable.Name != t.Name {
+				continue
+			}
+			for _, rc := range fk.RefColumns {
+				if rc.Name == c.Name {
+					refs = append(refs, strconv.Quote(fk.Symbol))
+					break
+				}
+			}
 		}
 	}
-	s.dropIndexes(modify.T, dropI...)
-	if len(alter) > 0 {
-		if err := s.alterTable(modify.T, alter); err != nil {
-			return err
+	return refs
+}
+
+// rewritesTable reports if the given ALTER TABLE changes
+// may cause the table (and its children) to be rewritten.
+func rewritesTable(changes []schema.Change) bool {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangeType) && RequiresRewrite(c.From, c.To) {
+				return true
+			}
+		case *schema.AddColumn:
+			if sqlx.Has(c.C.Attrs, &schema.GeneratedExpr{}) || volatileDefault(c.C) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableSizeQuery returns the estimated number of rows and the total size in
+// bytes of a table. The size of partitioned (or inherited) tables aggregates
+// their children, as they are rewritten along with their parent.
+const tableSizeQuery = `
+WITH RECURSIVE tree AS (
+	SELECT c.oid FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2
+	UNION ALL
+	SELECT i.inhrelid FROM pg_catalog.pg_inherits i JOIN tree ON i.inhparent = tree.oid
+)
+SELECT
+	COALESCE(SUM(GREATEST(c.reltuples, 0)), 0)::bigint AS rows,
+	COALESCE(SUM(pg_catalog.pg_total_relation_size(c.oid)), 0)::bigint AS bytes
+FROM tree JOIN pg_catalog.pg_class c ON c.oid = tree.oid
+HAVING COUNT(*) > 0
+`
+
+// annotateCost queries the size of the given table and attaches it to the
+// comment of its main ALTER TABLE change. Tables that do not exist in the
+// database (e.g. created by the plan itself) are not annotated.
+func (s *state) annotateCost(ctx context.Context, t *schema.Table, changes []*migrate.Change) error {
+	var ns string
+	switch {
+	case s.SchemaQualifier != nil:
+		ns = *s.SchemaQualifier
+	case t.Schema != nil:
+		ns = t.Schema.Name
+	}
+	rows, err := s.QueryContext(ctx, tableSizeQuery, ns, t.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying table %q size: %w", t.Name, err)
+	}
+	var n, size int64
+	if err := sqlx.ScanOne(rows, &n, &size); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("postgres: scanning table %q size: %w", t.Name, err)
+	}
+	for _, c := range changes {
+		if m, ok := c.Source.(*schema.ModifyTable); ok && m.T == t {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("table %q has about %d rows (%d bytes)", t.Name, n, size))
+			break
 		}
 	}
-	s.addIndexes(modify.T, addI...)
-	s.append(changes...)
 	return nil
 }
 
+// duplicatesQuery returns a query that lists the duplicate values (if any)
+// that prevent the creation of the given unique index on an existing table.
+// Rows with NULL values are excluded, as they are never considered equal by the
+// index, and for partial indexes, the predicate is included to check the indexed
+// rows only.
+func (s *state) duplicatesQuery(t *schema.Table, idx *schema.Index) (string, bool) {
+	if !idx.Unique || len(idx.Parts) == 0 {
+		return "", false
+	}
+	parts := make([]string, len(idx.Parts))
+	for i, p := range idx.Parts {
+		switch {
+		case p.C != nil:
+			parts[i] = s.Build().Ident(p.C.Name).String()
+		case p.X != nil:
+			parts[i] = sqlx.MayWrap(p.X.(*schema.RawExpr).X)
+		}
+	}
+	where := make([]string, 0, len(parts)+1)
+	for _, p := range parts {
+		where = append(where, p+" IS NOT NULL")
+	}
+	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) {
+		where = append(where, sqlx.MayWrap(p.P))
+	}
+	b := s.Build("SELECT").P(strings.Join(parts, ", ")+",", "count(*)", "FROM").Table(t).
+		P("WHERE", strings.Join(where, " AND ")).
+		P("GROUP BY", strings.Join(parts, ", "), "HAVING count(*) > 1")
+	return b.String(), true
+}
+
 // alterTable modifies the given table by executing on it a list of changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
@@ -347,15 +2383,22 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			switch change := changes[i].(type) {
 			case *schema.AddColumn:
 				b.P("ADD COLUMN")
+				drop := &schema.DropColumn{C: change.C}
+				if s.ColumnGuards || sqlx.Has(change.Extra, &schema.IfNotExists{}) {
+					b.P("IF NOT EXISTS")
+					drop.Extra = append(drop.Extra, &schema.IfExists{})
+				}
 				if err := s.column(b, t, change.C); err != nil {
 					return err
 				}
-				reverse = append(reverse, &schema.DropColumn{C: change.C})
+				reverse = append(reverse, drop)
 			case *schema.ModifyColumn:
 				if err := s.alterColumn(b, alter, t, change); err != nil {
 					return err
 				}
-				if change.Change.Is(schema.ChangeGenerated) {
+				// Converting serials to identities and vice versa carries the
+				// sequence values over, which cannot be reverted by a single statement.
+				if change.Change.Is(schema.ChangeGenerated) || serialIdentityChanged(change) {
 					reversible = false
 				}
 				reverse = append(reverse, &schema.ModifyColumn{
@@ -372,8 +2415,15 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 					}
 				}
 			case *schema.DropColumn:
-				b.P("DROP COLUMN").Ident(change.C.Name)
-				reverse = append(reverse, &schema.AddColumn{C: change.C})
+				b.P("DROP COLUMN")
+				add := &schema.AddColumn{C: change.C}
+				if s.ColumnGuards || sqlx.Has(change.Extra, &schema.IfExists{}) {
+					b.P("IF EXISTS")
+					add.Extra = append(add.Extra, &schema.IfNotExists{})
+				}
+				b.Ident(change.C.Name)
+				dropClause(b, s.dropBehavior(change.Extra))
+				reverse = append(reverse, add)
 				if e, ok := hasEnumType(change.C); ok {
 					if err := s.mayDropEnum(alter, t.Schema, e); err != nil {
 						return err
@@ -381,23 +2431,48 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 				}
 			case *schema.AddIndex:
 				b.P("ADD CONSTRAINT").Ident(change.I.Name).P("UNIQUE")
+				if !nullsDistinct(change.I.Attrs) {
+					b.P("NULLS NOT DISTINCT")
+				}
 				if err := s.indexParts(b, change.I); err != nil {
 					return err
 				}
-				// Skip reversing this operation as it is the inverse of
-				// the operation below and should not be used besides this.
+				// UNIQUE constraints are added either as the inverse of the operation
+				// below, or when a constraint is rebuilt (see modifyTable).
+				reverse = append(reverse, &schema.DropIndex{I: change.I})
 			case *schema.DropIndex:
-				b.P("DROP CONSTRAINT").Ident(change.I.Name)
-				reverse = append(reverse, &schema.AddIndex{I: change.I})
+				name := change.I.Name
+				if c, ok := indexConstraint(change.I); ok && c.N != "" {
+					name = c.N
+				}
+				b.P("DROP CONSTRAINT").Ident(name)
+				// Only UNIQUE constraints can be recreated from their index.
+				if reversible = reversible && isUniqueConstraint(change.I); reversible {
+					reverse = append(reverse, &schema.AddIndex{I: change.I})
+				}
 			case *schema.AddForeignKey:
 				b.P("ADD")
 				s.fks(b, change.F)
+				if sqlx.Has(change.Extra, &NotValid{}) {
+					b.P("NOT VALID")
+				}
 				reverse = append(reverse, &schema.DropForeignKey{F: change.F})
 			case *schema.DropForeignKey:
 				b.P("DROP CONSTRAINT").Ident(change.F.Symbol)
 				reverse = append(reverse, &schema.AddForeignKey{F: change.F})
+			case *schema.ModifyForeignKey:
+				b.P("ALTER CONSTRAINT").Ident(change.To.Symbol)
+				deferrable(b, change.To.Attrs)
+				reverse = append(reverse, &schema.ModifyForeignKey{
+					From:   change.To,
+					To:     change.From,
+					Change: change.Change,
+				})
 			case *schema.AddCheck:
 				check(b.P("ADD"), change.C)
+				if sqlx.Has(change.Extra, &NotValid{}) {
+					b.P("NOT VALID")
+				}
 				// Reverse operation is supported if
 				// the constraint name is not generated.
 				if reversible = reversible && change.C.Name != ""; reversible {
@@ -437,6 +2512,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	if err != nil {
 		return fmt.Errorf("alter table %q: %v", t.Name, err)
 	}
+	s.validateConstraints(t, changes)
 	cmd.main = &migrate.Change{
 		Cmd: stmt,
 		Source: &schema.ModifyTable{
@@ -445,6 +2521,16 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		},
 		Comment: fmt.Sprintf("modify %q table", t.Name),
 	}
+	if w := identityAlwaysWarning(changes); w != "" {
+		cmd.main.Warnings = append(cmd.main.Warnings, w)
+	}
+	if w := setDefaultWarning(alteredForeignKeys(t, changes)); w != "" {
+		cmd.main.Warnings = append(cmd.main.Warnings, w)
+	}
+	cmd.main.Warnings = append(cmd.main.Warnings, typeRewriteWarnings(changes)...)
+	if s.SafetyWarnings {
+		cmd.main.Warnings = append(cmd.main.Warnings, safetyWarnings(changes)...)
+	}
 	if reversible {
 		// Changes should be reverted in
 		// a reversed order they were created.
@@ -457,6 +2543,174 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	return nil
 }
 
+// validateConstraints collects the VALIDATE CONSTRAINT statements for constraints
+// that were added as NOT VALID and requested to be validated. These statements are
+// appended at the end of the plan, after all structural changes were applied, as
+// validation scans the table but does not block concurrent reads and writes.
+func (s *state) validateConstraints(t *schema.Table, changes []schema.Change) {
+	for _, c := range changes {
+		var (
+			name string
+			nv   NotValid
+		)
+		switch c := c.(type) {
+		case *schema.AddForeignKey:
+			if sqlx.Has(c.Extra, &nv) {
+				name = c.F.Symbol
+			}
+		case *schema.AddCheck:
+			if sqlx.Has(c.Extra, &nv) {
+				name = c.C.Name
+			}
+		}
+		if name == "" || !nv.Validate {
+			continue
+		}
+		s.validate = append(s.validate, &migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("VALIDATE CONSTRAINT").Ident(name).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("validate %q constraint of table %q", name, t.Name),
+		})
+	}
+}
+
+// identityAlwaysWarning returns a warning in case one of the given changes switches
+// an identity column to GENERATED ALWAYS. Unlike BY DEFAULT, such columns reject
+// explicit values on INSERT, unless OVERRIDING SYSTEM VALUE is specified.
+func identityAlwaysWarning(changes []schema.Change) string {
+	var names []string
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyColumn)
+		if !ok || !m.Change.Is(schema.ChangeAttr) {
+			continue
+		}
+		fromI, ok1 := identity(m.From.Attrs)
+		toI, ok2 := identity(m.To.Attrs)
+		if ok1 && ok2 && strings.ToUpper(fromI.Generation) != GeneratedTypeAlways && strings.ToUpper(toI.Generation) == GeneratedTypeAlways {
+			names = append(names, strconv.Quote(m.To.Name))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("identity column(s) %s switched to GENERATED ALWAYS, INSERT statements with explicit values will fail unless OVERRIDING SYSTEM VALUE is used", strings.Join(names, ", "))
+}
+
+// typeRewriteWarnings returns the warnings for the column type changes, describing
+// if changing the type of the column(s) rewrites the table. See RequiresRewrite.
+func typeRewriteWarnings(changes []schema.Change) []string {
+	var rewrite, inplace []string
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyColumn)
+		if !ok || !m.Change.Is(schema.ChangeType) {
+			continue
+		}
+		if RequiresRewrite(m.From, m.To) {
+			rewrite = append(rewrite, strconv.Quote(m.To.Name))
+		} else {
+			inplace = append(inplace, strconv.Quote(m.To.Name))
+		}
+	}
+	var warns []string
+	if len(rewrite) > 0 {
+		warns = append(warns, fmt.Sprintf("changing the type of column(s) %s rewrites the table", strings.Join(rewrite, ", ")))
+	}
+	if len(inplace) > 0 {
+		warns = append(warns, fmt.Sprintf("changing the type of column(s) %s does not rewrite the table", strings.Join(inplace, ", ")))
+	}
+	return warns
+}
+
+// safetyWarnings returns the warnings describing why the given table changes are
+// unsafe to apply on a populated table. i.e. they remove data, or may fail (or lose
+// data) on existing rows. See ClassifyColumnChange for the risk of column changes.
+func safetyWarnings(changes []schema.Change) []string {
+	var warns []string
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			if !c.C.Type.Null && c.C.Default == nil && !autoValue(c.C) {
+				warns = append(warns, fmt.Sprintf("adds NOT NULL column %q without a default value, fails if the table has rows", c.C.Name))
+			}
+		case *schema.DropColumn:
+			warns = append(warns, fmt.Sprintf("drops column %q along with its data", c.C.Name))
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangeNull) && c.From.Type.Null && !c.To.Type.Null {
+				warns = append(warns, fmt.Sprintf("adds NOT NULL to column %q, fails if rows contain NULL values", c.To.Name))
+			}
+			if !c.Change.Is(schema.ChangeType) {
+				continue
+			}
+			switch typeChangeRisk(c.From, c.To) {
+			case RiskLossy:
+				warns = append(warns, fmt.Sprintf("changing the type of column %q may fail or lose precision on existing values", c.To.Name))
+			case RiskDestructive:
+				warns = append(warns, fmt.Sprintf("changing the type of column %q does not preserve existing values", c.To.Name))
+			}
+		}
+	}
+	return warns
+}
+
+// autoValue reports if the column values are generated by the database,
+// and therefore, adding it as NOT NULL does not fail on existing rows.
+func autoValue(c *schema.Column) bool {
+	if _, ok := c.Type.Type.(*SerialType); ok {
+		return true
+	}
+	return sqlx.Has(c.Attrs, &Identity{}) || sqlx.Has(c.Attrs, &schema.GeneratedExpr{})
+}
+
+// setDefaultWarning returns a warning in case one of the given foreign keys uses the SET DEFAULT
+// referential action, but one of its referencing columns has no default value. In this case,
+// the column is set to NULL, which fails on NOT NULL columns or may violate the constraint.
+func setDefaultWarning(fks []*schema.ForeignKey) string {
+	var names []string
+	for _, fk := range fks {
+		if fk.OnDelete != schema.SetDefault && fk.OnUpdate != schema.SetDefault {
+			continue
+		}
+		for _, c := range fk.Columns {
+			if c.Default == nil {
+				names = append(names, fmt.Sprintf("%q.%q", fk.Symbol, c.Name))
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("foreign key column(s) %s use SET DEFAULT but have no default value", strings.Join(names, ", "))
+}
+
+// alteredForeignKeys returns the foreign keys of the table that are added by the given
+// changes, or that their referencing columns' defaults are changed.
+func alteredForeignKeys(t *schema.Table, changes []schema.Change) []*schema.ForeignKey {
+	var (
+		fks  []*schema.ForeignKey
+		seen = make(map[*schema.ForeignKey]bool)
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddForeignKey:
+			if !seen[c.F] {
+				seen[c.F] = true
+				fks = append(fks, c.F)
+			}
+		case *schema.ModifyColumn:
+			if !c.Change.Is(schema.ChangeDefault) {
+				continue
+			}
+			for _, fk := range t.ForeignKeys {
+				if _, ok := fk.Column(c.To.Name); ok && !seen[fk] {
+					seen[fk] = true
+					fks = append(fks, fk)
+				}
+			}
+		}
+	}
+	return fks
+}
+
 // alterChange describes an alter table migrate.Change where its main command
 // can be supported by additional statements before and after it is executed.
 type alterChange struct {
@@ -474,11 +2728,41 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *alterChange, t *schema.Table
 	for k := c.Change; !k.Is(schema.NoChange); {
 		b.P("ALTER COLUMN").Ident(c.To.Name)
 		switch {
+		// Identity is dropped first, as PostgreSQL rejects setting a default
+		// value or dropping the NOT NULL constraint of identity columns.
+		case k.Is(schema.ChangeAttr) && identityDropped(c):
+			if err := s.alterColumnAttrs(b, c); err != nil {
+				return err
+			}
+			k &= ^schema.ChangeAttr
+			// Identity was replaced with a serial. The serial sequence is created after
+			// the identity sequence, which may share its name, was dropped.
+			if st, ok := serialColumn(c.To).Type.Type.(*SerialType); ok && k.Is(schema.ChangeType) {
+				same, err := s.identityToSerial(alter, t, c, st)
+				if err != nil {
+					return err
+				}
+				// The underlying type was not changed. e.g. integer to serial.
+				if same {
+					k &= ^schema.ChangeType
+				}
+			}
 		case k.Is(schema.ChangeType):
 			if err := s.alterType(b, alter, t, c); err != nil {
 				return err
 			}
-			k &= ^schema.ChangeType
+			// The collation is set along with the column type.
+			k &= ^(schema.ChangeType | schema.ChangeCollate)
+		case k.Is(schema.ChangeCollate):
+			f, err := s.formatType(t, c.To)
+			if err != nil {
+				return err
+			}
+			b.P("TYPE", f)
+			if v := collation(c.To.Attrs); v != "" {
+				b.P("COLLATE").Ident(v)
+			}
+			k &= ^schema.ChangeCollate
 		case k.Is(schema.ChangeNull) && c.To.Type.Null:
 			if t, ok := c.To.Type.Type.(*SerialType); ok {
 				return fmt.Errorf("NOT NULL constraint is required for %s column %q", t.T, c.To.Name)
@@ -486,32 +2770,32 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *alterChange, t *schema.Table
 			b.P("DROP NOT NULL")
 			k &= ^schema.ChangeNull
 		case k.Is(schema.ChangeNull) && !c.To.Type.Null:
+			if s.SafeNotNull && s.supportsNotNullCheck() {
+				s.notNullCheck(alter, t, c)
+			}
 			b.P("SET NOT NULL")
 			k &= ^schema.ChangeNull
-		case k.Is(schema.ChangeDefault) && c.To.Default == nil:
-			b.P("DROP DEFAULT")
-			k &= ^schema.ChangeDefault
-		case k.Is(schema.ChangeDefault) && c.To.Default != nil:
-			s.columnDefault(b.P("SET"), c.To)
+		case k.Is(schema.ChangeDefault):
+			// Removing a default is expressed with DROP DEFAULT,
+			// rather than with its equivalent, SET DEFAULT NULL.
+			if _, ok := defaultValue(c.To); ok {
+				s.columnDefault(b.P("SET"), c.To)
+			} else {
+				b.P("DROP DEFAULT")
+			}
 			k &= ^schema.ChangeDefault
 		case k.Is(schema.ChangeAttr):
-			toI, ok := identity(c.To.Attrs)
-			if !ok {
-				return fmt.Errorf("unexpected attribute change (expect IDENTITY): %v", c.To.Attrs)
-			}
-			// The syntax for altering identity columns is identical to sequence_options.
-			// https://www.postgresql.org/docs/current/sql-altersequence.html
-			b.P("SET GENERATED", toI.Generation, "SET START WITH", strconv.FormatInt(toI.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(toI.Sequence.Increment, 10))
-			// Skip SEQUENCE RESTART in case the "start value" is less than the "current value" in one
-			// of the states (inspected and desired), because this function is used for both UP and DOWN.
-			if fromI, ok := identity(c.From.Attrs); (!ok || fromI.Sequence.Last < toI.Sequence.Start) && toI.Sequence.Last < toI.Sequence.Start {
-				b.P("RESTART")
+			if err := s.alterColumnAttrs(b, c); err != nil {
+				return err
 			}
 			k &= ^schema.ChangeAttr
 		case k.Is(schema.ChangeGenerated):
 			if sqlx.Has(c.To.Attrs, &schema.GeneratedExpr{}) {
 				return fmt.Errorf("unexpected generation expression change (expect DROP EXPRESSION): %v", c.To.Attrs)
 			}
+			if !s.supportsDropExpression() {
+				return fmt.Errorf("dropping the generation expression of column %q requires PostgreSQL 13 or above", c.To.Name)
+			}
 			b.P("DROP EXPRESSION")
 			k &= ^schema.ChangeGenerated
 		default: // e.g. schema.ChangeComment.
@@ -524,25 +2808,191 @@ func (s *state) alterColumn(b *sqlx.Builder, alter *alterChange, t *schema.Table
 	return nil
 }
 
+// notNullCheck adds a validated CHECK (c IS NOT NULL) constraint to the table before
+// the column is set to NOT NULL, and drops it afterwards. Unlike SET NOT NULL, the
+// constraint is added without scanning the table, and its validation does not block
+// concurrent reads and writes. SET NOT NULL then skips the scan of the table, as the
+// validated constraint proves that the column contains no NULL values.
+func (s *state) notNullCheck(alter *alterChange, t *schema.Table, c *schema.ModifyColumn) {
+	var (
+		name = fmt.Sprintf("%s_%s_not_null", t.Name, c.To.Name)
+		add  = s.Build("ALTER TABLE").Table(t).P("ADD CONSTRAINT").Ident(name).
+			P("CHECK").Wrap(func(b *sqlx.Builder) { b.Ident(c.To.Name).P("IS NOT NULL") }).
+			P("NOT VALID").String()
+		drop = s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT IF EXISTS").Ident(name).String()
+	)
+	alter.before = append(alter.before,
+		&migrate.Change{
+			Source:  c,
+			Cmd:     add,
+			Reverse: drop,
+			Comment: fmt.Sprintf("add a NOT NULL check to column %q of table %q without scanning it", c.To.Name, t.Name),
+		},
+		&migrate.Change{
+			Source:  c,
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("VALIDATE CONSTRAINT").Ident(name).String(),
+			Reverse: drop,
+			Comment: fmt.Sprintf("validate %q constraint of table %q", name, t.Name),
+		},
+	)
+	alter.after = append(alter.after, &migrate.Change{
+		Source:  c,
+		Cmd:     s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(name).String(),
+		Reverse: add,
+		Comment: fmt.Sprintf("drop %q constraint of table %q, as it is enforced by NOT NULL", name, t.Name),
+	})
+}
+
+// identityToSerial appends the statements for attaching a serial sequence to a
+// column whose identity was dropped. The sequence continues from the largest value
+// of the column, and the returned bool reports if the underlying type is the same.
+func (s *state) identityToSerial(alter *alterChange, t *schema.Table, c *schema.ModifyColumn, st *SerialType) (bool, error) {
+	create, drop, seq := s.serialSequence(t, c.To, st)
+	alter.after = append(alter.after,
+		&migrate.Change{
+			Source:  c,
+			Comment: fmt.Sprintf("create sequence for serial column %q", c.To.Name),
+			Cmd:     create,
+			Reverse: drop,
+		},
+		&migrate.Change{
+			Source:  c,
+			Comment: fmt.Sprintf("continue the sequence of serial column %q from its largest value", c.To.Name),
+			Cmd:     s.Build("SELECT").P(fmt.Sprintf("setval('%s', max(%s))", seq, s.ident(c.To.Name)), "FROM").Table(t).String(),
+		},
+		&migrate.Change{
+			Source:  c,
+			Comment: fmt.Sprintf("set the sequence of serial column %q as its default value", c.To.Name),
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.To.Name).P("SET DEFAULT", fmt.Sprintf("nextval('%s')", seq)).String(),
+			Reverse: s.Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.To.Name).P("DROP DEFAULT").String(),
+		},
+	)
+	toT, err := FormatType(st.IntegerType())
+	if err != nil {
+		return false, err
+	}
+	fromT, err := FormatType(c.From.Type.Type)
+	if err != nil {
+		return false, err
+	}
+	return toT == fromT, nil
+}
+
+// serialIdentityChanged reports if a serial column was converted to an identity
+// column, or vice versa.
+func serialIdentityChanged(c *schema.ModifyColumn) bool {
+	_, fromS := serialColumn(c.From).Type.Type.(*SerialType)
+	_, toS := serialColumn(c.To).Type.Type.(*SerialType)
+	_, fromI := identity(c.From.Attrs)
+	_, toI := identity(c.To.Attrs)
+	return fromS && toI || fromI && toS
+}
+
+// identityDropped reports if the identity of the column was dropped.
+func identityDropped(c *schema.ModifyColumn) bool {
+	_, fromHas := identity(c.From.Attrs)
+	_, toHas := identity(c.To.Attrs)
+	return fromHas && !toHas
+}
+
+// alterColumnAttrs appends the clause(s) to alter the column identity and its
+// compression method, assuming the "ALTER COLUMN <Name>" was called before.
+func (s *state) alterColumnAttrs(b *sqlx.Builder, c *schema.ModifyColumn) error {
+	idChanged, cmChanged := identityChanged(c.From, c.To), compressionChanged(c.From.Attrs, c.To.Attrs)
+	_, fromHas := identity(c.From.Attrs)
+	toI, toHas := identity(c.To.Attrs)
+	switch {
+	case !idChanged:
+	// Identity was added to an existing column. PostgreSQL requires the column
+	// to be NOT NULL and without a default value, which are handled before.
+	case !fromHas:
+		b.P("ADD")
+		identityClause(b, c.To)
+	// Identity was dropped. The column stays NOT NULL, as it may be a member
+	// of the primary key, unless the desired state says otherwise.
+	case !toHas:
+		b.P("DROP IDENTITY")
+	default:
+		// The syntax for altering identity columns is identical to sequence_options.
+		// https://www.postgresql.org/docs/current/sql-altersequence.html
+		b.P("SET GENERATED", toI.Generation, "SET START WITH", strconv.FormatInt(toI.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(toI.Sequence.Increment, 10))
+		if boundsChanged(c.From, c.To) {
+			fromMin, fromMax := identityBounds(c.From)
+			toMin, toMax := identityBounds(c.To)
+			defMin, defMax := implicitBounds(c.To, toI.Sequence.Increment)
+			switch {
+			case fromMin == toMin:
+			case toMin == defMin:
+				b.P("SET NO MINVALUE")
+			default:
+				b.P("SET MINVALUE", strconv.FormatInt(toMin, 10))
+			}
+			switch {
+			case fromMax == toMax:
+			case toMax == defMax:
+				b.P("SET NO MAXVALUE")
+			default:
+				b.P("SET MAXVALUE", strconv.FormatInt(toMax, 10))
+			}
+		}
+		// Skip SEQUENCE RESTART in case the "start value" is less than the "current value" in one
+		// of the states (inspected and desired), because this function is used for both UP and DOWN.
+		if fromI, ok := identity(c.From.Attrs); (!ok || fromI.Sequence.Last < toI.Sequence.Start) && toI.Sequence.Last < toI.Sequence.Start {
+			b.P("RESTART")
+		}
+	}
+	if cmChanged {
+		if !s.supportsCompression() {
+			return fmt.Errorf("setting the compression method of column %q requires PostgreSQL 14 or above", c.To.Name)
+		}
+		if idChanged {
+			b.Comma().P("ALTER COLUMN").Ident(c.To.Name)
+		}
+		m := compression(c.To.Attrs)
+		if m == "" {
+			m = "DEFAULT"
+		}
+		b.P("SET COMPRESSION", m)
+	}
+	if !idChanged && !cmChanged {
+		return fmt.Errorf("unexpected attribute change (expect IDENTITY or COMPRESSION): %v", c.To.Attrs)
+	}
+	return nil
+}
+
+// serialSequence returns the commands for creating and dropping the sequence
+// of a serial column, and its qualified name.
+func (s *state) serialSequence(t *schema.Table, c *schema.Column, st *SerialType) (create, drop, seq string) {
+	seq = s.schemaPrefix(t.Schema) + s.ident(st.sequence(t, c))
+	drop = s.Build("DROP SEQUENCE IF EXISTS").P(seq).String()
+	create = s.Build("CREATE SEQUENCE IF NOT EXISTS").P(seq, "OWNED BY").
+		P(s.schemaPrefix(t.Schema) + s.ident(t.Name) + "." + s.ident(c.Name)).
+		String()
+	return create, drop, seq
+}
+
 // alterType appends the clause(s) to alter the column type and assuming the
 // "ALTER COLUMN <Name>" was called before by the alterColumn function.
 func (s *state) alterType(b *sqlx.Builder, alter *alterChange, t *schema.Table, c *schema.ModifyColumn) error {
-	// Commands for creating and dropping serial sequences.
-	createDropSeq := func(st *SerialType) (string, string, string) {
-		seq := fmt.Sprintf(`%s%q`, s.schemaPrefix(t.Schema), st.sequence(t, c.To))
-		drop := s.Build("DROP SEQUENCE IF EXISTS").P(seq).String()
-		create := s.Build("CREATE SEQUENCE IF NOT EXISTS").P(seq, "OWNED BY").
-			P(fmt.Sprintf(`%s%q.%q`, s.schemaPrefix(t.Schema), t.Name, c.To.Name)).
-			String()
-		return create, drop, seq
-	}
-	toS, toHas := c.To.Type.Type.(*SerialType)
-	fromS, fromHas := c.From.Type.Type.(*SerialType)
+	// Integer columns with sequence defaults are handled as serials.
+	toS, toHas := serialColumn(c.To).Type.Type.(*SerialType)
+	fromS, fromHas := serialColumn(c.From).Type.Type.(*SerialType)
 	switch {
 	// Sequence was dropped.
 	case fromHas && !toHas:
 		b.P("DROP DEFAULT")
-		create, drop, _ := createDropSeq(fromS)
+		create, drop, seq := s.serialSequence(t, c.To, fromS)
+		// Serial was replaced with an identity. The identity sequence continues from
+		// the state of the serial sequence, before the latter is dropped.
+		if _, ok := identity(c.To.Attrs); ok {
+			alter.after = append(alter.after, &migrate.Change{
+				Source:  c,
+				Comment: fmt.Sprintf("continue the identity of column %q from its serial sequence", c.To.Name),
+				Cmd: s.Build("SELECT").
+					P(fmt.Sprintf("setval(pg_get_serial_sequence('%s', '%s'), last_value, is_called)", s.Build().Table(t).String(), c.To.Name)).
+					P("FROM", seq).String(),
+			})
+		}
 		// Sequence should be deleted after it was dropped
 		// from the DEFAULT value.
 		alter.after = append(alter.after, &migrate.Change{
@@ -563,9 +3013,17 @@ func (s *state) alterType(b *sqlx.Builder, alter *alterChange, t *schema.Table,
 		if toT != fromT {
 			b.Comma().P("ALTER COLUMN").Ident(c.To.Name).P("TYPE", toT)
 		}
+	// Identity was replaced with a serial, whose sequence is
+	// attached by identityToSerial. e.g. integer to bigserial.
+	case !fromHas && toHas && identityDropped(c):
+		f, err := FormatType(toS.IntegerType())
+		if err != nil {
+			return err
+		}
+		b.P("TYPE", f)
 	// Sequence was added.
 	case !fromHas && toHas:
-		create, drop, seq := createDropSeq(toS)
+		create, drop, seq := s.serialSequence(t, c.To, toS)
 		// Sequence should be created before it is used by the
 		// column DEFAULT value.
 		alter.before = append(alter.before, &migrate.Change{
@@ -605,9 +3063,12 @@ func (s *state) alterType(b *sqlx.Builder, alter *alterChange, t *schema.Table,
 			return err
 		}
 		b.P("TYPE", f)
+		if castRequired(c.From.Type.Type, c.To.Type.Type) {
+			b.P("USING", fmt.Sprintf("%s::%s", s.ident(c.To.Name), f))
+		}
 	}
-	if collate := (schema.Collation{}); sqlx.Has(c.To.Attrs, &collate) {
-		b.P("COLLATE", collate.V)
+	if v := collation(c.To.Attrs); v != "" {
+		b.P("COLLATE").Ident(v)
 	}
 	return nil
 }
@@ -621,6 +3082,17 @@ func (s *state) renameTable(c *schema.RenameTable) {
 	})
 }
 
+// moveTable builds the statement for moving a table to another schema.
+// Sequences owned by the table columns (e.g. serial) are moved along with it.
+func (s *state) moveTable(c *schema.MoveTable) {
+	s.append(&migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("move table %q from schema %q to %q", c.From.Name, c.From.Schema.Name, c.To.Schema.Name),
+		Cmd:     s.Build("ALTER TABLE").Table(c.From).P("SET SCHEMA").Ident(c.To.Schema.Name).String(),
+		Reverse: s.Build("ALTER TABLE").Table(c.To).P("SET SCHEMA").Ident(c.From.Schema.Name).String(),
+	})
+}
+
 func (s *state) addComments(t *schema.Table) {
 	var c schema.Comment
 	if sqlx.Has(t.Attrs, &c) && c.Text != "" {
@@ -647,6 +3119,14 @@ func (s *state) tableComment(t *schema.Table, to, from string) *migrate.Change {
 	}
 }
 
+// tableOwner returns the ALTER TABLE statement for changing the owner of the table.
+func (s *state) tableOwner(t *schema.Table, owner string) *migrate.Change {
+	return &migrate.Change{
+		Cmd:     s.Build("ALTER TABLE").Table(t).P("OWNER TO").Ident(owner).String(),
+		Comment: fmt.Sprintf("set the owner of table %q to %q", t.Name, owner),
+	}
+}
+
 func (s *state) columnComment(t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
 	b := s.Build("COMMENT ON COLUMN").Table(t)
 	b.WriteByte('.')
@@ -739,15 +3219,68 @@ func (s *state) alterEnum(t *schema.Table, from, to *schema.EnumType) error {
 	return nil
 }
 
-func (s *state) enumExists(ctx context.Context, ns *schema.Schema, e *schema.EnumType) (bool, error) {
-	query, args := `SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'`, []any{e.T}
-	if es := s.enumSchema(ns, e); es != "" {
-		query += " AND n.nspname = $2"
-		args = append(args, es)
+func (s *state) enumExists(ctx context.Context, ns *schema.Schema, e *schema.EnumType) (bool, error) {
+	if s.baseline != nil {
+		return s.baselineEnum(s.enumSchema(ns, e), e.T), nil
+	}
+	query, args := `SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'`, []any{e.T}
+	if es := s.enumSchema(ns, e); es != "" {
+		query += " AND n.nspname = $2"
+		args = append(args, es)
+	}
+	rows, err := s.QueryContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("check enum existence: %w", err)
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// baselineEnum reports if an enum type with the given name and schema is used by
+// the baseline schemas. An empty schema name matches enums in all schemas.
+func (s *state) baselineEnum(ns, name string) bool {
+	for _, bs := range s.baselineSchemas() {
+		for _, t := range bs.Tables {
+			for _, c := range t.Columns {
+				e, ok := hasEnumType(c)
+				if !ok || e.T != name {
+					continue
+				}
+				es := bs.Name
+				if e.Schema != nil && e.Schema.Name != "" {
+					es = e.Schema.Name
+				}
+				if ns == "" || ns == es {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// baselineSchemas returns the baseline schema along
+// with the other schemas of its realm, if attached.
+func (s *state) baselineSchemas() []*schema.Schema {
+	if r := s.baseline.Realm; r != nil && len(r.Schemas) > 0 {
+		return r.Schemas
+	}
+	return []*schema.Schema{s.baseline}
+}
+
+// schemaExists reports if the schema exists in the database, or in the baseline.
+func (s *state) schemaExists(ctx context.Context, name string) (bool, error) {
+	if s.baseline != nil {
+		for _, bs := range s.baselineSchemas() {
+			if bs.Name == name {
+				return true, nil
+			}
+		}
+		return false, nil
 	}
-	rows, err := s.QueryContext(ctx, query, args...)
+	rows, err := s.QueryContext(ctx, "SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1", name)
 	if err != nil {
-		return false, fmt.Errorf("check enum existence: %w", err)
+		return false, fmt.Errorf("check schema existence: %w", err)
 	}
 	defer rows.Close()
 	return rows.Next(), rows.Err()
@@ -787,52 +3320,397 @@ func (s *state) mayDropEnum(alter *alterChange, ns *schema.Schema, e *schema.Enu
 }
 
 func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) error {
+	for _, idx := range indexes {
+		c, err := s.addIndex(t, idx)
+		if err != nil {
+			return err
+		}
+		s.append(c)
+	}
+	return nil
+}
+
+// addIndex returns the change for creating the given index on the table.
+func (s *state) addIndex(t *schema.Table, idx *schema.Index) (*migrate.Change, error) {
+	b := s.Build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	b.P("INDEX")
+	if concurrently(t, idx) {
+		b.P("CONCURRENTLY")
+	}
+	if idx.Name != "" {
+		b.Ident(idx.Name)
+	}
+	b.P("ON").Table(t)
+	if err := s.index(b, idx); err != nil {
+		return nil, err
+	}
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+		Reverse: func() string {
+			b := s.Build("DROP INDEX")
+			if concurrently(t, idx) {
+				b.P("CONCURRENTLY")
+				// Promoted indexes are dropped along with their constraints
+				// when the plan is reverted (see promoteIndexes).
+				if isUniqueConstraint(idx) {
+					b.P("IF EXISTS")
+				}
+			}
+			// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
+			// Therefore, we print indexes with their qualified name, because
+			// the connection that executes the statements may not be attached
+			// to this schema.
+			if t.Schema != nil {
+				b.WriteString(s.schemaPrefix(t.Schema))
+			}
+			b.Ident(idx.Name)
+			return b.String()
+		}(),
+	}, nil
+}
+
+// promoteIndexes adds the UNIQUE constraints that are backed by the given unique
+// indexes, after they were built concurrently, using ADD CONSTRAINT ... USING INDEX.
+// Note that the index is renamed to the constraint name, if they are different.
+func (s *state) promoteIndexes(t *schema.Table, indexes ...*schema.Index) {
+	for _, idx := range indexes {
+		name := idx.Name
+		if c, ok := indexConstraint(idx); ok && c.N != "" {
+			name = c.N
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.Build("ALTER TABLE").Table(t).P("ADD CONSTRAINT").Ident(name).P("UNIQUE USING INDEX").Ident(idx.Name).String(),
+			Comment: fmt.Sprintf("promote index %q to a unique constraint of table %q", idx.Name, t.Name),
+			Reverse: s.Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(name).String(),
+		})
+	}
+}
+
+// ginParamsChanged reports if the GIN storage parameters are the only attributes that were changed.
+func ginParamsChanged(from, to *schema.Index) bool {
+	s1, s2 := &IndexStorageParams{}, &IndexStorageParams{}
+	sqlx.Has(from.Attrs, s1)
+	sqlx.Has(to.Attrs, s2)
+	if s1.AutoSummarize != s2.AutoSummarize || s1.pagesPerRange() != s2.pagesPerRange() || s1.FillFactor != s2.FillFactor || s1.ginEqual(s2) {
+		return false
+	}
+	attrs := make([]schema.Attr, 0, len(from.Attrs))
+	for _, a := range from.Attrs {
+		if _, ok := a.(*IndexStorageParams); !ok {
+			attrs = append(attrs, a)
+		}
+	}
+	return !(&diff{}).IndexAttrChanged(append(attrs, s2), to.Attrs)
+}
+
+// alterIndexParams returns the change for setting and resetting the GIN storage parameters of an index.
+func (s *state) alterIndexParams(t *schema.Table, change *schema.ModifyIndex) *migrate.Change {
+	build := func(from, to *schema.Index) string {
+		s1, s2 := &IndexStorageParams{}, &IndexStorageParams{}
+		sqlx.Has(from.Attrs, s1)
+		sqlx.Has(to.Attrs, s2)
+		var set, reset []string
+		if s1.fastUpdate() != s2.fastUpdate() {
+			if s2.fastUpdate() {
+				reset = append(reset, "fastupdate")
+			} else {
+				set = append(set, "fastupdate = off")
+			}
+		}
+		if s1.PendingListLimit != s2.PendingListLimit {
+			if s2.PendingListLimit == 0 {
+				reset = append(reset, "gin_pending_list_limit")
+			} else {
+				set = append(set, fmt.Sprintf("gin_pending_list_limit = %d", s2.PendingListLimit))
+			}
+		}
+		b := s.Build("ALTER INDEX")
+		b.WriteString(s.schemaPrefix(t.Schema))
+		b.Ident(to.Name)
+		if len(set) > 0 {
+			b.P("SET").Wrap(func(b *sqlx.Builder) {
+				b.WriteString(strings.Join(set, ", "))
+			})
+		}
+		if len(reset) > 0 {
+			if len(set) > 0 {
+				b.Comma()
+			}
+			b.P("RESET").Wrap(func(b *sqlx.Builder) {
+				b.WriteString(strings.Join(reset, ", "))
+			})
+		}
+		return b.String()
+	}
+	return &migrate.Change{
+		Source:  change,
+		Comment: fmt.Sprintf("modify index %q storage parameters", change.To.Name),
+		Cmd:     build(change.From, change.To),
+		Reverse: build(change.To, change.From),
+	}
+}
+
+// concurrently reports if the index should be created or dropped concurrently. Note
+// that indexes on partitioned tables cannot be created or dropped concurrently.
+func concurrently(t *schema.Table, idx *schema.Index) bool {
+	return sqlx.Has(idx.Attrs, &Concurrently{}) && !sqlx.Has(t.Attrs, &Partition{})
+}
+
+// addPartitionedIndexes creates the indexes of a partitioned table that were
+// requested to be built concurrently. The parent index is created first on
+// the partitioned table only, and then an index is built concurrently on each
+// partition and attached to it. The parent index becomes valid once all its
+// partitions have matching indexes attached.
+func (s *state) addPartitionedIndexes(ctx context.Context, t *schema.Table, indexes ...*schema.Index) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	parts, err := s.partitions(ctx, t)
+	if err != nil {
+		return err
+	}
 	for _, idx := range indexes {
 		b := s.Build("CREATE")
 		if idx.Unique {
 			b.P("UNIQUE")
 		}
-		b.P("INDEX")
-		if c := (Concurrently{}); sqlx.Has(idx.Attrs, &c) {
-			b.P("CONCURRENTLY")
-		}
-		if idx.Name != "" {
-			b.Ident(idx.Name)
-		}
-		b.P("ON").Table(t)
+		b.P("INDEX").Ident(idx.Name).P("ON ONLY").Table(t)
 		if err := s.index(b, idx); err != nil {
 			return err
 		}
 		s.append(&migrate.Change{
 			Cmd:     b.String(),
-			Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+			Comment: fmt.Sprintf("create index %q to partitioned table: %q", idx.Name, t.Name),
 			Reverse: func() string {
 				b := s.Build("DROP INDEX")
-				if c := (Concurrently{}); sqlx.Has(idx.Attrs, &c) {
-					b.P("CONCURRENTLY")
-				}
-				// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
-				// Therefore, we print indexes with their qualified name, because
-				// the connection that executes the statements may not be attached
-				// to this schema.
-				if t.Schema != nil {
-					b.WriteString(s.schemaPrefix(t.Schema))
-				}
-				b.Ident(idx.Name)
-				return b.String()
+				b.WriteString(s.schemaPrefix(t.Schema))
+				return b.Ident(idx.Name).String()
 			}(),
 		})
+		for _, p := range parts {
+			name := partitionIndexName(p, idx)
+			b := s.Build("CREATE")
+			if idx.Unique {
+				b.P("UNIQUE")
+			}
+			b.P("INDEX")
+			// Partitions that are partitioned themselves do not support
+			// CONCURRENTLY, but their partitions are attached automatically.
+			if !sqlx.Has(p.Attrs, &Partition{}) {
+				b.P("CONCURRENTLY")
+			}
+			b.Ident(name).P("ON").Table(p)
+			if err := s.index(b, idx); err != nil {
+				return err
+			}
+			attach := s.Build("ALTER INDEX")
+			attach.WriteString(s.schemaPrefix(t.Schema))
+			attach.Ident(idx.Name).P("ATTACH PARTITION")
+			attach.WriteString(s.schemaPrefix(p.Schema))
+			// Attached indexes cannot be detached or dropped, and are
+			// dropped along with their parent index on reverse.
+			s.append(
+				&migrate.Change{
+					Cmd:     b.String(),
+					Comment: fmt.Sprintf("create index %q to partition: %q", name, p.Name),
+				},
+				&migrate.Change{
+					Cmd:     attach.Ident(name).String(),
+					Comment: fmt.Sprintf("attach index %q to index %q", name, idx.Name),
+				},
+			)
+		}
 	}
 	return nil
 }
 
+// partitionIndexName returns the name of the index that is created on the partition
+// for the given index of its parent. Names that exceed the max identifier length are
+// trimmed on a rune boundary and suffixed with their hash to avoid collisions.
+func partitionIndexName(p *schema.Table, idx *schema.Index) string {
+	name := p.Name + "_" + idx.Name
+	if len(name) <= maxIdentLen {
+		return name
+	}
+	h := fnv.New32()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	n := maxIdentLen - len(suffix)
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n] + suffix
+}
+
+// partitions returns the partitions of the given table.
+func (s *state) partitions(ctx context.Context, t *schema.Table) ([]*schema.Table, error) {
+	if s.baseline != nil {
+		return nil, fmt.Errorf("query partitions of table %q: not supported without a database connection", t.Name)
+	}
+	rows, err := s.QueryContext(ctx, "SELECT n.nspname, c.relname, c.relkind FROM pg_catalog.pg_inherits AS i JOIN pg_catalog.pg_class AS c ON c.oid = i.inhrelid JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace WHERE i.inhparent = to_regclass($1)::oid ORDER BY c.relname", s.Build().Table(t).String())
+	if err != nil {
+		return nil, fmt.Errorf("query partitions of table %q: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var parts []*schema.Table
+	for rows.Next() {
+		var ns, name, kind string
+		if err := rows.Scan(&ns, &name, &kind); err != nil {
+			return nil, err
+		}
+		p := schema.NewTable(name).SetSchema(schema.New(ns))
+		if kind == "p" {
+			p.AddAttrs(&Partition{})
+		}
+		parts = append(parts, p)
+	}
+	return parts, rows.Err()
+}
+
+// inheritedChecks removes CHECK constraints that are added to child tables along
+// with the same constraint on one of their ancestors. PostgreSQL propagates checks
+// that are not marked as NO INHERIT to all inheriting tables, and adding them
+// explicitly to the children fails (or merges them) after the parent was altered.
+func (s *state) inheritedChecks(ctx context.Context, changes []schema.Change) ([]schema.Change, error) {
+	if s.baseline != nil {
+		return changes, nil
+	}
+	// Tables that add each check name. Only names
+	// added to more than one table are candidates.
+	added := make(map[string][]*schema.ModifyTable)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			continue
+		}
+		for _, mc := range m.Changes {
+			if a, ok := mc.(*schema.AddCheck); ok && a.C.Name != "" {
+				added[a.C.Name] = append(added[a.C.Name], m)
+			}
+		}
+	}
+	var (
+		skip      = make(map[*schema.AddCheck]bool)
+		ancestors = make(map[*schema.ModifyTable][]*schema.Table)
+	)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			continue
+		}
+		for _, mc := range m.Changes {
+			a, ok := mc.(*schema.AddCheck)
+			if !ok || len(added[a.C.Name]) < 2 {
+				continue
+			}
+			parents, ok := ancestors[m]
+			if !ok {
+				var err error
+				if parents, err = s.inherits(ctx, m.T); err != nil {
+					return nil, err
+				}
+				ancestors[m] = parents
+			}
+			for _, p := range added[a.C.Name] {
+				if p != m && inheritsFrom(parents, p.T) && propagatesCheck(p, a.C) {
+					skip[a] = true
+				}
+			}
+		}
+	}
+	if len(skip) == 0 {
+		return changes, nil
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			planned = append(planned, c)
+			continue
+		}
+		kept := make([]schema.Change, 0, len(m.Changes))
+		for _, mc := range m.Changes {
+			if a, ok := mc.(*schema.AddCheck); !ok || !skip[a] {
+				kept = append(kept, mc)
+			}
+		}
+		if len(kept) > 0 {
+			planned = append(planned, &schema.ModifyTable{T: m.T, Changes: kept})
+		}
+	}
+	return planned, nil
+}
+
+// propagatesCheck reports if the parent table modification adds
+// an inheritable CHECK constraint that is identical to the given one.
+func propagatesCheck(m *schema.ModifyTable, c *schema.Check) bool {
+	for _, mc := range m.Changes {
+		if a, ok := mc.(*schema.AddCheck); ok && a.C.Name == c.Name && !sqlx.Has(a.C.Attrs, &NoInherit{}) && checkExprEqual(a.C.Expr, c.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritsFrom reports if the given table is one of the ancestors.
+func inheritsFrom(ancestors []*schema.Table, t *schema.Table) bool {
+	for _, a := range ancestors {
+		if a.Name == t.Name && (t.Schema == nil || a.Schema.Name == t.Schema.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// inherits returns all tables the given table inherits from, directly or indirectly.
+func (s *state) inherits(ctx context.Context, t *schema.Table) ([]*schema.Table, error) {
+	rows, err := s.QueryContext(ctx, inheritsQuery, s.Build().Table(t).String())
+	if err != nil {
+		return nil, fmt.Errorf("query ancestors of table %q: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var parents []*schema.Table
+	for rows.Next() {
+		var ns, name string
+		if err := rows.Scan(&ns, &name); err != nil {
+			return nil, err
+		}
+		parents = append(parents, schema.NewTable(name).SetSchema(schema.New(ns)))
+	}
+	return parents, rows.Err()
+}
+
+// inheritsQuery returns the ancestors of a table in the inheritance tree.
+const inheritsQuery = `
+WITH RECURSIVE tree AS (
+	SELECT i.inhparent AS oid FROM pg_catalog.pg_inherits i WHERE i.inhrelid = to_regclass($1)::oid
+	UNION
+	SELECT i.inhparent FROM pg_catalog.pg_inherits i JOIN tree ON i.inhrelid = tree.oid
+)
+SELECT n.nspname, c.relname
+FROM tree JOIN pg_catalog.pg_class c ON c.oid = tree.oid JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+ORDER BY n.nspname, c.relname
+`
+
 func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error {
 	f, err := s.formatType(t, c)
 	if err != nil {
 		return err
 	}
 	b.Ident(c.Name).P(f)
-	if !c.Type.Null {
+	if m := compression(c.Attrs); m != "" {
+		if !s.supportsCompression() {
+			return fmt.Errorf("setting the compression method of column %q requires PostgreSQL 14 or above", c.Name)
+		}
+		b.P("COMPRESSION", m)
+	}
+	// Identity columns are implicitly NOT NULL, and PostgreSQL
+	// rejects an explicit NULL constraint on them.
+	if !c.Type.Null || sqlx.Has(c.Attrs, &Identity{}) {
 		b.P("NOT")
 	} else if t, ok := c.Type.Type.(*SerialType); ok {
 		return fmt.Errorf("NOT NULL constraint is required for %s column %q", t.T, c.Name)
@@ -844,6 +3722,8 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 		case *schema.Comment:
 		case *schema.Collation:
 			b.P("COLLATE").Ident(a.V)
+		case *Compression:
+			// Handled above.
 		case *Identity, *schema.GeneratedExpr:
 			// Handled below.
 		default:
@@ -854,18 +3734,7 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 	case hasI && hasX:
 		return fmt.Errorf("both identity and generation expression specified for column %q", c.Name)
 	case hasI:
-		id, _ := identity(c.Attrs)
-		b.P("GENERATED", id.Generation, "AS IDENTITY")
-		if id.Sequence.Start != defaultSeqStart || id.Sequence.Increment != defaultSeqIncrement {
-			b.Wrap(func(b *sqlx.Builder) {
-				if id.Sequence.Start != defaultSeqStart {
-					b.P("START WITH", strconv.FormatInt(id.Sequence.Start, 10))
-				}
-				if id.Sequence.Increment != defaultSeqIncrement {
-					b.P("INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
-				}
-			})
-		}
+		identityClause(b, c)
 	case hasX:
 		x := &schema.GeneratedExpr{}
 		sqlx.Has(c.Attrs, x)
@@ -874,6 +3743,33 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 	return nil
 }
 
+// identityClause appends the "GENERATED ... AS IDENTITY" clause of the given
+// column, along with the sequence options that differ from their defaults.
+func identityClause(b *sqlx.Builder, c *schema.Column) {
+	id, _ := identity(c.Attrs)
+	b.P("GENERATED", id.Generation, "AS IDENTITY")
+	var (
+		minV, maxV     = identityBounds(c)
+		defMin, defMax = implicitBounds(c, id.Sequence.Increment)
+	)
+	if id.Sequence.Start != defaultSeqStart || id.Sequence.Increment != defaultSeqIncrement || minV != defMin || maxV != defMax {
+		b.Wrap(func(b *sqlx.Builder) {
+			if id.Sequence.Start != defaultSeqStart {
+				b.P("START WITH", strconv.FormatInt(id.Sequence.Start, 10))
+			}
+			if id.Sequence.Increment != defaultSeqIncrement {
+				b.P("INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
+			}
+			if minV != defMin {
+				b.P("MINVALUE", strconv.FormatInt(minV, 10))
+			}
+			if maxV != defMax {
+				b.P("MAXVALUE", strconv.FormatInt(maxV, 10))
+			}
+		})
+	}
+}
+
 // columnDefault writes the default value of column to the builder.
 func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
 	switch x := c.Default.(type) {
@@ -962,16 +3858,28 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 			})
 		})
 	}
+	if !nullsDistinct(idx.Attrs) {
+		b.P("NULLS NOT DISTINCT")
+	}
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		b.P("WITH")
 		b.Wrap(func(b *sqlx.Builder) {
 			var parts []string
+			if p.FillFactor != 0 {
+				parts = append(parts, fmt.Sprintf("fillfactor = %d", p.FillFactor))
+			}
 			if p.AutoSummarize {
 				parts = append(parts, "autosummarize = true")
 			}
 			if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagePerRange {
 				parts = append(parts, fmt.Sprintf("pages_per_range = %d", p.PagesPerRange))
 			}
+			if !p.fastUpdate() {
+				parts = append(parts, "fastupdate = off")
+			}
+			if p.PendingListLimit != 0 {
+				parts = append(parts, fmt.Sprintf("gin_pending_list_limit = %d", p.PendingListLimit))
+			}
 			b.WriteString(strings.Join(parts, ", "))
 		})
 	}
@@ -980,7 +3888,7 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) error {
 	}
 	for _, attr := range idx.Attrs {
 		switch attr.(type) {
-		case *schema.Comment, *IndexType, *IndexInclude, *Concurrently, *Constraint, *IndexPredicate, *IndexStorageParams:
+		case *schema.Comment, *IndexType, *IndexInclude, *Concurrently, *Constraint, *IndexPredicate, *IndexStorageParams, *IndexNullsDistinct:
 		default:
 			return fmt.Errorf("postgres: unexpected index attribute: %T", attr)
 		}
@@ -1012,9 +3920,24 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 		if fk.OnDelete != "" {
 			b.P("ON DELETE", string(fk.OnDelete))
 		}
+		if sqlx.Has(fk.Attrs, &Deferrable{}) {
+			deferrable(b, fk.Attrs)
+		}
 	})
 }
 
+// deferrable writes the deferrability clause of a constraint.
+func deferrable(b *sqlx.Builder, attrs []schema.Attr) {
+	switch d := (Deferrable{}); {
+	case !sqlx.Has(attrs, &d):
+		b.P("NOT DEFERRABLE")
+	case d.InitiallyDeferred:
+		b.P("DEFERRABLE INITIALLY DEFERRED")
+	default:
+		b.P("DEFERRABLE INITIALLY IMMEDIATE")
+	}
+}
+
 func (s *state) append(c ...*migrate.Change) {
 	s.Changes = append(s.Changes, c...)
 }
@@ -1022,9 +3945,52 @@ func (s *state) append(c ...*migrate.Change) {
 // Build instantiates a new builder and writes the given phrase to it.
 func (s *state) Build(phrases ...string) *sqlx.Builder {
 	b := &sqlx.Builder{QuoteChar: '"', Schema: s.SchemaQualifier}
+	if s.QuotePolicy == migrate.QuoteMinimal {
+		b.Quote = mustQuote
+	}
 	return b.P(phrases...)
 }
 
+// ident returns the given identifier quoted according to the quoting policy of the
+// plan. Unlike Builder.Ident, it is used for identifiers that are formatted as part
+// of qualified names, e.g. "schema"."name".
+func (s *state) ident(name string) string {
+	if s.QuotePolicy == migrate.QuoteMinimal && !mustQuote(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// reUnquoted matches identifiers that are kept as-is by PostgreSQL when unquoted.
+var reUnquoted = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// mustQuote reports if the given identifier requires quoting. i.e. it is a
+// reserved keyword, or it is folded to lowercase or rejected when unquoted.
+func mustQuote(s string) bool {
+	return !reUnquoted.MatchString(s) || reservedWords[s]
+}
+
+// reservedWords holds the keywords that cannot be used as column names unquoted.
+// https://www.postgresql.org/docs/current/sql-keywords-appendix.html
+var reservedWords = func() map[string]bool {
+	words := strings.Fields(`
+		all analyse analyze and any array as asc asymmetric authorization binary both case cast
+		check collate collation column concurrently constraint create cross current_catalog
+		current_date current_role current_schema current_time current_timestamp current_user
+		default deferrable desc distinct do else end except false fetch for foreign freeze from
+		full grant group having ilike in initially inner intersect into is isnull join lateral
+		leading left like limit localtime localtimestamp natural not notnull null offset on only
+		or order outer overlaps placing primary references returning right select session_user
+		similar some symmetric system_user table tablesample then to trailing true union unique
+		user using variadic verbose when where window with
+	`)
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}()
+
 // skipAutoChanges filters unnecessary changes that are automatically
 // happened by the database when ALTER TABLE is executed.
 func skipAutoChanges(changes []schema.Change) []schema.Change {
@@ -1087,6 +4053,208 @@ func commentChange(c schema.Change) (from, to string, err error) {
 	return
 }
 
+// storageParams extracts the table storage parameters from the given attribute change.
+func storageParams(c schema.Change) (from, to *TableStorageParams, ok bool) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, ok = c.A.(*TableStorageParams)
+		from = &TableStorageParams{}
+	case *schema.DropAttr:
+		from, ok = c.A.(*TableStorageParams)
+		to = &TableStorageParams{}
+	case *schema.ModifyAttr:
+		var ok2 bool
+		from, ok = c.From.(*TableStorageParams)
+		to, ok2 = c.To.(*TableStorageParams)
+		ok = ok && ok2
+	}
+	return
+}
+
+// tableOwner extracts the table owners from the given attribute change.
+// The "from" owner is nil in case it was added by the change.
+func tableOwner(c schema.Change) (from, to *TableOwner, ok bool) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, ok = c.A.(*TableOwner)
+	case *schema.ModifyAttr:
+		var ok2 bool
+		from, ok = c.From.(*TableOwner)
+		to, ok2 = c.To.(*TableOwner)
+		ok = ok && ok2
+	}
+	return
+}
+
+// accessMethod extracts the table access methods from the given attribute change.
+func accessMethod(c schema.Change) (from, to *TableAccessMethod, ok bool) {
+	if c, ok1 := c.(*schema.ModifyAttr); ok1 {
+		var ok2 bool
+		from, ok = c.From.(*TableAccessMethod)
+		to, ok2 = c.To.(*TableAccessMethod)
+		ok = ok && ok2
+	}
+	return
+}
+
+// partitionBounds returns the partition bounds of an attribute change.
+func partitionBounds(c schema.Change) (from, to *PartitionBounds, ok bool) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, ok = c.A.(*PartitionBounds)
+		from = &PartitionBounds{}
+	case *schema.DropAttr:
+		from, ok = c.A.(*PartitionBounds)
+		to = &PartitionBounds{}
+	case *schema.ModifyAttr:
+		var ok2 bool
+		from, ok = c.From.(*PartitionBounds)
+		to, ok2 = c.To.(*PartitionBounds)
+		ok = ok && ok2
+	}
+	return
+}
+
+// alterPartitions returns the changes for bringing the partitions of a table to their
+// desired bounds. Partitions are detached first, and then attached with their new bounds
+// (or created), to avoid overlapping with bounds that were not changed yet. Partitions
+// that were removed from the table are detached, but not dropped.
+func (s *state) alterPartitions(t *schema.Table, c schema.Change, from, to *PartitionBounds) []*migrate.Change {
+	var detached, attached []*migrate.Change
+	for _, p1 := range from.Parts {
+		switch p2, ok := partitionBound(to.Parts, p1.Name); {
+		case !ok:
+			detached = append(detached, &migrate.Change{
+				Source:  c,
+				Comment: fmt.Sprintf("detach partition %q from table %q", p1.Name, t.Name),
+				Cmd:     s.detachPartition(t, p1),
+				Reverse: s.attachPartition(t, p1),
+			})
+		case !boundEqual(p1.Bound, p2.Bound):
+			detached = append(detached, &migrate.Change{
+				Source:  c,
+				Comment: fmt.Sprintf("detach partition %q from table %q for changing its bound", p1.Name, t.Name),
+				Cmd:     s.detachPartition(t, p1),
+				Reverse: s.attachPartition(t, p1),
+			})
+			attached = append(attached, &migrate.Change{
+				Source:  c,
+				Comment: fmt.Sprintf("attach partition %q to table %q", p2.Name, t.Name),
+				Cmd:     s.attachPartition(t, p2),
+				Reverse: s.detachPartition(t, p2),
+			})
+		}
+	}
+	for _, p2 := range to.Parts {
+		if _, ok := partitionBound(from.Parts, p2.Name); !ok {
+			attached = append(attached, s.createPartition(t, c, p2))
+		}
+	}
+	return append(detached, attached...)
+}
+
+// createPartition returns the change for creating a partition of the table.
+func (s *state) createPartition(t *schema.Table, c schema.Change, p *PartitionBound) *migrate.Change {
+	pt := &schema.Table{Name: p.Name, Schema: t.Schema}
+	return &migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("create partition %q of table %q", p.Name, t.Name),
+		Cmd:     s.Build("CREATE TABLE").Table(pt).P("PARTITION OF").Table(t).P(p.Bound).String(),
+		Reverse: s.Build("DROP TABLE").Table(pt).String(),
+	}
+}
+
+func (s *state) attachPartition(t *schema.Table, p *PartitionBound) string {
+	return s.Build("ALTER TABLE").Table(t).P("ATTACH PARTITION").Table(&schema.Table{Name: p.Name, Schema: t.Schema}).P(p.Bound).String()
+}
+
+func (s *state) detachPartition(t *schema.Table, p *PartitionBound) string {
+	return s.Build("ALTER TABLE").Table(t).P("DETACH PARTITION").Table(&schema.Table{Name: p.Name, Schema: t.Schema}).String()
+}
+
+// oidsWarning is attached to changes that define tables WITH OIDS.
+const oidsWarning = "WITH OIDS is not supported by PostgreSQL 12 and above"
+
+// oidsSkippedWarning is attached to changes that had their OID clauses
+// skipped, because the target server does not support them.
+const oidsSkippedWarning = "OID changes were skipped as they are not supported by PostgreSQL 12 and above"
+
+// alterStorageParams returns the change for setting and resetting the table storage parameters.
+// The legacy oids option cannot be set or reset, and is changed using SET WITH(OUT) OIDS.
+// OID clauses are skipped on servers that no longer support them, and
+// nil is returned in case there is nothing else left to change.
+func (s *state) alterStorageParams(t *schema.Table, c schema.Change, from, to *TableStorageParams) *migrate.Change {
+	build := func(from, to *TableStorageParams) string {
+		var (
+			n          int
+			set, reset []struct{ N, V string }
+			b          = s.Build("ALTER TABLE").Table(t)
+			clause     = func(p string) *sqlx.Builder {
+				if n++; n > 1 {
+					b.Comma()
+				}
+				return b.P(p)
+			}
+		)
+		for _, p := range to.withoutOIDs().Params {
+			switch d, ok := s.storageParamDefault(p.N); {
+			case s.storageParamEqual(from, to, p.N):
+			// Parameters that were set back to their default values are
+			// reset, instead of being kept in the table options.
+			case ok && storageValueEqual(d, p.V):
+				reset = append(reset, p)
+			default:
+				set = append(set, p)
+			}
+		}
+		for _, p := range from.withoutOIDs().Params {
+			if _, ok := to.Value(p.N); !ok && !s.storageParamEqual(from, to, p.N) {
+				reset = append(reset, p)
+			}
+		}
+		switch {
+		case !s.supportsOIDs():
+		case from.oids() && !to.oids():
+			clause("SET WITHOUT OIDS")
+		case !from.oids() && to.oids():
+			clause("SET WITH OIDS")
+		}
+		if len(set) > 0 {
+			clause("SET").Wrap(func(b *sqlx.Builder) {
+				b.MapComma(set, func(i int, b *sqlx.Builder) {
+					b.P(set[i].N, "=", set[i].V)
+				})
+			})
+		}
+		if len(reset) > 0 {
+			clause("RESET").Wrap(func(b *sqlx.Builder) {
+				b.MapComma(reset, func(i int, b *sqlx.Builder) {
+					b.P(reset[i].N)
+				})
+			})
+		}
+		if n == 0 {
+			return ""
+		}
+		return b.String()
+	}
+	change := &migrate.Change{
+		Source:  c,
+		Comment: fmt.Sprintf("modify %q table storage parameters", t.Name),
+		Cmd:     build(from, to),
+		Reverse: build(to, from),
+	}
+	switch {
+	case change.Cmd == "":
+		return nil
+	case from.oids() != to.oids() && !s.supportsOIDs():
+		change.Warnings = append(change.Warnings, oidsSkippedWarning)
+	case to.oids():
+		change.Warnings = append(change.Warnings, oidsWarning)
+	}
+	return change
+}
+
 // checks writes the CHECK constraint to the builder.
 func check(b *sqlx.Builder, c *schema.Check) {
 	if c.Name != "" {
@@ -1098,6 +4266,17 @@ func check(b *sqlx.Builder, c *schema.Check) {
 	}
 }
 
+// indexConstraint returns the constraint (e.g. UNIQUE or EXCLUDE)
+// that owns the index, if the index was created to back one.
+func indexConstraint(i *schema.Index) (*Constraint, bool) {
+	for _, a := range i.Attrs {
+		if c, ok := a.(*Constraint); ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
 // isUniqueConstraint reports if the index is a valid UNIQUE constraint.
 func isUniqueConstraint(i *schema.Index) bool {
 	hasC := func() bool {
@@ -1156,9 +4335,9 @@ func (s *state) createDropEnum(sc *schema.Schema, e *schema.EnumType) (string, s
 func (s *state) enumIdent(ns *schema.Schema, e *schema.EnumType) string {
 	es := s.enumSchema(ns, e)
 	if es != "" {
-		return fmt.Sprintf("%q.%q", es, e.T)
+		return s.ident(es) + "." + s.ident(e.T)
 	}
-	return strconv.Quote(e.T)
+	return s.ident(e.T)
 }
 
 func (s *state) enumSchema(ns *schema.Schema, e *schema.EnumType) (es string) {
@@ -1182,10 +4361,10 @@ func (s *state) schemaPrefix(ns *schema.Schema) string {
 	case s.SchemaQualifier != nil:
 		// In case the qualifier is empty, ignore.
 		if *s.SchemaQualifier != "" {
-			return fmt.Sprintf("%q.", *s.SchemaQualifier)
+			return s.ident(*s.SchemaQualifier) + "."
 		}
 	case ns != nil && ns.Name != "":
-		return fmt.Sprintf("%q.", ns.Name)
+		return s.ident(ns.Name) + "."
 	}
 	return ""
 }
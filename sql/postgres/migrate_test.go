@@ -6,8 +6,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/migrate"
@@ -17,6 +21,368 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestPlanChanges_DropExpression(t *testing.T) {
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: schema.NewTable("posts"),
+			Changes: []schema.Change{
+				&schema.ModifyColumn{
+					Change: schema.ChangeGenerated,
+					From:   schema.NewIntColumn("c1", "int").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id+1", Type: "STORED"}),
+					To:     schema.NewIntColumn("c1", "int"),
+				},
+			},
+		},
+	}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("120000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.EqualError(t, err, `alter table "posts": dropping the generation expression of column "c1" requires PostgreSQL 13 or above`)
+
+	db, mk, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("140000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "posts" ALTER COLUMN "c1" DROP EXPRESSION`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_Compression(t *testing.T) {
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: schema.NewTable("logs"),
+			Changes: []schema.Change{
+				&schema.ModifyColumn{
+					Change: schema.ChangeAttr,
+					From:   schema.NewStringColumn("body", "text"),
+					To:     schema.NewStringColumn("body", "text").AddAttrs(&Compression{V: "lz4"}),
+				},
+			},
+		},
+	}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.EqualError(t, err, `alter table "logs": setting the compression method of column "body" requires PostgreSQL 14 or above`)
+
+	db, mk, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("140000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "logs" ALTER COLUMN "body" SET COMPRESSION lz4`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "logs" ALTER COLUMN "body" SET COMPRESSION DEFAULT`, plan.Changes[0].Reverse)
+
+	changes = []schema.Change{
+		&schema.AddTable{
+			T: schema.NewTable("logs").AddColumns(schema.NewStringColumn("body", "text").AddAttrs(&Compression{V: "lz4"})),
+		},
+	}
+	plan, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "logs" ("body" text COMPRESSION lz4 NOT NULL)`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_IdentityPrimaryKey(t *testing.T) {
+	from := schema.NewIntColumn("id", "bigint").SetDefault(&schema.RawExpr{X: "0"})
+	to := schema.NewIntColumn("id", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 100}})
+	events := schema.NewTable("events").AddColumns(to, schema.NewIntColumn("tenant_id", "bigint"))
+	events.SetPrimaryKey(schema.NewPrimaryKey(events.Columns...))
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+
+	// The default value is dropped before the identity is added.
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: events,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeDefault | schema.ChangeAttr},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "events" ALTER COLUMN "id" DROP DEFAULT, ALTER COLUMN "id" ADD GENERATED ALWAYS AS IDENTITY (START WITH 100)`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "events" ALTER COLUMN "id" DROP IDENTITY, ALTER COLUMN "id" SET DEFAULT 0`, plan.Changes[0].Reverse)
+
+	// The identity is dropped before the default value is set.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: events,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: to, To: from, Change: schema.ChangeDefault | schema.ChangeAttr},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "events" ALTER COLUMN "id" DROP IDENTITY, ALTER COLUMN "id" SET DEFAULT 0`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "events" ALTER COLUMN "id" DROP DEFAULT, ALTER COLUMN "id" ADD GENERATED ALWAYS AS IDENTITY (START WITH 100)`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_SerialIdentity(t *testing.T) {
+	serial := schema.NewColumn("id").SetType(&SerialType{T: "serial"})
+	ident := schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 1, Increment: 1}})
+	users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(ident)
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+
+	// Serial to identity.
+	changes, err := drv.TableDiff(schema.NewTable("users").SetSchema(users.Schema).AddColumns(serial), users)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyColumn{From: serial, To: ident, Change: schema.ChangeType | schema.ChangeAttr},
+	}, changes)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: users, Changes: changes}})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "id" DROP DEFAULT, ALTER COLUMN "id" ADD GENERATED BY DEFAULT AS IDENTITY`, plan.Changes[0].Cmd)
+	require.Equal(t, `SELECT setval(pg_get_serial_sequence('"public"."users"', 'id'), last_value, is_called) FROM "public"."users_id_seq"`, plan.Changes[1].Cmd)
+	require.Equal(t, `DROP SEQUENCE IF EXISTS "public"."users_id_seq"`, plan.Changes[2].Cmd)
+
+	// Identity to serial, where the serial is written in its explicit form.
+	normalized := schema.NewIntColumn("id", "integer").SetDefault(&schema.RawExpr{X: "nextval('users_id_seq'::regclass)"})
+	changes, err = drv.TableDiff(users, schema.NewTable("users").SetSchema(users.Schema).AddColumns(normalized))
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyColumn{From: ident, To: normalized, Change: schema.ChangeType | schema.ChangeAttr},
+	}, changes)
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: users, Changes: changes}})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "id" DROP IDENTITY`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE SEQUENCE IF NOT EXISTS "public"."users_id_seq" OWNED BY "public"."users"."id"`, plan.Changes[1].Cmd)
+	require.Equal(t, `SELECT setval('"public"."users_id_seq"', max("id")) FROM "public"."users"`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "id" SET DEFAULT nextval('"public"."users_id_seq"')`, plan.Changes[3].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "id" DROP DEFAULT`, plan.Changes[3].Reverse)
+
+	// Identity to a serial of a larger type.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: ident, To: schema.NewColumn("id").SetType(&SerialType{T: "bigserial"}), Change: schema.ChangeType | schema.ChangeAttr},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "id" DROP IDENTITY, ALTER COLUMN "id" TYPE bigint`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_QuotePolicy(t *testing.T) {
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("user", "text"),
+			schema.NewStringColumn("FirstName", "text"),
+		)
+	users.AddIndexes(schema.NewUniqueIndex("users_user").AddColumns(users.Columns[1]))
+	changes := []schema.Change{&schema.AddTable{T: users}}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	for _, p := range []migrate.QuotePolicy{migrate.QuoteDefault, migrate.QuoteAlways} {
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+			o.QuotePolicy = p
+		})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 2)
+		require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL, "user" text NOT NULL, "FirstName" text NOT NULL)`, plan.Changes[0].Cmd)
+		require.Equal(t, `CREATE UNIQUE INDEX "users_user" ON "public"."users" ("user")`, plan.Changes[1].Cmd)
+	}
+
+	// Reserved words and mixed-case identifiers are still quoted.
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) {
+		o.QuotePolicy = migrate.QuoteMinimal
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE public.users (id integer NOT NULL, "user" text NOT NULL, "FirstName" text NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TABLE public.users`, plan.Changes[0].Reverse)
+	require.Equal(t, `CREATE UNIQUE INDEX users_user ON public.users ("user")`, plan.Changes[1].Cmd)
+	require.Equal(t, `DROP INDEX public.users_user`, plan.Changes[1].Reverse)
+
+	// Qualified names of objects follow the policy as well.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddObject{O: &Statistics{Name: "users_stats", Schema: users.Schema, Table: users, Columns: users.Columns[1:]}},
+	}, func(o *migrate.PlanOptions) {
+		o.QuotePolicy = migrate.QuoteMinimal
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE STATISTICS public.users_stats ON "user", "FirstName" FROM public.users`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP STATISTICS public.users_stats`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_IndexFillFactor(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	users.AddIndexes(
+		schema.NewIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&IndexStorageParams{FillFactor: 70}),
+		// The default fillfactor of BTREE indexes is omitted.
+		schema.NewIndex("users_id_default").AddColumns(users.Columns[0]).AddAttrs(&IndexStorageParams{FillFactor: 90}),
+	)
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: users.Indexes[0]},
+				&schema.AddIndex{I: users.Indexes[1]},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE INDEX "users_id" ON "users" ("id") WITH (fillfactor = 70)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "users_id_default" ON "users" ("id")`, plan.Changes[1].Cmd)
+}
+
+func TestPlanChanges_TableAccessMethod(t *testing.T) {
+	events := schema.NewTable("events").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: events,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{From: &TableAccessMethod{V: "heap"}, To: &TableAccessMethod{V: "columnar"}},
+			},
+		},
+	}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("140000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.EqualError(t, err, `changing the access method of table "events" requires PostgreSQL 15 or above`)
+
+	db, mk, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("150000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(tableSizeQuery)).
+		WithArgs("public", "events").
+		WillReturnRows(sqlmock.NewRows([]string{"rows", "bytes"}).AddRow(1000, 65536))
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes, func(o *migrate.PlanOptions) { o.CostEstimate = true })
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."events" SET ACCESS METHOD "columnar"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."events" SET ACCESS METHOD "heap"`, plan.Changes[0].Reverse)
+	require.Equal(t, `set the access method of table "events" to "columnar"`, plan.Changes[0].Comment)
+	require.Equal(t, []string{"changing the access method rewrites the table", `table "events" has about 1000 rows (65536 bytes)`}, plan.Changes[0].Warnings)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(&TableAccessMethod{V: "columnar"})},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "logs" ("id" integer NOT NULL) USING "columnar"`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_AttrStmt(t *testing.T) {
+	users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddAttr{
+					A:     &schema.Comment{Text: "users"},
+					Extra: []schema.Clause{&schema.AttrStmt{Cmd: "SELECT audit_table('users')"}},
+				},
+				&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.False(t, plan.Reversible)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "age" integer NOT NULL`, plan.Changes[0].Cmd)
+	require.Equal(t, `SELECT audit_table('users')`, plan.Changes[1].Cmd)
+	require.Empty(t, plan.Changes[1].Reverse)
+	require.Equal(t, `modify "users" table attribute`, plan.Changes[1].Comment)
+
+	// Changes without statements are reported by the differ only.
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{
+					From:  &schema.Comment{Text: "a"},
+					To:    &schema.Comment{Text: "b"},
+					Extra: []schema.Clause{&schema.AttrStmt{}},
+				},
+				&schema.AddAttr{
+					A:     &schema.Comment{Text: "id"},
+					Extra: []schema.Clause{&schema.AttrStmt{C: users.Columns[0]}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+}
+
+func TestPlanChanges_ForeignKeyDeferrable(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", "int"))
+	from := schema.NewForeignKey("author_fk").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	to := schema.NewForeignKey("author_fk").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).
+		AddAttrs(&Deferrable{InitiallyDeferred: true})
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T:       posts,
+			Changes: []schema.Change{&schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeAttr}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "posts" ALTER CONSTRAINT "author_fk" DEFERRABLE INITIALLY DEFERRED`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "posts" ALTER CONSTRAINT "author_fk" NOT DEFERRABLE`, plan.Changes[0].Reverse)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: posts, Changes: []schema.Change{&schema.AddForeignKey{F: to}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "posts" ADD CONSTRAINT "author_fk" FOREIGN KEY ("author_id") REFERENCES "users" ("id") DEFERRABLE INITIALLY DEFERRED`, plan.Changes[0].Cmd)
+}
+
 func TestPlanChanges(t *testing.T) {
 	tests := []struct {
 		changes  []schema.Change
@@ -27,224 +393,261 @@ func TestPlanChanges(t *testing.T) {
 	}{
 		{
 			changes: []schema.Change{
-				&schema.AddSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfNotExists{}}},
-				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfExists{}}},
-				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{}},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.RenameCheck{
+							From: schema.NewCheck().SetName("users_age_check").SetExpr("(age > 0)"),
+							To:   schema.NewCheck().SetName("age_positive").SetExpr("(age > 0)"),
+						},
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    false,
+				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE SCHEMA IF NOT EXISTS "test"`,
-						Reverse: `DROP SCHEMA "test" CASCADE`,
-					},
-					{
-						Cmd: `DROP SCHEMA IF EXISTS "test" CASCADE`,
-					},
-					{
-						Cmd: `DROP SCHEMA "test" CASCADE`,
+						Cmd:     `ALTER TABLE "users" RENAME CONSTRAINT "users_age_check" TO "age_positive"`,
+						Reverse: `ALTER TABLE "users" RENAME CONSTRAINT "age_positive" TO "users_age_check"`,
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-						},
-					}
-					pets := &schema.Table{
-						Name: "pets",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-							{Name: "user_id",
-								Type: &schema.ColumnType{
-									Type: &schema.IntegerType{T: "bigint"},
-								},
-							},
-						},
-					}
-					fk := &schema.ForeignKey{
-						Symbol:     "pets_user_id_fkey",
-						Table:      pets,
-						OnUpdate:   schema.NoAction,
-						OnDelete:   schema.Cascade,
-						RefTable:   users,
-						Columns:    []*schema.Column{pets.Columns[1]},
-						RefColumns: []*schema.Column{users.Columns[0]},
-					}
-					pets.ForeignKeys = []*schema.ForeignKey{fk}
-					return &schema.ModifyTable{
-						T: pets,
-						Changes: []schema.Change{
-							&schema.DropForeignKey{
-								F: fk,
-							},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewNullStringColumn("name", "text").SetDefault(&schema.Literal{V: "'unknown'"}),
+							To:     schema.NewNullStringColumn("name", "text").SetDefault(&schema.RawExpr{X: "NULL::text"}),
+							Change: schema.ChangeDefault,
 						},
-					}
-				}(),
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "pets" DROP CONSTRAINT "pets_user_id_fkey"`,
-						Reverse: `ALTER TABLE "pets" ADD CONSTRAINT "pets_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE`,
+						Cmd:     `ALTER TABLE "users" ALTER COLUMN "name" DROP DEFAULT`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "name" SET DEFAULT 'unknown'`,
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-							{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}}}},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.DropIndex{
-								I: schema.NewIndex("name_index").
-									AddParts(schema.NewColumnPart(schema.NewColumn("name"))),
-							},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("flags").SetType(&BitType{T: TypeBit, Len: 8}),
+							To:     schema.NewColumn("flags").SetType(&BitType{T: TypeBit, Len: 16}),
+							Change: schema.ChangeType,
 						},
-					}
-				}(),
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("mask").SetType(&BitType{T: TypeBitVar, Len: 8}),
+							To:     schema.NewColumn("mask").SetType(&BitType{T: TypeBitVar, Len: 16}),
+							Change: schema.ChangeType,
+						},
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `DROP INDEX "name_index"`,
-						Reverse: `CREATE INDEX "name_index" ON "users" ("name")`,
+						Cmd:      `ALTER TABLE "users" ALTER COLUMN "flags" TYPE bit(16) USING "flags"::bit(16), ALTER COLUMN "mask" TYPE bit varying(16)`,
+						Reverse:  `ALTER TABLE "users" ALTER COLUMN "mask" TYPE bit varying(8) USING "mask"::bit varying(8), ALTER COLUMN "flags" TYPE bit(8) USING "flags"::bit(8)`,
+						Warnings: []string{`changing the type of column(s) "flags" rewrites the table`, `changing the type of column(s) "mask" does not rewrite the table`},
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-							{Name: "nickname", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}}}},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.DropIndex{
-								I: schema.NewUniqueIndex("unique_nickname").
-									AddColumns(schema.NewColumn("nickname")).
-									AddAttrs(&Constraint{T: "u"}),
-							},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("data").SetType(&schema.JSONType{T: TypeJSON}),
+							To:     schema.NewColumn("data").SetType(&schema.JSONType{T: TypeJSONB}),
+							Change: schema.ChangeType,
 						},
-					}
-				}(),
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "users" DROP CONSTRAINT "unique_nickname"`,
-						Reverse: `ALTER TABLE "users" ADD CONSTRAINT "unique_nickname" UNIQUE ("nickname")`,
+						Cmd:      `ALTER TABLE "users" ALTER COLUMN "data" TYPE jsonb USING "data"::jsonb`,
+						Reverse:  `ALTER TABLE "users" ALTER COLUMN "data" TYPE json USING "data"::json`,
+						Warnings: []string{`changing the type of column(s) "data" rewrites the table`},
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.AddSchema{S: &schema.Schema{Name: "test"}},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("id", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 5, Last: 500}}),
+							To:     schema.NewIntColumn("id", "bigint").AddAttrs(&Identity{Generation: GeneratedTypeAlways}),
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
-				Changes:       []*migrate.Change{{Cmd: `CREATE SCHEMA "test"`, Reverse: `DROP SCHEMA "test" CASCADE`}}},
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 1 SET INCREMENT BY 1`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 1 SET INCREMENT BY 5`,
+					},
+				},
+			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.DropSchema{S: &schema.Schema{Name: "atlas"}},
+				&schema.ModifyTable{
+					T: schema.NewTable("logs").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &PartitionBounds{Parts: []*PartitionBound{
+								{Name: "logs_2023", Bound: "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')"},
+								{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-01-31')"},
+							}},
+							To: &PartitionBounds{Parts: []*PartitionBound{
+								{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+								{Name: "logs_2024_02", Bound: "FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')"},
+							}},
+						},
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
+				Reversible:    true,
 				Transactional: true,
-				Changes:       []*migrate.Change{{Cmd: `DROP SCHEMA "atlas" CASCADE`}},
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "public"."logs" DETACH PARTITION "public"."logs_2023"`,
+						Reverse: `ALTER TABLE "public"."logs" ATTACH PARTITION "public"."logs_2023" FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."logs" DETACH PARTITION "public"."logs_2024_01"`,
+						Reverse: `ALTER TABLE "public"."logs" ATTACH PARTITION "public"."logs_2024_01" FOR VALUES FROM ('2024-01-01') TO ('2024-01-31')`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."logs" ATTACH PARTITION "public"."logs_2024_01" FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')`,
+						Reverse: `ALTER TABLE "public"."logs" DETACH PARTITION "public"."logs_2024_01"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."logs_2024_02" PARTITION OF "public"."logs" FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')`,
+						Reverse: `DROP TABLE "public"."logs_2024_02"`,
+					},
+				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{}, &schema.Comment{}}},
-							{Name: "text", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}, Null: true}},
-							{Name: "directions", Type: &schema.ColumnType{Type: &ArrayType{T: "direction[]", Type: &schema.EnumType{T: "direction", Values: []string{"NORTH", "SOUTH"}, Schema: schema.New("public")}}}},
-							{Name: "states", Type: &schema.ColumnType{Type: &ArrayType{T: "state[]", Type: &schema.EnumType{T: "state", Values: []string{"ON", "OFF"}}}}},
+				&schema.ModifyTable{
+					T: schema.NewTable("staging"),
+					Changes: []schema.Change{
+						&schema.AddAttr{
+							A: &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_enabled", "false"}}},
 						},
-						Attrs: []schema.Attr{
-							&schema.Comment{},
-							&schema.Check{Name: "id_nonzero", Expr: `("id" > 0)`},
-							&schema.Check{Name: "text_len", Expr: `(length("text") > 0)`, Attrs: []schema.Attr{&NoInherit{}}},
-							&schema.Check{Name: "a_in_b", Expr: `(a) in (b)`},
-							&Partition{T: "HASH", Parts: []*PartitionPart{{C: schema.NewColumn("text")}}},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("events"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_enabled", "false"}}},
+							To:   &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_enabled", "true"}}},
 						},
 					},
 				},
 			},
-			mock: func(m mock) {
-				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2")).
-					WithArgs("direction", "public").
-					WillReturnRows(sqlmock.NewRows([]string{"name"}))
-				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'")).
-					WithArgs("state").
-					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("state"))
-			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `CREATE TYPE "public"."direction" AS ENUM ('NORTH', 'SOUTH')`, Reverse: `DROP TYPE "public"."direction"`},
-					{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY, "text" text NULL, "directions" "public"."direction"[] NOT NULL, "states" "state"[] NOT NULL, CONSTRAINT "id_nonzero" CHECK ("id" > 0), CONSTRAINT "text_len" CHECK (length("text") > 0) NO INHERIT, CONSTRAINT "a_in_b" CHECK ((a) in (b))) PARTITION BY HASH ("text")`, Reverse: `DROP TABLE "posts"`},
+					{
+						Cmd:     `ALTER TABLE "staging" SET (autovacuum_enabled = false)`,
+						Reverse: `ALTER TABLE "staging" RESET (autovacuum_enabled)`,
+					},
+					{
+						Cmd:     `ALTER TABLE "events" RESET (autovacuum_enabled)`,
+						Reverse: `ALTER TABLE "events" SET (autovacuum_enabled = false)`,
+					},
 				},
 			},
 		},
+		// Timeouts are set at the start of each transaction
+		// block, and before non-transactional statements.
 		{
-			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+				return []schema.Change{
+					&schema.AddTable{T: schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int"))},
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&Concurrently{})},
 						},
 					},
+					&schema.DropTable{T: schema.NewTable("posts")},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) {
+					o.LockTimeout, o.StatementTimeout = 5*time.Second, 90*time.Second
 				},
 			},
+			mock: func(m mock) { m.noDependents("", "posts") },
 			wantPlan: &migrate.Plan{
-				Reversible:    true,
 				Transactional: true,
-				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 1024))`, Reverse: `DROP TABLE "posts"`}},
-			},
+				Changes: []*migrate.Change{
+					{Cmd: `SET LOCAL lock_timeout = '5s'`, Comment: "set the lock timeout"},
+					{Cmd: `SET LOCAL statement_timeout = '90s'`, Comment: "set the statement timeout"},
+					{Cmd: `CREATE TABLE "logs" ("id" integer NOT NULL)`, Reverse: `DROP TABLE "logs"`},
+					{Cmd: `SET lock_timeout = '5s'`},
+					{Cmd: `SET statement_timeout = '90s'`},
+					{Cmd: `CREATE INDEX CONCURRENTLY "users_id" ON "users" ("id")`, Reverse: `DROP INDEX CONCURRENTLY "users_id"`},
+					{Cmd: `SET LOCAL lock_timeout = '5s'`},
+					{Cmd: `SET LOCAL statement_timeout = '90s'`},
+					{Cmd: `DROP TABLE "posts" RESTRICT`},
+				},
+			},
 		},
+		// Describing the logical changes of each statement.
 		{
-			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}},
-							{Name: "nid", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "id+1"}}},
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(schema.NewStringColumn("email", "text"), schema.NewIntColumn("age", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{From: schema.NewNullStringColumn("email", "text"), To: users.Columns[0], Change: schema.ChangeNull},
+							&schema.AddColumn{C: users.Columns[1]},
 						},
 					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) {
+					o.Provenance = true
 				},
 			},
 			wantPlan: &migrate.Plan{
@@ -252,64 +655,177 @@ func TestPlanChanges(t *testing.T) {
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE TABLE "posts" ("id" integer NOT NULL, "nid" integer NOT NULL GENERATED ALWAYS AS (id+1) STORED)`,
-						Reverse: `DROP TABLE "posts"`,
+						Cmd:        `ALTER TABLE "users" ALTER COLUMN "email" SET NOT NULL, ADD COLUMN "age" integer NOT NULL`,
+						Reverse:    `ALTER TABLE "users" DROP COLUMN "age", ALTER COLUMN "email" DROP NOT NULL`,
+						Comment:    `modify "users" table`,
+						Provenance: []string{`modify "users".column "email": set not null`, `modify "users": add column "age"`},
+					},
+				},
+			},
+		},
+		// Changing a generation expression recreates the column and its indexes.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				posts := schema.NewTable("posts").
+					SetSchema(public).
+					AddColumns(
+						schema.NewStringColumn("title", "text"),
+						schema.NewStringColumn("slug", "text").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "lower(title)", Type: "STORED"}),
+					)
+				posts.AddIndexes(schema.NewIndex("posts_slug").AddColumns(posts.Columns[1]))
+				comments := schema.NewTable("comments").
+					SetSchema(public).
+					AddColumns(schema.NewStringColumn("post_slug", "text"))
+				comments.AddForeignKeys(schema.NewForeignKey("comments_post_slug").AddColumns(comments.Columns[0]).SetRefTable(posts).AddRefColumns(posts.Columns[1]))
+				public.AddTables(posts, comments)
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: posts,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   schema.NewStringColumn("slug", "text").SetGeneratedExpr(&schema.GeneratedExpr{Expr: "title", Type: "STORED"}),
+								To:     posts.Columns[1],
+								Change: schema.ChangeGenerated,
+							},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP INDEX "public"."posts_slug"`,
+						Reverse: `CREATE INDEX "posts_slug" ON "public"."posts" ("slug")`,
+					},
+					{
+						Cmd:      `ALTER TABLE "public"."posts" DROP COLUMN "slug", ADD COLUMN "slug" text NOT NULL GENERATED ALWAYS AS (lower(title)) STORED`,
+						Reverse:  `ALTER TABLE "public"."posts" DROP COLUMN "slug", ADD COLUMN "slug" text NOT NULL GENERATED ALWAYS AS (title) STORED`,
+						Comment:  `modify "posts" table. the values of column "slug" are recomputed using its new generation expression`,
+						Warnings: []string{`the foreign keys "comments_post_slug" that reference column "slug" must be dropped first`},
+					},
+					{
+						Cmd:     `CREATE INDEX "posts_slug" ON "public"."posts" ("slug")`,
+						Reverse: `DROP INDEX "public"."posts_slug"`,
 					},
 				},
 			},
 		},
+		// Adding an identity column to an existing table.
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						AddColumns(
-							schema.NewIntColumn("c1", "int").
-								SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id+1"}),
-						),
+					T: schema.NewTable("logs"),
 					Changes: []schema.Change{
-						&schema.ModifyColumn{
-							Change: schema.ChangeGenerated,
-							From: schema.NewIntColumn("c1", "int").
-								SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id+1"}),
-							To: schema.NewIntColumn("c1", "int"),
+						&schema.AddColumn{
+							C: schema.NewNullIntColumn("id", "bigint").
+								AddAttrs(&Identity{Generation: "ALWAYS", Sequence: &Sequence{Start: 100}}),
 						},
 					},
 				},
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    false,
+				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd: `ALTER TABLE "posts" ALTER COLUMN "c1" DROP EXPRESSION`,
+						Cmd:     `ALTER TABLE "logs" ADD COLUMN "id" bigint NOT NULL GENERATED ALWAYS AS IDENTITY (START WITH 100)`,
+						Reverse: `ALTER TABLE "logs" DROP COLUMN "id"`,
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Increment: 2}}}},
-						},
-					},
-				},
+				&schema.AddSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfNotExists{}}},
+				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{&schema.IfExists{}}},
+				&schema.DropSchema{S: schema.New("test"), Extra: []schema.Clause{}},
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    true,
+				Reversible:    false,
 				Transactional: true,
-				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (INCREMENT BY 2))`, Reverse: `DROP TABLE "posts"`}},
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE SCHEMA IF NOT EXISTS "test"`,
+						Reverse: `DROP SCHEMA "test" CASCADE`,
+					},
+					{
+						Cmd: `DROP SCHEMA IF EXISTS "test" CASCADE`,
+					},
+					{
+						Cmd: `DROP SCHEMA "test" CASCADE`,
+					},
+				},
 			},
 		},
+		func() struct {
+			changes  []schema.Change
+			options  []migrate.PlanOption
+			mock     func(mock)
+			wantPlan *migrate.Plan
+			wantErr  bool
+		} {
+			s := schema.New("public")
+			var (
+				german  = &Collation{Name: "german", Schema: s, Provider: "libc", Locale: "de_DE"}
+				nocase  = &Collation{Name: "nocase", Schema: s, Provider: "icu", Locale: "und-u-ks-l2", Nondeterministic: true}
+				posix1  = &Collation{Name: "posix", Schema: s, Provider: "libc", Locale: "C"}
+				posix2  = &Collation{Name: "posix", Schema: s, Provider: "libc", LCCollate: "C", LCCtype: "POSIX"}
+				users   = schema.NewTable("users").SetSchema(s).AddColumns(schema.NewStringColumn("name", "text").SetCollation("nocase"))
+				changes = []schema.Change{
+					&schema.DropObject{O: german},
+					&schema.ModifyObject{From: posix1, To: posix2},
+					&schema.AddObject{O: nocase},
+					&schema.AddTable{T: users},
+				}
+			)
+			return struct {
+				changes  []schema.Change
+				options  []migrate.PlanOption
+				mock     func(mock)
+				wantPlan *migrate.Plan
+				wantErr  bool
+			}{
+				changes: changes,
+				wantPlan: &migrate.Plan{
+					Reversible:    true,
+					Transactional: true,
+					Changes: []*migrate.Change{
+						{
+							Cmd:     `DROP COLLATION "public"."posix"`,
+							Reverse: `CREATE COLLATION "public"."posix" (provider = libc, locale = 'C')`,
+						},
+						{
+							Cmd:     `CREATE COLLATION "public"."posix" (provider = libc, lc_collate = 'C', lc_ctype = 'POSIX')`,
+							Reverse: `DROP COLLATION "public"."posix"`,
+						},
+						{
+							Cmd:     `CREATE COLLATION "public"."nocase" (provider = icu, locale = 'und-u-ks-l2', deterministic = false)`,
+							Reverse: `DROP COLLATION "public"."nocase"`,
+						},
+						{
+							Cmd:     `CREATE TABLE "public"."users" ("name" text NOT NULL COLLATE "nocase")`,
+							Reverse: `DROP TABLE "public"."users"`,
+						},
+						{
+							Cmd:     `DROP COLLATION "public"."german"`,
+							Reverse: `CREATE COLLATION "public"."german" (provider = libc, locale = 'de_DE')`,
+						},
+					},
+				},
+			}
+		}(),
 		{
 			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 100, Increment: 2}}}},
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 1, Last: 10, Min: i64(1), Max: i64(2147483647)}}),
+							To:     schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Max: i64(1000)}}),
+							Change: schema.ChangeAttr,
 						},
 					},
 				},
@@ -317,190 +833,407 @@ func TestPlanChanges(t *testing.T) {
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
-				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 2))`, Reverse: `DROP TABLE "posts"`}},
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 1 SET INCREMENT BY 1 SET MAXVALUE 1000`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 1 SET INCREMENT BY 1 SET NO MAXVALUE`,
+					},
+				},
 			},
 		},
+		// Unsafe changes are annotated with the reason.
 		{
 			changes: []schema.Change{
-				&schema.DropTable{T: &schema.Table{Name: "posts"}},
+				&schema.DropTable{T: schema.NewTable("logs").SetSchema(schema.New("public"))},
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+						&schema.AddColumn{C: schema.NewIntColumn("rank", "int").SetDefault(&schema.RawExpr{X: "0"})},
+						&schema.DropColumn{C: schema.NewStringColumn("bio", "text")},
+						&schema.ModifyColumn{
+							From:   schema.NewNullStringColumn("name", "text"),
+							To:     schema.NewStringColumn("name", "text"),
+							Change: schema.ChangeNull,
+						},
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SafetyWarnings = true },
 			},
+			mock: func(m mock) { m.noDependents("public", "logs") },
 			wantPlan: &migrate.Plan{
 				Reversible:    false,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `DROP TABLE "posts"`},
+					{
+						Cmd:      `DROP TABLE "public"."logs" RESTRICT`,
+						Comment:  `drop "logs" table`,
+						Warnings: []string{`drops table "logs" along with all of its rows`},
+					},
+					{
+						Cmd:      `ALTER TABLE "public"."users" ADD COLUMN "age" integer NOT NULL, ADD COLUMN "rank" integer NOT NULL DEFAULT 0, DROP COLUMN "bio", ALTER COLUMN "name" SET NOT NULL`,
+						Reverse:  `ALTER TABLE "public"."users" ALTER COLUMN "name" DROP NOT NULL, ADD COLUMN "bio" text NOT NULL, DROP COLUMN "rank", DROP COLUMN "age"`,
+						Comment:  `modify "users" table`,
+						Warnings: []string{`adds NOT NULL column "age" without a default value, fails if the table has rows`, `drops column "bio" along with its data`, `adds NOT NULL to column "name", fails if rows contain NULL values`},
+					},
 				},
 			},
 		},
+		// Indexes are rebuilt after changing the collation of their columns.
 		{
-			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-						},
-					}
-					return &schema.ModifyTable{
+			changes: func() []schema.Change {
+				from := schema.NewStringColumn("name", "text")
+				to := schema.NewStringColumn("name", "text").SetCollation("C")
+				users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(to)
+				users.AddIndexes(schema.NewIndex("users_name").AddColumns(to))
+				return []schema.Change{
+					&schema.ModifyTable{
 						T: users,
 						Changes: []schema.Change{
-							&schema.AddColumn{
-								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}, Default: &schema.Literal{V: "'logged_in'"}},
-							},
-							&schema.AddColumn{
-								C: &schema.Column{Name: "last", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Comment{Text: "bar"}}, Default: &schema.RawExpr{X: "'logged_in'"}},
-							},
-							&schema.AddIndex{
-								I: &schema.Index{
-									Name: "id_key",
-									Parts: []*schema.IndexPart{
-										{C: users.Columns[0], Desc: true},
-									},
-									Attrs: []schema.Attr{
-										&schema.Comment{Text: "comment"},
-										&IndexPredicate{P: "success"},
-									},
-								},
-							},
-							&schema.AddIndex{
-								I: &schema.Index{
-									Name: "id_brin",
-									Parts: []*schema.IndexPart{
-										{C: users.Columns[0], Desc: true},
-									},
-									Attrs: []schema.Attr{
-										&IndexType{T: IndexTypeBRIN},
-										&IndexStorageParams{PagesPerRange: 2},
-									},
-								},
-							},
-							&schema.AddCheck{
-								C: &schema.Check{Name: "name_not_empty", Expr: `("name" <> '')`},
-							},
-							&schema.DropCheck{
-								C: &schema.Check{Name: "id_nonzero", Expr: `("id" <> 0)`},
-							},
-							&schema.ModifyCheck{
-								From: &schema.Check{Name: "id_iseven", Expr: `("id" % 2 = 0)`},
-								To:   &schema.Check{Name: "id_iseven", Expr: `(("id") % 2 = 0)`},
-							},
-							&schema.AddIndex{
-								I: &schema.Index{
-									Name: "include_key",
-									Parts: []*schema.IndexPart{
-										{C: users.Columns[0]},
-									},
-									Attrs: []schema.Attr{
-										&IndexInclude{Columns: []*schema.Column{schema.NewColumn("a"), schema.NewColumn("b")}},
-									},
-								},
-							},
-							&schema.AddIndex{
-								I: &schema.Index{
-									Name: "concurrently",
-									Parts: []*schema.IndexPart{
-										{C: users.Columns[0]},
-									},
-									Attrs: []schema.Attr{
-										&Concurrently{},
-									},
-								},
-							},
-							&schema.AddIndex{
-								I: &schema.Index{
-									Name: "operator_class",
-									Parts: []*schema.IndexPart{
-										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_bloom_ops"}}},
-										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_minmax_ops"}}},
-										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_minmax_multi_ops", Params: []struct{ N, V string }{{"values_per_range", "8"}}}}},
-									},
-									Attrs: []schema.Attr{
-										&IndexType{T: IndexTypeBRIN},
-									},
-								},
-							},
+							&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeCollate},
 						},
-					}
-				}(),
-			},
+					},
+				}
+			}(),
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "users" ADD COLUMN "name" character varying(255) NOT NULL DEFAULT 'logged_in', ADD COLUMN "last" character varying(255) NOT NULL DEFAULT 'logged_in', ADD CONSTRAINT "name_not_empty" CHECK ("name" <> ''), DROP CONSTRAINT "id_nonzero", DROP CONSTRAINT "id_iseven", ADD CONSTRAINT "id_iseven" CHECK (("id") % 2 = 0)`,
-						Reverse: `ALTER TABLE "users" DROP CONSTRAINT "id_iseven", ADD CONSTRAINT "id_iseven" CHECK ("id" % 2 = 0), ADD CONSTRAINT "id_nonzero" CHECK ("id" <> 0), DROP CONSTRAINT "name_not_empty", DROP COLUMN "last", DROP COLUMN "name"`,
+						Cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE text COLLATE "C"`,
+						Reverse: `ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE text`,
 					},
 					{
-						Cmd:     `CREATE INDEX "id_key" ON "users" ("id" DESC) WHERE success`,
-						Reverse: `DROP INDEX "id_key"`,
+						Cmd:     `REINDEX INDEX "public"."users_name"`,
+						Comment: `rebuild index "users_name" after changing the collation of column "name"`,
+						Reverse: `REINDEX INDEX "public"."users_name"`,
 					},
-					{
-						Cmd:     `CREATE INDEX "id_brin" ON "users" USING BRIN ("id" DESC) WITH (pages_per_range = 2)`,
-						Reverse: `DROP INDEX "id_brin"`,
+				},
+			},
+		},
+		// Statements that cannot be guarded with IF NOT EXISTS are wrapped in DO blocks.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))
+				posts := schema.NewTable("posts").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("author_id", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: posts,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+							&schema.AddForeignKey{F: schema.NewForeignKey("author").SetTable(posts).AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])},
+							&schema.AddCheck{C: schema.NewCheck().SetName("positive").SetExpr("author_id > 0")},
+						},
 					},
-					{
-						Cmd:     `CREATE INDEX "include_key" ON "users" ("id") INCLUDE ("a", "b")`,
-						Reverse: `DROP INDEX "include_key"`,
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddCheck{C: schema.NewCheck().SetExpr("id > 0")},
+						},
 					},
+					&schema.AddObject{O: &Cast{Source: "text", Target: "mood", InOut: true}},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.IdempotentDDL = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE INDEX CONCURRENTLY "concurrently" ON "users" ("id")`,
-						Reverse: `DROP INDEX CONCURRENTLY "concurrently"`,
+						Cmd: `ALTER TABLE "public"."users" ADD CHECK (id > 0)`,
 					},
+					// Constraints are added in their own statements, and
+					// other actions are not skipped in case they exist.
 					{
-						Cmd:     `CREATE INDEX "operator_class" ON "users" USING BRIN ("id" int8_bloom_ops, "id", "id" int8_minmax_multi_ops(values_per_range=8))`,
-						Reverse: `DROP INDEX "operator_class"`,
+						Cmd:     `ALTER TABLE "public"."posts" ADD COLUMN "age" integer NOT NULL`,
+						Reverse: `ALTER TABLE "public"."posts" DROP COLUMN "age"`,
 					},
 					{
-						Cmd:     `COMMENT ON COLUMN "users" ."name" IS 'foo'`,
-						Reverse: `COMMENT ON COLUMN "users" ."name" IS ''`,
+						Cmd:     `DO $$ BEGIN ALTER TABLE "public"."posts" ADD CONSTRAINT "author" FOREIGN KEY ("author_id") REFERENCES "public"."users" ("id"); EXCEPTION WHEN duplicate_object OR duplicate_table THEN NULL; END $$`,
+						Reverse: `ALTER TABLE "public"."posts" DROP CONSTRAINT "author"`,
 					},
 					{
-						Cmd:     `COMMENT ON COLUMN "users" ."last" IS 'bar'`,
-						Reverse: `COMMENT ON COLUMN "users" ."last" IS ''`,
+						Cmd:     `DO $$ BEGIN ALTER TABLE "public"."posts" ADD CONSTRAINT "positive" CHECK (author_id > 0); EXCEPTION WHEN duplicate_object OR duplicate_table THEN NULL; END $$`,
+						Reverse: `ALTER TABLE "public"."posts" DROP CONSTRAINT "positive"`,
 					},
 					{
-						Cmd:     `COMMENT ON INDEX "id_key" IS 'comment'`,
-						Reverse: `COMMENT ON INDEX "id_key" IS ''`,
+						Cmd:     `DO $$ BEGIN CREATE CAST (text AS mood) WITH INOUT; EXCEPTION WHEN duplicate_object OR duplicate_table THEN NULL; END $$`,
+						Reverse: `DROP CAST (text AS mood)`,
 					},
 				},
 			},
 		},
+		// Casts are dropped before, and created after the table changes.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-						},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.DropColumn{
-								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar"}}},
-							},
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{}, &schema.Comment{Text: "comment"}}},
-								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
-								Change: schema.ChangeAttr | schema.ChangeComment,
-							},
-						},
-					}
-				}(),
+				&schema.AddObject{O: &Cast{Source: "text", Target: "mood", InOut: true}},
+				&schema.ModifyObject{
+					From: &Cast{Source: "integer", Target: "mood", Func: "int_mood(integer)"},
+					To:   &Cast{Source: "integer", Target: "mood", Func: "int_mood(integer)", Context: "IMPLICIT"},
+				},
+				&schema.DropObject{O: &Cast{Source: "mood", Target: "text", Func: "mood_text(mood)", Context: "ASSIGNMENT"}},
+				&schema.AddTable{T: schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int"))},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "users" DROP COLUMN "name", ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1 RESTART`,
-						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 RESTART, ADD COLUMN "name" character varying NOT NULL`,
+						Cmd:     `DROP CAST (integer AS mood)`,
+						Comment: `drop cast (integer AS mood)`,
+						Reverse: `CREATE CAST (integer AS mood) WITH FUNCTION int_mood(integer)`,
 					},
 					{
-						Cmd:     `COMMENT ON COLUMN "users" ."id" IS ''`,
-						Reverse: `COMMENT ON COLUMN "users" ."id" IS 'comment'`,
+						Cmd:     `DROP CAST (mood AS text)`,
+						Reverse: `CREATE CAST (mood AS text) WITH FUNCTION mood_text(mood) AS ASSIGNMENT`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."users" ("id" integer NOT NULL)`,
+						Reverse: `DROP TABLE "public"."users"`,
+					},
+					{
+						Cmd:     `CREATE CAST (text AS mood) WITH INOUT`,
+						Comment: `create cast (text AS mood)`,
+						Reverse: `DROP CAST (text AS mood)`,
+					},
+					{
+						Cmd:     `CREATE CAST (integer AS mood) WITH FUNCTION int_mood(integer) AS IMPLICIT`,
+						Reverse: `DROP CAST (integer AS mood)`,
+					},
+				},
+			},
+		},
+		// Access methods are created before, and dropped after the table changes.
+		{
+			changes: func() []schema.Change {
+				id := schema.NewIntColumn("id", "int")
+				users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id)
+				users.AddIndexes(schema.NewIndex("users_id").AddColumns(id).AddAttrs(&IndexType{T: "bloom"}))
+				return []schema.Change{
+					&schema.DropObject{O: &AccessMethod{Name: "heap2", Type: "TABLE", Handler: "heap_tableam_handler"}},
+					&schema.AddObject{O: &AccessMethod{Name: "bloom", Type: "INDEX", Handler: "blhandler"}},
+					&schema.AddTable{T: users},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE ACCESS METHOD "bloom" TYPE INDEX HANDLER blhandler`,
+						Comment: `create "bloom" access method`,
+						Reverse: `DROP ACCESS METHOD "bloom"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."users" ("id" integer NOT NULL)`,
+						Reverse: `DROP TABLE "public"."users"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "users_id" ON "public"."users" USING bloom ("id")`,
+						Reverse: `DROP INDEX "public"."users_id"`,
+					},
+					{
+						Cmd:     `DROP ACCESS METHOD "heap2"`,
+						Comment: `drop "heap2" access method`,
+						Reverse: `CREATE ACCESS METHOD "heap2" TYPE TABLE HANDLER heap_tableam_handler`,
+					},
+				},
+			},
+		},
+		// UNIQUE constraints built concurrently are promoted from their indexes.
+		{
+			changes: func() []schema.Change {
+				email := schema.NewStringColumn("email", "text")
+				users := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(email)
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewUniqueIndex("users_email_idx").AddColumns(email).AddAttrs(&Concurrently{}, &Constraint{N: "users_email_key", T: "u"})},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE UNIQUE INDEX CONCURRENTLY "users_email_idx" ON "public"."users" ("email")`,
+						Reverse: `DROP INDEX CONCURRENTLY IF EXISTS "public"."users_email_idx"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ADD CONSTRAINT "users_email_key" UNIQUE USING INDEX "users_email_idx"`,
+						Comment: `promote index "users_email_idx" to a unique constraint of table "users"`,
+						Reverse: `ALTER TABLE "public"."users" DROP CONSTRAINT "users_email_key"`,
+					},
+				},
+			},
+		},
+		// Adding a column with a volatile default rewrites the table.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewColumn("uid").SetType(&UUIDType{T: "uuid"}).SetDefault(&schema.RawExpr{X: "gen_random_uuid()"})},
+						&schema.AddColumn{C: schema.NewColumn("created_at").SetType(&schema.TimeType{T: "timestamp with time zone"}).SetDefault(&schema.RawExpr{X: "now()"})},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "public"."users" ADD COLUMN "uid" uuid NOT NULL DEFAULT gen_random_uuid(), ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT now()`,
+						Reverse:  `ALTER TABLE "public"."users" DROP COLUMN "created_at", DROP COLUMN "uid"`,
+						Comment:  `modify "users" table`,
+						Warnings: []string{`adding column "uid" with a volatile default rewrites the table. To avoid it, add the column without a default, backfill the existing rows in batches, and then set its default and NOT NULL`},
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewColumn("uid").SetType(&UUIDType{T: "uuid"}).SetDefault(&schema.RawExpr{X: "gen_random_uuid()"})},
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.BackfillDefaults = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "public"."users" ADD COLUMN "uid" uuid NULL`,
+						Reverse: `ALTER TABLE "public"."users" DROP COLUMN "uid"`,
+					},
+					{
+						Cmd:     `UPDATE "public"."users" SET "uid" = gen_random_uuid() WHERE "uid" IS NULL`,
+						Comment: `backfill column "uid" of table "users". On large tables, consider running it in batches`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "uid" SET NOT NULL, ALTER COLUMN "uid" SET DEFAULT gen_random_uuid()`,
+						Reverse: `ALTER TABLE "public"."users" ALTER COLUMN "uid" DROP NOT NULL, ALTER COLUMN "uid" DROP DEFAULT`,
+					},
+				},
+			},
+		},
+		// Logical replication publications and subscriptions.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public").AddTables(schema.NewTable("users"), schema.NewTable("orders"), schema.NewTable("items"))
+				return []schema.Change{
+					&schema.ModifyObject{
+						From: &Publication{Name: "app", Tables: []*schema.Table{public.Tables[0], public.Tables[1]}},
+						To:   &Publication{Name: "app", Tables: []*schema.Table{public.Tables[0], public.Tables[2]}, Publish: []string{"insert"}},
+					},
+					&schema.AddObject{O: &Subscription{Name: "replica", Conn: "host=primary user=rep password=s3cr3t", Publications: []string{"app"}, Options: []struct{ N, V string }{{N: "copy_data", V: "false"}}}},
+					&schema.ModifyObject{
+						From: &Subscription{Name: "legacy", Publications: []string{"a"}, Options: []struct{ N, V string }{{N: "synchronous_commit", V: "off"}}},
+						To:   &Subscription{Name: "legacy", Publications: []string{"a", "b"}, Options: []struct{ N, V string }{{N: "synchronous_commit", V: "local"}}, Disabled: true},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.RedactSecrets = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER SUBSCRIPTION "legacy" SET PUBLICATION "a", "b"`,
+						Comment: `change the publications of "legacy" subscription`,
+						Reverse: `ALTER SUBSCRIPTION "legacy" SET PUBLICATION "a"`,
+					},
+					{
+						Cmd:     `ALTER SUBSCRIPTION "legacy" SET (synchronous_commit = 'local')`,
+						Comment: `change the options of "legacy" subscription`,
+						Reverse: `ALTER SUBSCRIPTION "legacy" SET (synchronous_commit = off)`,
+					},
+					{
+						Cmd:     `ALTER SUBSCRIPTION "legacy" DISABLE`,
+						Comment: `change the state of "legacy" subscription`,
+						Reverse: `ALTER SUBSCRIPTION "legacy" ENABLE`,
+					},
+					{
+						Cmd:     `ALTER PUBLICATION "app" ADD TABLE "public"."items"`,
+						Comment: `add tables to "app" publication`,
+						Reverse: `ALTER PUBLICATION "app" DROP TABLE "public"."items"`,
+					},
+					{
+						Cmd:     `ALTER PUBLICATION "app" DROP TABLE "public"."orders"`,
+						Comment: `drop tables from "app" publication`,
+						Reverse: `ALTER PUBLICATION "app" ADD TABLE "public"."orders"`,
+					},
+					{
+						Cmd:     `ALTER PUBLICATION "app" SET (publish = 'insert', publish_via_partition_root = false)`,
+						Comment: `change the parameters of "app" publication`,
+						Reverse: `ALTER PUBLICATION "app" SET (publish = 'insert, update, delete, truncate', publish_via_partition_root = false)`,
+					},
+					{
+						Cmd:     `CREATE SUBSCRIPTION "replica" CONNECTION 'host=primary user=rep password=********' PUBLICATION "app" WITH (copy_data = false)`,
+						Comment: `create "replica" subscription`,
+						Reverse: `DROP SUBSCRIPTION "replica"`,
+					},
+				},
+			},
+		},
+		// Checks added to an inheritance parent are propagated to its children.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("products").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddCheck{C: schema.NewCheck().SetName("positive_price").SetExpr("price > 0")},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("books").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddCheck{C: schema.NewCheck().SetName("positive_price").SetExpr("(price > 0)")},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("prices").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddCheck{C: schema.NewCheck().SetName("positive_price").SetExpr("price > 0")},
+					},
+				},
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape(inheritsQuery)).
+					WithArgs(`"public"."products"`).
+					WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname"}))
+				m.ExpectQuery(sqltest.Escape(inheritsQuery)).
+					WithArgs(`"public"."books"`).
+					WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname"}).AddRow("public", "products"))
+				m.ExpectQuery(sqltest.Escape(inheritsQuery)).
+					WithArgs(`"public"."prices"`).
+					WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname"}))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "public"."products" ADD CONSTRAINT "positive_price" CHECK (price > 0)`,
+						Reverse: `ALTER TABLE "public"."products" DROP CONSTRAINT "positive_price"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."prices" ADD CONSTRAINT "positive_price" CHECK (price > 0)`,
+						Reverse: `ALTER TABLE "public"."prices" DROP CONSTRAINT "positive_price"`,
 					},
 				},
 			},
@@ -514,16 +1247,32 @@ func TestPlanChanges(t *testing.T) {
 							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
 						},
 					}
+					pets := &schema.Table{
+						Name: "pets",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							{Name: "user_id",
+								Type: &schema.ColumnType{
+									Type: &schema.IntegerType{T: "bigint"},
+								},
+							},
+						},
+					}
+					fk := &schema.ForeignKey{
+						Symbol:     "pets_user_id_fkey",
+						Table:      pets,
+						OnUpdate:   schema.NoAction,
+						OnDelete:   schema.Cascade,
+						RefTable:   users,
+						Columns:    []*schema.Column{pets.Columns[1]},
+						RefColumns: []*schema.Column{users.Columns[0]},
+					}
+					pets.ForeignKeys = []*schema.ForeignKey{fk}
 					return &schema.ModifyTable{
-						T: users,
+						T: pets,
 						Changes: []schema.Change{
-							&schema.DropColumn{
-								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar"}}},
-							},
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 0, Last: 1025}}}},
-								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
-								Change: schema.ChangeAttr,
+							&schema.DropForeignKey{
+								F: fk,
 							},
 						},
 					}
@@ -534,39 +1283,177 @@ func TestPlanChanges(t *testing.T) {
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "users" DROP COLUMN "name", ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1`,
-						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1, ADD COLUMN "name" character varying NOT NULL`,
+						Cmd:     `ALTER TABLE "pets" DROP CONSTRAINT "pets_user_id_fkey"`,
+						Reverse: `ALTER TABLE "pets" ADD CONSTRAINT "pets_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE`,
 					},
 				},
 			},
 		},
-		{
-			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}},
-					Changes: []schema.Change{
-						&schema.AddAttr{
-							A: &schema.Comment{Text: "foo"},
+		func() struct {
+			changes  []schema.Change
+			options  []migrate.PlanOption
+			mock     func(mock)
+			wantPlan *migrate.Plan
+			wantErr  bool
+		} {
+			users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+			pets := schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("owner_id", "bigint"))
+			toys := schema.NewTable("toys").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("pet_id", "bigint"))
+			return struct {
+				changes  []schema.Change
+				options  []migrate.PlanOption
+				mock     func(mock)
+				wantPlan *migrate.Plan
+				wantErr  bool
+			}{
+				changes: []schema.Change{
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.AddForeignKey{
+								F:     schema.NewForeignKey("pets_owner_id_fkey").SetTable(pets).AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]),
+								Extra: []schema.Clause{&NotValid{Validate: true}},
+							},
+							&schema.AddCheck{
+								C:     schema.NewCheck().SetName("pets_owner_id_check").SetExpr("owner_id > 0"),
+								Extra: []schema.Clause{&NotValid{}},
+							},
+						},
+					},
+					&schema.ModifyTable{
+						T: toys,
+						Changes: []schema.Change{
+							&schema.AddForeignKey{
+								F:     schema.NewForeignKey("toys_pet_id_fkey").SetTable(toys).AddColumns(toys.Columns[1]).SetRefTable(pets).AddRefColumns(pets.Columns[0]),
+								Extra: []schema.Clause{&NotValid{Validate: true}},
+							},
+						},
+					},
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+						},
+					},
+				},
+				wantPlan: &migrate.Plan{
+					Reversible:    false,
+					Transactional: true,
+					Changes: []*migrate.Change{
+						{
+							Cmd:     `ALTER TABLE "users" ADD COLUMN "age" integer NOT NULL`,
+							Reverse: `ALTER TABLE "users" DROP COLUMN "age"`,
+						},
+						{
+							Cmd:     `ALTER TABLE "pets" ADD CONSTRAINT "pets_owner_id_fkey" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") NOT VALID, ADD CONSTRAINT "pets_owner_id_check" CHECK (owner_id > 0) NOT VALID`,
+							Reverse: `ALTER TABLE "pets" DROP CONSTRAINT "pets_owner_id_check", DROP CONSTRAINT "pets_owner_id_fkey"`,
+						},
+						{
+							Cmd:     `ALTER TABLE "toys" ADD CONSTRAINT "toys_pet_id_fkey" FOREIGN KEY ("pet_id") REFERENCES "pets" ("id") NOT VALID`,
+							Reverse: `ALTER TABLE "toys" DROP CONSTRAINT "toys_pet_id_fkey"`,
+						},
+						{
+							Cmd:     `ALTER TABLE "pets" VALIDATE CONSTRAINT "pets_owner_id_fkey"`,
+							Comment: `validate "pets_owner_id_fkey" constraint of table "pets"`,
+						},
+						{
+							Cmd:     `ALTER TABLE "toys" VALIDATE CONSTRAINT "toys_pet_id_fkey"`,
+							Comment: `validate "toys_pet_id_fkey" constraint of table "toys"`,
+						},
+					},
+				},
+			}
+		}(),
+		func() struct {
+			changes  []schema.Change
+			options  []migrate.PlanOption
+			mock     func(mock)
+			wantPlan *migrate.Plan
+			wantErr  bool
+		} {
+			users := schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewNullIntColumn("deleted_at", "bigint"))
+			from := schema.NewUniqueIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&IndexPredicate{P: "deleted_at IS NULL"})
+			to := schema.NewUniqueIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&IndexPredicate{P: "deleted_at IS NULL"}, &IndexNullsDistinct{})
+			return struct {
+				changes  []schema.Change
+				options  []migrate.PlanOption
+				mock     func(mock)
+				wantPlan *migrate.Plan
+				wantErr  bool
+			}{
+				changes: []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyIndex{From: from, To: to, Change: schema.ChangeAttr},
 						},
 					},
 				},
+				wantPlan: &migrate.Plan{
+					Reversible:    true,
+					Transactional: true,
+					Changes: []*migrate.Change{
+						{
+							Cmd:     `DROP INDEX "users_id"`,
+							Reverse: `CREATE UNIQUE INDEX "users_id" ON "users" ("id") WHERE deleted_at IS NULL`,
+						},
+						{
+							Cmd:     `CREATE UNIQUE INDEX "users_id" ON "users" ("id") NULLS NOT DISTINCT WHERE deleted_at IS NULL`,
+							Reverse: `DROP INDEX "users_id"`,
+						},
+					},
+				},
+			}
+		}(),
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("logs").
+						AddColumns(schema.NewIntColumn("id", "bigint")).
+						AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "70"}, {"oids", "false"}}}),
+				},
+				&schema.AddTable{
+					T: schema.NewTable("events").
+						AddColumns(schema.NewIntColumn("id", "bigint")).
+						AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"oids", "true"}}}),
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `COMMENT ON TABLE "public"."users" IS 'foo'`, Reverse: `COMMENT ON TABLE "public"."users" IS ''`},
+					{
+						Cmd:     `CREATE TABLE "logs" ("id" bigint NOT NULL) WITH (fillfactor = 70)`,
+						Reverse: `DROP TABLE "logs"`,
+						Comment: `create "logs" table`,
+					},
+					{
+						Cmd:      `CREATE TABLE "events" ("id" bigint NOT NULL)`,
+						Reverse:  `DROP TABLE "events"`,
+						Comment:  `create "events" table`,
+						Warnings: []string{`WITH OIDS is not supported by PostgreSQL 12 and above`},
+					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
-					T: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}},
+					T: schema.NewTable("logs"),
 					Changes: []schema.Change{
 						&schema.ModifyAttr{
-							To:   &schema.Comment{Text: "foo"},
-							From: &schema.Comment{Text: "bar"},
+							From: &TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "70"}, {"oids", "true"}}},
+							To:   &TableStorageParams{Params: []struct{ N, V string }{{"fillfactor", "80"}}},
+						},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("events"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &TableStorageParams{Params: []struct{ N, V string }{{"oids", "false"}}},
+							To:   &TableStorageParams{Params: []struct{ N, V string }{{"oids", "true"}}},
 						},
 					},
 				},
@@ -575,431 +1462,1987 @@ func TestPlanChanges(t *testing.T) {
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `COMMENT ON TABLE "public"."users" IS 'foo'`, Reverse: `COMMENT ON TABLE "public"."users" IS 'bar'`},
+					{
+						Cmd:      `ALTER TABLE "logs" SET (fillfactor = 80)`,
+						Reverse:  `ALTER TABLE "logs" SET (fillfactor = 70)`,
+						Comment:  `modify "logs" table storage parameters`,
+						Warnings: []string{`OID changes were skipped as they are not supported by PostgreSQL 12 and above`},
+					},
 				},
 			},
 		},
+		func() struct {
+			changes  []schema.Change
+			options  []migrate.PlanOption
+			mock     func(mock)
+			wantPlan *migrate.Plan
+			wantErr  bool
+		} {
+			users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+			pets := schema.NewTable("pets").AddColumns(
+				schema.NewIntColumn("id", "bigint"),
+				schema.NewNullIntColumn("owner_id", "bigint"),
+				schema.NewIntColumn("vet_id", "bigint").SetDefault(&schema.RawExpr{X: "0"}),
+			)
+			pets.AddForeignKeys(
+				schema.NewForeignKey("pets_owner_id_fkey").AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.SetDefault),
+				schema.NewForeignKey("pets_vet_id_fkey").AddColumns(pets.Columns[2]).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.SetDefault),
+			)
+			return struct {
+				changes  []schema.Change
+				options  []migrate.PlanOption
+				mock     func(mock)
+				wantPlan *migrate.Plan
+				wantErr  bool
+			}{
+				changes: []schema.Change{
+					&schema.AddTable{T: pets},
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   schema.NewIntColumn("vet_id", "bigint").SetDefault(&schema.RawExpr{X: "1"}),
+								To:     pets.Columns[2],
+								Change: schema.ChangeDefault,
+							},
+						},
+					},
+				},
+				wantPlan: &migrate.Plan{
+					Reversible:    true,
+					Transactional: true,
+					Changes: []*migrate.Change{
+						{
+							Cmd:      `CREATE TABLE "pets" ("id" bigint NOT NULL, "owner_id" bigint NULL, "vet_id" bigint NOT NULL DEFAULT 0, CONSTRAINT "pets_owner_id_fkey" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") ON DELETE SET DEFAULT, CONSTRAINT "pets_vet_id_fkey" FOREIGN KEY ("vet_id") REFERENCES "users" ("id") ON DELETE SET DEFAULT)`,
+							Reverse:  `DROP TABLE "pets"`,
+							Comment:  `create "pets" table`,
+							Warnings: []string{`foreign key column(s) "pets_owner_id_fkey"."owner_id" use SET DEFAULT but have no default value`},
+						},
+						{
+							Cmd:     `ALTER TABLE "pets" ALTER COLUMN "vet_id" SET DEFAULT 0`,
+							Reverse: `ALTER TABLE "pets" ALTER COLUMN "vet_id" SET DEFAULT 1`,
+							Comment: `modify "pets" table`,
+						},
+					},
+				},
+			}
+		}(),
 		{
 			changes: []schema.Change{
 				func() schema.Change {
-					users := schema.NewTable("users").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
-							schema.NewEnumColumn("status", schema.EnumName("status"), schema.EnumValues("a", "b"), schema.EnumSchema(schema.New("test"))),
-						)
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}}}},
+					}
 					return &schema.ModifyTable{
 						T: users,
 						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
-								To:     users.Columns[0],
-								Change: schema.ChangeType,
+							&schema.DropIndex{
+								I: schema.NewIndex("name_index").
+									AddParts(schema.NewColumnPart(schema.NewColumn("name"))),
 							},
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "status", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
-								To:     users.Columns[1],
-								Change: schema.ChangeType,
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP INDEX "name_index"`,
+						Reverse: `CREATE INDEX "name_index" ON "users" ("name")`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							{Name: "nickname", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}}}},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.DropIndex{
+								I: schema.NewUniqueIndex("unique_nickname").
+									AddColumns(schema.NewColumn("nickname")).
+									AddAttrs(&Constraint{T: "u"}),
 							},
-							&schema.DropColumn{
-								C: schema.NewEnumColumn("dc1", schema.EnumName("de"), schema.EnumValues("on")),
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" DROP CONSTRAINT "unique_nickname"`,
+						Reverse: `ALTER TABLE "users" ADD CONSTRAINT "unique_nickname" UNIQUE ("nickname")`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := schema.NewTable("users").
+						AddColumns(
+							schema.NewIntColumn("id", "bigint"),
+							schema.NewStringColumn("nickname", "varchar(255)"),
+							schema.NewTimeColumn("during", "tstzrange"),
+						)
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyIndex{
+								From: schema.NewUniqueIndex("users_nickname_key").
+									AddColumns(users.Columns[1]).
+									AddAttrs(&Constraint{N: "users_nickname_key", T: "u"}),
+								To: schema.NewUniqueIndex("users_nickname_key").
+									AddColumns(users.Columns[1], users.Columns[0]),
+								Change: schema.ChangeParts,
 							},
-							&schema.DropColumn{
-								C: schema.NewEnumColumn("dc2", schema.EnumName("de"), schema.EnumValues("on")),
+							&schema.DropIndex{
+								I: schema.NewIndex("users_during_excl").
+									AddColumns(users.Columns[2]).
+									AddAttrs(&IndexType{T: "gist"}, &Constraint{N: "users_during_excl", T: "x"}),
 							},
 						},
 					}
 				}(),
 			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd: `ALTER TABLE "users" DROP CONSTRAINT "users_nickname_key", DROP CONSTRAINT "users_during_excl"`,
+					},
+					{
+						Cmd:     `CREATE UNIQUE INDEX "users_nickname_key" ON "users" ("nickname", "id")`,
+						Reverse: `DROP INDEX "users_nickname_key"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddSchema{S: &schema.Schema{Name: "test"}},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `CREATE SCHEMA "test"`, Reverse: `DROP SCHEMA "test" CASCADE`}}},
+		},
+		{
+			changes: []schema.Change{
+				&schema.DropSchema{S: &schema.Schema{Name: "atlas"}},
+			},
+			wantPlan: &migrate.Plan{
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `DROP SCHEMA "atlas" CASCADE`}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{}, &schema.Comment{}}},
+							{Name: "text", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}, Null: true}},
+							{Name: "directions", Type: &schema.ColumnType{Type: &ArrayType{T: "direction[]", Type: &schema.EnumType{T: "direction", Values: []string{"NORTH", "SOUTH"}, Schema: schema.New("public")}}}},
+							{Name: "states", Type: &schema.ColumnType{Type: &ArrayType{T: "state[]", Type: &schema.EnumType{T: "state", Values: []string{"ON", "OFF"}}}}},
+						},
+						Attrs: []schema.Attr{
+							&schema.Comment{},
+							&schema.Check{Name: "id_nonzero", Expr: `("id" > 0)`},
+							&schema.Check{Name: "text_len", Expr: `(length("text") > 0)`, Attrs: []schema.Attr{&NoInherit{}}},
+							&schema.Check{Name: "a_in_b", Expr: `(a) in (b)`},
+							&Partition{T: "HASH", Parts: []*PartitionPart{{C: schema.NewColumn("text")}}},
+						},
+					},
+				},
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2")).
+					WithArgs("direction", "public").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'")).
+					WithArgs("state").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("state"))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TYPE "public"."direction" AS ENUM ('NORTH', 'SOUTH')`, Reverse: `DROP TYPE "public"."direction"`},
+					{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY, "text" text NULL, "directions" "public"."direction"[] NOT NULL, "states" "state"[] NOT NULL, CONSTRAINT "id_nonzero" CHECK ("id" > 0), CONSTRAINT "text_len" CHECK (length("text") > 0) NO INHERIT, CONSTRAINT "a_in_b" CHECK ((a) in (b))) PARTITION BY HASH ("text")`, Reverse: `DROP TABLE "posts"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("blog")).
+						AddColumns(
+							schema.NewIntColumn("id", "int"),
+							schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy", "sad"), schema.EnumSchema(schema.New("types"))),
+						),
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.CreateSchemas = true },
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape("SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1")).
+					WithArgs("blog").
+					WillReturnRows(sqlmock.NewRows([]string{"1"}))
+				m.ExpectQuery(sqltest.Escape("SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1")).
+					WithArgs("types").
+					WillReturnRows(sqlmock.NewRows([]string{"1"}))
+				m.ExpectQuery(sqltest.Escape("SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1")).
+					WithArgs("public").
+					WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2")).
+					WithArgs("mood", "types").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE SCHEMA IF NOT EXISTS "blog"`, Reverse: `DROP SCHEMA "blog" CASCADE`},
+					{Cmd: `CREATE SCHEMA IF NOT EXISTS "types"`, Reverse: `DROP SCHEMA "types" CASCADE`},
+					{Cmd: `CREATE TYPE "types"."mood" AS ENUM ('happy', 'sad')`, Reverse: `DROP TYPE "types"."mood"`},
+					{Cmd: `CREATE TABLE "blog"."posts" ("id" integer NOT NULL, "mood" "types"."mood" NOT NULL)`, Reverse: `DROP TABLE "blog"."posts"`},
+					{Cmd: `ALTER TABLE "public"."users" ADD COLUMN "age" integer NOT NULL`, Reverse: `ALTER TABLE "public"."users" DROP COLUMN "age"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewStringColumn("name", "varchar", schema.StringSize(50)),
+							To:     schema.NewStringColumn("name", "varchar", schema.StringSize(100)),
+							Change: schema.ChangeType,
+						},
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("id", "int"),
+							To:     schema.NewIntColumn("id", "bigint"),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "users" ALTER COLUMN "name" TYPE character varying(100), ALTER COLUMN "id" TYPE bigint`,
+						Reverse:  `ALTER TABLE "users" ALTER COLUMN "id" TYPE integer, ALTER COLUMN "name" TYPE character varying(50)`,
+						Comment:  `modify "users" table`,
+						Warnings: []string{`changing the type of column(s) "id" rewrites the table`, `changing the type of column(s) "name" does not rewrite the table`},
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddObject{O: &EventTrigger{Name: "audit", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, FuncSchema: "public", Func: "log_ddl"}},
+				&schema.AddTable{T: schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int"))},
+				&schema.ModifyObject{
+					From: &EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "public", Func: "abort_drop", State: "ENABLE"},
+					To:   &EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "public", Func: "abort_drop", State: "DISABLE"},
+				},
+				&schema.ModifyObject{
+					From: &EventTrigger{Name: "rewrites", Event: "table_rewrite", Func: "log_rewrite", State: "ENABLE"},
+					To:   &EventTrigger{Name: "rewrites", Event: "table_rewrite", Func: "log_rewrite_v2", State: "ENABLE ALWAYS"},
+				},
+				&schema.DropObject{O: &EventTrigger{Name: "legacy", Event: "ddl_command_start", FuncSchema: "public", Func: "legacy", State: "ENABLE"}},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER EVENT TRIGGER "no_drops" DISABLE`,
+						Reverse: `ALTER EVENT TRIGGER "no_drops" ENABLE`,
+						Comment: `change the state of "no_drops" event trigger to DISABLE`,
+					},
+					{
+						Cmd:     `DROP EVENT TRIGGER "rewrites"`,
+						Reverse: `CREATE EVENT TRIGGER "rewrites" ON table_rewrite EXECUTE FUNCTION "log_rewrite"()`,
+					},
+					{
+						Cmd:     `CREATE EVENT TRIGGER "rewrites" ON table_rewrite EXECUTE FUNCTION "log_rewrite_v2"()`,
+						Reverse: `DROP EVENT TRIGGER "rewrites"`,
+					},
+					{
+						Cmd:     `ALTER EVENT TRIGGER "rewrites" ENABLE ALWAYS`,
+						Reverse: `ALTER EVENT TRIGGER "rewrites" ENABLE`,
+					},
+					{
+						Cmd:     `CREATE TABLE "logs" ("id" integer NOT NULL)`,
+						Reverse: `DROP TABLE "logs"`,
+					},
+					{
+						Cmd:     `CREATE EVENT TRIGGER "audit" ON ddl_command_end WHEN TAG IN ('CREATE TABLE', 'ALTER TABLE') EXECUTE FUNCTION "public"."log_ddl"()`,
+						Reverse: `DROP EVENT TRIGGER "audit"`,
+						Comment: `create "audit" event trigger`,
+					},
+					{
+						Cmd:     `DROP EVENT TRIGGER "legacy"`,
+						Reverse: `CREATE EVENT TRIGGER "legacy" ON ddl_command_start EXECUTE FUNCTION "public"."legacy"()`,
+						Comment: `drop "legacy" event trigger`,
+					},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				users := schema.NewTable("users").
+					SetSchema(s).
+					AddColumns(schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int"), schema.NewIntColumn("c", "int"))
+				a, b, c := users.Columns[0], users.Columns[1], users.Columns[2]
+				return []schema.Change{
+					&schema.AddObject{O: &Statistics{Name: "s1", Schema: s, Table: users, Kinds: []string{"mcv", "ndistinct"}, Columns: []*schema.Column{a, c}}},
+					&schema.ModifyObject{
+						From: &Statistics{Name: "s2", Schema: s, Table: users, Columns: []*schema.Column{a, b}},
+						To:   &Statistics{Name: "s2", Schema: s, Table: users, Columns: []*schema.Column{a, c}},
+					},
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: c},
+							&schema.DropColumn{C: b},
+						},
+					},
+					&schema.DropObject{O: &Statistics{Name: "s3", Schema: s, Table: users, Kinds: []string{"dependencies"}, Columns: []*schema.Column{a, b}}},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP STATISTICS "public"."s2"`,
+						Reverse: `CREATE STATISTICS "public"."s2" ON "a", "b" FROM "public"."users"`,
+						Comment: `drop "s2" statistics from table "users"`,
+					},
+					{
+						Cmd:     `DROP STATISTICS "public"."s3"`,
+						Reverse: `CREATE STATISTICS "public"."s3" (dependencies) ON "a", "b" FROM "public"."users"`,
+						Comment: `drop "s3" statistics from table "users"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ADD COLUMN "c" integer NOT NULL, DROP COLUMN "b"`,
+						Reverse: `ALTER TABLE "public"."users" ADD COLUMN "b" integer NOT NULL, DROP COLUMN "c"`,
+					},
+					{
+						Cmd:     `CREATE STATISTICS "public"."s1" (mcv, ndistinct) ON "a", "c" FROM "public"."users"`,
+						Reverse: `DROP STATISTICS "public"."s1"`,
+						Comment: `create "s1" statistics on table "users"`,
+					},
+					{
+						Cmd:     `CREATE STATISTICS "public"."s2" ON "a", "c" FROM "public"."users"`,
+						Reverse: `DROP STATISTICS "public"."s2"`,
+						Comment: `create "s2" statistics on table "users"`,
+					},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				users := schema.NewTable("users").
+					SetSchema(s).
+					AddColumns(schema.NewIntColumn("id", "int"))
+				logs := schema.NewTable("logs").
+					SetSchema(s).
+					AddColumns(schema.NewIntColumn("id", "int"))
+				return []schema.Change{
+					&schema.AddObject{O: &Rule{Name: "users_audit", Table: users, Event: "UPDATE", Where: "old.id <> new.id", Actions: "INSERT INTO logs (id) VALUES (new.id)"}},
+					&schema.ModifyObject{
+						From: &Rule{Name: "users_protect", Table: users, Event: "DELETE", Instead: true, Actions: "NOTHING"},
+						To:   &Rule{Name: "users_protect", Table: users, Event: "DELETE", Instead: true, Actions: "(INSERT INTO logs (id) VALUES (old.id); NOTHING)"},
+					},
+					&schema.DropTable{T: logs},
+					&schema.DropObject{O: &Rule{Name: "logs_protect", Table: logs, Event: "DELETE", Instead: true}},
+				}
+			}(),
+			mock: func(m mock) { m.noDependents("public", "logs") },
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP RULE "logs_protect" ON "public"."logs"`,
+						Reverse: `CREATE RULE "logs_protect" AS ON DELETE TO "public"."logs" DO INSTEAD NOTHING`,
+						Comment: `drop "logs_protect" rule from table "logs"`,
+					},
+					{
+						Cmd: `DROP TABLE "public"."logs" RESTRICT`,
+					},
+					{
+						Cmd:     `CREATE RULE "users_audit" AS ON UPDATE TO "public"."users" WHERE old.id <> new.id DO INSERT INTO logs (id) VALUES (new.id)`,
+						Reverse: `DROP RULE "users_audit" ON "public"."users"`,
+						Comment: `create "users_audit" rule on table "users"`,
+					},
+					{
+						Cmd:     `CREATE OR REPLACE RULE "users_protect" AS ON DELETE TO "public"."users" DO INSTEAD (INSERT INTO logs (id) VALUES (old.id); NOTHING)`,
+						Reverse: `CREATE OR REPLACE RULE "users_protect" AS ON DELETE TO "public"."users" DO INSTEAD NOTHING`,
+						Comment: `replace "users_protect" rule on table "users"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("events").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("id", "int"),
+							To:     schema.NewIntColumn("id", "bigint"),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.CostEstimate = true },
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape(tableSizeQuery)).
+					WithArgs("public", "events").
+					WillReturnRows(sqlmock.NewRows([]string{"rows", "bytes"}).AddRow(10000000, 1181116006))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "public"."events" ALTER COLUMN "id" TYPE bigint`,
+						Reverse:  `ALTER TABLE "public"."events" ALTER COLUMN "id" TYPE integer`,
+						Comment:  `modify "events" table`,
+						Warnings: []string{`changing the type of column(s) "id" rewrites the table`, `table "events" has about 10000000 rows (1181116006 bytes)`},
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ADD COLUMN "age" integer NOT NULL`,
+						Reverse: `ALTER TABLE "public"."users" DROP COLUMN "age"`,
+						Comment: `modify "users" table`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 1024))`, Reverse: `DROP TABLE "posts"`}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}},
+							{Name: "nid", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "id+1"}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "posts" ("id" integer NOT NULL, "nid" integer NOT NULL GENERATED ALWAYS AS (id+1) STORED)`,
+						Reverse: `DROP TABLE "posts"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
+						AddColumns(
+							schema.NewIntColumn("c1", "int").
+								SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id+1"}),
+						),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							Change: schema.ChangeGenerated,
+							From: schema.NewIntColumn("c1", "int").
+								SetGeneratedExpr(&schema.GeneratedExpr{Expr: "id+1"}),
+							To: schema.NewIntColumn("c1", "int"),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd: `ALTER TABLE "posts" ALTER COLUMN "c1" DROP EXPRESSION`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Increment: 2}}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (INCREMENT BY 2))`, Reverse: `DROP TABLE "posts"`}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "integer"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 100, Increment: 2}}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `CREATE TABLE "posts" ("id" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 100 INCREMENT BY 2))`, Reverse: `DROP TABLE "posts"`}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.DropTable{T: &schema.Table{Name: "posts"}},
+			},
+			// Tables are dropped restricted by default.
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape(dependentsQuery)).
+					WithArgs("", "posts").
+					WillReturnRows(sqlmock.NewRows([]string{"object"}).AddRow("view recent_posts"))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `DROP TABLE "posts" RESTRICT`,
+						Warnings: []string{`the drop fails unless the objects that depend on table "posts" are dropped first: view recent_posts`},
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.DropTable{T: schema.NewTable("users").SetSchema(schema.New("public"))},
+				&schema.DropTable{T: schema.NewTable("logs").SetSchema(schema.New("public")), Extra: []schema.Clause{&Cascade{}}},
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.DropColumn{C: schema.NewIntColumn("author_id", "int")},
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.DropBehavior = migrate.DropRestrict },
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape(dependentsQuery)).
+					WithArgs("public", "users").
+					WillReturnRows(sqlmock.NewRows([]string{"object"}).AddRow("constraint posts_author_fk on table posts").AddRow("rule _RETURN on view active_users"))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `DROP TABLE "public"."users" RESTRICT`,
+						Comment:  `drop "users" table`,
+						Warnings: []string{`the drop fails unless the objects that depend on table "users" are dropped first: constraint posts_author_fk on table posts, rule _RETURN on view active_users`},
+					},
+					{
+						Cmd:     `DROP TABLE "public"."logs" CASCADE`,
+						Comment: `drop "logs" table`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."posts" DROP COLUMN "author_id" RESTRICT`,
+						Reverse: `ALTER TABLE "public"."posts" ADD COLUMN "author_id" integer NOT NULL`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{
+								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}, Default: &schema.Literal{V: "'logged_in'"}},
+							},
+							&schema.AddColumn{
+								C: &schema.Column{Name: "last", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Comment{Text: "bar"}}, Default: &schema.RawExpr{X: "'logged_in'"}},
+							},
+							&schema.AddIndex{
+								I: &schema.Index{
+									Name: "id_key",
+									Parts: []*schema.IndexPart{
+										{C: users.Columns[0], Desc: true},
+									},
+									Attrs: []schema.Attr{
+										&schema.Comment{Text: "comment"},
+										&IndexPredicate{P: "success"},
+									},
+								},
+							},
+							&schema.AddIndex{
+								I: &schema.Index{
+									Name: "id_brin",
+									Parts: []*schema.IndexPart{
+										{C: users.Columns[0], Desc: true},
+									},
+									Attrs: []schema.Attr{
+										&IndexType{T: IndexTypeBRIN},
+										&IndexStorageParams{PagesPerRange: 2},
+									},
+								},
+							},
+							&schema.AddCheck{
+								C: &schema.Check{Name: "name_not_empty", Expr: `("name" <> '')`},
+							},
+							&schema.DropCheck{
+								C: &schema.Check{Name: "id_nonzero", Expr: `("id" <> 0)`},
+							},
+							&schema.ModifyCheck{
+								From: &schema.Check{Name: "id_iseven", Expr: `("id" % 2 = 0)`},
+								To:   &schema.Check{Name: "id_iseven", Expr: `(("id") % 2 = 0)`},
+							},
+							&schema.AddIndex{
+								I: &schema.Index{
+									Name: "include_key",
+									Parts: []*schema.IndexPart{
+										{C: users.Columns[0]},
+									},
+									Attrs: []schema.Attr{
+										&IndexInclude{Columns: []*schema.Column{schema.NewColumn("a"), schema.NewColumn("b")}},
+									},
+								},
+							},
+							&schema.AddIndex{
+								I: &schema.Index{
+									Name: "concurrently",
+									Parts: []*schema.IndexPart{
+										{C: users.Columns[0]},
+									},
+									Attrs: []schema.Attr{
+										&Concurrently{},
+									},
+								},
+							},
+							&schema.AddIndex{
+								I: &schema.Index{
+									Name: "operator_class",
+									Parts: []*schema.IndexPart{
+										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_bloom_ops"}}},
+										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_minmax_ops"}}},
+										{C: users.Columns[0], Attrs: []schema.Attr{&IndexOpClass{Name: "int8_minmax_multi_ops", Params: []struct{ N, V string }{{"values_per_range", "8"}}}}},
+									},
+									Attrs: []schema.Attr{
+										&IndexType{T: IndexTypeBRIN},
+									},
+								},
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" ADD COLUMN "name" character varying(255) NOT NULL DEFAULT 'logged_in', ADD COLUMN "last" character varying(255) NOT NULL DEFAULT 'logged_in', ADD CONSTRAINT "name_not_empty" CHECK ("name" <> ''), DROP CONSTRAINT "id_nonzero", DROP CONSTRAINT "id_iseven", ADD CONSTRAINT "id_iseven" CHECK (("id") % 2 = 0)`,
+						Reverse: `ALTER TABLE "users" DROP CONSTRAINT "id_iseven", ADD CONSTRAINT "id_iseven" CHECK ("id" % 2 = 0), ADD CONSTRAINT "id_nonzero" CHECK ("id" <> 0), DROP CONSTRAINT "name_not_empty", DROP COLUMN "last", DROP COLUMN "name"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "id_key" ON "users" ("id" DESC) WHERE success`,
+						Reverse: `DROP INDEX "id_key"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "id_brin" ON "users" USING BRIN ("id" DESC) WITH (pages_per_range = 2)`,
+						Reverse: `DROP INDEX "id_brin"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "include_key" ON "users" ("id") INCLUDE ("a", "b")`,
+						Reverse: `DROP INDEX "include_key"`,
+					},
+					{
+						Cmd:     `CREATE INDEX CONCURRENTLY "concurrently" ON "users" ("id")`,
+						Reverse: `DROP INDEX CONCURRENTLY "concurrently"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "operator_class" ON "users" USING BRIN ("id" int8_bloom_ops, "id", "id" int8_minmax_multi_ops(values_per_range=8))`,
+						Reverse: `DROP INDEX "operator_class"`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "users" ."name" IS 'foo'`,
+						Reverse: `COMMENT ON COLUMN "users" ."name" IS ''`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "users" ."last" IS 'bar'`,
+						Reverse: `COMMENT ON COLUMN "users" ."last" IS ''`,
+					},
+					{
+						Cmd:     `COMMENT ON INDEX "id_key" IS 'comment'`,
+						Reverse: `COMMENT ON INDEX "id_key" IS ''`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.DropColumn{
+								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar"}}},
+							},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{}, &schema.Comment{Text: "comment"}}},
+								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
+								Change: schema.ChangeAttr | schema.ChangeComment,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" DROP COLUMN "name", ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1 RESTART`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 RESTART, ADD COLUMN "name" character varying NOT NULL`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "users" ."id" IS ''`,
+						Reverse: `COMMENT ON COLUMN "users" ."id" IS 'comment'`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.DropColumn{
+								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar"}}},
+							},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 0, Last: 1025}}}},
+								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
+								Change: schema.ChangeAttr,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" DROP COLUMN "name", ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1, ADD COLUMN "name" character varying NOT NULL`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "users"},
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 1, Last: 10}}}},
+							To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1}}}},
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 1 SET INCREMENT BY 1`,
+						Reverse:  `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1`,
+						Comment:  `modify "users" table`,
+						Warnings: []string{`identity column(s) "id" switched to GENERATED ALWAYS, INSERT statements with explicit values will fail unless OVERRIDING SYSTEM VALUE is used`},
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}},
+					Changes: []schema.Change{
+						&schema.AddAttr{
+							A: &schema.Comment{Text: "foo"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `COMMENT ON TABLE "public"."users" IS 'foo'`, Reverse: `COMMENT ON TABLE "public"."users" IS ''`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}},
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							To:   &schema.Comment{Text: "foo"},
+							From: &schema.Comment{Text: "bar"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `COMMENT ON TABLE "public"."users" IS 'foo'`, Reverse: `COMMENT ON TABLE "public"."users" IS 'bar'`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := schema.NewTable("users").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
+							schema.NewEnumColumn("status", schema.EnumName("status"), schema.EnumValues("a", "b"), schema.EnumSchema(schema.New("test"))),
+						)
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
+								To:     users.Columns[0],
+								Change: schema.ChangeType,
+							},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "status", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
+								To:     users.Columns[1],
+								Change: schema.ChangeType,
+							},
+							&schema.DropColumn{
+								C: schema.NewEnumColumn("dc1", schema.EnumName("de"), schema.EnumValues("on")),
+							},
+							&schema.DropColumn{
+								C: schema.NewEnumColumn("dc2", schema.EnumName("de"), schema.EnumValues("on")),
+							},
+						},
+					}
+				}(),
+			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2 ")).
+					WithArgs("state", "public").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2 ")).
+					WithArgs("status", "test").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TYPE "public"."state" AS ENUM ('on', 'off')`, Reverse: `DROP TYPE "public"."state"`},
+					{Cmd: `CREATE TYPE "test"."status" AS ENUM ('a', 'b')`, Reverse: `DROP TYPE "test"."status"`},
+					{Cmd: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state", ALTER COLUMN "status" TYPE "test"."status", DROP COLUMN "dc1", DROP COLUMN "dc2"`, Reverse: `ALTER TABLE "public"."users" ADD COLUMN "dc2" "public"."de" NOT NULL, ADD COLUMN "dc1" "public"."de" NOT NULL, ALTER COLUMN "status" TYPE text, ALTER COLUMN "state" TYPE text`, Warnings: []string{`changing the type of column(s) "state", "status" rewrites the table`}},
+					{Cmd: `DROP TYPE "public"."de"`, Reverse: `CREATE TYPE "public"."de" AS ENUM ('on')`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := schema.NewTable("users").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
+						)
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   users.Columns[0],
+								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}},
+								Change: schema.ChangeType,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'unknown'`},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				users := schema.NewTable("users").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"))
+				pets := schema.NewTable("pets").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(&TableOwner{V: "app"})
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyAttr{From: &TableOwner{V: "admin"}, To: &TableOwner{V: "app"}},
+						},
+					},
+					&schema.AddTable{T: pets},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "public"."users" OWNER TO "app"`,
+						Reverse: `ALTER TABLE "public"."users" OWNER TO "admin"`,
+						Comment: `set the owner of table "users" to "app"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."pets" ("id" integer NOT NULL)`,
+						Reverse: `DROP TABLE "public"."pets"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."pets" OWNER TO "app"`,
+						Reverse: `ALTER TABLE "public"."pets" OWNER TO CURRENT_USER`,
+						Comment: `set the owner of table "pets" to "app"`,
+					},
+				},
+			},
+		},
+		// Values that are added to enums are grouped before the changes that may use them.
+		{
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				users := schema.NewTable("users").SetSchema(s).
+					AddColumns(schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")))
+				pets := schema.NewTable("pets").SetSchema(s).
+					AddColumns(schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy")))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   users.Columns[0],
+								To:     schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off", "unknown")).SetDefault(&schema.Literal{V: "'unknown'"}),
+								Change: schema.ChangeType | schema.ChangeDefault,
+							},
+						},
+					},
+					&schema.ModifyTable{
+						T: pets,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   pets.Columns[0],
+								To:     schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy", "sad", "angry")),
+								Change: schema.ChangeType,
+							},
+						},
+					},
+				}
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.Coalesce = true },
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'unknown'`},
+					{Cmd: `ALTER TYPE "public"."mood" ADD VALUE 'sad'`},
+					{Cmd: `ALTER TYPE "public"."mood" ADD VALUE 'angry'`},
+					{Cmd: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state", ALTER COLUMN "state" SET DEFAULT 'unknown'`, Reverse: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state", ALTER COLUMN "state" DROP DEFAULT`, Warnings: []string{`changing the type of column(s) "state" rewrites the table`}},
+				},
+			},
+		},
+		// Modify column type and drop comment.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := schema.NewTable("users").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
+						)
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
+								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}},
+								Change: schema.ChangeType | schema.ChangeComment,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'unknown'`},
+					{Cmd: `COMMENT ON COLUMN "public"."users" ."state" IS ''`, Reverse: `COMMENT ON COLUMN "public"."users" ."state" IS 'foo'`},
+				},
+			},
+		},
+		// Modify column type and add comment.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}},
+								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
+								Change: schema.ChangeType | schema.ChangeComment,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TYPE "state" ADD VALUE 'unknown'`},
+					{Cmd: `COMMENT ON COLUMN "users" ."state" IS 'foo'`, Reverse: `COMMENT ON COLUMN "users" ."state" IS ''`},
+				},
+			},
+		},
+		// Modify column comment.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "bar"}}},
+								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
+								Change: schema.ChangeComment,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `COMMENT ON COLUMN "users" ."state" IS 'foo'`, Reverse: `COMMENT ON COLUMN "users" ."state" IS 'bar'`},
+				},
+			},
+		},
+		// Modify index comment.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyIndex{
+								From: schema.NewIndex("id_key").
+									AddColumns(users.Columns[0]).
+									SetComment("foo"),
+								To: schema.NewIndex("id_key").
+									AddColumns(users.Columns[0]).
+									SetComment("bar"),
+								Change: schema.ChangeComment,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `COMMENT ON INDEX "id_key" IS 'bar'`, Reverse: `COMMENT ON INDEX "id_key" IS 'foo'`},
+				},
+			},
+		},
+		// Modify default values.
+		{
+			changes: []schema.Change{
+				func() schema.Change {
+					users := &schema.Table{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							{Name: "one", Default: &schema.Literal{V: "'one'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							{Name: "two", Default: &schema.Literal{V: "'two'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+						},
+					}
+					return &schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "one", Default: &schema.Literal{V: "'one'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+								To:     &schema.Column{Name: "one", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+								Change: schema.ChangeDefault,
+							},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "two", Default: &schema.Literal{V: "'two'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+								To:     &schema.Column{Name: "two", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+								Change: schema.ChangeDefault,
+							},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{}}},
+								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
+								Change: schema.ChangeAttr,
+							},
+						},
+					}
+				}(),
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" ALTER COLUMN "one" DROP DEFAULT, ALTER COLUMN "two" DROP DEFAULT, ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1 RESTART`,
+						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 RESTART, ALTER COLUMN "two" SET DEFAULT 'two', ALTER COLUMN "one" SET DEFAULT 'one'`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.RenameTable{
+					From: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					To:   schema.NewTable("t2").SetSchema(schema.New("s2")),
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" RENAME TO "s2"."t2"`,
+						Reverse: `ALTER TABLE "s2"."t2" RENAME TO "s1"."t1"`,
+					},
+				},
+			},
+		},
+		{
+			changes: func() []schema.Change {
+				legacy, public := schema.New("legacy"), schema.New("public")
+				return []schema.Change{
+					&schema.DropSchema{S: legacy},
+					&schema.MoveObject{
+						From: &Collation{Name: "natural", Schema: legacy},
+						To:   &Collation{Name: "natural", Schema: public},
+					},
+					&schema.MoveTable{
+						From: schema.NewTable("users").SetSchema(legacy),
+						To:   schema.NewTable("users").SetSchema(public),
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER COLLATION "legacy"."natural" SET SCHEMA "public"`,
+						Reverse: `ALTER COLLATION "public"."natural" SET SCHEMA "legacy"`,
+						Comment: `move collation "natural" from schema "legacy" to "public"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "legacy"."users" SET SCHEMA "public"`,
+						Reverse: `ALTER TABLE "public"."users" SET SCHEMA "legacy"`,
+						Comment: `move table "users" from schema "legacy" to "public"`,
+					},
+					{
+						Cmd: `DROP SCHEMA "legacy" CASCADE`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					Changes: []schema.Change{
+						&schema.RenameColumn{
+							From: schema.NewColumn("a"),
+							To:   schema.NewColumn("b"),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" RENAME COLUMN "a" TO "b"`,
+						Reverse: `ALTER TABLE "s1"."t1" RENAME COLUMN "b" TO "a"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					Changes: []schema.Change{
+						&schema.RenameColumn{
+							From: schema.NewColumn("a"),
+							To:   schema.NewColumn("b"),
+						},
+						&schema.AddColumn{
+							C: schema.NewIntColumn("c", "int"),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" ADD COLUMN "c" integer NOT NULL`,
+						Reverse: `ALTER TABLE "s1"."t1" DROP COLUMN "c"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "s1"."t1" RENAME COLUMN "a" TO "b"`,
+						Reverse: `ALTER TABLE "s1"."t1" RENAME COLUMN "b" TO "a"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
+					Changes: []schema.Change{
+						&schema.RenameIndex{
+							From: schema.NewIndex("a"),
+							To:   schema.NewIndex("b"),
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER INDEX "a" RENAME TO "b"`,
+						Reverse: `ALTER INDEX "b" RENAME TO "a"`,
+					},
+				},
+			},
+		},
+		// Invalid serial type.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name: "posts",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Type: &SerialType{T: "serial"}, Null: true}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		// Drop serial sequence.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewIntColumn("c1", "integer"),
+							schema.NewIntColumn("c2", "integer"),
+						),
+					Changes: schema.Changes{
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("c1").SetType(&SerialType{T: "smallserial"}),
+							To:     schema.NewIntColumn("c1", "integer"),
+							Change: schema.ChangeType,
+						},
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("c2").SetType(&SerialType{T: "serial", SequenceName: "previous_name"}),
+							To:     schema.NewIntColumn("c2", "integer"),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "public"."posts" ALTER COLUMN "c1" DROP DEFAULT, ALTER COLUMN "c1" TYPE integer, ALTER COLUMN "c2" DROP DEFAULT`,
+						Reverse:  `ALTER TABLE "public"."posts" ALTER COLUMN "c2" SET DEFAULT nextval('"public"."previous_name"'), ALTER COLUMN "c1" SET DEFAULT nextval('"public"."posts_c1_seq"'), ALTER COLUMN "c1" TYPE smallint`,
+						Warnings: []string{`changing the type of column(s) "c1", "c2" rewrites the table`},
+					},
+					{
+						Cmd:     `DROP SEQUENCE IF EXISTS "public"."posts_c1_seq"`,
+						Reverse: `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c1_seq" OWNED BY "public"."posts"."c1"`,
+					},
+					{
+						Cmd:     `DROP SEQUENCE IF EXISTS "public"."previous_name"`,
+						Reverse: `CREATE SEQUENCE IF NOT EXISTS "public"."previous_name" OWNED BY "public"."posts"."c2"`,
+					},
+				},
+			},
+		},
+		// Add serial sequence.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
+						),
+					Changes: schema.Changes{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("c1", "integer"),
+							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							Change: schema.ChangeType,
+						},
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("c2", "integer"),
+							To:     schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c1_seq" OWNED BY "public"."posts"."c1"`,
+						Reverse: `DROP SEQUENCE IF EXISTS "public"."posts_c1_seq"`,
+					},
+					{
+						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c2_seq" OWNED BY "public"."posts"."c2"`,
+						Reverse: `DROP SEQUENCE IF EXISTS "public"."posts_c2_seq"`,
+					},
+					{
+						Cmd:      `ALTER TABLE "public"."posts" ALTER COLUMN "c1" SET DEFAULT nextval('"public"."posts_c1_seq"'), ALTER COLUMN "c2" SET DEFAULT nextval('"public"."posts_c2_seq"'), ALTER COLUMN "c2" TYPE bigint`,
+						Reverse:  `ALTER TABLE "public"."posts" ALTER COLUMN "c2" DROP DEFAULT, ALTER COLUMN "c2" TYPE integer, ALTER COLUMN "c1" DROP DEFAULT`,
+						Warnings: []string{`changing the type of column(s) "c1", "c2" rewrites the table`},
+					},
+				},
+			},
+		},
+		// Change underlying sequence type.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
+						),
+					Changes: schema.Changes{
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("c1").SetType(&SerialType{T: "smallserial"}),
+							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							Change: schema.ChangeType,
+						},
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("c2").SetType(&SerialType{T: "serial"}),
+							To:     schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "public"."posts" ALTER COLUMN "c1" TYPE integer, ALTER COLUMN "c2" TYPE bigint`,
+						Reverse:  `ALTER TABLE "public"."posts" ALTER COLUMN "c2" TYPE integer, ALTER COLUMN "c1" TYPE smallint`,
+						Warnings: []string{`changing the type of column(s) "c1", "c2" rewrites the table`},
+					},
+				},
+			},
+		},
+		// Change the type of a serial column that is written in its explicit form.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("public")).
+						AddColumns(
+							schema.NewIntColumn("c1", "bigint").SetDefault(&schema.RawExpr{X: "nextval('posts_c1_seq')"}),
+						),
+					Changes: schema.Changes{
+						&schema.ModifyColumn{
+							From:   schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							To:     schema.NewIntColumn("c1", "bigint").SetDefault(&schema.RawExpr{X: "nextval('posts_c1_seq')"}),
+							Change: schema.ChangeType,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:      `ALTER TABLE "public"."posts" ALTER COLUMN "c1" TYPE bigint`,
+						Reverse:  `ALTER TABLE "public"."posts" ALTER COLUMN "c1" TYPE integer`,
+						Warnings: []string{`changing the type of column(s) "c1" rewrites the table`},
+					},
+				},
+			},
+		},
+		// Empty qualifier.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("posts").
+						SetSchema(schema.New("test1")).
+						AddColumns(
+							schema.NewEnumColumn("c1", schema.EnumName("enum"), schema.EnumValues("a"), schema.EnumSchema(schema.New("test2"))),
+						),
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
+			},
 			mock: func(m mock) {
-				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2 ")).
-					WithArgs("state", "public").
-					WillReturnRows(sqlmock.NewRows([]string{"name"}))
-				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2 ")).
-					WithArgs("status", "test").
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'")).
+					WithArgs("enum").
 					WillReturnRows(sqlmock.NewRows([]string{"name"}))
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `CREATE TYPE "public"."state" AS ENUM ('on', 'off')`, Reverse: `DROP TYPE "public"."state"`},
-					{Cmd: `CREATE TYPE "test"."status" AS ENUM ('a', 'b')`, Reverse: `DROP TYPE "test"."status"`},
-					{Cmd: `ALTER TABLE "public"."users" ALTER COLUMN "state" TYPE "public"."state", ALTER COLUMN "status" TYPE "test"."status", DROP COLUMN "dc1", DROP COLUMN "dc2"`, Reverse: `ALTER TABLE "public"."users" ADD COLUMN "dc2" "public"."de" NOT NULL, ADD COLUMN "dc1" "public"."de" NOT NULL, ALTER COLUMN "status" TYPE text, ALTER COLUMN "state" TYPE text`},
-					{Cmd: `DROP TYPE "public"."de"`, Reverse: `CREATE TYPE "public"."de" AS ENUM ('on')`},
+					{
+						Cmd:     `CREATE TYPE "enum" AS ENUM ('a')`,
+						Reverse: `DROP TYPE "enum"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "posts" ("c1" "enum" NOT NULL)`,
+						Reverse: `DROP TABLE "posts"`,
+					},
 				},
 			},
 		},
+		// Empty sequence qualifier.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := schema.NewTable("users").
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
 						SetSchema(schema.New("public")).
 						AddColumns(
-							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
-						)
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   users.Columns[0],
-								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}},
-								Change: schema.ChangeType,
-							},
+							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+						),
+					Changes: schema.Changes{
+						&schema.ModifyColumn{
+							From:   schema.NewIntColumn("c1", "integer"),
+							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
+							Change: schema.ChangeType,
 						},
-					}
-				}(),
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    false,
+				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'unknown'`},
+					{
+						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "posts_c1_seq" OWNED BY "posts"."c1"`,
+						Reverse: `DROP SEQUENCE IF EXISTS "posts_c1_seq"`,
+					},
+					{
+						Cmd:      `ALTER TABLE "posts" ALTER COLUMN "c1" SET DEFAULT nextval('"posts_c1_seq"')`,
+						Reverse:  `ALTER TABLE "posts" ALTER COLUMN "c1" DROP DEFAULT`,
+						Warnings: []string{`changing the type of column(s) "c1" rewrites the table`},
+					},
 				},
 			},
 		},
-		// Modify column type and drop comment.
+		// Empty index qualifier.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := schema.NewTable("users").
+				&schema.ModifyTable{
+					T: schema.NewTable("posts").
 						SetSchema(schema.New("public")).
 						AddColumns(
-							schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
-						)
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
-								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}},
-								Change: schema.ChangeType | schema.ChangeComment,
-							},
+							schema.NewIntColumn("c", "int"),
+						),
+					Changes: schema.Changes{
+						&schema.AddIndex{
+							I: schema.NewIndex("i").AddColumns(schema.NewIntColumn("c", "int")),
 						},
-					}
-				}(),
+					},
+				},
+			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    false,
+				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `ALTER TYPE "public"."state" ADD VALUE 'unknown'`},
-					{Cmd: `COMMENT ON COLUMN "public"."users" ."state" IS ''`, Reverse: `COMMENT ON COLUMN "public"."users" ."state" IS 'foo'`},
+					{
+						Cmd:     `CREATE INDEX "i" ON "posts" ("c")`,
+						Reverse: `DROP INDEX "i"`,
+					},
 				},
 			},
 		},
-		// Modify column type and add comment.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}},
-						},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off"}}}},
-								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.EnumType{T: "state", Values: []string{"on", "off", "unknown"}}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
-								Change: schema.ChangeType | schema.ChangeComment,
-							},
-						},
-					}
-				}(),
+				&schema.AddTable{
+					T: schema.NewTable("logs").
+						SetSchema(schema.New("public")).
+						AddColumns(schema.NewIntColumn("a", "int")).
+						AddAttrs(&TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"toast.autovacuum_enabled", "false"}}}),
+				},
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    false,
+				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `ALTER TYPE "state" ADD VALUE 'unknown'`},
-					{Cmd: `COMMENT ON COLUMN "users" ."state" IS 'foo'`, Reverse: `COMMENT ON COLUMN "users" ."state" IS ''`},
+					{
+						Cmd:     `CREATE TABLE "public"."logs" ("a" integer NOT NULL) WITH (parallel_workers = 4, toast.autovacuum_enabled = false)`,
+						Reverse: `DROP TABLE "public"."logs"`,
+					},
 				},
 			},
 		},
-		// Modify column comment.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}},
-						},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "bar"}}},
-								To:     &schema.Column{Name: "state", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "foo"}}},
-								Change: schema.ChangeComment,
-							},
+				&schema.ModifyTable{
+					T: schema.NewTable("logs").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"toast.autovacuum_enabled", "false"}}},
+							To:   &TableStorageParams{Params: []struct{ N, V string }{{"parallel_workers", "8"}, {"fillfactor", "70"}}},
 						},
-					}
-				}(),
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `COMMENT ON COLUMN "users" ."state" IS 'foo'`, Reverse: `COMMENT ON COLUMN "users" ."state" IS 'bar'`},
+					{
+						Cmd:     `ALTER TABLE "public"."logs" SET (parallel_workers = 8, fillfactor = 70), RESET (toast.autovacuum_enabled)`,
+						Reverse: `ALTER TABLE "public"."logs" SET (parallel_workers = 4, toast.autovacuum_enabled = false), RESET (fillfactor)`,
+					},
 				},
 			},
 		},
-		// Modify index comment.
 		{
 			changes: []schema.Change{
-				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-						},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyIndex{
-								From: schema.NewIndex("id_key").
-									AddColumns(users.Columns[0]).
-									SetComment("foo"),
-								To: schema.NewIndex("id_key").
-									AddColumns(users.Columns[0]).
-									SetComment("bar"),
-								Change: schema.ChangeComment,
-							},
+				&schema.ModifyTable{
+					T: schema.NewTable("logs").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_analyze_threshold", "50"}}},
+							To:   &TableStorageParams{Params: []struct{ N, V string }{{"autovacuum_analyze_threshold", "50.0"}, {"autovacuum_vacuum_scale_factor", "0.05"}}},
 						},
-					}
-				}(),
+					},
+				},
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: `COMMENT ON INDEX "id_key" IS 'bar'`, Reverse: `COMMENT ON INDEX "id_key" IS 'foo'`},
+					{
+						Cmd:     `ALTER TABLE "public"."logs" SET (autovacuum_vacuum_scale_factor = 0.05)`,
+						Reverse: `ALTER TABLE "public"."logs" RESET (autovacuum_vacuum_scale_factor)`,
+					},
 				},
 			},
 		},
-		// Modify default values.
 		{
 			changes: []schema.Change{
 				func() schema.Change {
-					users := &schema.Table{
-						Name: "users",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-							{Name: "one", Default: &schema.Literal{V: "'one'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-							{Name: "two", Default: &schema.Literal{V: "'two'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-						},
-					}
-					return &schema.ModifyTable{
-						T: users,
-						Changes: []schema.Change{
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "one", Default: &schema.Literal{V: "'one'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-								To:     &schema.Column{Name: "one", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-								Change: schema.ChangeDefault,
-							},
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "two", Default: &schema.Literal{V: "'two'"}, Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-								To:     &schema.Column{Name: "two", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
-								Change: schema.ChangeDefault,
-							},
-							&schema.ModifyColumn{
-								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{}}},
-								To:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024}}}},
-								Change: schema.ChangeAttr,
-							},
-						},
-					}
+					c := schema.NewIntColumn("c", "int")
+					t := schema.NewTable("logs").
+						SetSchema(schema.New("public")).
+						AddColumns(c).
+						AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}})
+					idx := schema.NewIndex("logs_c").AddColumns(c).AddAttrs(&Concurrently{})
+					return &schema.ModifyTable{T: t, Changes: []schema.Change{&schema.AddIndex{I: idx}}}
 				}(),
 			},
+			mock: func(m mock) {
+				m.ExpectQuery(sqltest.Escape("SELECT n.nspname, c.relname, c.relkind FROM pg_catalog.pg_inherits AS i JOIN pg_catalog.pg_class AS c ON c.oid = i.inhrelid JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace WHERE i.inhparent = to_regclass($1)::oid ORDER BY c.relname")).
+					WithArgs(`"public"."logs"`).
+					WillReturnRows(sqlmock.NewRows([]string{"nspname", "relname", "relkind"}).AddRow("public", "logs_2022", "r").AddRow("public", "logs_2023", "p"))
+			},
 			wantPlan: &migrate.Plan{
-				Reversible:    true,
+				Reversible:    false,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "users" ALTER COLUMN "one" DROP DEFAULT, ALTER COLUMN "two" DROP DEFAULT, ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1024 SET INCREMENT BY 1 RESTART`,
-						Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 RESTART, ALTER COLUMN "two" SET DEFAULT 'two', ALTER COLUMN "one" SET DEFAULT 'one'`,
+						Cmd:     `CREATE INDEX "logs_c" ON ONLY "public"."logs" ("c")`,
+						Reverse: `DROP INDEX "public"."logs_c"`,
+					},
+					{
+						Cmd: `CREATE INDEX CONCURRENTLY "logs_2022_logs_c" ON "public"."logs_2022" ("c")`,
+					},
+					{
+						Cmd: `ALTER INDEX "public"."logs_c" ATTACH PARTITION "public"."logs_2022_logs_c"`,
+					},
+					{
+						Cmd: `CREATE INDEX "logs_2023_logs_c" ON "public"."logs_2023" ("c")`,
+					},
+					{
+						Cmd: `ALTER INDEX "public"."logs_c" ATTACH PARTITION "public"."logs_2023_logs_c"`,
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.RenameTable{
-					From: schema.NewTable("t1").SetSchema(schema.New("s1")),
-					To:   schema.NewTable("t2").SetSchema(schema.New("s2")),
-				},
+				func() schema.Change {
+					c := schema.NewIntColumn("c", "int")
+					t := schema.NewTable("logs").
+						SetSchema(schema.New("public")).
+						AddColumns(c).
+						AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}})
+					t.AddIndexes(schema.NewIndex("logs_c").AddColumns(c).AddAttrs(&Concurrently{}))
+					return &schema.AddTable{T: t}
+				}(),
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "s1"."t1" RENAME TO "s2"."t2"`,
-						Reverse: `ALTER TABLE "s2"."t2" RENAME TO "s1"."t1"`,
+						Cmd:     `CREATE TABLE "public"."logs" ("c" integer NOT NULL) PARTITION BY RANGE ("c")`,
+						Reverse: `DROP TABLE "public"."logs"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "logs_c" ON "public"."logs" ("c")`,
+						Reverse: `DROP INDEX "public"."logs_c"`,
 					},
 				},
 			},
 		},
 		{
 			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
-					Changes: []schema.Change{
-						&schema.RenameColumn{
-							From: schema.NewColumn("a"),
-							To:   schema.NewColumn("b"),
+				func() schema.Change {
+					off := false
+					c := schema.NewColumn("c").SetType(&schema.JSONType{T: "jsonb"})
+					t := schema.NewTable("t").SetSchema(schema.New("public")).AddColumns(c)
+					return &schema.ModifyTable{
+						T: t,
+						Changes: []schema.Change{
+							&schema.ModifyIndex{
+								From:   schema.NewIndex("t_c").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}),
+								To:     schema.NewIndex("t_c").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}, &IndexStorageParams{FastUpdate: &off, PendingListLimit: 1024}),
+								Change: schema.ChangeAttr,
+							},
+							&schema.ModifyIndex{
+								From:   schema.NewIndex("t_c2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}),
+								To:     schema.NewIndex("t_c2").AddColumns(c).AddAttrs(&IndexType{T: IndexTypeGIN}, &IndexPredicate{P: "c IS NOT NULL"}, &IndexStorageParams{FastUpdate: &off}),
+								Change: schema.ChangeAttr,
+							},
 						},
-					},
-				},
+					}
+				}(),
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "s1"."t1" RENAME COLUMN "a" TO "b"`,
-						Reverse: `ALTER TABLE "s1"."t1" RENAME COLUMN "b" TO "a"`,
+						Cmd:     `DROP INDEX "public"."t_c2"`,
+						Reverse: `CREATE INDEX "t_c2" ON "public"."t" USING GIN ("c")`,
+					},
+					{
+						Cmd:     `CREATE INDEX "t_c2" ON "public"."t" USING GIN ("c") WITH (fastupdate = off) WHERE c IS NOT NULL`,
+						Reverse: `DROP INDEX "public"."t_c2"`,
+					},
+					{
+						Cmd:     `ALTER INDEX "public"."t_c" SET (fastupdate = off, gin_pending_list_limit = 1024)`,
+						Reverse: `ALTER INDEX "public"."t_c" RESET (fastupdate, gin_pending_list_limit)`,
 					},
 				},
 			},
 		},
 		{
-			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
-					Changes: []schema.Change{
-						&schema.RenameColumn{
-							From: schema.NewColumn("a"),
-							To:   schema.NewColumn("b"),
-						},
-						&schema.AddColumn{
-							C: schema.NewIntColumn("c", "int"),
-						},
-					},
-				},
+			changes: func() []schema.Change {
+				t := schema.NewTable("t").SetSchema(schema.New("public"))
+				changes := make([]schema.Change, 0, 11)
+				for i := 0; i < 10; i++ {
+					changes = append(changes, &schema.ModifyTable{
+						T:       t,
+						Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("c"+strconv.Itoa(i), "int")}},
+					})
+				}
+				return append(changes, &schema.ModifyTable{
+					T:       t,
+					Changes: []schema.Change{&schema.RenameColumn{From: schema.NewIntColumn("c0", "int"), To: schema.NewIntColumn("d0", "int")}},
+				})
+			}(),
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.Coalesce = true },
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "s1"."t1" ADD COLUMN "c" integer NOT NULL`,
-						Reverse: `ALTER TABLE "s1"."t1" DROP COLUMN "c"`,
+						Cmd:     `ALTER TABLE "public"."t" ADD COLUMN "c0" integer NOT NULL, ADD COLUMN "c1" integer NOT NULL, ADD COLUMN "c2" integer NOT NULL, ADD COLUMN "c3" integer NOT NULL, ADD COLUMN "c4" integer NOT NULL, ADD COLUMN "c5" integer NOT NULL, ADD COLUMN "c6" integer NOT NULL, ADD COLUMN "c7" integer NOT NULL, ADD COLUMN "c8" integer NOT NULL, ADD COLUMN "c9" integer NOT NULL`,
+						Reverse: `ALTER TABLE "public"."t" DROP COLUMN "c9", DROP COLUMN "c8", DROP COLUMN "c7", DROP COLUMN "c6", DROP COLUMN "c5", DROP COLUMN "c4", DROP COLUMN "c3", DROP COLUMN "c2", DROP COLUMN "c1", DROP COLUMN "c0"`,
 					},
 					{
-						Cmd:     `ALTER TABLE "s1"."t1" RENAME COLUMN "a" TO "b"`,
-						Reverse: `ALTER TABLE "s1"."t1" RENAME COLUMN "b" TO "a"`,
+						Cmd:     `ALTER TABLE "public"."t" RENAME COLUMN "c0" TO "d0"`,
+						Reverse: `ALTER TABLE "public"."t" RENAME COLUMN "d0" TO "c0"`,
 					},
 				},
 			},
 		},
-		{
-			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: schema.NewTable("t1").SetSchema(schema.New("s1")),
-					Changes: []schema.Change{
-						&schema.RenameIndex{
-							From: schema.NewIndex("a"),
-							To:   schema.NewIndex("b"),
+		func() struct {
+			changes  []schema.Change
+			options  []migrate.PlanOption
+			mock     func(mock)
+			wantPlan *migrate.Plan
+			wantErr  bool
+		} {
+			t := schema.NewTable("users").
+				SetSchema(schema.New("public")).
+				AddColumns(
+					schema.NewStringColumn("email", "text"),
+					schema.NewStringColumn("name", "text"),
+				)
+			return struct {
+				changes  []schema.Change
+				options  []migrate.PlanOption
+				mock     func(mock)
+				wantPlan *migrate.Plan
+				wantErr  bool
+			}{
+				changes: []schema.Change{
+					&schema.ModifyTable{
+						T: t,
+						Changes: []schema.Change{
+							&schema.AddIndex{I: schema.NewUniqueIndex("users_email").AddColumns(t.Columns[0])},
+							&schema.AddIndex{I: schema.NewIndex("users_name").AddColumns(t.Columns[1])},
+							&schema.AddIndex{
+								I: schema.NewUniqueIndex("users_name_email").
+									AddColumns(t.Columns[1]).
+									AddExprs(&schema.RawExpr{X: "lower(email)"}).
+									AddAttrs(&IndexPredicate{P: "name <> ''"}),
+							},
 						},
 					},
 				},
-			},
-			wantPlan: &migrate.Plan{
-				Reversible:    true,
-				Transactional: true,
-				Changes: []*migrate.Change{
-					{
-						Cmd:     `ALTER INDEX "a" RENAME TO "b"`,
-						Reverse: `ALTER INDEX "b" RENAME TO "a"`,
+				options: []migrate.PlanOption{
+					func(o *migrate.PlanOptions) { o.UniquePreflight = true },
+				},
+				wantPlan: &migrate.Plan{
+					Reversible:    true,
+					Transactional: true,
+					Changes: []*migrate.Change{
+						{
+							Cmd:      `CREATE UNIQUE INDEX "users_email" ON "public"."users" ("email")`,
+							Reverse:  `DROP INDEX "public"."users_email"`,
+							Comment:  `create index "users_email" to table: "users"`,
+							Warnings: []string{`check for duplicates before applying: SELECT "email", count(*) FROM "public"."users" WHERE "email" IS NOT NULL GROUP BY "email" HAVING count(*) > 1`},
+						},
+						{
+							Cmd:     `CREATE INDEX "users_name" ON "public"."users" ("name")`,
+							Reverse: `DROP INDEX "public"."users_name"`,
+							Comment: `create index "users_name" to table: "users"`,
+						},
+						{
+							Cmd:      `CREATE UNIQUE INDEX "users_name_email" ON "public"."users" ("name", (lower(email))) WHERE name <> ''`,
+							Reverse:  `DROP INDEX "public"."users_name_email"`,
+							Comment:  `create index "users_name_email" to table: "users"`,
+							Warnings: []string{`check for duplicates before applying: SELECT "name", (lower(email)), count(*) FROM "public"."users" WHERE "name" IS NOT NULL AND (lower(email)) IS NOT NULL AND (name <> '') GROUP BY "name", (lower(email)) HAVING count(*) > 1`},
+						},
 					},
 				},
-			},
-		},
-		// Invalid serial type.
+			}
+		}(),
+		// Indexes that cannot be created fail the plan.
 		{
 			changes: []schema.Change{
-				&schema.AddTable{
-					T: &schema.Table{
-						Name: "posts",
-						Columns: []*schema.Column{
-							{Name: "id", Type: &schema.ColumnType{Type: &SerialType{T: "serial"}, Null: true}},
-						},
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddIndex{I: schema.NewUniqueIndex("users_email").AddColumns(schema.NewStringColumn("email", "text")).AddAttrs(&schema.Charset{V: "utf8"})},
 					},
 				},
 			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.UniquePreflight = true },
+			},
 			wantErr: true,
 		},
-		// Drop serial sequence.
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewIntColumn("c1", "integer"),
-							schema.NewIntColumn("c2", "integer"),
-						),
-					Changes: schema.Changes{
-						&schema.ModifyColumn{
-							From:   schema.NewColumn("c1").SetType(&SerialType{T: "smallserial"}),
-							To:     schema.NewIntColumn("c1", "integer"),
-							Change: schema.ChangeType,
-						},
-						&schema.ModifyColumn{
-							From:   schema.NewColumn("c2").SetType(&SerialType{T: "serial", SequenceName: "previous_name"}),
-							To:     schema.NewIntColumn("c2", "integer"),
-							Change: schema.ChangeType,
-						},
+					T: schema.NewTable("t").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("a", "int")},
+						&schema.DropColumn{C: schema.NewIntColumn("b", "int")},
 					},
 				},
 			},
+			options: []migrate.PlanOption{
+				func(o *migrate.PlanOptions) { o.ColumnGuards = true },
+			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "public"."posts" ALTER COLUMN "c1" DROP DEFAULT, ALTER COLUMN "c1" TYPE integer, ALTER COLUMN "c2" DROP DEFAULT`,
-						Reverse: `ALTER TABLE "public"."posts" ALTER COLUMN "c2" SET DEFAULT nextval('"public"."previous_name"'), ALTER COLUMN "c1" SET DEFAULT nextval('"public"."posts_c1_seq"'), ALTER COLUMN "c1" TYPE smallint`,
-					},
-					{
-						Cmd:     `DROP SEQUENCE IF EXISTS "public"."posts_c1_seq"`,
-						Reverse: `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c1_seq" OWNED BY "public"."posts"."c1"`,
-					},
-					{
-						Cmd:     `DROP SEQUENCE IF EXISTS "public"."previous_name"`,
-						Reverse: `CREATE SEQUENCE IF NOT EXISTS "public"."previous_name" OWNED BY "public"."posts"."c2"`,
+						Cmd:     `ALTER TABLE "public"."t" ADD COLUMN IF NOT EXISTS "a" integer NOT NULL, DROP COLUMN IF EXISTS "b"`,
+						Reverse: `ALTER TABLE "public"."t" ADD COLUMN IF NOT EXISTS "b" integer NOT NULL, DROP COLUMN IF EXISTS "a"`,
 					},
 				},
 			},
 		},
-		// Add serial sequence.
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-							schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
-						),
-					Changes: schema.Changes{
-						&schema.ModifyColumn{
-							From:   schema.NewIntColumn("c1", "integer"),
-							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-							Change: schema.ChangeType,
-						},
-						&schema.ModifyColumn{
-							From:   schema.NewIntColumn("c2", "integer"),
-							To:     schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
-							Change: schema.ChangeType,
-						},
+					T: schema.NewTable("t").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewIntColumn("a", "int"), Extra: []schema.Clause{&schema.IfNotExists{}}},
+						&schema.AddColumn{C: schema.NewIntColumn("b", "int")},
+						&schema.DropColumn{C: schema.NewIntColumn("c", "int"), Extra: []schema.Clause{&schema.IfExists{}}},
 					},
 				},
 			},
@@ -1008,151 +3451,196 @@ func TestPlanChanges(t *testing.T) {
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c1_seq" OWNED BY "public"."posts"."c1"`,
-						Reverse: `DROP SEQUENCE IF EXISTS "public"."posts_c1_seq"`,
-					},
-					{
-						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "public"."posts_c2_seq" OWNED BY "public"."posts"."c2"`,
-						Reverse: `DROP SEQUENCE IF EXISTS "public"."posts_c2_seq"`,
-					},
-					{
-						Cmd:     `ALTER TABLE "public"."posts" ALTER COLUMN "c1" SET DEFAULT nextval('"public"."posts_c1_seq"'), ALTER COLUMN "c2" SET DEFAULT nextval('"public"."posts_c2_seq"'), ALTER COLUMN "c2" TYPE bigint`,
-						Reverse: `ALTER TABLE "public"."posts" ALTER COLUMN "c2" DROP DEFAULT, ALTER COLUMN "c2" TYPE integer, ALTER COLUMN "c1" DROP DEFAULT`,
+						Cmd:     `ALTER TABLE "public"."t" ADD COLUMN IF NOT EXISTS "a" integer NOT NULL, ADD COLUMN "b" integer NOT NULL, DROP COLUMN IF EXISTS "c"`,
+						Reverse: `ALTER TABLE "public"."t" ADD COLUMN IF NOT EXISTS "c" integer NOT NULL, DROP COLUMN "b", DROP COLUMN IF EXISTS "a"`,
 					},
 				},
 			},
 		},
-		// Change underlying sequence type.
 		{
-			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-							schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
-						),
-					Changes: schema.Changes{
-						&schema.ModifyColumn{
-							From:   schema.NewColumn("c1").SetType(&SerialType{T: "smallserial"}),
-							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-							Change: schema.ChangeType,
-						},
-						&schema.ModifyColumn{
-							From:   schema.NewColumn("c2").SetType(&SerialType{T: "serial"}),
-							To:     schema.NewColumn("c2").SetType(&SerialType{T: "bigserial"}),
-							Change: schema.ChangeType,
-						},
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				return []schema.Change{
+					&schema.AddObject{O: &DefaultPrivilege{Schema: s, Owner: "admin", ObjType: "TABLES", Grantee: "reader", Privileges: []string{"SELECT"}}},
+					&schema.ModifyObject{
+						From: &DefaultPrivilege{Schema: s, ObjType: "TABLES", Grantee: "writer", Privileges: []string{"DELETE", "INSERT"}},
+						To:   &DefaultPrivilege{Schema: s, ObjType: "TABLES", Grantee: "writer", Privileges: []string{"INSERT", "UPDATE"}},
 					},
-				},
-			},
+					&schema.DropObject{O: &DefaultPrivilege{Schema: s, Owner: "admin", ObjType: "SEQUENCES", Grantee: "PUBLIC", Privileges: []string{"USAGE"}}},
+				}
+			}(),
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `ALTER TABLE "public"."posts" ALTER COLUMN "c1" TYPE integer, ALTER COLUMN "c2" TYPE bigint`,
-						Reverse: `ALTER TABLE "public"."posts" ALTER COLUMN "c2" TYPE integer, ALTER COLUMN "c1" TYPE smallint`,
+						Cmd:     `ALTER DEFAULT PRIVILEGES FOR ROLE "admin" IN SCHEMA "public" GRANT SELECT ON TABLES TO "reader"`,
+						Reverse: `ALTER DEFAULT PRIVILEGES FOR ROLE "admin" IN SCHEMA "public" REVOKE SELECT ON TABLES FROM "reader"`,
+					},
+					{
+						Cmd:     `ALTER DEFAULT PRIVILEGES IN SCHEMA "public" REVOKE DELETE ON TABLES FROM "writer"`,
+						Reverse: `ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT DELETE ON TABLES TO "writer"`,
+					},
+					{
+						Cmd:     `ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT UPDATE ON TABLES TO "writer"`,
+						Reverse: `ALTER DEFAULT PRIVILEGES IN SCHEMA "public" REVOKE UPDATE ON TABLES FROM "writer"`,
+					},
+					{
+						Cmd:     `ALTER DEFAULT PRIVILEGES FOR ROLE "admin" IN SCHEMA "public" REVOKE USAGE ON SEQUENCES FROM PUBLIC`,
+						Reverse: `ALTER DEFAULT PRIVILEGES FOR ROLE "admin" IN SCHEMA "public" GRANT USAGE ON SEQUENCES TO PUBLIC`,
 					},
 				},
 			},
 		},
-		// Empty qualifier.
 		{
-			changes: []schema.Change{
-				&schema.AddTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("test1")).
-						AddColumns(
-							schema.NewEnumColumn("c1", schema.EnumName("enum"), schema.EnumValues("a"), schema.EnumSchema(schema.New("test2"))),
-						),
-				},
-			},
-			options: []migrate.PlanOption{
-				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
-			},
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				return []schema.Change{
+					&schema.AddObject{O: &TypeOwner{Schema: s, Name: "state", Owner: "app"}},
+					&schema.ModifyObject{
+						From: &TypeOwner{Schema: s, Name: "mood", Owner: "admin"},
+						To:   &TypeOwner{Schema: s, Name: "mood", Owner: "app"},
+					},
+					&schema.AddTable{
+						T: schema.NewTable("users").
+							SetSchema(s).
+							AddColumns(schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off"), schema.EnumSchema(s))),
+					},
+				}
+			}(),
 			mock: func(m mock) {
-				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e'")).
-					WithArgs("enum").
+				m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t JOIN pg_namespace n on t.typnamespace = n.oid WHERE t.typname = $1 AND t.typtype = 'e' AND n.nspname = $2")).
+					WithArgs("state", "public").
 					WillReturnRows(sqlmock.NewRows([]string{"name"}))
 			},
 			wantPlan: &migrate.Plan{
-				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE TYPE "enum" AS ENUM ('a')`,
-						Reverse: `DROP TYPE "enum"`,
+						Cmd:     `ALTER TYPE "public"."mood" OWNER TO "app"`,
+						Reverse: `ALTER TYPE "public"."mood" OWNER TO "admin"`,
 					},
 					{
-						Cmd:     `CREATE TABLE "posts" ("c1" "enum" NOT NULL)`,
-						Reverse: `DROP TABLE "posts"`,
+						Cmd:     `CREATE TYPE "public"."state" AS ENUM ('on', 'off')`,
+						Reverse: `DROP TYPE "public"."state"`,
+					},
+					{
+						Cmd:     `CREATE TABLE "public"."users" ("state" "public"."state" NOT NULL)`,
+						Reverse: `DROP TABLE "public"."users"`,
+					},
+					{
+						Cmd:     `ALTER TYPE "public"."state" OWNER TO "app"`,
+						Comment: `set the owner of type "state" to "app"`,
 					},
 				},
 			},
 		},
-		// Empty sequence qualifier.
 		{
-			changes: []schema.Change{
-				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-						),
-					Changes: schema.Changes{
-						&schema.ModifyColumn{
-							From:   schema.NewIntColumn("c1", "integer"),
-							To:     schema.NewColumn("c1").SetType(&SerialType{T: "serial"}),
-							Change: schema.ChangeType,
+			changes: func() []schema.Change {
+				s := schema.New("public")
+				return []schema.Change{
+					&schema.AddObject{O: &TextSearchConfiguration{
+						Name: "english", Schema: s, Parser: "default",
+						Mappings: []*TextSearchMapping{{Token: "asciiword", Dicts: []string{"english_stem"}}},
+					}},
+					&schema.ModifyObject{
+						From: &TextSearchConfiguration{
+							Name: "simple", Schema: s, Parser: "default",
+							Mappings: []*TextSearchMapping{
+								{Token: "asciiword", Dicts: []string{"simple"}},
+								{Token: "word", Dicts: []string{"simple"}},
+							},
+						},
+						To: &TextSearchConfiguration{
+							Name: "simple", Schema: s, Parser: "default",
+							Mappings: []*TextSearchMapping{
+								{Token: "asciiword", Dicts: []string{"unaccent", "simple"}},
+								{Token: "numword", Dicts: []string{"simple"}},
+							},
 						},
 					},
-				},
-			},
-			options: []migrate.PlanOption{
-				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
-			},
+					&schema.ModifyObject{
+						From: &TextSearchConfiguration{Name: "custom", Schema: s, Parser: "default"},
+						To:   &TextSearchConfiguration{Name: "custom", Schema: s, Parser: "public.parser"},
+					},
+					&schema.DropObject{O: &TextSearchConfiguration{Name: "old", Schema: s, Parser: "default"}},
+				}
+			}(),
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE SEQUENCE IF NOT EXISTS "posts_c1_seq" OWNED BY "posts"."c1"`,
-						Reverse: `DROP SEQUENCE IF EXISTS "posts_c1_seq"`,
+						Cmd:     `CREATE TEXT SEARCH CONFIGURATION "public"."english" (PARSER = "pg_catalog"."default")`,
+						Reverse: `DROP TEXT SEARCH CONFIGURATION "public"."english"`,
+					},
+					{
+						Cmd:     `ALTER TEXT SEARCH CONFIGURATION "public"."english" ADD MAPPING FOR asciiword WITH english_stem`,
+						Reverse: `ALTER TEXT SEARCH CONFIGURATION "public"."english" DROP MAPPING FOR asciiword`,
+					},
+					{
+						Cmd:     `ALTER TEXT SEARCH CONFIGURATION "public"."simple" ALTER MAPPING FOR asciiword WITH unaccent, simple`,
+						Reverse: `ALTER TEXT SEARCH CONFIGURATION "public"."simple" ALTER MAPPING FOR asciiword WITH simple`,
+					},
+					{
+						Cmd:     `ALTER TEXT SEARCH CONFIGURATION "public"."simple" ADD MAPPING FOR numword WITH simple`,
+						Reverse: `ALTER TEXT SEARCH CONFIGURATION "public"."simple" DROP MAPPING FOR numword`,
+					},
+					{
+						Cmd:     `ALTER TEXT SEARCH CONFIGURATION "public"."simple" DROP MAPPING FOR word`,
+						Reverse: `ALTER TEXT SEARCH CONFIGURATION "public"."simple" ADD MAPPING FOR word WITH simple`,
+					},
+					{
+						Cmd:     `DROP TEXT SEARCH CONFIGURATION "public"."custom"`,
+						Reverse: `CREATE TEXT SEARCH CONFIGURATION "public"."custom" (PARSER = "pg_catalog"."default")`,
 					},
 					{
-						Cmd:     `ALTER TABLE "posts" ALTER COLUMN "c1" SET DEFAULT nextval('"posts_c1_seq"')`,
-						Reverse: `ALTER TABLE "posts" ALTER COLUMN "c1" DROP DEFAULT`,
+						Cmd:     `CREATE TEXT SEARCH CONFIGURATION "public"."custom" (PARSER = "public"."parser")`,
+						Reverse: `DROP TEXT SEARCH CONFIGURATION "public"."custom"`,
+					},
+					{
+						Cmd:     `DROP TEXT SEARCH CONFIGURATION "public"."old"`,
+						Reverse: `CREATE TEXT SEARCH CONFIGURATION "public"."old" (PARSER = "pg_catalog"."default")`,
 					},
 				},
 			},
 		},
-		// Empty index qualifier.
+		// Safe NOT NULL using a validated check constraint.
 		{
 			changes: []schema.Change{
 				&schema.ModifyTable{
-					T: schema.NewTable("posts").
-						SetSchema(schema.New("public")).
-						AddColumns(
-							schema.NewIntColumn("c", "int"),
-						),
-					Changes: schema.Changes{
-						&schema.AddIndex{
-							I: schema.NewIndex("i").AddColumns(schema.NewIntColumn("c", "int")),
+					T: schema.NewTable("users").SetSchema(schema.New("public")),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   schema.NewNullStringColumn("email", "text"),
+							To:     schema.NewStringColumn("email", "text"),
+							Change: schema.ChangeNull,
 						},
 					},
 				},
 			},
 			options: []migrate.PlanOption{
-				func(o *migrate.PlanOptions) { o.SchemaQualifier = new(string) },
+				func(o *migrate.PlanOptions) { o.SafeNotNull = true },
 			},
 			wantPlan: &migrate.Plan{
 				Reversible:    true,
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{
-						Cmd:     `CREATE INDEX "i" ON "posts" ("c")`,
-						Reverse: `DROP INDEX "i"`,
+						Cmd:     `ALTER TABLE "public"."users" ADD CONSTRAINT "users_email_not_null" CHECK ("email" IS NOT NULL) NOT VALID`,
+						Reverse: `ALTER TABLE "public"."users" DROP CONSTRAINT IF EXISTS "users_email_not_null"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" VALIDATE CONSTRAINT "users_email_not_null"`,
+						Reverse: `ALTER TABLE "public"."users" DROP CONSTRAINT IF EXISTS "users_email_not_null"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" ALTER COLUMN "email" SET NOT NULL`,
+						Reverse: `ALTER TABLE "public"."users" ALTER COLUMN "email" DROP NOT NULL`,
+					},
+					{
+						Cmd:     `ALTER TABLE "public"."users" DROP CONSTRAINT "users_email_not_null"`,
+						Reverse: `ALTER TABLE "public"."users" ADD CONSTRAINT "users_email_not_null" CHECK ("email" IS NOT NULL) NOT VALID`,
 					},
 				},
 			},
@@ -1188,10 +3676,209 @@ func TestPlanChanges(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, tt.wantPlan.Reversible, plan.Reversible)
 			require.Equal(t, tt.wantPlan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.wantPlan.Changes))
 			for i, c := range plan.Changes {
 				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
 				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+				if tt.wantPlan.Changes[i].Comment != "" {
+					require.Equal(t, tt.wantPlan.Changes[i].Comment, c.Comment)
+				}
+				if tt.wantPlan.Changes[i].Provenance != nil {
+					require.Equal(t, tt.wantPlan.Changes[i].Provenance, c.Provenance)
+				}
+				require.Equal(t, tt.wantPlan.Changes[i].Warnings, c.Warnings)
 			}
 		})
 	}
 }
+
+func TestTxFormatter(t *testing.T) {
+	files, err := TxFormatter.Format(&migrate.Plan{Version: "1", Name: "empty"})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "1_empty.sql", files[0].Name())
+	require.Empty(t, files[0].Bytes())
+
+	files, err = TxFormatter.Format(&migrate.Plan{
+		Version: "2",
+		Name:    "users",
+		Changes: []*migrate.Change{
+			{Cmd: `CREATE TABLE "users" ("id" bigint NOT NULL)`, Comment: `create "users" table`},
+			{Cmd: `ALTER TABLE "users" ADD COLUMN "name" text NOT NULL`},
+			{Cmd: `CREATE INDEX CONCURRENTLY "users_name" ON "users" ("name")`, Comment: `create index "users_name" to table: "users"`},
+			{Cmd: `DROP INDEX CONCURRENTLY "users_old"`},
+			{Cmd: `ALTER TYPE "state" ADD VALUE 'unknown'`},
+			{Cmd: `CREATE INDEX "users_id" ON "users" ("id")`},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "2_users.sql", files[0].Name())
+	require.Equal(t, `BEGIN;
+-- create "users" table
+CREATE TABLE "users" ("id" bigint NOT NULL);
+ALTER TABLE "users" ADD COLUMN "name" text NOT NULL;
+COMMIT;
+-- atlas:nontransactional
+-- create index "users_name" to table: "users"
+CREATE INDEX CONCURRENTLY "users_name" ON "users" ("name");
+-- atlas:nontransactional
+DROP INDEX CONCURRENTLY "users_old";
+-- atlas:nontransactional
+ALTER TYPE "state" ADD VALUE 'unknown';
+BEGIN;
+CREATE INDEX "users_id" ON "users" ("id");
+COMMIT;
+`, string(files[0].Bytes()))
+}
+
+func TestPlanBaseline(t *testing.T) {
+	var baseline, desired schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = bigint
+  }
+  column "status" {
+    type = enum.status
+  }
+}
+enum "status" {
+  schema = schema.public
+  values = ["active", "inactive"]
+}
+`), &baseline, nil))
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = bigint
+  }
+  column "status" {
+    type = enum.status
+  }
+  column "role" {
+    type = enum.role
+  }
+}
+enum "status" {
+  schema = schema.public
+  values = ["active", "inactive"]
+}
+enum "role" {
+  schema = schema.public
+  values = ["admin", "member"]
+}
+`), &desired, nil))
+	plan, err := PlanBaseline(context.Background(), "add_role", &baseline, &desired)
+	require.NoError(t, err)
+	require.Equal(t, "add_role", plan.Name)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TYPE "public"."role" AS ENUM ('admin', 'member')`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "role" "public"."role" NOT NULL`, plan.Changes[1].Cmd)
+
+	changes, err := DiffBaseline(&desired, &desired)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestApply(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "bigint"))
+	pets := schema.NewTable("pets").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "bigint"))
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: schema.NewIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&Concurrently{})},
+			},
+		},
+		&schema.AddTable{T: pets},
+	}
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape(`CREATE TABLE "public"."users" ("id" bigint NOT NULL)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectCommit()
+	m.ExpectExec(sqltest.Escape(`CREATE INDEX CONCURRENTLY "users_id" ON "public"."users" ("id")`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape(`CREATE TABLE "public"."pets" ("id" bigint NOT NULL)`)).
+		WillReturnError(errors.New("relation exists"))
+	m.ExpectRollback()
+	r, err := Apply(context.Background(), db, changes)
+	require.EqualError(t, err, `create "pets" table: relation exists`)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, r.Applied, 2)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" bigint NOT NULL)`, r.Applied[0].Change.Cmd)
+	require.Equal(t, `CREATE INDEX CONCURRENTLY "users_id" ON "public"."users" ("id")`, r.Applied[1].Change.Cmd)
+	require.Empty(t, r.RolledBack)
+	require.Empty(t, r.Pending)
+	require.NotNil(t, r.Failed)
+	require.Equal(t, `CREATE TABLE "public"."pets" ("id" bigint NOT NULL)`, r.Failed.Change.Cmd)
+	require.EqualError(t, r.Failed.Err, `create "pets" table: relation exists`)
+}
+
+func TestApply_PendingGroup(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "bigint"))
+	pets := schema.NewTable("pets").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewIntColumn("id", "bigint"))
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.AddTable{T: pets},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: schema.NewIndex("users_id").AddColumns(users.Columns[0]).AddAttrs(&Concurrently{})},
+			},
+		},
+	}
+	m.ExpectBegin()
+	m.ExpectExec(sqltest.Escape(`CREATE TABLE "public"."users" ("id" bigint NOT NULL)`)).
+		WillReturnError(errors.New("relation exists"))
+	m.ExpectRollback()
+	r, err := Apply(context.Background(), db, changes)
+	require.EqualError(t, err, `create "users" table: relation exists`)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Empty(t, r.Applied)
+	require.Empty(t, r.RolledBack)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" bigint NOT NULL)`, r.Failed.Change.Cmd)
+	// Statements of the failed transaction are reported along with the following groups.
+	require.Len(t, r.Pending, 2)
+	require.Equal(t, `CREATE TABLE "public"."pets" ("id" bigint NOT NULL)`, r.Pending[0].Cmd)
+	require.Equal(t, `CREATE INDEX CONCURRENTLY "users_id" ON "public"."users" ("id")`, r.Pending[1].Cmd)
+}
+
+func TestPartitionIndexName(t *testing.T) {
+	idx := schema.NewIndex("logs_c")
+	require.Equal(t, "logs_2022_logs_c", partitionIndexName(schema.NewTable("logs_2022"), idx))
+
+	// Long names are trimmed and suffixed with their hash.
+	long := strings.Repeat("p", 60)
+	n1, n2 := partitionIndexName(schema.NewTable(long+"1"), idx), partitionIndexName(schema.NewTable(long+"2"), idx)
+	require.Len(t, n1, maxIdentLen)
+	require.Len(t, n2, maxIdentLen)
+	require.NotEqual(t, n1, n2)
+	require.True(t, strings.HasPrefix(n1, long[:54]+"_"))
+
+	// Multibyte names are trimmed on a rune boundary.
+	name := partitionIndexName(schema.NewTable(strings.Repeat("é", 40)), idx)
+	require.True(t, utf8.ValidString(name))
+	require.LessOrEqual(t, len(name), maxIdentLen)
+	require.True(t, strings.HasPrefix(name, strings.Repeat("é", 27)+"_"))
+}
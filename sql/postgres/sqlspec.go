@@ -7,6 +7,7 @@ package postgres
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,25 +20,146 @@ import (
 
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 type (
 	doc struct {
-		Tables  []*sqlspec.Table  `spec:"table"`
-		Enums   []*Enum           `spec:"enum"`
-		Schemas []*sqlspec.Schema `spec:"schema"`
+		Tables            []*sqlspec.Table        `spec:"table"`
+		Enums             []*Enum                 `spec:"enum"`
+		Collations        []*collationSpec        `spec:"collation"`
+		TSConfigs         []*tsConfigSpec         `spec:"text_search_configuration"`
+		DefaultPrivileges []*defaultPrivilegeSpec `spec:"default_privilege"`
+		EventTriggers     []*eventTriggerSpec     `spec:"event_trigger"`
+		Statistics        []*statisticsSpec       `spec:"statistics"`
+		Rules             []*ruleSpec             `spec:"rule"`
+		Publications      []*publicationSpec      `spec:"publication"`
+		Subscriptions     []*subscriptionSpec     `spec:"subscription"`
+		Casts             []*castSpec             `spec:"cast"`
+		AccessMethods     []*accessMethodSpec     `spec:"access_method"`
+		Schemas           []*sqlspec.Schema       `spec:"schema"`
 	}
 	// Enum holds a specification for an enum, that can be referenced as a column type.
 	Enum struct {
 		Name   string         `spec:",name"`
 		Schema *schemahcl.Ref `spec:"schema"`
 		Values []string       `spec:"values"`
+		Owner  string         `spec:"owner,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// collationSpec holds a specification for a collation object.
+	collationSpec struct {
+		Name          string         `spec:",name"`
+		Schema        *schemahcl.Ref `spec:"schema"`
+		Provider      string         `spec:"provider,omitempty"`
+		Locale        string         `spec:"locale,omitempty"`
+		LCCollate     string         `spec:"lc_collate,omitempty"`
+		LCCtype       string         `spec:"lc_ctype,omitempty"`
+		Deterministic *bool          `spec:"deterministic"`
+		schemahcl.DefaultExtension
+	}
+	// tsConfigSpec holds a specification for a text search configuration object.
+	tsConfigSpec struct {
+		Name     string           `spec:",name"`
+		Schema   *schemahcl.Ref   `spec:"schema"`
+		Parser   string           `spec:"parser,omitempty"`
+		Mappings []*tsMappingSpec `spec:"mapping"`
+		schemahcl.DefaultExtension
+	}
+	// tsMappingSpec holds a specification for the token mappings of a text search configuration.
+	tsMappingSpec struct {
+		Tokens []string `spec:"tokens"`
+		Dicts  []string `spec:"dictionaries"`
+		schemahcl.DefaultExtension
+	}
+	// defaultPrivilegeSpec holds a specification for the default privileges of a schema.
+	defaultPrivilegeSpec struct {
+		Name       string         `spec:",name"`
+		Schema     *schemahcl.Ref `spec:"schema"`
+		Owner      string         `spec:"for_role,omitempty"`
+		On         string         `spec:"on"`
+		To         string         `spec:"to"`
+		Privileges []string       `spec:"privileges"`
+		schemahcl.DefaultExtension
+	}
+	// eventTriggerSpec holds a specification for a database-level event trigger.
+	eventTriggerSpec struct {
+		Name     string   `spec:",name"`
+		On       string   `spec:"on"`
+		Tags     []string `spec:"tags,omitempty"`
+		Function string   `spec:"function"`
+		State    string   `spec:"state,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// statisticsSpec holds a specification for an extended statistics object.
+	statisticsSpec struct {
+		Name    string           `spec:",name"`
+		Schema  *schemahcl.Ref   `spec:"schema"`
+		Columns []*schemahcl.Ref `spec:"columns"`
+		Kinds   []string         `spec:"kinds,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// ruleSpec holds a specification for a rewrite rule. The rule is placed
+	// in the schema of the table it is defined on.
+	ruleSpec struct {
+		Name    string         `spec:",name"`
+		On      *schemahcl.Ref `spec:"on"`
+		Event   string         `spec:"event"`
+		Instead bool           `spec:"instead,omitempty"`
+		Where   string         `spec:"where,omitempty"`
+		Actions string         `spec:"actions,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// publicationSpec holds a specification for a logical replication publication.
+	publicationSpec struct {
+		Name      string           `spec:",name"`
+		AllTables bool             `spec:"all_tables,omitempty"`
+		Tables    []*schemahcl.Ref `spec:"tables,omitempty"`
+		Publish   []string         `spec:"publish,omitempty"`
+		ViaRoot   bool             `spec:"via_partition_root,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// subscriptionSpec holds a specification for a logical replication subscription.
+	// Its parameters (e.g. slot_name) are defined in the options block.
+	subscriptionSpec struct {
+		Name         string   `spec:",name"`
+		Conn         string   `spec:"conn,omitempty"`
+		Publications []string `spec:"publications"`
+		Enabled      *bool    `spec:"enabled"`
+		schemahcl.DefaultExtension
+	}
+	// castSpec holds a specification for a user-defined cast. Casts are identified by
+	// their source and target types, and the block name is used only for readability.
+	castSpec struct {
+		Name     string `spec:",name"`
+		Source   string `spec:"source"`
+		Target   string `spec:"target"`
+		Function string `spec:"function,omitempty"`
+		InOut    bool   `spec:"inout,omitempty"`
+		Context  string `spec:"context,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// accessMethodSpec holds a specification for a custom table or index access method.
+	accessMethodSpec struct {
+		Name    string `spec:",name"`
+		Type    string `spec:"type"`
+		Handler string `spec:"handler"`
 		schemahcl.DefaultExtension
 	}
 )
 
 func init() {
 	schemahcl.Register("enum", &Enum{})
+	schemahcl.Register("collation", &collationSpec{})
+	schemahcl.Register("text_search_configuration", &tsConfigSpec{})
+	schemahcl.Register("default_privilege", &defaultPrivilegeSpec{})
+	schemahcl.Register("event_trigger", &eventTriggerSpec{})
+	schemahcl.Register("statistics", &statisticsSpec{})
+	schemahcl.Register("rule", &ruleSpec{})
+	schemahcl.Register("publication", &publicationSpec{})
+	schemahcl.Register("subscription", &subscriptionSpec{})
+	schemahcl.Register("cast", &castSpec{})
+	schemahcl.Register("access_method", &accessMethodSpec{})
 }
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
@@ -51,11 +173,38 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		if err := specutil.Scan(v, d.Schemas, d.Tables, convertTable); err != nil {
 			return fmt.Errorf("specutil: failed converting to *schema.Realm: %w", err)
 		}
+		if err := convertForeignKeys(d.Tables, v); err != nil {
+			return err
+		}
 		if len(d.Enums) > 0 {
 			if err := convertEnums(d.Tables, d.Enums, v); err != nil {
 				return err
 			}
 		}
+		if err := convertCollations(d.Collations, v); err != nil {
+			return err
+		}
+		if err := convertTSConfigs(d.TSConfigs, v); err != nil {
+			return err
+		}
+		if err := convertDefaultPrivileges(d.DefaultPrivileges, v); err != nil {
+			return err
+		}
+		if err := convertStatistics(d.Statistics, v); err != nil {
+			return err
+		}
+		if err := convertRules(d.Rules, v); err != nil {
+			return err
+		}
+		convertEventTriggers(d.EventTriggers, v)
+		if err := convertPublications(d.Publications, v); err != nil {
+			return err
+		}
+		if err := convertSubscriptions(d.Subscriptions, v); err != nil {
+			return err
+		}
+		convertCasts(d.Casts, v)
+		convertAccessMethods(d.AccessMethods, v)
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
@@ -64,9 +213,27 @@ func evalSpec(p *hclparse.Parser, v any, input map[string]cty.Value) error {
 		if err := specutil.Scan(r, d.Schemas, d.Tables, convertTable); err != nil {
 			return err
 		}
+		if err := convertForeignKeys(d.Tables, r); err != nil {
+			return err
+		}
 		if err := convertEnums(d.Tables, d.Enums, r); err != nil {
 			return err
 		}
+		if err := convertCollations(d.Collations, r); err != nil {
+			return err
+		}
+		if err := convertTSConfigs(d.TSConfigs, r); err != nil {
+			return err
+		}
+		if err := convertDefaultPrivileges(d.DefaultPrivileges, r); err != nil {
+			return err
+		}
+		if err := convertStatistics(d.Statistics, r); err != nil {
+			return err
+		}
+		if err := convertRules(d.Rules, r); err != nil {
+			return err
+		}
 		*v = *r.Schemas[0]
 	default:
 		return fmt.Errorf("specutil: failed unmarshaling spec. %T is not supported", v)
@@ -87,6 +254,11 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 		d.Tables = doc.Tables
 		d.Schemas = doc.Schemas
 		d.Enums = doc.Enums
+		d.Collations = doc.Collations
+		d.TSConfigs = doc.TSConfigs
+		d.DefaultPrivileges = doc.DefaultPrivileges
+		d.Statistics = doc.Statistics
+		d.Rules = doc.Rules
 	case *schema.Realm:
 		for _, s := range s.Schemas {
 			doc, err := schemaSpec(s)
@@ -96,6 +268,25 @@ func MarshalSpec(v any, marshaler schemahcl.Marshaler) ([]byte, error) {
 			d.Tables = append(d.Tables, doc.Tables...)
 			d.Schemas = append(d.Schemas, doc.Schemas...)
 			d.Enums = append(d.Enums, doc.Enums...)
+			d.Collations = append(d.Collations, doc.Collations...)
+			d.TSConfigs = append(d.TSConfigs, doc.TSConfigs...)
+			d.DefaultPrivileges = append(d.DefaultPrivileges, doc.DefaultPrivileges...)
+			d.Statistics = append(d.Statistics, doc.Statistics...)
+			d.Rules = append(d.Rules, doc.Rules...)
+		}
+		for _, o := range s.Objects {
+			switch o := o.(type) {
+			case *EventTrigger:
+				d.EventTriggers = append(d.EventTriggers, fromEventTrigger(o))
+			case *Publication:
+				d.Publications = append(d.Publications, fromPublication(s, o))
+			case *Subscription:
+				d.Subscriptions = append(d.Subscriptions, fromSubscription(o))
+			case *Cast:
+				d.Casts = append(d.Casts, fromCast(o))
+			case *AccessMethod:
+				d.AccessMethods = append(d.AccessMethods, fromAccessMethod(o))
+			}
 		}
 		if err := specutil.QualifyDuplicates(d.Tables); err != nil {
 			return nil, err
@@ -141,16 +332,108 @@ var (
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
 func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
-	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, specutil.Check)
+	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, convertCheck)
 	if err != nil {
 		return nil, err
 	}
 	if err := convertPartition(spec.Extra, t); err != nil {
 		return nil, err
 	}
+	if err := convertStorageParams(spec.Extra, t); err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("owner"); ok {
+		o, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&TableOwner{V: o})
+	}
+	if attr, ok := spec.Attr("access_method"); ok {
+		a, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&TableAccessMethod{V: a})
+	}
 	return t, nil
 }
 
+// convertCheck converts a sqlspec.Check into a schema.Check.
+func convertCheck(spec *sqlspec.Check) (*schema.Check, error) {
+	c, err := specutil.Check(spec)
+	if err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("no_inherit"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			c.AddAttrs(&NoInherit{})
+		}
+	}
+	return c, nil
+}
+
+// convertStorageParams converts and appends the storage_params block into the table attributes if exists.
+func convertStorageParams(spec schemahcl.Resource, table *schema.Table) error {
+	r, ok := spec.Resource("storage_params")
+	if !ok {
+		return nil
+	}
+	p := &TableStorageParams{}
+	add := func(r *schemahcl.Resource, prefix string) error {
+		for _, a := range r.Attrs {
+			v, err := convert.Convert(a.V, cty.String)
+			if err != nil || v.IsNull() {
+				return fmt.Errorf("unexpected value for %s.storage_params.%s%s", table.Name, prefix, a.K)
+			}
+			p.Params = append(p.Params, struct{ N, V string }{N: prefix + a.K, V: v.AsString()})
+		}
+		return nil
+	}
+	if err := add(r, ""); err != nil {
+		return err
+	}
+	if t, ok := r.Resource("toast"); ok {
+		if err := add(t, "toast."); err != nil {
+			return err
+		}
+	}
+	if len(p.Params) > 0 {
+		table.AddAttrs(p)
+	}
+	return nil
+}
+
+// fromStorageParams returns the resource spec for representing the table storage parameters.
+func fromStorageParams(p *TableStorageParams) *schemahcl.Resource {
+	var (
+		toast = &schemahcl.Resource{Type: "toast"}
+		spec  = &schemahcl.Resource{Type: "storage_params"}
+	)
+	for _, kv := range p.Params {
+		r, n := spec, kv.N
+		if strings.HasPrefix(n, "toast.") {
+			r, n = toast, strings.TrimPrefix(n, "toast.")
+		}
+		switch i, err := strconv.ParseInt(kv.V, 10, 64); {
+		case err == nil:
+			r.Attrs = append(r.Attrs, schemahcl.Int64Attr(n, i))
+		case kv.V == "true" || kv.V == "false":
+			r.Attrs = append(r.Attrs, schemahcl.BoolAttr(n, kv.V == "true"))
+		default:
+			r.Attrs = append(r.Attrs, schemahcl.StringAttr(n, kv.V))
+		}
+	}
+	if len(toast.Attrs) > 0 {
+		spec.Children = append(spec.Children, toast)
+	}
+	return spec
+}
+
 // convertPartition converts and appends the partition block into the table attributes if exists.
 func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 	r, ok := spec.Resource("partition")
@@ -164,6 +447,10 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 			Expr   string         `spec:"expr"`
 			Column *schemahcl.Ref `spec:"column"`
 		} `spec:"by"`
+		Bounds []*struct {
+			Name string `spec:",name"`
+			Spec string `spec:"spec"`
+		} `spec:"bound"`
 	}
 	if err := r.As(&p); err != nil {
 		return fmt.Errorf("parsing %s.partition: %w", table.Name, err)
@@ -204,6 +491,16 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 		}
 	}
 	table.AddAttrs(key)
+	if len(p.Bounds) > 0 {
+		bounds := &PartitionBounds{}
+		for _, b := range p.Bounds {
+			if b.Spec == "" {
+				return fmt.Errorf("missing attribute %s.partition.bound.%s.spec", table.Name, b.Name)
+			}
+			bounds.Parts = append(bounds.Parts, &PartitionBound{Name: b.Name, Bound: b.Spec})
+		}
+		table.AddAttrs(bounds)
+	}
 	return nil
 }
 
@@ -258,6 +555,20 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 		}
 		c.Attrs = append(c.Attrs, id)
 	}
+	if attr, ok := spec.Attr("collate"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		c.SetCollation(v)
+	}
+	if attr, ok := spec.Attr("compression"); ok {
+		v, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		c.Attrs = append(c.Attrs, &Compression{V: v})
+	}
 	if err := specutil.ConvertGenExpr(spec.Remain(), c, generatedType); err != nil {
 		return nil, err
 	}
@@ -269,6 +580,8 @@ func convertIdentity(r *schemahcl.Resource) (*Identity, error) {
 		Generation string `spec:"generated"`
 		Start      int64  `spec:"start"`
 		Increment  int64  `spec:"increment"`
+		Min        *int64 `spec:"min_value"`
+		Max        *int64 `spec:"max_value"`
 	}
 	if err := r.As(&spec); err != nil {
 		return nil, err
@@ -280,6 +593,7 @@ func convertIdentity(r *schemahcl.Resource) (*Identity, error) {
 	if spec.Increment != 0 {
 		id.Sequence.Increment = spec.Increment
 	}
+	id.Sequence.Min, id.Sequence.Max = spec.Min, spec.Max
 	return id, nil
 }
 
@@ -320,12 +634,19 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 		}
 		idx.Attrs = append(idx.Attrs, &IndexPredicate{P: p})
 	}
-	if attr, ok := spec.Attr("page_per_range"); ok {
-		p, err := attr.Int64()
+	if attr, ok := spec.Attr("nulls_distinct"); ok {
+		b, err := attr.Bool()
 		if err != nil {
 			return nil, err
 		}
-		idx.Attrs = append(idx.Attrs, &IndexStorageParams{PagesPerRange: p})
+		if !b {
+			idx.Attrs = append(idx.Attrs, &IndexNullsDistinct{V: b})
+		}
+	}
+	if params, err := convertStorage(spec); err != nil {
+		return nil, err
+	} else if params != nil {
+		idx.Attrs = append(idx.Attrs, params)
 	}
 	if attr, ok := spec.Attr("include"); ok {
 		refs, err := attr.Refs()
@@ -346,6 +667,46 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 	return idx, nil
 }
 
+// convertStorage converts the index storage parameters, if exist.
+func convertStorage(spec *sqlspec.Index) (*IndexStorageParams, error) {
+	var (
+		params IndexStorageParams
+		found  bool
+	)
+	if attr, ok := spec.Attr("page_per_range"); ok {
+		p, err := attr.Int64()
+		if err != nil {
+			return nil, err
+		}
+		params.PagesPerRange, found = p, true
+	}
+	if attr, ok := spec.Attr("fillfactor"); ok {
+		p, err := attr.Int64()
+		if err != nil {
+			return nil, err
+		}
+		params.FillFactor, found = p, true
+	}
+	if attr, ok := spec.Attr("fastupdate"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		params.FastUpdate, found = &b, true
+	}
+	if attr, ok := spec.Attr("pending_list_limit"); ok {
+		p, err := attr.Int64()
+		if err != nil {
+			return nil, err
+		}
+		params.PendingListLimit, found = p, true
+	}
+	if !found {
+		return nil, nil
+	}
+	return &params, nil
+}
+
 func convertPart(spec *sqlspec.IndexPart, part *schema.IndexPart) error {
 	switch opc, ok := spec.Attr("ops"); {
 	case !ok:
@@ -395,6 +756,58 @@ func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
 	return typ, nil
 }
 
+// convertForeignKeys converts the driver-specific attributes of the foreign
+// keys (e.g. deferrable) and sets them on the linked schema.ForeignKey.
+func convertForeignKeys(tables []*sqlspec.Table, r *schema.Realm) error {
+	for _, spec := range tables {
+		for _, f := range spec.ForeignKeys {
+			a, ok := f.Attr("deferrable")
+			if !ok {
+				continue
+			}
+			switch b, err := a.Bool(); {
+			case err != nil:
+				return err
+			case !b:
+				continue
+			}
+			s, err := specutil.SchemaName(spec.Schema)
+			if err != nil {
+				return err
+			}
+			fk, err := findForeignKey(r, s, spec.Name, f.Symbol)
+			if err != nil {
+				return err
+			}
+			d := &Deferrable{}
+			if a, ok := f.Attr("initially_deferred"); ok {
+				if d.InitiallyDeferred, err = a.Bool(); err != nil {
+					return err
+				}
+			}
+			fk.AddAttrs(d)
+		}
+	}
+	return nil
+}
+
+// findForeignKey returns the foreign key of the given table.
+func findForeignKey(r *schema.Realm, schemaName, tableName, symbol string) (*schema.ForeignKey, error) {
+	s, ok := r.Schema(schemaName)
+	if !ok {
+		return nil, fmt.Errorf("schema %q was not found", schemaName)
+	}
+	t, ok := s.Table(tableName)
+	if !ok {
+		return nil, fmt.Errorf("table %q was not found in schema %q", tableName, schemaName)
+	}
+	fk, ok := t.ForeignKey(symbol)
+	if !ok {
+		return nil, fmt.Errorf("foreign key %q was not found in table %q", symbol, tableName)
+	}
+	return fk, nil
+}
+
 // convertEnums converts possibly referenced column types (like enums) to
 // an actual schema.Type and sets it on the correct schema.Column.
 func convertEnums(tables []*sqlspec.Table, enums []*Enum, r *schema.Realm) error {
@@ -464,10 +877,484 @@ func convertEnums(tables []*sqlspec.Table, enums []*Enum, r *schema.Realm) error
 		if _, ok := used[e]; !ok {
 			return fmt.Errorf("enum %q declared but not used", e.Name)
 		}
+		if e.Owner == "" {
+			continue
+		}
+		n, err := specutil.SchemaName(e.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from enum reference: %w", err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for enum %q", n, e.Name)
+		}
+		s.AddObjects(&TypeOwner{Schema: s, Name: e.Name, Owner: e.Owner})
+	}
+	return nil
+}
+
+// convertCollations converts the collation specs to Collation objects
+// and adds them to the schemas they are defined in.
+func convertCollations(specs []*collationSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from collation reference: %w", err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for collation %q", n, spec.Name)
+		}
+		s.AddObjects(&Collation{
+			Name:             spec.Name,
+			Schema:           s,
+			Provider:         spec.Provider,
+			Locale:           spec.Locale,
+			LCCollate:        spec.LCCollate,
+			LCCtype:          spec.LCCtype,
+			Nondeterministic: spec.Deterministic != nil && !*spec.Deterministic,
+		})
+	}
+	return nil
+}
+
+// fromCollation converts a Collation object to its spec.
+func fromCollation(c *Collation, ns string) *collationSpec {
+	spec := &collationSpec{
+		Name:      c.Name,
+		Schema:    specutil.SchemaRef(ns),
+		Provider:  c.Provider,
+		Locale:    c.Locale,
+		LCCollate: c.LCCollate,
+		LCCtype:   c.LCCtype,
+	}
+	if c.Nondeterministic {
+		spec.Deterministic = new(bool)
+	}
+	return spec
+}
+
+// convertTSConfigs converts the text search configuration specs to
+// TextSearchConfiguration objects and adds them to their schemas.
+func convertTSConfigs(specs []*tsConfigSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from text search configuration reference: %w", err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for text search configuration %q", n, spec.Name)
+		}
+		c := &TextSearchConfiguration{Name: spec.Name, Schema: s, Parser: spec.Parser}
+		if c.Parser == "" {
+			c.Parser = "default"
+		}
+		for _, m := range spec.Mappings {
+			for _, t := range m.Tokens {
+				c.Mappings = append(c.Mappings, &TextSearchMapping{Token: t, Dicts: m.Dicts})
+			}
+		}
+		sort.Slice(c.Mappings, func(i, j int) bool {
+			return c.Mappings[i].Token < c.Mappings[j].Token
+		})
+		s.AddObjects(c)
+	}
+	return nil
+}
+
+// fromTSConfig converts a TextSearchConfiguration object to its spec. Tokens
+// that are mapped to the same list of dictionaries are grouped together.
+func fromTSConfig(c *TextSearchConfiguration, ns string) *tsConfigSpec {
+	spec := &tsConfigSpec{
+		Name:   c.Name,
+		Schema: specutil.SchemaRef(ns),
+		Parser: c.parser(),
+	}
+	if spec.Parser == "default" {
+		spec.Parser = ""
+	}
+	groups := make(map[string]*tsMappingSpec)
+	for _, m := range c.Mappings {
+		k := strings.Join(m.Dicts, ",")
+		if g, ok := groups[k]; ok {
+			g.Tokens = append(g.Tokens, m.Token)
+			continue
+		}
+		groups[k] = &tsMappingSpec{Tokens: []string{m.Token}, Dicts: m.Dicts}
+		spec.Mappings = append(spec.Mappings, groups[k])
+	}
+	return spec
+}
+
+// convertDefaultPrivileges converts the default privileges specs to
+// DefaultPrivilege objects and adds them to their schemas.
+func convertDefaultPrivileges(specs []*defaultPrivilegeSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from default privileges reference: %w", err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for default privileges %q", n, spec.Name)
+		}
+		// Default privileges are defined IN SCHEMA, which does not apply to SCHEMAS.
+		switch strings.ToUpper(spec.On) {
+		case "TABLES", "SEQUENCES", "FUNCTIONS", "TYPES":
+		default:
+			return fmt.Errorf("unexpected object type %q for default privileges %q. Expect one of: TABLES, SEQUENCES, FUNCTIONS, TYPES", spec.On, spec.Name)
+		}
+		p := &DefaultPrivilege{
+			Schema:     s,
+			Owner:      spec.Owner,
+			ObjType:    strings.ToUpper(spec.On),
+			Grantee:    spec.To,
+			Privileges: make([]string, len(spec.Privileges)),
+		}
+		for i := range spec.Privileges {
+			p.Privileges[i] = strings.ToUpper(spec.Privileges[i])
+		}
+		sort.Strings(p.Privileges)
+		s.AddObjects(p)
+	}
+	return nil
+}
+
+// fromDefaultPrivilege converts a DefaultPrivilege object to its spec.
+// The block name is derived from the object type and the grantee.
+func fromDefaultPrivilege(p *DefaultPrivilege, ns string) *defaultPrivilegeSpec {
+	return &defaultPrivilegeSpec{
+		Name:       strings.ToLower(p.ObjType) + "_" + p.Grantee,
+		Schema:     specutil.SchemaRef(ns),
+		Owner:      p.Owner,
+		On:         p.ObjType,
+		To:         p.Grantee,
+		Privileges: p.Privileges,
+	}
+}
+
+// convertEventTriggers converts the event trigger specs to EventTrigger
+// objects and adds them to the realm. The function may be qualified with
+// its schema name (e.g. public.audit_ddl).
+func convertEventTriggers(specs []*eventTriggerSpec, r *schema.Realm) {
+	for _, spec := range specs {
+		e := &EventTrigger{
+			Name:  spec.Name,
+			Event: spec.On,
+			Tags:  spec.Tags,
+			Func:  spec.Function,
+			State: strings.ToUpper(spec.State),
+		}
+		if ns, name, ok := strings.Cut(spec.Function, "."); ok {
+			e.FuncSchema, e.Func = ns, name
+		}
+		r.AddObjects(e)
+	}
+}
+
+// fromEventTrigger converts an EventTrigger object to its spec.
+func fromEventTrigger(e *EventTrigger) *eventTriggerSpec {
+	spec := &eventTriggerSpec{
+		Name:     e.Name,
+		On:       e.Event,
+		Tags:     e.Tags,
+		Function: e.Func,
+	}
+	if e.FuncSchema != "" {
+		spec.Function = e.FuncSchema + "." + e.Func
+	}
+	if e.state() != "ENABLE" {
+		spec.State = e.state()
+	}
+	return spec
+}
+
+// convertPublications converts the publication specs to Publication objects and adds
+// them to the realm. Unqualified table references are resolved from all schemas.
+func convertPublications(specs []*publicationSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		p := &Publication{Name: spec.Name, AllTables: spec.AllTables, Publish: publishOps(spec.Publish), ViaRoot: spec.ViaRoot}
+		for _, ref := range spec.Tables {
+			t, err := refTable(r, ref)
+			if err != nil {
+				return fmt.Errorf("publication %q: %w", spec.Name, err)
+			}
+			p.Tables = append(p.Tables, t)
+		}
+		r.AddObjects(p)
+	}
+	return nil
+}
+
+// refTable returns the referenced table from the realm. Unqualified
+// references are resolved only if they match a single table.
+func refTable(r *schema.Realm, ref *schemahcl.Ref) (*schema.Table, error) {
+	name := strings.TrimPrefix(ref.V, "$table.")
+	if name == ref.V {
+		return nil, fmt.Errorf("unexpected table reference %q", ref.V)
+	}
+	if ns, tn, ok := strings.Cut(name, "."); ok {
+		if t, ok := realmTable(r, ns, tn); ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("table %q was not found in schema %q", tn, ns)
+	}
+	var found []*schema.Table
+	for _, s := range r.Schemas {
+		if t, ok := s.Table(name); ok {
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("table %q was not found", name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous reference to table %q, qualify it with its schema name", name)
+	}
+}
+
+// fromPublication converts a Publication object to its spec. Table references
+// are qualified with their schema names in case their names are ambiguous.
+func fromPublication(r *schema.Realm, p *Publication) *publicationSpec {
+	spec := &publicationSpec{
+		Name:      p.Name,
+		AllTables: p.AllTables,
+		Publish:   publishOps(p.Publish),
+		ViaRoot:   p.ViaRoot,
+	}
+	for _, t := range p.Tables {
+		spec.Tables = append(spec.Tables, tableRef(r, t))
+	}
+	return spec
+}
+
+// tableRef returns a reference to the given table, which is qualified with
+// its schema name in case other schemas in the realm hold the same name.
+func tableRef(r *schema.Realm, t *schema.Table) *schemahcl.Ref {
+	var n int
+	if r != nil {
+		for _, s := range r.Schemas {
+			if _, ok := s.Table(t.Name); ok {
+				n++
+			}
+		}
+	}
+	v := "$table." + t.Name
+	if n > 1 && t.Schema != nil {
+		v = "$table." + t.Schema.Name + "." + t.Name
+	}
+	return &schemahcl.Ref{V: v}
+}
+
+// convertSubscriptions converts the subscription specs to Subscription objects and adds
+// them to the realm. The attributes of the options block are the subscription parameters.
+func convertSubscriptions(specs []*subscriptionSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		sub := &Subscription{Name: spec.Name, Conn: spec.Conn, Publications: spec.Publications}
+		if spec.Enabled != nil {
+			sub.Disabled = !*spec.Enabled
+		}
+		if opts, ok := spec.Extra.Resource("options"); ok {
+			for _, a := range opts.Attrs {
+				v, err := convert.Convert(a.V, cty.String)
+				if err != nil || v.IsNull() {
+					return fmt.Errorf("unexpected value for subscription.%s.options.%s", spec.Name, a.K)
+				}
+				sub.Options = append(sub.Options, struct{ N, V string }{N: a.K, V: v.AsString()})
+			}
+		}
+		r.AddObjects(sub)
+	}
+	return nil
+}
+
+// fromSubscription converts a Subscription object to its spec.
+func fromSubscription(sub *Subscription) *subscriptionSpec {
+	spec := &subscriptionSpec{
+		Name:         sub.Name,
+		Conn:         sub.Conn,
+		Publications: sub.Publications,
+	}
+	if sub.Disabled {
+		spec.Enabled = new(bool)
+	}
+	if len(sub.Options) > 0 {
+		opts := &schemahcl.Resource{Type: "options"}
+		for _, o := range sub.Options {
+			opts.Attrs = append(opts.Attrs, schemahcl.StringAttr(o.N, o.V))
+		}
+		spec.Extra.Children = append(spec.Extra.Children, opts)
+	}
+	return spec
+}
+
+// convertCasts converts the cast specs to Cast objects and adds them to the realm.
+func convertCasts(specs []*castSpec, r *schema.Realm) {
+	for _, spec := range specs {
+		r.AddObjects(&Cast{
+			Source:  spec.Source,
+			Target:  spec.Target,
+			Func:    spec.Function,
+			InOut:   spec.InOut,
+			Context: strings.ToUpper(spec.Context),
+		})
+	}
+}
+
+// fromCast converts a Cast object to its spec. The block is named
+// after the source and target types, e.g. "text_to_mood".
+func fromCast(c *Cast) *castSpec {
+	spec := &castSpec{
+		Name:     strings.NewReplacer(" ", "_", ".", "_").Replace(c.Source + "_to_" + c.Target),
+		Source:   c.Source,
+		Target:   c.Target,
+		Function: c.Func,
+		InOut:    c.InOut,
+	}
+	if c.context() != "EXPLICIT" {
+		spec.Context = c.context()
+	}
+	return spec
+}
+
+// convertAccessMethods converts the access method specs to
+// AccessMethod objects and adds them to the realm.
+func convertAccessMethods(specs []*accessMethodSpec, r *schema.Realm) {
+	for _, spec := range specs {
+		r.AddObjects(&AccessMethod{
+			Name:    spec.Name,
+			Type:    strings.ToUpper(spec.Type),
+			Handler: spec.Handler,
+		})
+	}
+}
+
+// fromAccessMethod converts an AccessMethod object to its spec.
+func fromAccessMethod(a *AccessMethod) *accessMethodSpec {
+	return &accessMethodSpec{
+		Name:    a.Name,
+		Type:    a.Type,
+		Handler: a.Handler,
+	}
+}
+
+// convertStatistics converts the extended statistics specs to Statistics
+// objects and adds them to their schemas. The statistics table is derived
+// from its column references.
+func convertStatistics(specs []*statisticsSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("extract schema name from statistics reference: %w", err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("schema %q not found in realm for statistics %q", n, spec.Name)
+		}
+		st := &Statistics{Name: spec.Name, Schema: s}
+		for _, ref := range spec.Columns {
+			t, err := statisticsTable(r, s, ref)
+			if err != nil {
+				return fmt.Errorf("statistics %q: %w", spec.Name, err)
+			}
+			if st.Table != nil && st.Table != t {
+				return fmt.Errorf("statistics %q: columns must belong to the same table", spec.Name)
+			}
+			c, err := specutil.ColumnByRef(t, ref)
+			if err != nil {
+				return fmt.Errorf("statistics %q: %w", spec.Name, err)
+			}
+			st.Table = t
+			st.Columns = append(st.Columns, c)
+		}
+		if st.Table == nil {
+			return fmt.Errorf("statistics %q: missing columns", spec.Name)
+		}
+		for _, k := range spec.Kinds {
+			st.Kinds = append(st.Kinds, strings.ToLower(k))
+		}
+		sort.Strings(st.Kinds)
+		s.AddObjects(st)
 	}
 	return nil
 }
 
+// statisticsTable returns the table of the referenced column. Unqualified
+// table references are resolved from the schema of the statistics.
+func statisticsTable(r *schema.Realm, s *schema.Schema, ref *schemahcl.Ref) (*schema.Table, error) {
+	path, _, ok := strings.Cut(ref.V, ".$column.")
+	if !ok || !strings.HasPrefix(path, "$table.") {
+		return nil, fmt.Errorf("unexpected column reference %q", ref.V)
+	}
+	name := strings.TrimPrefix(path, "$table.")
+	if ns, tn, ok := strings.Cut(name, "."); ok {
+		if s, ok = r.Schema(ns); !ok {
+			return nil, fmt.Errorf("schema %q was not found for column reference %q", ns, ref.V)
+		}
+		name = tn
+	}
+	t, ok := s.Table(name)
+	if !ok {
+		return nil, fmt.Errorf("table %q was not found in schema %q", name, s.Name)
+	}
+	return t, nil
+}
+
+// fromStatistics converts a Statistics object to its spec.
+func fromStatistics(st *Statistics, ns string) *statisticsSpec {
+	spec := &statisticsSpec{
+		Name:   st.Name,
+		Schema: specutil.SchemaRef(ns),
+		Kinds:  st.Kinds,
+	}
+	for _, c := range st.Columns {
+		v := "$table." + st.Table.Name + ".$column." + c.Name
+		if st.Table.Schema != nil && st.Table.Schema.Name != ns {
+			v = "$table." + st.Table.Schema.Name + "." + st.Table.Name + ".$column." + c.Name
+		}
+		spec.Columns = append(spec.Columns, &schemahcl.Ref{V: v})
+	}
+	return spec
+}
+
+// convertRules converts the rule specs to Rule objects and
+// adds them to the schemas of the tables they are defined on.
+func convertRules(specs []*ruleSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		if spec.On == nil {
+			return fmt.Errorf("rule %q: missing table reference", spec.Name)
+		}
+		t, err := refTable(r, spec.On)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", spec.Name, err)
+		}
+		t.Schema.AddObjects(&Rule{
+			Name:    spec.Name,
+			Table:   t,
+			Event:   strings.ToUpper(spec.Event),
+			Instead: spec.Instead,
+			Where:   spec.Where,
+			Actions: spec.Actions,
+		})
+	}
+	return nil
+}
+
+// fromRule converts a Rule object to its spec.
+func fromRule(r *schema.Realm, rl *Rule) *ruleSpec {
+	return &ruleSpec{
+		Name:    rl.Name,
+		On:      tableRef(r, rl.Table),
+		Event:   rl.Event,
+		Instead: rl.Instead,
+		Where:   rl.Where,
+		Actions: rl.Actions,
+	}
+}
+
 // enumName extracts the name of the referenced Enum from the reference string.
 func enumName(ref *schemahcl.Type) (string, error) {
 	s := strings.Split(ref.T, "$enum.")
@@ -502,14 +1389,66 @@ func schemaSpec(schem *schema.Schema) (*doc, error) {
 					Name:   e.T,
 					Schema: specutil.SchemaRef(s.Name),
 					Values: e.Values,
+					Owner:  enumOwner(schem, e),
 				})
 				enums[e.T] = true
 			}
 		}
 	}
+	for _, o := range schem.Objects {
+		switch o := o.(type) {
+		case *Collation:
+			d.Collations = append(d.Collations, fromCollation(o, s.Name))
+		case *TextSearchConfiguration:
+			d.TSConfigs = append(d.TSConfigs, fromTSConfig(o, s.Name))
+		case *DefaultPrivilege:
+			d.DefaultPrivileges = append(d.DefaultPrivileges, fromDefaultPrivilege(o, s.Name))
+		case *Statistics:
+			d.Statistics = append(d.Statistics, fromStatistics(o, s.Name))
+		case *Rule:
+			d.Rules = append(d.Rules, fromRule(schem.Realm, o))
+		}
+	}
 	return d, nil
 }
 
+// enumOwner returns the owner of the enum type, if it was inspected or defined.
+func enumOwner(s *schema.Schema, e *schema.EnumType) string {
+	if e.Schema != nil {
+		s = e.Schema
+	}
+	for _, o := range s.Objects {
+		if o, ok := o.(*TypeOwner); ok && o.Name == e.T {
+			return o.Owner
+		}
+	}
+	return ""
+}
+
+// checkSpec converts from a concrete Postgres schema.Check into a sqlspec.Check.
+func checkSpec(s *schema.Check) *sqlspec.Check {
+	c := specutil.FromCheck(s)
+	if sqlx.Has(s.Attrs, &NoInherit{}) {
+		c.Extra.Attrs = append(c.Extra.Attrs, schemahcl.BoolAttr("no_inherit", true))
+	}
+	return c
+}
+
+// fkSpec converts from a concrete Postgres schema.ForeignKey into a sqlspec.ForeignKey.
+func fkSpec(fk *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
+	spec, err := specutil.FromForeignKey(fk)
+	if err != nil {
+		return nil, err
+	}
+	if d := (Deferrable{}); sqlx.Has(fk.Attrs, &d) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("deferrable", true))
+		if d.InitiallyDeferred {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("initially_deferred", true))
+		}
+	}
+	return spec, nil
+}
+
 // tableSpec converts from a concrete Postgres sqlspec.Table to a schema.Table.
 func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 	spec, err := specutil.FromTable(
@@ -517,14 +1456,33 @@ func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 		columnSpec,
 		specutil.FromPrimaryKey,
 		indexSpec,
-		specutil.FromForeignKey,
-		specutil.FromCheck,
+		fkSpec,
+		checkSpec,
 	)
 	if err != nil {
 		return nil, err
 	}
 	if p := (Partition{}); sqlx.Has(table.Attrs, &p) {
-		spec.Extra.Children = append(spec.Extra.Children, fromPartition(p))
+		key := fromPartition(p)
+		if b := (PartitionBounds{}); sqlx.Has(table.Attrs, &b) {
+			for _, p := range b.Parts {
+				key.Children = append(key.Children, &schemahcl.Resource{
+					Type:  "bound",
+					Name:  p.Name,
+					Attrs: []*schemahcl.Attr{schemahcl.StringAttr("spec", p.Bound)},
+				})
+			}
+		}
+		spec.Extra.Children = append(spec.Extra.Children, key)
+	}
+	if p, ok := tableStorageParams(table.Attrs); ok {
+		spec.Extra.Children = append(spec.Extra.Children, fromStorageParams(p))
+	}
+	if o := (TableOwner{}); sqlx.Has(table.Attrs, &o) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("owner", o.V))
+	}
+	if a := (TableAccessMethod{}); sqlx.Has(table.Attrs, &a) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.StringAttr("access_method", a.V))
 	}
 	return spec, nil
 }
@@ -548,8 +1506,22 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	if i := (IndexPredicate{}); sqlx.Has(idx.Attrs, &i) && i.P != "" {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.VarAttr("where", strconv.Quote(i.P)))
 	}
+	if !nullsDistinct(idx.Attrs) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("nulls_distinct", false))
+	}
 	if p, ok := indexStorageParams(idx.Attrs); ok {
-		spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("page_per_range", p.PagesPerRange))
+		if p.FillFactor != 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("fillfactor", p.FillFactor))
+		}
+		if p.PagesPerRange != 0 && p.PagesPerRange != defaultPagePerRange {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("page_per_range", p.PagesPerRange))
+		}
+		if !p.fastUpdate() {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.BoolAttr("fastupdate", false))
+		}
+		if p.PendingListLimit != 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, schemahcl.Int64Attr("pending_list_limit", p.PendingListLimit))
+		}
 	}
 	return spec, nil
 }
@@ -577,8 +1549,14 @@ func columnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error) {
 	if err != nil {
 		return nil, err
 	}
+	if v := (schema.Collation{}); sqlx.Has(c.Attrs, &v) && v.V != "" {
+		s.Extra.Attrs = append(s.Extra.Attrs, schemahcl.StringAttr("collate", v.V))
+	}
+	if m := compression(c.Attrs); m != "" {
+		s.Extra.Attrs = append(s.Extra.Attrs, schemahcl.StringAttr("compression", m))
+	}
 	if i := (&Identity{}); sqlx.Has(c.Attrs, i) {
-		s.Extra.Children = append(s.Extra.Children, fromIdentity(i))
+		s.Extra.Children = append(s.Extra.Children, fromIdentity(c, i))
 	}
 	if x := (schema.GeneratedExpr{}); sqlx.Has(c.Attrs, &x) {
 		s.Extra.Children = append(s.Extra.Children, specutil.FromGenExpr(x, generatedType))
@@ -587,7 +1565,8 @@ func columnSpec(c *schema.Column, _ *schema.Table) (*sqlspec.Column, error) {
 }
 
 // fromIdentity returns the resource spec for representing the identity attributes.
-func fromIdentity(i *Identity) *schemahcl.Resource {
+// Sequence bounds are printed only if they differ from the implicit ones.
+func fromIdentity(c *schema.Column, i *Identity) *schemahcl.Resource {
 	id := &schemahcl.Resource{
 		Type: "identity",
 		Attrs: []*schemahcl.Attr{
@@ -601,6 +1580,14 @@ func fromIdentity(i *Identity) *schemahcl.Resource {
 		if s.Increment != 1 {
 			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("increment", s.Increment))
 		}
+		minV, maxV := identityBounds(c)
+		defMin, defMax := implicitBounds(c, s.Increment)
+		if minV != defMin {
+			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("min_value", minV))
+		}
+		if maxV != defMax {
+			id.Attrs = append(id.Attrs, schemahcl.Int64Attr("max_value", maxV))
+		}
 	}
 	return id
 }
@@ -632,6 +1619,7 @@ var TypeRegistry = schemahcl.NewRegistry(
 		schemahcl.AliasTypeSpec("character_varying", TypeCharVar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
 		schemahcl.NewTypeSpec(TypeChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
 		schemahcl.NewTypeSpec(TypeCharacter, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
+		schemahcl.NewTypeSpec(TypeBPChar, schemahcl.WithAttributes(schemahcl.SizeTypeAttr(false))),
 		schemahcl.NewTypeSpec(TypeInt2),
 		schemahcl.NewTypeSpec(TypeInt4),
 		schemahcl.NewTypeSpec(TypeInt8),
@@ -676,6 +1664,8 @@ var TypeRegistry = schemahcl.NewRegistry(
 		schemahcl.NewTypeSpec(TypeJSON),
 		schemahcl.NewTypeSpec(TypeJSONB),
 		schemahcl.NewTypeSpec(TypeUUID),
+		schemahcl.NewTypeSpec(TypeOID),
+		schemahcl.NewTypeSpec(TypeLO),
 		schemahcl.NewTypeSpec(TypeMoney),
 		schemahcl.NewTypeSpec(TypeTSVector),
 		schemahcl.NewTypeSpec(TypeTSQuery),
@@ -692,6 +1682,8 @@ var TypeRegistry = schemahcl.NewRegistry(
 		schemahcl.NewTypeSpec(TypeDateRange),
 		schemahcl.NewTypeSpec(TypeDateMultiRange),
 		schemahcl.NewTypeSpec("hstore"),
+		schemahcl.NewTypeSpec("ltree"),
+		schemahcl.NewTypeSpec("citext"),
 		schemahcl.NewTypeSpec("sql", schemahcl.WithAttributes(&schemahcl.TypeAttr{Name: "def", Required: true, Kind: reflect.String})),
 	),
 	schemahcl.WithSpecs(func() (specs []*schemahcl.TypeSpec) {
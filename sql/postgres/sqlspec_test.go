@@ -321,6 +321,35 @@ table "t" {
 	require.EqualValues(t, 2, idx.Attrs[1].(*IndexStorageParams).PagesPerRange)
 }
 
+func TestUnmarshalSpec_GINIndex(t *testing.T) {
+	f := `
+schema "s" {}
+table "t" {
+	schema = schema.s
+	column "c" {
+		type = jsonb
+	}
+	index "i" {
+		type = GIN
+		columns = [column.c]
+		fastupdate = false
+		pending_list_limit = 1024
+	}
+}
+`
+	var s schema.Schema
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.NoError(t, err)
+	idx := s.Tables[0].Indexes[0]
+	require.Equal(t, IndexTypeGIN, idx.Attrs[0].(*IndexType).T)
+	p := idx.Attrs[1].(*IndexStorageParams)
+	require.False(t, p.fastUpdate())
+	require.EqualValues(t, 1024, p.PendingListLimit)
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), "    fastupdate         = false\n    pending_list_limit = 1024\n")
+}
+
 func TestUnmarshalSpec_IndexOpClass(t *testing.T) {
 	const f = `table "users" {
   schema = schema.test
@@ -470,6 +499,44 @@ table "logs" {
 		require.Equal(t, expected, s)
 	})
 
+	t.Run("Bounds", func(t *testing.T) {
+		var (
+			s = &schema.Schema{}
+			f = `
+schema "test" {}
+table "logs" {
+	schema = schema.test
+	column "day" {
+		type = date
+	}
+	partition {
+		type = RANGE
+		columns = [column.day]
+		bound "logs_2024_01" {
+			spec = "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"
+		}
+		bound "logs_default" {
+			spec = "DEFAULT"
+		}
+	}
+}
+`
+		)
+		err := EvalHCLBytes([]byte(f), s, nil)
+		require.NoError(t, err)
+		c := schema.NewTimeColumn("day", "date")
+		expected := schema.New("test").
+			AddTables(schema.NewTable("logs").AddColumns(c).AddAttrs(
+				&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}},
+				&PartitionBounds{Parts: []*PartitionBound{
+					{Name: "logs_2024_01", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+					{Name: "logs_default", Bound: "DEFAULT"},
+				}},
+			))
+		expected.SetRealm(schema.NewRealm(expected))
+		require.Equal(t, expected, s)
+	})
+
 	t.Run("Invalid", func(t *testing.T) {
 		err := EvalHCLBytes([]byte(`
 			schema "test" {}
@@ -558,10 +625,79 @@ schema "test" {
 }
 schema "test" {
 }
+`, string(buf))
+	})
+
+	t.Run("Bounds", func(t *testing.T) {
+		c := schema.NewIntColumn("id", "int")
+		s := schema.New("test").
+			AddTables(schema.NewTable("logs").AddColumns(c).AddAttrs(
+				&Partition{T: PartitionTypeList, Parts: []*PartitionPart{{C: c}}},
+				&PartitionBounds{Parts: []*PartitionBound{{Name: "logs_1", Bound: "FOR VALUES IN (1, 2)"}}},
+			))
+		buf, err := MarshalHCL(s)
+		require.NoError(t, err)
+		require.Equal(t, `table "logs" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  partition {
+    type    = LIST
+    columns = [column.id]
+    bound "logs_1" {
+      spec = "FOR VALUES IN (1, 2)"
+    }
+  }
+}
+schema "test" {
+}
 `, string(buf))
 	})
 }
 
+func TestMarshalSpec_StorageParams(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("logs").
+				AddColumns(schema.NewStringColumn("name", "text")).
+				AddAttrs(&TableStorageParams{
+					Params: []struct{ N, V string }{{"parallel_workers", "4"}, {"vacuum_truncate", "false"}, {"autovacuum_vacuum_scale_factor", "0.2"}, {"toast.autovacuum_enabled", "false"}},
+				}),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "logs" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = text
+  }
+  storage_params {
+    parallel_workers               = 4
+    vacuum_truncate                = false
+    autovacuum_vacuum_scale_factor = "0.2"
+    toast {
+      autovacuum_enabled = false
+    }
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	tt, ok := got.Table("logs")
+	require.True(t, ok)
+	p, ok := tableStorageParams(tt.Attrs)
+	require.True(t, ok)
+	require.Nil(t, (&diff{}).storageParamsChange(s.Tables[0].Attrs, tt.Attrs))
+	v, ok := p.Value("toast.autovacuum_enabled")
+	require.True(t, ok)
+	require.Equal(t, "false", v)
+}
+
 func TestMarshalSpec_IndexPredicate(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -612,6 +748,52 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_IndexNullsNotDistinct(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewNullIntColumn("id", "int"),
+					schema.NewNullIntColumn("deleted_at", "int"),
+				),
+		)
+	t1 := s.Tables[0]
+	t1.AddIndexes(
+		schema.NewUniqueIndex("users_id").
+			AddColumns(t1.Columns[0]).
+			AddAttrs(&IndexType{T: IndexTypeBTree}, &IndexPredicate{P: "(deleted_at IS NULL)"}, &IndexNullsDistinct{}),
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = true
+    type = int
+  }
+  column "deleted_at" {
+    null = true
+    type = int
+  }
+  index "users_id" {
+    unique         = true
+    columns        = [column.id]
+    where          = "(deleted_at IS NULL)"
+    nulls_distinct = false
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
 func TestMarshalSpec_BRINIndex(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -1062,6 +1244,473 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Collation(t *testing.T) {
+	s := schema.New("test")
+	s.AddObjects(
+		&Collation{Name: "german", Schema: s, Provider: "libc", Locale: "de_DE"},
+		&Collation{Name: "nocase", Schema: s, Provider: "icu", Locale: "und-u-ks-l2", Nondeterministic: true},
+	)
+	s.AddTables(
+		schema.NewTable("users").
+			AddColumns(schema.NewStringColumn("name", "text").SetCollation("nocase")),
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "name" {
+    null    = false
+    type    = text
+    collate = "nocase"
+  }
+}
+collation "german" {
+  schema   = schema.test
+  provider = "libc"
+  locale   = "de_DE"
+}
+collation "nocase" {
+  schema        = schema.test
+  provider      = "icu"
+  locale        = "und-u-ks-l2"
+  deterministic = false
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Len(t, got.Objects, 2)
+	require.Equal(t, &Collation{Name: "nocase", Schema: &got, Provider: "icu", Locale: "und-u-ks-l2", Nondeterministic: true}, got.Objects[1])
+}
+
+func TestMarshalSpec_TextSearchConfiguration(t *testing.T) {
+	s := schema.New("test")
+	s.AddObjects(
+		&TextSearchConfiguration{
+			Name:   "english",
+			Schema: s,
+			Parser: "default",
+			Mappings: []*TextSearchMapping{
+				{Token: "asciihword", Dicts: []string{"english_stem"}},
+				{Token: "asciiword", Dicts: []string{"english_stem"}},
+				{Token: "word", Dicts: []string{"unaccent", "english_stem"}},
+			},
+		},
+		&TextSearchConfiguration{Name: "custom", Schema: s, Parser: "test.parser"},
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `text_search_configuration "english" {
+  schema = schema.test
+  mapping {
+    tokens       = ["asciihword", "asciiword"]
+    dictionaries = ["english_stem"]
+  }
+  mapping {
+    tokens       = ["word"]
+    dictionaries = ["unaccent", "english_stem"]
+  }
+}
+text_search_configuration "custom" {
+  schema = schema.test
+  parser = "test.parser"
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_DefaultPrivileges(t *testing.T) {
+	s := schema.New("test")
+	s.AddObjects(
+		&DefaultPrivilege{Schema: s, Owner: "admin", ObjType: "TABLES", Grantee: "reader", Privileges: []string{"INSERT", "SELECT"}},
+		&DefaultPrivilege{Schema: s, ObjType: "SEQUENCES", Grantee: "PUBLIC", Privileges: []string{"USAGE"}},
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `default_privilege "tables_reader" {
+  schema     = schema.test
+  for_role   = "admin"
+  on         = "TABLES"
+  to         = "reader"
+  privileges = ["INSERT", "SELECT"]
+}
+default_privilege "sequences_PUBLIC" {
+  schema     = schema.test
+  on         = "SEQUENCES"
+  to         = "PUBLIC"
+  privileges = ["USAGE"]
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+default_privilege "schemas_reader" {
+  schema     = schema.test
+  on         = "schemas"
+  to         = "reader"
+  privileges = ["USAGE"]
+}
+`), &got, nil)
+	require.EqualError(t, err, `unexpected object type "schemas" for default privileges "schemas_reader". Expect one of: TABLES, SEQUENCES, FUNCTIONS, TYPES`)
+}
+
+func TestMarshalSpec_Statistics(t *testing.T) {
+	s := schema.New("test")
+	s.AddTables(
+		schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("city", "int"), schema.NewIntColumn("zip", "int")),
+	)
+	users := s.Tables[0]
+	s.AddObjects(&Statistics{Name: "users_city_zip", Schema: s, Table: users, Kinds: []string{"dependencies", "ndistinct"}, Columns: users.Columns})
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "city" {
+    null = false
+    type = int
+  }
+  column "zip" {
+    null = false
+    type = int
+  }
+}
+statistics "users_city_zip" {
+  schema  = schema.test
+  columns = [table.users.column.city, table.users.column.zip]
+  kinds   = ["dependencies", "ndistinct"]
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	st, ok := got.Objects[0].(*Statistics)
+	require.True(t, ok)
+	require.Equal(t, "users", st.Table.Name)
+	require.Equal(t, got.Tables[0].Columns, st.Columns)
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_Rules(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	r := schema.NewRealm(schema.New("public").AddTables(users))
+	r.Schemas[0].AddObjects(
+		&Rule{Name: "users_audit", Table: users, Event: "UPDATE", Where: "(old.id <> new.id)", Actions: "INSERT INTO logs (id) VALUES (new.id)"},
+		&Rule{Name: "users_protect", Table: users, Event: "DELETE", Instead: true, Actions: "NOTHING"},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+}
+rule "users_audit" {
+  on      = table.users
+  event   = "UPDATE"
+  where   = "(old.id <> new.id)"
+  actions = "INSERT INTO logs (id) VALUES (new.id)"
+}
+rule "users_protect" {
+  on      = table.users
+  event   = "DELETE"
+  instead = true
+  actions = "NOTHING"
+}
+schema "public" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Schemas[0].Objects, 2)
+	rl, ok := got.Schemas[0].Objects[0].(*Rule)
+	require.True(t, ok)
+	require.Equal(t, got.Schemas[0].Tables[0], rl.Table)
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_TableOwner(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddAttrs(&TableOwner{V: "app"}),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  owner  = "app"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Attr{&TableOwner{V: "app"}}, got.Tables[0].Attrs)
+}
+
+func TestMarshalSpec_TableAccessMethod(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("events").
+				AddColumns(schema.NewIntColumn("id", "int")).
+				AddAttrs(&TableAccessMethod{V: "columnar"}),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "events" {
+  schema        = schema.test
+  access_method = "columnar"
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Attr{&TableAccessMethod{V: "columnar"}}, got.Tables[0].Attrs)
+}
+
+func TestMarshalSpec_Casts(t *testing.T) {
+	r := schema.NewRealm(schema.New("public")).AddObjects(
+		&Cast{Source: "mood", Target: "text", Func: "mood_text(mood)", Context: "ASSIGNMENT"},
+		&Cast{Source: "text", Target: "mood", InOut: true, Context: "EXPLICIT"},
+		&Cast{Source: "character varying", Target: "mood", Context: "EXPLICIT"},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `cast "mood_to_text" {
+  source   = "mood"
+  target   = "text"
+  function = "mood_text(mood)"
+  context  = "ASSIGNMENT"
+}
+cast "text_to_mood" {
+  source = "text"
+  target = "mood"
+  inout  = true
+}
+cast "character_varying_to_mood" {
+  source = "character varying"
+  target = "mood"
+}
+schema "public" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_AccessMethods(t *testing.T) {
+	r := schema.NewRealm(schema.New("public")).AddObjects(
+		&AccessMethod{Name: "columnar", Type: "TABLE", Handler: "columnar_handler"},
+		&AccessMethod{Name: "bloom", Type: "INDEX", Handler: "blhandler"},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `access_method "columnar" {
+  type    = "TABLE"
+  handler = "columnar_handler"
+}
+access_method "bloom" {
+  type    = "INDEX"
+  handler = "blhandler"
+}
+schema "public" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_ExtensionTypes(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewColumn("attrs").SetType(&UserDefinedType{T: "hstore"}).SetDefault(&schema.RawExpr{X: `'"a"=>"1", "b"=>"2"'::hstore`}),
+					schema.NewColumn("path").SetType(&UserDefinedType{T: "ltree"}),
+					schema.NewColumn("email").SetType(&UserDefinedType{T: "citext"}),
+				),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "attrs" {
+    null    = false
+    type    = hstore
+    default = sql("'\"a\"=>\"1\", \"b\"=>\"2\"'::hstore")
+  }
+  column "path" {
+    null = false
+    type = ltree
+  }
+  column "email" {
+    null = false
+    type = citext
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+  schema = schema.test
+  column "attrs" {
+    type    = hstore
+    default = sql("'b=>2, a=>1'")
+  }
+  column "path" {
+    type = ltree
+  }
+  column "email" {
+    type = citext
+  }
+}
+`), &got, nil))
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], got.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_IdentityBounds(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("id", "integer").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 1, Increment: 1, Min: i64(1), Max: i64(2147483647)}}),
+					schema.NewIntColumn("rank", "integer").AddAttrs(&Identity{Generation: GeneratedTypeAlways, Sequence: &Sequence{Start: 10, Increment: 1, Min: i64(10), Max: i64(1000)}}),
+				),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = integer
+    identity {
+      generated = ALWAYS
+    }
+  }
+  column "rank" {
+    null = false
+    type = integer
+    identity {
+      generated = ALWAYS
+      start     = 10
+      min_value = 10
+      max_value = 1000
+    }
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	changes, err := DefaultDiff.TableDiff(s.Tables[0], got.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_EnumOwner(t *testing.T) {
+	s := schema.New("test")
+	s.AddTables(
+		schema.NewTable("users").
+			AddColumns(schema.NewEnumColumn("mood", schema.EnumName("mood"), schema.EnumValues("happy", "sad"), schema.EnumSchema(s))),
+	)
+	s.AddObjects(&TypeOwner{Schema: s, Name: "mood", Owner: "app"})
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "mood" {
+    null = false
+    type = enum.mood
+  }
+}
+enum "mood" {
+  schema = schema.test
+  values = ["happy", "sad"]
+  owner  = "app"
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Equal(t, []schema.Object{&TypeOwner{Schema: &got, Name: "mood", Owner: "app"}}, got.Objects)
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
 func TestMarshalSpec_TimePrecision(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -1147,6 +1796,10 @@ func TestTypes(t *testing.T) {
 			typeExpr: "character(255)",
 			expected: &schema.StringType{T: TypeCharacter, Size: 255},
 		},
+		{
+			typeExpr: "bpchar(255)",
+			expected: &schema.StringType{T: TypeBPChar, Size: 255},
+		},
 		{
 			typeExpr: "text",
 			expected: &schema.StringType{T: TypeText},
@@ -1307,6 +1960,10 @@ func TestTypes(t *testing.T) {
 			typeExpr: "numeric(10, 2)",
 			expected: &schema.DecimalType{T: TypeNumeric, Precision: 10, Scale: 2},
 		},
+		{
+			typeExpr: `sql("numeric(2, -3)")`,
+			expected: &schema.DecimalType{T: TypeNumeric, Precision: 2, Scale: -3},
+		},
 		{
 			typeExpr: "decimal",
 			expected: &schema.DecimalType{T: TypeDecimal},
@@ -1359,6 +2016,14 @@ func TestTypes(t *testing.T) {
 			typeExpr: "uuid",
 			expected: &UUIDType{T: TypeUUID},
 		},
+		{
+			typeExpr: "oid",
+			expected: &OIDType{T: TypeOID},
+		},
+		{
+			typeExpr: "lo",
+			expected: &OIDType{T: TypeLO},
+		},
 		{
 			typeExpr: "money",
 			expected: &CurrencyType{T: TypeMoney},
@@ -1435,6 +2100,14 @@ func TestTypes(t *testing.T) {
 			typeExpr: `sql("integer ARRAY")`,
 			expected: &ArrayType{Type: &schema.IntegerType{T: "integer"}, T: "integer[]"},
 		},
+		{
+			typeExpr: `sql("VARCHAR(255)")`,
+			expected: &schema.StringType{T: TypeVarChar, Size: 255},
+		},
+		{
+			typeExpr: `sql("CHARACTER VARYING(255)")`,
+			expected: &schema.StringType{T: TypeCharVar, Size: 255},
+		},
 		{
 			typeExpr: `sql("character varying(255) [1][2]")`,
 			expected: &ArrayType{Type: &schema.StringType{T: "character varying", Size: 255}, T: "character varying(255)[]"},
@@ -1598,6 +2271,10 @@ func TestFormatType_Interval(t *testing.T) {
 			typ: &IntervalType{T: "interval", F: "DAY TO HOUR", Precision: p(6)},
 			fmt: "interval day to hour",
 		},
+		{
+			typ: &IntervalType{T: "interval", F: "DAY TO HOUR", Precision: p(3)},
+			fmt: "interval day to hour",
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			f, err := FormatType(tt.typ)
@@ -1737,3 +2414,182 @@ schema "s2" {
 `,
 		string(got))
 }
+
+func TestMarshalSpec_CheckNoInherit(t *testing.T) {
+	s := schema.New("test")
+	s.AddTables(
+		schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("age", "int")).
+			AddChecks(
+				schema.NewCheck().SetName("age_positive").SetExpr("(age > 0)").AddAttrs(&CheckColumns{Columns: []string{"age"}}, &NoInherit{}),
+				schema.NewCheck().SetName("age_limit").SetExpr("(age < 150)").AddAttrs(&CheckColumns{Columns: []string{"age"}}),
+			),
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "age" {
+    null = false
+    type = int
+  }
+  check "age_positive" {
+    expr       = "(age > 0)"
+    no_inherit = true
+  }
+  check "age_limit" {
+    expr = "(age < 150)"
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	tt, ok := got.Table("users")
+	require.True(t, ok)
+	require.Len(t, tt.Attrs, 2)
+	require.True(t, sqlx.Has(tt.Attrs[0].(*schema.Check).Attrs, &NoInherit{}))
+	require.False(t, sqlx.Has(tt.Attrs[1].(*schema.Check).Attrs, &NoInherit{}))
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_EventTrigger(t *testing.T) {
+	r := schema.NewRealm(schema.New("public")).AddObjects(
+		&EventTrigger{Name: "audit", Event: "ddl_command_end", Tags: []string{"CREATE TABLE", "ALTER TABLE"}, FuncSchema: "public", Func: "log_ddl", State: "ENABLE"},
+		&EventTrigger{Name: "no_drops", Event: "sql_drop", FuncSchema: "admin", Func: "abort_drop", State: "DISABLE"},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `event_trigger "audit" {
+  on       = "ddl_command_end"
+  tags     = ["CREATE TABLE", "ALTER TABLE"]
+  function = "public.log_ddl"
+}
+event_trigger "no_drops" {
+  on       = "sql_drop"
+  function = "admin.abort_drop"
+  state    = "DISABLE"
+}
+schema "public" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Objects, 2)
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_Replication(t *testing.T) {
+	var (
+		sales  = schema.New("sales").AddTables(schema.NewTable("orders").AddColumns(schema.NewIntColumn("id", "int")))
+		public = schema.New("public").AddTables(
+			schema.NewTable("orders").AddColumns(schema.NewIntColumn("id", "int")),
+			schema.NewTable("customers").AddColumns(schema.NewIntColumn("id", "int")),
+		)
+		r = schema.NewRealm(public, sales).AddObjects(
+			&Publication{Name: "all_changes", AllTables: true},
+			&Publication{Name: "orders", Tables: []*schema.Table{sales.Tables[0], public.Tables[1]}, Publish: []string{"insert", "update"}, ViaRoot: true},
+			&Subscription{
+				Name:         "replica",
+				Conn:         "host=primary dbname=app",
+				Publications: []string{"orders"},
+				Disabled:     true,
+				Options:      []struct{ N, V string }{{N: "slot_name", V: "replica"}},
+			},
+		)
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `table "public" "orders" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "customers" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "sales" "orders" {
+  schema = schema.sales
+  column "id" {
+    null = false
+    type = int
+  }
+}
+publication "all_changes" {
+  all_tables = true
+}
+publication "orders" {
+  tables             = [table.sales.orders, table.customers]
+  publish            = ["insert", "update"]
+  via_partition_root = true
+}
+subscription "replica" {
+  conn         = "host=primary dbname=app"
+  publications = ["orders"]
+  enabled      = false
+  options {
+    slot_name = "replica"
+  }
+}
+schema "public" {
+}
+schema "sales" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	require.Len(t, got.Objects, 3)
+	changes, err := DefaultDiff.RealmDiff(r, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestMarshalSpec_ForeignKeyDeferrable(t *testing.T) {
+	s := schema.New("test")
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	posts := schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", "int"))
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author_fk").
+			AddColumns(posts.Columns[0]).
+			SetRefTable(users).
+			AddRefColumns(users.Columns[0]).
+			AddAttrs(&Deferrable{InitiallyDeferred: true}),
+	)
+	s.AddTables(users, posts)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	require.Contains(t, string(buf), `  foreign_key "author_fk" {
+    columns            = [column.author_id]
+    ref_columns        = [table.users.column.id]
+    deferrable         = true
+    initially_deferred = true
+  }`)
+
+	var got schema.Schema
+	require.NoError(t, EvalHCLBytes(buf, &got, nil))
+	tt, ok := got.Table("posts")
+	require.True(t, ok)
+	fk, ok := tt.ForeignKey("author_fk")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Deferrable{InitiallyDeferred: true}}, fk.Attrs)
+	changes, err := DefaultDiff.SchemaDiff(s, &got)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -0,0 +1,596 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Describe returns short, human-readable descriptions of the given change.
+// Table and schema modifications are described by their nested changes,
+// one description per nested change. For example:
+//
+//	modify "users".column "email": set not null
+//	modify "users": add index "users_email"
+func Describe(c Change) []string {
+	switch c := c.(type) {
+	case *AddSchema:
+		return []string{fmt.Sprintf("add schema %q", c.S.Name)}
+	case *DropSchema:
+		return []string{fmt.Sprintf("drop schema %q", c.S.Name)}
+	case *ModifySchema:
+		descs := make([]string, 0, len(c.Changes))
+		for _, c1 := range c.Changes {
+			descs = append(descs, fmt.Sprintf("modify schema %q: %s", c.S.Name, describeAttr(c1)))
+		}
+		return descs
+	case *AddObject:
+		return []string{"add " + objectKind(c.O)}
+	case *DropObject:
+		return []string{"drop " + objectKind(c.O)}
+	case *ModifyObject:
+		return []string{"modify " + objectKind(c.To)}
+	case *MoveObject:
+		return []string{"move " + objectKind(c.To)}
+	case *AddTable:
+		return []string{fmt.Sprintf("add table %q", c.T.Name)}
+	case *DropTable:
+		return []string{fmt.Sprintf("drop table %q", c.T.Name)}
+	case *RenameTable:
+		return []string{fmt.Sprintf("rename table %q to %q", c.From.Name, c.To.Name)}
+	case *MoveTable:
+		return []string{fmt.Sprintf("move table %q from schema %q to %q", c.From.Name, schemaName(c.From), schemaName(c.To))}
+	case *ModifyTable:
+		descs := make([]string, 0, len(c.Changes))
+		for _, c1 := range c.Changes {
+			descs = append(descs, describeTable(c.T, c1))
+		}
+		return descs
+	}
+	return []string{describeKind(c)}
+}
+
+// describeTable describes a nested change of the given table.
+func describeTable(t *Table, c Change) string {
+	switch c := c.(type) {
+	case *AddColumn:
+		return fmt.Sprintf("modify %q: add column %q", t.Name, c.C.Name)
+	case *DropColumn:
+		return fmt.Sprintf("modify %q: drop column %q", t.Name, c.C.Name)
+	case *RenameColumn:
+		return fmt.Sprintf("modify %q: rename column %q to %q", t.Name, c.From.Name, c.To.Name)
+	case *ModifyColumn:
+		return fmt.Sprintf("modify %q.column %q: %s", t.Name, c.To.Name, joinChanges(columnChanges(c)))
+	case *AddIndex:
+		return fmt.Sprintf("modify %q: add index %q", t.Name, c.I.Name)
+	case *DropIndex:
+		return fmt.Sprintf("modify %q: drop index %q", t.Name, c.I.Name)
+	case *RenameIndex:
+		return fmt.Sprintf("modify %q: rename index %q to %q", t.Name, c.From.Name, c.To.Name)
+	case *ModifyIndex:
+		return fmt.Sprintf("modify %q.index %q: %s", t.Name, c.To.Name, joinChanges(kindChanges(c.Change)))
+	case *AddForeignKey:
+		return fmt.Sprintf("modify %q: add foreign key %q", t.Name, c.F.Symbol)
+	case *DropForeignKey:
+		return fmt.Sprintf("modify %q: drop foreign key %q", t.Name, c.F.Symbol)
+	case *ModifyForeignKey:
+		return fmt.Sprintf("modify %q.foreign key %q: %s", t.Name, c.To.Symbol, joinChanges(kindChanges(c.Change)))
+	case *AddCheck:
+		return fmt.Sprintf("modify %q: add check %s", t.Name, checkName(c.C))
+	case *DropCheck:
+		return fmt.Sprintf("modify %q: drop check %s", t.Name, checkName(c.C))
+	case *ModifyCheck:
+		return fmt.Sprintf("modify %q: modify check %s", t.Name, checkName(c.To))
+	case *RenameCheck:
+		return fmt.Sprintf("modify %q: rename check %q to %q", t.Name, c.From.Name, c.To.Name)
+	}
+	return fmt.Sprintf("modify %q: %s", t.Name, describeAttr(c))
+}
+
+// describeAttr describes an attribute change, or any other change by its kind.
+func describeAttr(c Change) string {
+	switch c := c.(type) {
+	case *AddAttr:
+		return "add " + attrKind(c.A)
+	case *DropAttr:
+		return "drop " + attrKind(c.A)
+	case *ModifyAttr:
+		return "modify " + attrKind(c.To)
+	}
+	return describeKind(c)
+}
+
+// columnChanges describes the changes of a column modification.
+func columnChanges(c *ModifyColumn) []string {
+	var descs []string
+	if c.Change.Is(ChangeNull) {
+		if c.To.Type != nil && c.To.Type.Null {
+			descs = append(descs, "drop not null")
+		} else {
+			descs = append(descs, "set not null")
+		}
+	}
+	if c.Change.Is(ChangeDefault) {
+		if c.To.Default == nil {
+			descs = append(descs, "drop default")
+		} else {
+			descs = append(descs, "set default")
+		}
+	}
+	return append(descs, kindChanges(c.Change&^(ChangeNull|ChangeDefault))...)
+}
+
+// kindChanges describes the changes that are set in the given kind.
+func kindChanges(k ChangeKind) []string {
+	var descs []string
+	for _, d := range []struct {
+		k ChangeKind
+		s string
+	}{
+		{ChangeType, "change type"},
+		{ChangeGenerated, "change generation expression"},
+		{ChangeCharset, "change charset"},
+		{ChangeCollate, "change collation"},
+		{ChangeComment, "change comment"},
+		{ChangeUnique, "change uniqueness"},
+		{ChangeParts, "change parts"},
+		{ChangeColumn, "change columns"},
+		{ChangeRefTable, "change referenced table"},
+		{ChangeRefColumn, "change referenced columns"},
+		{ChangeUpdateAction, "change on update action"},
+		{ChangeDeleteAction, "change on delete action"},
+		{ChangeAttr, "change attributes"},
+	} {
+		if k.Is(d.k) {
+			descs = append(descs, d.s)
+		}
+	}
+	return descs
+}
+
+// joinChanges joins the given change descriptions.
+func joinChanges(descs []string) string {
+	if len(descs) == 0 {
+		return "modify"
+	}
+	return strings.Join(descs, ", ")
+}
+
+// checkName returns the quoted name of the check, or its expression if it is unnamed.
+func checkName(c *Check) string {
+	if c.Name != "" {
+		return fmt.Sprintf("%q", c.Name)
+	}
+	return fmt.Sprintf("(%s)", c.Expr)
+}
+
+func schemaName(t *Table) string {
+	if t.Schema == nil {
+		return ""
+	}
+	return t.Schema.Name
+}
+
+// attrKind returns the kind of the given attribute, e.g. "comment".
+func attrKind(a Attr) string {
+	return spaced(reflect.TypeOf(a))
+}
+
+// objectKind returns the kind of the given object, e.g. "text search configuration".
+func objectKind(o Object) string {
+	return spaced(reflect.TypeOf(o))
+}
+
+// describeKind describes an unknown change by its type, e.g. "add partition".
+func describeKind(c Change) string {
+	return spaced(reflect.TypeOf(c))
+}
+
+// spaced returns the name of the (indirected) type, with
+// spaces between its words. For example, "DefaultPrivilege"
+// is returned as "default privilege".
+func spaced(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	return words(indirect(t).Name())
+}
+
+// words returns the given Go identifier in lowercase,
+// with spaces between its words, e.g. "pages per range".
+func words(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// UnifiedDiff returns a git-style report of the given changes, grouped by the
+// schema elements they change. Lines of added elements are prefixed with "+",
+// lines of dropped elements with "-", and modified elements are printed twice,
+// with their previous ("-") and current ("+") definitions. For example:
+//
+//	  table "public"."users"
+//	-   column "name" varchar(255) null
+//	+   column "name" varchar(255) not null
+//	+   index "users_name" (name)
+func UnifiedDiff(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		unified(&b, c)
+	}
+	return b.String()
+}
+
+// unified writes the lines describing a top-level change to the builder.
+func unified(b *strings.Builder, c Change) {
+	switch c := c.(type) {
+	case *AddSchema:
+		diffLine(b, '+', 0, fmt.Sprintf("schema %q", c.S.Name))
+		for _, a := range c.S.Attrs {
+			diffLine(b, '+', 1, attrLine(a))
+		}
+	case *DropSchema:
+		diffLine(b, '-', 0, fmt.Sprintf("schema %q", c.S.Name))
+	case *ModifySchema:
+		diffLine(b, ' ', 0, fmt.Sprintf("schema %q", c.S.Name))
+		for _, c1 := range c.Changes {
+			unifiedAttr(b, c1)
+		}
+	case *AddTable:
+		tableLines(b, '+', c.T)
+	case *DropTable:
+		tableLines(b, '-', c.T)
+	case *RenameTable:
+		diffLine(b, '-', 0, "table "+tableName(c.From))
+		diffLine(b, '+', 0, "table "+tableName(c.To))
+	case *MoveTable:
+		diffLine(b, '-', 0, "table "+tableName(c.From))
+		diffLine(b, '+', 0, "table "+tableName(c.To))
+	case *ModifyTable:
+		diffLine(b, ' ', 0, "table "+tableName(c.T))
+		for _, c1 := range c.Changes {
+			unifiedTable(b, c1)
+		}
+	case *AddObject:
+		diffLine(b, '+', 0, objectLine(c.O))
+	case *DropObject:
+		diffLine(b, '-', 0, objectLine(c.O))
+	case *ModifyObject:
+		diffLine(b, '-', 0, objectLine(c.From))
+		diffLine(b, '+', 0, objectLine(c.To))
+	case *MoveObject:
+		diffLine(b, '-', 0, objectLine(c.From))
+		diffLine(b, '+', 0, objectLine(c.To))
+	default:
+		diffLine(b, ' ', 0, describeKind(c))
+	}
+}
+
+// unifiedTable writes the lines describing a nested change of a table.
+func unifiedTable(b *strings.Builder, c Change) {
+	switch c := c.(type) {
+	case *AddColumn:
+		diffLine(b, '+', 1, columnLine(c.C))
+	case *DropColumn:
+		diffLine(b, '-', 1, columnLine(c.C))
+	case *ModifyColumn:
+		diffLine(b, '-', 1, columnLine(c.From))
+		diffLine(b, '+', 1, columnLine(c.To))
+	case *RenameColumn:
+		diffLine(b, '-', 1, columnLine(c.From))
+		diffLine(b, '+', 1, columnLine(c.To))
+	case *AddIndex:
+		diffLine(b, '+', 1, indexLine(c.I))
+	case *DropIndex:
+		diffLine(b, '-', 1, indexLine(c.I))
+	case *ModifyIndex:
+		diffLine(b, '-', 1, indexLine(c.From))
+		diffLine(b, '+', 1, indexLine(c.To))
+	case *RenameIndex:
+		diffLine(b, '-', 1, indexLine(c.From))
+		diffLine(b, '+', 1, indexLine(c.To))
+	case *AddForeignKey:
+		diffLine(b, '+', 1, foreignKeyLine(c.F))
+	case *DropForeignKey:
+		diffLine(b, '-', 1, foreignKeyLine(c.F))
+	case *ModifyForeignKey:
+		diffLine(b, '-', 1, foreignKeyLine(c.From))
+		diffLine(b, '+', 1, foreignKeyLine(c.To))
+	case *AddCheck:
+		diffLine(b, '+', 1, checkLine(c.C))
+	case *DropCheck:
+		diffLine(b, '-', 1, checkLine(c.C))
+	case *ModifyCheck:
+		diffLine(b, '-', 1, checkLine(c.From))
+		diffLine(b, '+', 1, checkLine(c.To))
+	case *RenameCheck:
+		diffLine(b, '-', 1, checkLine(c.From))
+		diffLine(b, '+', 1, checkLine(c.To))
+	default:
+		unifiedAttr(b, c)
+	}
+}
+
+// unifiedAttr writes the lines describing an attribute change,
+// or any other change by its kind.
+func unifiedAttr(b *strings.Builder, c Change) {
+	switch c := c.(type) {
+	case *AddAttr:
+		diffLine(b, '+', 1, attrLine(c.A))
+	case *DropAttr:
+		diffLine(b, '-', 1, attrLine(c.A))
+	case *ModifyAttr:
+		diffLine(b, '-', 1, attrLine(c.From))
+		diffLine(b, '+', 1, attrLine(c.To))
+	default:
+		diffLine(b, ' ', 1, describeKind(c))
+	}
+}
+
+// tableLines writes the lines of an added or dropped table.
+func tableLines(b *strings.Builder, sign byte, t *Table) {
+	diffLine(b, sign, 0, "table "+tableName(t))
+	for _, c := range t.Columns {
+		diffLine(b, sign, 1, columnLine(c))
+	}
+	if t.PrimaryKey != nil {
+		diffLine(b, sign, 1, "primary key "+partsList(t.PrimaryKey.Parts))
+	}
+	for _, idx := range t.Indexes {
+		diffLine(b, sign, 1, indexLine(idx))
+	}
+	for _, fk := range t.ForeignKeys {
+		diffLine(b, sign, 1, foreignKeyLine(fk))
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*Check); ok {
+			diffLine(b, sign, 1, checkLine(c))
+		} else {
+			diffLine(b, sign, 1, attrLine(a))
+		}
+	}
+}
+
+// diffLine writes a single line with the given sign and indentation level.
+func diffLine(b *strings.Builder, sign byte, level int, s string) {
+	b.WriteByte(sign)
+	b.WriteByte(' ')
+	b.WriteString(strings.Repeat("  ", level))
+	b.WriteString(s)
+	b.WriteByte('\n')
+}
+
+func tableName(t *Table) string {
+	if t.Schema == nil || t.Schema.Name == "" {
+		return fmt.Sprintf("%q", t.Name)
+	}
+	return fmt.Sprintf("%q.%q", t.Schema.Name, t.Name)
+}
+
+func columnLine(c *Column) string {
+	s := fmt.Sprintf("column %q", c.Name)
+	if c.Type != nil {
+		if t := typeString(c.Type); t != "" {
+			s += " " + t
+		}
+		if c.Type.Null {
+			s += " null"
+		} else {
+			s += " not null"
+		}
+	}
+	if c.Default != nil {
+		s += " default " + exprString(c.Default)
+	}
+	return s + attrsString(c.Attrs)
+}
+
+func indexLine(idx *Index) string {
+	s := fmt.Sprintf("index %q", idx.Name)
+	if idx.Unique {
+		s += " unique"
+	}
+	return s + " " + partsList(idx.Parts) + attrsString(idx.Attrs)
+}
+
+func foreignKeyLine(fk *ForeignKey) string {
+	s := fmt.Sprintf("foreign key %q (%s)", fk.Symbol, columnNames(fk.Columns))
+	if fk.RefTable != nil {
+		s += fmt.Sprintf(" references %s (%s)", tableName(fk.RefTable), columnNames(fk.RefColumns))
+	}
+	if fk.OnUpdate != "" {
+		s += " on update " + strings.ToLower(string(fk.OnUpdate))
+	}
+	if fk.OnDelete != "" {
+		s += " on delete " + strings.ToLower(string(fk.OnDelete))
+	}
+	return s + attrsString(fk.Attrs)
+}
+
+func checkLine(c *Check) string {
+	return "check " + checkName(c) + attrsString(c.Attrs)
+}
+
+// attrLine returns the kind of the attribute followed by its value,
+// e.g. "table storage params: fillfactor = 70, autovacuum_enabled = false".
+func attrLine(a Attr) string {
+	if v := valueString(reflect.ValueOf(a), 0); v != "" {
+		return attrKind(a) + ": " + v
+	}
+	return attrKind(a)
+}
+
+// objectLine returns the kind of the object followed by its fields.
+func objectLine(o Object) string {
+	if v := valueString(reflect.ValueOf(o), 0); v != "" {
+		return objectKind(o) + ": " + v
+	}
+	return objectKind(o)
+}
+
+// attrsString returns the extra attributes of a schema element, wrapped in brackets.
+func attrsString(attrs []Attr) string {
+	var vs []string
+	for _, a := range attrs {
+		switch a.(type) {
+		// Printed by the element, or as separate changes.
+		case *Check:
+		default:
+			vs = append(vs, attrLine(a))
+		}
+	}
+	if len(vs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(vs, "; ") + "]"
+}
+
+func partsList(parts []*IndexPart) string {
+	vs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		var v string
+		switch {
+		case p.C != nil:
+			v = p.C.Name
+		case p.X != nil:
+			v = exprString(p.X)
+		}
+		if p.Desc {
+			v += " desc"
+		}
+		vs = append(vs, v)
+	}
+	return "(" + strings.Join(vs, ", ") + ")"
+}
+
+func columnNames(columns []*Column) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// typeString returns the type of the column as it is defined
+// in the database, or its name in case its raw form is unknown.
+func typeString(ct *ColumnType) string {
+	if ct.Raw != "" {
+		return ct.Raw
+	}
+	if ct.Type == nil {
+		return ""
+	}
+	if v := reflect.Indirect(reflect.ValueOf(ct.Type)); v.Kind() == reflect.Struct {
+		if f := v.FieldByName("T"); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return spaced(reflect.TypeOf(ct.Type))
+}
+
+func exprString(x Expr) string {
+	switch x := x.(type) {
+	case *Literal:
+		return x.V
+	case *RawExpr:
+		return x.X
+	}
+	return valueString(reflect.ValueOf(x), 0)
+}
+
+// valueString returns a readable form of the (non-zero) fields of the given value.
+// Schema elements are referenced by their names, and key-value pairs (structs with
+// N and V fields, e.g. storage parameters) are printed as "N = V".
+func valueString(v reflect.Value, depth int) string {
+	if !v.IsValid() || depth > 3 {
+		return ""
+	}
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case *Schema:
+			if x != nil {
+				return fmt.Sprintf("%q", x.Name)
+			}
+		case *Table:
+			if x != nil {
+				return tableName(x)
+			}
+		case *Column:
+			if x != nil {
+				return fmt.Sprintf("%q", x.Name)
+			}
+		case *Index:
+			if x != nil {
+				return fmt.Sprintf("%q", x.Name)
+			}
+		case *Literal:
+			if x != nil {
+				return x.V
+			}
+		case *RawExpr:
+			if x != nil {
+				return x.X
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return valueString(v.Elem(), depth)
+	case reflect.Struct:
+		if n, v1 := v.FieldByName("N"), v.FieldByName("V"); v.NumField() == 2 && n.IsValid() && v1.IsValid() {
+			return fmt.Sprintf("%v = %v", n.Interface(), v1.Interface())
+		}
+		var (
+			n      int
+			fields []string
+		)
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.Anonymous || !f.IsExported() {
+				continue
+			}
+			n++
+			if s := valueString(v.Field(i), depth+1); s != "" && !v.Field(i).IsZero() {
+				fields = append(fields, words(f.Name)+": "+s)
+			}
+		}
+		// Single-field values (e.g. comments) are printed without their field names.
+		if n == 1 && len(fields) == 1 {
+			_, s, _ := strings.Cut(fields[0], ": ")
+			return s
+		}
+		return strings.Join(fields, ", ")
+	case reflect.Slice, reflect.Array:
+		vs := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if s := valueString(v.Index(i), depth+1); s != "" {
+				vs = append(vs, s)
+			}
+		}
+		return strings.Join(vs, ", ")
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		if v.Bool() {
+			return "true"
+		}
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,66 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+type storageParams struct {
+	schema.Attr
+	Params []struct{ N, V string }
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		from   = schema.NewStringColumn("name", "varchar(255)").SetNull(true)
+		to     = schema.NewStringColumn("name", "varchar(255)").SetComment("full name")
+		age    = schema.NewIntColumn("age", "integer").SetDefault(&schema.Literal{V: "0"})
+		posts  = schema.NewTable("posts").SetSchema(public).
+			AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("author_id", "bigint"))
+	)
+	posts.SetPrimaryKey(schema.NewPrimaryKey(posts.Columns[0]))
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(posts.Columns[1]).SetRefTable(users).AddRefColumns(schema.NewIntColumn("id", "bigint")).SetOnDelete(schema.Cascade))
+	changes := []schema.Change{
+		&schema.AddSchema{S: public},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeNull | schema.ChangeComment},
+				&schema.AddColumn{C: age},
+				&schema.AddIndex{I: schema.NewUniqueIndex("users_age").AddColumns(age)},
+				&schema.DropCheck{C: schema.NewCheck().SetName("positive").SetExpr("age > 0")},
+				&schema.ModifyAttr{
+					From: &storageParams{Params: []struct{ N, V string }{{"fillfactor", "70"}}},
+					To:   &storageParams{Params: []struct{ N, V string }{{"fillfactor", "80"}, {"toast.autovacuum_enabled", "false"}}},
+				},
+			},
+		},
+		&schema.AddTable{T: posts},
+		&schema.DropTable{T: schema.NewTable("logs")},
+	}
+	require.Equal(t, `+ schema "public"
+  table "public"."users"
+-   column "name" varchar(255) null
++   column "name" varchar(255) not null [comment: full name]
++   column "age" integer not null default 0
++   index "users_age" unique (age)
+-   check "positive"
+-   storage params: fillfactor = 70
++   storage params: fillfactor = 80, toast.autovacuum_enabled = false
++ table "public"."posts"
++   column "id" bigint not null
++   column "author_id" bigint not null
++   primary key (id)
++   foreign key "author" (author_id) references "public"."users" (id) on delete cascade
+- table "logs"
+`, schema.UnifiedDiff(changes))
+}
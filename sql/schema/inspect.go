@@ -43,6 +43,16 @@ const (
 	// InspectTables enables schema tables inspection including
 	// all its child resources (e.g. columns or indexes).
 	InspectTables
+
+	// InspectObjects enables inspection of driver-specific
+	// schema objects (e.g. collations).
+	InspectObjects
+
+	// InspectOwners enables inspection of the roles that own the schema
+	// resources (e.g. table owners), if supported by the driver. Unlike the
+	// modes above, it is not enabled by default, and should be set along with
+	// them by users that manage ownership in their desired state.
+	InspectOwners
 )
 
 // Is reports whether the given mode is enabled.
@@ -7,6 +7,8 @@ package schema
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"time"
 )
 
@@ -63,6 +65,23 @@ type (
 		Changes []Change
 	}
 
+	// AddObject describes a generic object creation change.
+	AddObject struct {
+		O     Object
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// DropObject describes a generic object removal change.
+	DropObject struct {
+		O     Object
+		Extra []Clause // Extra clauses and options.
+	}
+
+	// ModifyObject describes a generic object modification change.
+	ModifyObject struct {
+		From, To Object
+	}
+
 	// AddTable describes a table creation change.
 	AddTable struct {
 		T     *Table
@@ -86,14 +105,28 @@ type (
 		From, To *Table
 	}
 
+	// MoveTable describes a change that moves a table to another
+	// schema, e.g. "ALTER TABLE a.t SET SCHEMA b" in PostgreSQL.
+	MoveTable struct {
+		From, To *Table
+	}
+
+	// MoveObject describes a change that moves
+	// a schema object to another schema.
+	MoveObject struct {
+		From, To Object
+	}
+
 	// AddColumn describes a column creation change.
 	AddColumn struct {
-		C *Column
+		C     *Column
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropColumn describes a column removal change.
 	DropColumn struct {
-		C *Column
+		C     *Column
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// ModifyColumn describes a change that modifies a column.
@@ -130,7 +163,8 @@ type (
 
 	// AddForeignKey describes a foreign-key creation change.
 	AddForeignKey struct {
-		F *ForeignKey
+		F     *ForeignKey
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropForeignKey describes a foreign-key removal change.
@@ -146,7 +180,8 @@ type (
 
 	// AddCheck describes a CHECK constraint creation change.
 	AddCheck struct {
-		C *Check
+		C     *Check
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropCheck describes a CHECK constraint removal change.
@@ -160,19 +195,27 @@ type (
 		Change   ChangeKind
 	}
 
+	// RenameCheck describes a CHECK constraint rename change.
+	RenameCheck struct {
+		From, To *Check
+	}
+
 	// AddAttr describes an attribute addition.
 	AddAttr struct {
-		A Attr
+		A     Attr
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// DropAttr describes an attribute removal.
 	DropAttr struct {
-		A Attr
+		A     Attr
+		Extra []Clause // Extra clauses and options.
 	}
 
 	// ModifyAttr describes a change that modifies an element attribute.
 	ModifyAttr struct {
 		From, To Attr
+		Extra    []Clause // Extra clauses and options.
 	}
 
 	// IfExists represents a clause in a schema change that is commonly
@@ -182,6 +225,15 @@ type (
 	// IfNotExists represents a clause in a schema change that is commonly
 	// supported by multiple statements (e.g. CREATE TABLE or CREATE SCHEMA).
 	IfNotExists struct{}
+
+	// AttrStmt represents a clause in an attribute change that was reported
+	// by an AttrDiffer. It holds the statement for applying the change, which
+	// is executed as is by the planners, or is empty in case the change is
+	// reported by the Differ only, and is therefore skipped by the planners.
+	AttrStmt struct {
+		Cmd string
+		C   *Column // Column of the attribute, or nil for table attributes.
+	}
 )
 
 // A ChangeKind describes a change kind that can be combined
@@ -265,6 +317,305 @@ type Differ interface {
 	TableDiff(from, to *Table) ([]Change, error)
 }
 
+// DifferWithOptions is an optional interface implemented by
+// Differ implementations that can be configured with DiffOptions.
+type DifferWithOptions interface {
+	Differ
+
+	// WithOptions returns a copy of the Differ that applies
+	// the given options on all of its diff calls.
+	WithOptions(opts ...DiffOption) Differ
+}
+
+type (
+	// DiffOptions defines the standard configuration for the diffing process.
+	DiffOptions struct {
+		// IgnoreAttrs holds the attribute types that are skipped
+		// by the Differ. For example, &schema.Comment{}.
+		IgnoreAttrs []Attr
+
+		// MergeDropAdd indicates if pairs of drop and add changes of the same
+		// object (e.g. DropIndex and AddIndex) should be merged into a single
+		// modify change (e.g. ModifyIndex) when the driver supports it.
+		MergeDropAdd bool
+
+		// FoldIdentifiers indicates if identifiers of the desired state should
+		// be matched case-insensitively against their lowercased form in the
+		// current state, as databases like PostgreSQL fold unquoted identifiers.
+		FoldIdentifiers bool
+
+		// RealmQualifier holds the names of the schemas that are managed by a realm
+		// diff. Schemas that are not listed are ignored, and neither dropped nor
+		// modified. An empty list indicates that all schemas are managed.
+		RealmQualifier []string
+
+		// AdditiveOnly indicates if the Differ should return only the changes
+		// that add objects, and skip the ones that drop or modify them.
+		AdditiveOnly bool
+
+		// StructuralOnly indicates if the Differ should skip changes of attributes
+		// that do not affect the logical structure of the schema, like comments and
+		// attributes that implement the PhysicalAttr interface.
+		StructuralOnly bool
+
+		// SchemaMoves maps the qualified names of the tables and objects in
+		// the current state (e.g. "a.users") to the schemas they are moved to
+		// in the desired state. Used by realm diffs only.
+		SchemaMoves map[string]string
+
+		// AttrDiffers holds the comparators of custom attribute types (e.g.
+		// attributes that are added to the schema elements by extensions),
+		// that are compared for tables and columns. See WithAttrDiffer.
+		AttrDiffers []AttrDiffer
+
+		// WarnFunc is called with the warnings reported by the Differ for
+		// differences that cannot be migrated, and are therefore ignored.
+		// For example, a column order that cannot be applied.
+		WarnFunc func(string)
+	}
+
+	// PhysicalAttr is implemented by attributes that describe how an element is
+	// stored, rather than its logical structure. For example, table storage
+	// parameters like fillfactor. These attributes are skipped by the Differ
+	// in case the StructuralOnly option is set.
+	PhysicalAttr interface {
+		Attr
+		Physical()
+	}
+
+	// AttrDiffer compares the attributes of a custom type between the current
+	// and the desired state of an element.
+	AttrDiffer struct {
+		// Attr is the attribute type that is compared. For example, &Audit{}.
+		Attr Attr
+
+		// Diff reports if the attribute was changed, where a missing attribute is
+		// passed as nil. Optionally, it returns the statement that applies the change.
+		Diff func(from, to Attr) (changed bool, stmt string)
+	}
+
+	// DiffOption allows configuring the DiffOptions using functional options.
+	DiffOption func(*DiffOptions)
+)
+
+// NewDiffOptions creates a new DiffOptions from the given configuration.
+func NewDiffOptions(opts ...DiffOption) *DiffOptions {
+	o := &DiffOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithIgnoreAttrs instructs the Differ to skip changes of the given attribute
+// types. For example, ignoring all comment changes:
+//
+//	d.WithOptions(schema.WithIgnoreAttrs(&schema.Comment{})).SchemaDiff(from, to)
+func WithIgnoreAttrs(attrs ...Attr) DiffOption {
+	return func(o *DiffOptions) {
+		o.IgnoreAttrs = append(o.IgnoreAttrs, attrs...)
+	}
+}
+
+// WithMergeDropAdd instructs the Differ to merge pairs of drop and add changes
+// that target the same logical object into a single modify change. Pairs that
+// cannot be modified in place by the driver are kept as is.
+func WithMergeDropAdd() DiffOption {
+	return func(o *DiffOptions) {
+		o.MergeDropAdd = true
+	}
+}
+
+// WithFoldIdentifiers instructs the Differ to match the identifiers of the
+// desired state (e.g. MyTable) with their lowercased form in the current state
+// (e.g. mytable), in case there is no exact match. Matched elements of the
+// desired state are renamed to their current names. Identifiers that are
+// stored in mixed-case (i.e. quoted) are still compared case-sensitively.
+func WithFoldIdentifiers() DiffOption {
+	return func(o *DiffOptions) {
+		o.FoldIdentifiers = true
+	}
+}
+
+// WithRealmQualifier restricts a realm diff to the given schemas. Other schemas
+// are ignored by the Differ, and references to their tables (e.g. foreign keys
+// from managed tables) are kept as is.
+func WithRealmQualifier(schemas ...string) DiffOption {
+	return func(o *DiffOptions) {
+		o.RealmQualifier = append(o.RealmQualifier, schemas...)
+	}
+}
+
+// WithAdditiveOnly instructs the Differ to return only changes that add objects
+// (e.g. AddTable or AddColumn), and skip the changes that drop, modify or rename
+// them. Use AdditiveOnly to get a summary of the skipped changes. An error is
+// returned in case an added object depends on a skipped change.
+func WithAdditiveOnly() DiffOption {
+	return func(o *DiffOptions) {
+		o.AdditiveOnly = true
+	}
+}
+
+// WithStructuralOnly instructs the Differ to skip changes of attributes that do
+// not affect the logical structure of the schema, like comments or storage
+// parameters, and report only changes of tables, columns, types and constraints.
+func WithStructuralOnly() DiffOption {
+	return func(o *DiffOptions) {
+		o.StructuralOnly = true
+	}
+}
+
+// WithSchemaMove hints the Differ that the table (or object) with the given
+// qualified name is moved to another schema. Moved tables are planned as
+// moves (e.g. "SET SCHEMA" in PostgreSQL), instead of being dropped from
+// the current schema and created in the new one. For example:
+//
+//	d.WithOptions(schema.WithSchemaMove("a.users", "b")).RealmDiff(from, to)
+func WithSchemaMove(name, schema string) DiffOption {
+	return func(o *DiffOptions) {
+		if o.SchemaMoves == nil {
+			o.SchemaMoves = make(map[string]string)
+		}
+		o.SchemaMoves[name] = schema
+	}
+}
+
+// WithWarnings instructs the Differ to report its warnings to the given function.
+// Warnings describe differences between the states that the Differ ignores, as
+// they cannot be applied by the database. For example:
+//
+//	var warns []string
+//	d.WithOptions(schema.WithWarnings(func(w string) { warns = append(warns, w) })).SchemaDiff(from, to)
+func WithWarnings(f func(string)) DiffOption {
+	return func(o *DiffOptions) {
+		o.WarnFunc = f
+	}
+}
+
+// WithAttrDiffer registers a comparator for the given attribute type, which
+// is invoked by the Differ for the tables and columns in both states that have
+// an attribute of this type. Changed attributes are reported as AddAttr,
+// ModifyAttr or DropAttr changes of their tables (also for columns), holding
+// an AttrStmt clause with the returned statement. For example:
+//
+//	d.WithOptions(schema.WithAttrDiffer(&Audit{}, func(from, to schema.Attr) (bool, string) {
+//		...
+//	})).SchemaDiff(from, to)
+//
+// Changes without statements are reported by the Differ, but are not planned.
+func WithAttrDiffer(a Attr, diff func(from, to Attr) (bool, string)) DiffOption {
+	return func(o *DiffOptions) {
+		o.AttrDiffers = append(o.AttrDiffers, AttrDiffer{Attr: a, Diff: diff})
+	}
+}
+
+// Warnf reports a formatted warning to the configured WarnFunc, if any.
+func (o *DiffOptions) Warnf(format string, args ...any) {
+	if o != nil && o.WarnFunc != nil {
+		o.WarnFunc(fmt.Sprintf(format, args...))
+	}
+}
+
+// Managed reports if the given schema is managed by a realm diff.
+func (o *DiffOptions) Managed(name string) bool {
+	if o == nil || len(o.RealmQualifier) == 0 {
+		return true
+	}
+	for _, s := range o.RealmQualifier {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Filtering reports if the Differ skips some attributes.
+func (o *DiffOptions) Filtering() bool {
+	return o != nil && (o.StructuralOnly || len(o.IgnoreAttrs) > 0)
+}
+
+// Ignored reports if the given attribute type should be skipped by the Differ.
+func (o *DiffOptions) Ignored(a Attr) bool {
+	if o == nil || a == nil {
+		return false
+	}
+	if o.StructuralOnly {
+		switch a.(type) {
+		case *Comment, PhysicalAttr:
+			return true
+		}
+	}
+	t := indirect(reflect.TypeOf(a))
+	for _, i := range o.IgnoreAttrs {
+		if indirect(reflect.TypeOf(i)) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// AttrChanges returns the changes of the custom attributes between the
+// given attribute lists, as reported by the registered AttrDiffers.
+func (o *DiffOptions) AttrChanges(from, to []Attr) []Change {
+	if o == nil {
+		return nil
+	}
+	var changes []Change
+	for _, d := range o.AttrDiffers {
+		a1, a2 := attrOf(from, d.Attr), attrOf(to, d.Attr)
+		if a1 == nil && a2 == nil || o.Ignored(d.Attr) {
+			continue
+		}
+		changed, stmt := d.Diff(a1, a2)
+		if !changed {
+			continue
+		}
+		extra := []Clause{&AttrStmt{Cmd: stmt}}
+		switch {
+		case a1 == nil:
+			changes = append(changes, &AddAttr{A: a2, Extra: extra})
+		case a2 == nil:
+			changes = append(changes, &DropAttr{A: a1, Extra: extra})
+		default:
+			changes = append(changes, &ModifyAttr{From: a1, To: a2, Extra: extra})
+		}
+	}
+	return changes
+}
+
+// attrOf returns the first attribute with the same type as a, or nil.
+func attrOf(attrs []Attr, a Attr) Attr {
+	t := indirect(reflect.TypeOf(a))
+	for _, a := range attrs {
+		if indirect(reflect.TypeOf(a)) == t {
+			return a
+		}
+	}
+	return nil
+}
+
+// FilterAttrs returns the given attributes without the ignored ones.
+// The input slice is returned as-is in case no attribute was ignored.
+func (o *DiffOptions) FilterAttrs(attrs []Attr) []Attr {
+	if !o.Filtering() {
+		return attrs
+	}
+	filtered := make([]Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if !o.Ignored(a) {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
 // ErrLocked is returned on Lock calls which have failed to obtain the lock.
 var ErrLocked = errors.New("sql/schema: lock is held by other session")
 
@@ -357,6 +708,230 @@ Loop:
 	*c = changes
 }
 
+// DestructiveChanges returns the changes that remove data-bearing objects from
+// the database: DropSchema, DropTable and DropColumn. Column drops are returned
+// wrapped in a ModifyTable that holds only them, in order to keep their table.
+func DestructiveChanges(changes []Change) []Change {
+	var drops []Change
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *DropSchema, *DropTable:
+			drops = append(drops, c)
+		case *ModifyTable:
+			var cols []Change
+			for _, c1 := range c.Changes {
+				if d, ok := c1.(*DropColumn); ok {
+					cols = append(cols, d)
+				}
+			}
+			if len(cols) > 0 {
+				drops = append(drops, &ModifyTable{T: c.T, Changes: cols})
+			}
+		}
+	}
+	return drops
+}
+
+type (
+	// RealmChanges holds the changes of a realm grouped by the objects they apply to.
+	// Changes that are not bound to a schema, such as object changes, are held in
+	// Changes, and the rest are grouped by their schema in Schemas.
+	RealmChanges struct {
+		Changes []Change
+		Schemas []*SchemaChanges
+	}
+
+	// SchemaChanges holds the changes of a schema. Changes that apply to the schema
+	// itself, or add, drop or rename its tables, are held in Changes, and the changes
+	// of its existing tables are grouped by table in Tables.
+	SchemaChanges struct {
+		Name    string
+		Changes []Change
+		Tables  []*TableChanges
+	}
+
+	// TableChanges holds the changes of a table, such as column, index and constraint changes.
+	TableChanges struct {
+		T       *Table
+		Changes []Change
+	}
+)
+
+// GroupChanges groups the given changes by the objects they apply to. Schemas, tables
+// and changes keep the order in which they first appear in the given changes, and the
+// changes of multiple ModifyTable changes of the same table are merged into one group.
+func GroupChanges(changes []Change) *RealmChanges {
+	var (
+		r       = &RealmChanges{}
+		schemas = make(map[string]*SchemaChanges)
+		tables  = make(map[*SchemaChanges]map[string]*TableChanges)
+	)
+	group := func(s *Schema) *SchemaChanges {
+		var name string
+		if s != nil {
+			name = s.Name
+		}
+		g, ok := schemas[name]
+		if !ok {
+			g = &SchemaChanges{Name: name}
+			schemas[name] = g
+			tables[g] = make(map[string]*TableChanges)
+			r.Schemas = append(r.Schemas, g)
+		}
+		return g
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *AddSchema:
+			g := group(c.S)
+			g.Changes = append(g.Changes, c)
+		case *DropSchema:
+			g := group(c.S)
+			g.Changes = append(g.Changes, c)
+		case *ModifySchema:
+			g := group(c.S)
+			g.Changes = append(g.Changes, c)
+		case *AddTable:
+			g := group(c.T.Schema)
+			g.Changes = append(g.Changes, c)
+		case *DropTable:
+			g := group(c.T.Schema)
+			g.Changes = append(g.Changes, c)
+		case *RenameTable:
+			g := group(c.From.Schema)
+			g.Changes = append(g.Changes, c)
+		case *MoveTable:
+			g := group(c.From.Schema)
+			g.Changes = append(g.Changes, c)
+		case *ModifyTable:
+			g := group(c.T.Schema)
+			t, ok := tables[g][c.T.Name]
+			if !ok {
+				t = &TableChanges{T: c.T}
+				tables[g][c.T.Name] = t
+				g.Tables = append(g.Tables, t)
+			}
+			t.Changes = append(t.Changes, c.Changes...)
+		default:
+			r.Changes = append(r.Changes, c)
+		}
+	}
+	return r
+}
+
+// AdditiveOnly splits the given changes into the changes that only add objects
+// (e.g. tables, columns or indexes) and the ones that drop, modify or rename
+// them. Table and schema modifications are split by their nested changes, and
+// changes of types that are not defined by this package are skipped.
+//
+// An error is returned in case an added object depends on a skipped change.
+// For example, a foreign key that references a column whose type is modified.
+func AdditiveOnly(changes []Change) (added, skipped []Change, err error) {
+	// Columns and tables of the desired state
+	// that are changed by the skipped changes.
+	var (
+		columns = make(map[*Column]Change)
+		tables  = make(map[*Table]Change)
+	)
+	added, skipped = additiveOnly(changes, columns, tables)
+	for _, c := range added {
+		if err := checkAdditive(c, columns, tables); err != nil {
+			return nil, nil, err
+		}
+	}
+	return added, skipped, nil
+}
+
+func additiveOnly(changes []Change, columns map[*Column]Change, tables map[*Table]Change) (added, skipped []Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *AddSchema, *AddObject, *AddTable, *AddColumn, *AddIndex, *AddForeignKey, *AddCheck, *AddAttr:
+			added = append(added, c)
+		case *ModifySchema:
+			a, s := additiveOnly(c.Changes, columns, tables)
+			if len(a) > 0 {
+				added = append(added, &ModifySchema{S: c.S, Changes: a})
+			}
+			if len(s) > 0 {
+				skipped = append(skipped, &ModifySchema{S: c.S, Changes: s})
+			}
+		case *ModifyTable:
+			a, s := additiveOnly(c.Changes, columns, tables)
+			if len(a) > 0 {
+				added = append(added, &ModifyTable{T: c.T, Changes: a})
+			}
+			if len(s) > 0 {
+				skipped = append(skipped, &ModifyTable{T: c.T, Changes: s})
+			}
+		case *ModifyColumn:
+			columns[c.To] = c
+			skipped = append(skipped, c)
+		case *RenameColumn:
+			columns[c.To] = c
+			skipped = append(skipped, c)
+		case *RenameTable:
+			tables[c.To] = c
+			skipped = append(skipped, c)
+		case *MoveTable:
+			tables[c.To] = c
+			skipped = append(skipped, c)
+		default:
+			skipped = append(skipped, c)
+		}
+	}
+	return added, skipped
+}
+
+// checkAdditive checks that the added change does not
+// depend on the given columns and tables.
+func checkAdditive(c Change, columns map[*Column]Change, tables map[*Table]Change) error {
+	var (
+		indexes []*Index
+		fks     []*ForeignKey
+	)
+	switch c := c.(type) {
+	case *ModifySchema:
+		for _, c := range c.Changes {
+			if err := checkAdditive(c, columns, tables); err != nil {
+				return err
+			}
+		}
+	case *ModifyTable:
+		for _, c := range c.Changes {
+			if err := checkAdditive(c, columns, tables); err != nil {
+				return err
+			}
+		}
+	case *AddTable:
+		indexes, fks = c.T.Indexes, c.T.ForeignKeys
+	case *AddIndex:
+		indexes = append(indexes, c.I)
+	case *AddForeignKey:
+		fks = append(fks, c.F)
+	}
+	for _, idx := range indexes {
+		for _, p := range idx.Parts {
+			if p.C == nil {
+				continue
+			}
+			if _, ok := columns[p.C]; ok {
+				return fmt.Errorf("sql/schema: index %q depends on skipped change of column %q", idx.Name, p.C.Name)
+			}
+		}
+	}
+	for _, fk := range fks {
+		if _, ok := tables[fk.RefTable]; ok {
+			return fmt.Errorf("sql/schema: foreign key %q depends on skipped change of table %q", fk.Symbol, fk.RefTable.Name)
+		}
+		for _, c := range append(fk.Columns[:len(fk.Columns):len(fk.Columns)], fk.RefColumns...) {
+			if _, ok := columns[c]; ok {
+				return fmt.Errorf("sql/schema: foreign key %q depends on skipped change of column %q", fk.Symbol, c.Name)
+			}
+		}
+	}
+	return nil
+}
+
 // search returns the index of the first call to f that returns true, or -1.
 func (c Changes) search(f func(Change) bool) int {
 	for i := range c {
@@ -374,10 +949,15 @@ func (*ModifyAttr) change()       {}
 func (*AddSchema) change()        {}
 func (*DropSchema) change()       {}
 func (*ModifySchema) change()     {}
+func (*AddObject) change()        {}
+func (*DropObject) change()       {}
+func (*ModifyObject) change()     {}
 func (*AddTable) change()         {}
 func (*DropTable) change()        {}
 func (*ModifyTable) change()      {}
 func (*RenameTable) change()      {}
+func (*MoveTable) change()        {}
+func (*MoveObject) change()       {}
 func (*AddIndex) change()         {}
 func (*DropIndex) change()        {}
 func (*ModifyIndex) change()      {}
@@ -385,6 +965,7 @@ func (*RenameIndex) change()      {}
 func (*AddCheck) change()         {}
 func (*DropCheck) change()        {}
 func (*ModifyCheck) change()      {}
+func (*RenameCheck) change()      {}
 func (*AddColumn) change()        {}
 func (*DropColumn) change()       {}
 func (*ModifyColumn) change()     {}
@@ -396,3 +977,4 @@ func (*ModifyForeignKey) change() {}
 // clauses.
 func (*IfExists) clause()    {}
 func (*IfNotExists) clause() {}
+func (*AttrStmt) clause()    {}
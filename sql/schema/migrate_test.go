@@ -91,6 +91,114 @@ func TestChanges_RemoveIndex(t *testing.T) {
 	require.Equal(t, "2", changes[0].(*schema.AddColumn).C.Name)
 }
 
+func TestDestructiveChanges(t *testing.T) {
+	users := schema.NewTable("users")
+	changes := []schema.Change{
+		&schema.AddTable{T: schema.NewTable("posts")},
+		&schema.DropTable{T: schema.NewTable("pets")},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewColumn("age")},
+				&schema.DropColumn{C: schema.NewColumn("name")},
+				&schema.DropIndex{I: schema.NewIndex("name_idx")},
+			},
+		},
+		&schema.ModifyTable{
+			T:       schema.NewTable("tags"),
+			Changes: []schema.Change{&schema.DropIndex{I: schema.NewIndex("tag_idx")}},
+		},
+		&schema.DropSchema{S: schema.New("archive")},
+	}
+	require.Equal(t, []schema.Change{
+		changes[1],
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: schema.NewColumn("name")}}},
+		changes[4],
+	}, schema.DestructiveChanges(changes))
+	require.Empty(t, schema.DestructiveChanges(changes[:1]))
+}
+
+func TestGroupChanges(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		posts  = schema.NewTable("posts").SetSchema(public)
+		logs   = schema.NewTable("logs").SetSchema(schema.New("audit"))
+		object = &schema.AddObject{}
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewColumn("age")}}},
+		&schema.AddTable{T: posts},
+		&schema.AddSchema{S: logs.Schema},
+		&schema.AddTable{T: logs},
+		object,
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropIndex{I: schema.NewIndex("name_idx")}}},
+	}
+	r := schema.GroupChanges(changes)
+	require.Equal(t, []schema.Change{object}, r.Changes)
+	require.Len(t, r.Schemas, 2)
+	require.Equal(t, "public", r.Schemas[0].Name)
+	require.Equal(t, []schema.Change{changes[1]}, r.Schemas[0].Changes)
+	require.Equal(t, []*schema.TableChanges{
+		{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewColumn("age")},
+				&schema.DropIndex{I: schema.NewIndex("name_idx")},
+			},
+		},
+	}, r.Schemas[0].Tables)
+	require.Equal(t, "audit", r.Schemas[1].Name)
+	require.Equal(t, []schema.Change{changes[2], changes[3]}, r.Schemas[1].Changes)
+	require.Empty(t, r.Schemas[1].Tables)
+}
+
+func TestAdditiveOnly(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		name  = schema.NewStringColumn("name", "text")
+		users = schema.NewTable("users").AddColumns(id, name)
+		posts = schema.NewTable("posts").AddColumns(schema.NewIntColumn("author_id", "int"))
+		idx   = schema.NewIndex("name_idx").AddColumns(name)
+	)
+	changes := []schema.Change{
+		&schema.DropTable{T: schema.NewTable("logs")},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddColumn{C: name},
+			&schema.DropColumn{C: schema.NewColumn("age")},
+			&schema.ModifyColumn{From: schema.NewIntColumn("id", "smallint"), To: id, Change: schema.ChangeType},
+		}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropIndex{I: schema.NewIndex("age_idx")}}},
+		&schema.AddTable{T: posts},
+	}
+	added, skipped, err := schema.AdditiveOnly(changes)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+		changes[3],
+	}, added)
+	require.Equal(t, []schema.Change{
+		changes[0],
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.DropColumn{C: schema.NewColumn("age")},
+			&schema.ModifyColumn{From: schema.NewIntColumn("id", "smallint"), To: id, Change: schema.ChangeType},
+		}},
+		changes[2],
+	}, skipped)
+
+	// Added objects that depend on skipped changes.
+	posts.AddForeignKeys(schema.NewForeignKey("author_id").AddColumns(posts.Columns[0]).SetRefTable(users).AddRefColumns(id))
+	_, _, err = schema.AdditiveOnly(changes)
+	require.EqualError(t, err, `sql/schema: foreign key "author_id" depends on skipped change of column "id"`)
+	_, _, err = schema.AdditiveOnly([]schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.ModifyColumn{From: schema.NewStringColumn("name", "varchar"), To: name, Change: schema.ChangeType},
+			&schema.AddIndex{I: idx},
+		}},
+	})
+	require.EqualError(t, err, `sql/schema: index "name_idx" depends on skipped change of column "name"`)
+}
+
 func ExampleChanges_Replace() {
 	changes := schema.Changes{
 		&schema.AddIndex{I: schema.NewIndex("id")},
@@ -124,3 +232,40 @@ func ExampleChanges_Replace() {
 	// *schema.AddColumn(created_at)
 	// *schema.RenameColumn(old_name -> new_name)
 }
+
+func TestDescribe(t *testing.T) {
+	users := schema.NewTable("users").
+		SetSchema(schema.New("public")).
+		AddColumns(schema.NewStringColumn("email", "text"), schema.NewNullIntColumn("age", "int"))
+	for _, tt := range []struct {
+		change schema.Change
+		want   []string
+	}{
+		{change: &schema.AddTable{T: users}, want: []string{`add table "users"`}},
+		{change: &schema.DropSchema{S: users.Schema}, want: []string{`drop schema "public"`}},
+		{change: &schema.MoveTable{From: users, To: schema.NewTable("users").SetSchema(schema.New("app"))}, want: []string{`move table "users" from schema "public" to "app"`}},
+		{
+			change: &schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.ModifyColumn{From: users.Columns[0], To: users.Columns[0], Change: schema.ChangeNull | schema.ChangeDefault | schema.ChangeType},
+					&schema.ModifyColumn{From: users.Columns[1], To: users.Columns[1], Change: schema.ChangeNull},
+					&schema.AddIndex{I: schema.NewIndex("users_email")},
+					&schema.ModifyForeignKey{To: schema.NewForeignKey("owner"), Change: schema.ChangeDeleteAction},
+					&schema.AddCheck{C: schema.NewCheck().SetExpr("age > 0")},
+					&schema.AddAttr{A: &schema.Comment{Text: "users"}},
+				},
+			},
+			want: []string{
+				`modify "users".column "email": set not null, drop default, change type`,
+				`modify "users".column "age": drop not null`,
+				`modify "users": add index "users_email"`,
+				`modify "users".foreign key "owner": change on delete action`,
+				`modify "users": add check (age > 0)`,
+				`modify "users": add comment`,
+			},
+		},
+	} {
+		require.Equal(t, tt.want, schema.Describe(tt.change))
+	}
+}
@@ -10,14 +10,16 @@ type (
 	Realm struct {
 		Schemas []*Schema
 		Attrs   []Attr
+		Objects []Object // Driver specific objects (e.g. event triggers).
 	}
 
 	// A Schema describes a database schema (i.e. named database).
 	Schema struct {
-		Name   string
-		Realm  *Realm
-		Tables []*Table
-		Attrs  []Attr // Attrs and options.
+		Name    string
+		Realm   *Realm
+		Tables  []*Table
+		Objects []Object // Driver specific objects (e.g. collations).
+		Attrs   []Attr   // Attrs and options.
 	}
 
 	// A Table represents a table definition.
@@ -82,6 +84,7 @@ type (
 		RefColumns []*Column
 		OnUpdate   ReferenceOption
 		OnDelete   ReferenceOption
+		Attrs      []Attr
 	}
 )
 
@@ -278,6 +281,19 @@ type (
 		attr()
 	}
 
+	// Object represents the interface that all schema objects implement,
+	// other than tables. For example, collations or sequences. Drivers
+	// can implement this interface by embedding it in their types:
+	//
+	//	type Collation struct {
+	//		schema.Object
+	//		Name string
+	//	}
+	//
+	Object interface {
+		obj()
+	}
+
 	// Comment describes a schema element comment.
 	Comment struct {
 		Text string
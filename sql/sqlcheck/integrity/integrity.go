@@ -0,0 +1,351 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package integrity provides an analyzer that replays the changes of migration
+// files on an in-memory schema.Realm in order to detect conflicting or impossible
+// changes, such as two files creating the same table, or a file dropping a column
+// that was already dropped by a previous file.
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+// Analyzer checks that the changes of migration files are consistent with the
+// schema built by the files that precede them. Unlike most analyzers, it keeps
+// state between passes and expects to be called with the files in their order.
+type Analyzer struct {
+	sqlcheck.Options
+	// Schema is the default schema that tables without a schema qualifier belong
+	// to (e.g. "public"). If empty, it is resolved to the first schema that is used
+	// by the changes of the files, without being created by them.
+	Schema string
+	realm  *schema.Realm
+	// Schemas that were created by the files.
+	created map[string]bool
+}
+
+// New creates a new integrity Analyzer with the given options.
+func New(r *schemahcl.Resource) (*Analyzer, error) {
+	az := newAnalyzer()
+	az.Error = sqlx.P(true)
+	if r, ok := r.Resource(az.Name()); ok {
+		if err := r.As(&az.Options); err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing integrity check options: %w", err)
+		}
+		if a, ok := r.Attr("schema"); ok {
+			s, err := a.String()
+			if err != nil {
+				return nil, fmt.Errorf("sql/sqlcheck: parsing integrity check schema: %w", err)
+			}
+			az.Schema = s
+		}
+	}
+	return az, nil
+}
+
+func newAnalyzer() *Analyzer {
+	return &Analyzer{realm: schema.NewRealm(), created: make(map[string]bool)}
+}
+
+// List of codes.
+var (
+	codeExists  = sqlcheck.Code("IN101")
+	codeMissing = sqlcheck.Code("IN102")
+)
+
+// Name of the analyzer. Implements the sqlcheck.NamedAnalyzer interface.
+func (*Analyzer) Name() string {
+	return "integrity"
+}
+
+// Realm returns the schema built by the files analyzed so far.
+func (a *Analyzer) Realm() *schema.Realm {
+	return a.realm
+}
+
+const reportText = "conflicting or impossible changes detected"
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		var pos int
+		if sc.Stmt != nil {
+			pos = sc.Stmt.Pos
+		}
+		for _, c := range sc.Changes {
+			for _, d := range a.apply(c) {
+				d.Pos = pos
+				diags = append(diags, d)
+			}
+		}
+	}
+	if len(diags) > 0 {
+		p.Reporter.WriteReport(sqlcheck.Report{Text: reportText, Diagnostics: diags})
+		if sqlx.V(a.Error) {
+			return errors.New(reportText)
+		}
+	}
+	return nil
+}
+
+// FileReport holds the report of a migration file.
+type FileReport struct {
+	File   migrate.File
+	Report sqlcheck.Report
+}
+
+// VerifyDir loads the files of the given migration directory, parses each one of them
+// using the given function, and replays their changes on an empty realm. The returned
+// reports describe the files that contain conflicting or impossible changes.
+func VerifyDir(ctx context.Context, dir migrate.Dir, parse func(migrate.File) ([]*sqlcheck.Change, error)) ([]*FileReport, error) {
+	files, err := dir.Files()
+	if err != nil {
+		return nil, err
+	}
+	az := newAnalyzer()
+	az.Error = sqlx.P(false)
+	var reports []*FileReport
+	for _, f := range files {
+		changes, err := parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("sql/sqlcheck: parsing file %q: %w", f.Name(), err)
+		}
+		if err := az.Analyze(ctx, &sqlcheck.Pass{
+			File: &sqlcheck.File{File: f, Changes: changes},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				reports = append(reports, &FileReport{File: f, Report: r})
+			}),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
+// apply applies the change on the realm, and returns the
+// diagnostics for changes that cannot be applied on it.
+func (a *Analyzer) apply(c schema.Change) []sqlcheck.Diagnostic {
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		if _, ok := a.realm.Schema(c.S.Name); ok {
+			if !hasClause[*schema.IfNotExists](c.Extra) {
+				return exists("Schema %q was already created", c.S.Name)
+			}
+			return nil
+		}
+		a.realm.AddSchemas(schema.New(c.S.Name))
+		a.created[c.S.Name] = true
+	case *schema.DropSchema:
+		if !dropSchema(a.realm, c.S.Name) && !hasClause[*schema.IfExists](c.Extra) {
+			return missing("Dropping schema %q that does not exist", c.S.Name)
+		}
+	case *schema.AddTable:
+		s := a.schema(c.T.Schema)
+		if _, ok := s.Table(c.T.Name); ok {
+			if !hasClause[*schema.IfNotExists](c.Extra) {
+				return exists("Table %q was already created", c.T.Name)
+			}
+			return nil
+		}
+		t := schema.NewTable(c.T.Name)
+		for _, c := range c.T.Columns {
+			t.AddColumns(schema.NewColumn(c.Name))
+		}
+		for _, idx := range c.T.Indexes {
+			t.AddIndexes(schema.NewIndex(idx.Name))
+		}
+		for _, fk := range c.T.ForeignKeys {
+			t.AddForeignKeys(schema.NewForeignKey(fk.Symbol))
+		}
+		s.AddTables(t)
+	case *schema.DropTable:
+		if !dropTable(a.schema(c.T.Schema), c.T.Name) && !hasClause[*schema.IfExists](c.Extra) {
+			return missing("Dropping table %q that does not exist", c.T.Name)
+		}
+	case *schema.RenameTable:
+		s := a.schema(c.From.Schema)
+		t, ok := s.Table(c.From.Name)
+		if !ok {
+			return missing("Renaming table %q that does not exist", c.From.Name)
+		}
+		if _, ok := s.Table(c.To.Name); ok {
+			return exists("Renaming table %q to %q that already exists", c.From.Name, c.To.Name)
+		}
+		t.Name = c.To.Name
+	case *schema.ModifyTable:
+		t, ok := a.schema(c.T.Schema).Table(c.T.Name)
+		if !ok {
+			return missing("Modifying table %q that does not exist", c.T.Name)
+		}
+		var diags []sqlcheck.Diagnostic
+		for _, c1 := range c.Changes {
+			diags = append(diags, modifyTable(t, c1)...)
+		}
+		return diags
+	}
+	return nil
+}
+
+// modifyTable applies the table change on t.
+func modifyTable(t *schema.Table, c schema.Change) []sqlcheck.Diagnostic {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		if _, ok := t.Column(c.C.Name); ok {
+			if !hasClause[*schema.IfNotExists](c.Extra) {
+				return exists("Column %q was already added to table %q", c.C.Name, t.Name)
+			}
+			return nil
+		}
+		t.AddColumns(schema.NewColumn(c.C.Name))
+	case *schema.DropColumn:
+		i := columnIndex(t, c.C.Name)
+		if i == -1 {
+			if !hasClause[*schema.IfExists](c.Extra) {
+				return missing("Dropping column %q that does not exist in table %q", c.C.Name, t.Name)
+			}
+			return nil
+		}
+		t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+	case *schema.ModifyColumn:
+		if _, ok := t.Column(c.From.Name); !ok {
+			return missing("Modifying column %q that does not exist in table %q", c.From.Name, t.Name)
+		}
+	case *schema.RenameColumn:
+		col, ok := t.Column(c.From.Name)
+		if !ok {
+			return missing("Renaming column %q that does not exist in table %q", c.From.Name, t.Name)
+		}
+		if _, ok := t.Column(c.To.Name); ok {
+			return exists("Renaming column %q to %q that already exists in table %q", c.From.Name, c.To.Name, t.Name)
+		}
+		col.Name = c.To.Name
+	case *schema.AddIndex:
+		if _, ok := t.Index(c.I.Name); ok && c.I.Name != "" {
+			return exists("Index %q was already added to table %q", c.I.Name, t.Name)
+		}
+		t.AddIndexes(schema.NewIndex(c.I.Name))
+	case *schema.DropIndex:
+		i := indexIndex(t, c.I.Name)
+		if i == -1 {
+			return missing("Dropping index %q that does not exist in table %q", c.I.Name, t.Name)
+		}
+		t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+	case *schema.RenameIndex:
+		idx, ok := t.Index(c.From.Name)
+		if !ok {
+			return missing("Renaming index %q that does not exist in table %q", c.From.Name, t.Name)
+		}
+		if _, ok := t.Index(c.To.Name); ok {
+			return exists("Renaming index %q to %q that already exists in table %q", c.From.Name, c.To.Name, t.Name)
+		}
+		idx.Name = c.To.Name
+	case *schema.AddForeignKey:
+		if _, ok := t.ForeignKey(c.F.Symbol); ok && c.F.Symbol != "" {
+			return exists("Foreign-key constraint %q was already added to table %q", c.F.Symbol, t.Name)
+		}
+		t.AddForeignKeys(schema.NewForeignKey(c.F.Symbol))
+	case *schema.DropForeignKey:
+		for i, fk := range t.ForeignKeys {
+			if fk.Symbol == c.F.Symbol {
+				t.ForeignKeys = append(t.ForeignKeys[:i], t.ForeignKeys[i+1:]...)
+				return nil
+			}
+		}
+		return missing("Dropping foreign-key constraint %q that does not exist in table %q", c.F.Symbol, t.Name)
+	}
+	return nil
+}
+
+// schema returns the realm schema of the given one. Schemas that were not
+// created explicitly (e.g. the default schema) are added on first use, and
+// tables without a schema qualifier are resolved to the default schema.
+func (a *Analyzer) schema(s *schema.Schema) *schema.Schema {
+	var name string
+	if s != nil {
+		name = s.Name
+	}
+	switch {
+	case name == "":
+		name = a.Schema
+	// The first schema that is used without being created by the files
+	// is the default one, and owns the tables that were added without
+	// a qualifier until now.
+	case a.Schema == "" && !a.created[name]:
+		a.Schema = name
+		if s, ok := a.realm.Schema(""); ok {
+			s.Name = name
+		}
+	}
+	if s, ok := a.realm.Schema(name); ok {
+		return s
+	}
+	s = schema.New(name)
+	a.realm.AddSchemas(s)
+	return s
+}
+
+func dropSchema(r *schema.Realm, name string) bool {
+	for i, s := range r.Schemas {
+		if s.Name == name {
+			r.Schemas = append(r.Schemas[:i], r.Schemas[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func dropTable(s *schema.Schema, name string) bool {
+	for i, t := range s.Tables {
+		if t.Name == name {
+			s.Tables = append(s.Tables[:i], s.Tables[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func columnIndex(t *schema.Table, name string) int {
+	for i, c := range t.Columns {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexIndex(t *schema.Table, name string) int {
+	for i, idx := range t.Indexes {
+		if idx.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasClause[T schema.Clause](clauses []schema.Clause) bool {
+	for _, c := range clauses {
+		if _, ok := c.(T); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func exists(format string, args ...any) []sqlcheck.Diagnostic {
+	return []sqlcheck.Diagnostic{{Code: codeExists, Text: fmt.Sprintf(format, args...)}}
+}
+
+func missing(format string, args ...any) []sqlcheck.Diagnostic {
+	return []sqlcheck.Diagnostic{{Code: codeMissing, Text: fmt.Sprintf(format, args...)}}
+}
@@ -0,0 +1,138 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package integrity_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/integrity"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_DropColumnTwice(t *testing.T) {
+	var (
+		reports []sqlcheck.Report
+		users   = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+		drop = &sqlcheck.Change{
+			Stmt: &migrate.Stmt{Pos: 10, Text: `ALTER TABLE "users" DROP COLUMN "name"`},
+			Changes: schema.Changes{
+				&schema.ModifyTable{T: users, Changes: schema.Changes{&schema.DropColumn{C: users.Columns[1]}}},
+			},
+		}
+		files = [][]*sqlcheck.Change{
+			{{Stmt: &migrate.Stmt{Text: `CREATE TABLE "users"`}, Changes: schema.Changes{&schema.AddTable{T: users}}}},
+			{drop},
+			{drop},
+		}
+	)
+	az, err := integrity.New(&schemahcl.Resource{})
+	require.NoError(t, err)
+	for i, changes := range files {
+		err = az.Analyze(context.Background(), &sqlcheck.Pass{
+			File: &sqlcheck.File{File: testFile{name: "file.sql"}, Changes: changes},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				reports = append(reports, r)
+			}),
+		})
+		if i < 2 {
+			require.NoError(t, err)
+		}
+	}
+	require.EqualError(t, err, "conflicting or impossible changes detected")
+	require.Len(t, reports, 1)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Pos: 10, Code: "IN102", Text: `Dropping column "name" that does not exist in table "users"`},
+	}, reports[0].Diagnostics)
+	tt, ok := az.Realm().Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, tt.Columns, 1)
+}
+
+func TestVerifyDir(t *testing.T) {
+	dir, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_users.sql", []byte(`CREATE TABLE users (id int);`)))
+	require.NoError(t, dir.WriteFile("2_users.sql", []byte(`CREATE TABLE users (id int);`)))
+	require.NoError(t, dir.WriteFile("3_users.sql", []byte(`CREATE TABLE IF NOT EXISTS users (id int);`)))
+	parse := func(f migrate.File) ([]*sqlcheck.Change, error) {
+		c := &schema.AddTable{T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))}
+		if f.Name() == "3_users.sql" {
+			c.Extra = append(c.Extra, &schema.IfNotExists{})
+		}
+		return []*sqlcheck.Change{{Changes: schema.Changes{c}}}, nil
+	}
+	reports, err := integrity.VerifyDir(context.Background(), dir, parse)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, "2_users.sql", reports[0].File.Name())
+	require.Equal(t, `Table "users" was already created`, reports[0].Report.Diagnostics[0].Text)
+}
+
+type testFile struct {
+	name string
+	migrate.File
+}
+
+func (t testFile) Name() string {
+	return t.name
+}
+
+func TestAnalyzer_Clauses(t *testing.T) {
+	var (
+		reports []sqlcheck.Report
+		users   = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+		public = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(schema.NewIntColumn("id", "int"))
+		files = [][]*sqlcheck.Change{
+			{{Changes: schema.Changes{&schema.AddTable{T: users}}}},
+			// The same table, qualified with the default schema.
+			{{Changes: schema.Changes{
+				&schema.ModifyTable{T: public, Changes: schema.Changes{
+					&schema.AddColumn{C: users.Columns[1], Extra: []schema.Clause{&schema.IfNotExists{}}},
+					&schema.DropColumn{C: users.Columns[1]},
+					&schema.DropColumn{C: users.Columns[1], Extra: []schema.Clause{&schema.IfExists{}}},
+				}},
+			}}},
+			{{Changes: schema.Changes{
+				&schema.ModifyTable{T: users, Changes: schema.Changes{&schema.DropColumn{C: users.Columns[1]}}},
+			}}},
+		}
+	)
+	az, err := integrity.New(&schemahcl.Resource{})
+	require.NoError(t, err)
+	for _, changes := range files {
+		err = az.Analyze(context.Background(), &sqlcheck.Pass{
+			File: &sqlcheck.File{File: testFile{name: "file.sql"}, Changes: changes},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				reports = append(reports, r)
+			}),
+		})
+	}
+	require.EqualError(t, err, "conflicting or impossible changes detected")
+	require.Len(t, reports, 1)
+	require.Equal(t, []sqlcheck.Diagnostic{
+		{Code: "IN102", Text: `Dropping column "name" that does not exist in table "users"`},
+	}, reports[0].Diagnostics)
+	require.Len(t, az.Realm().Schemas, 1)
+	require.Equal(t, "public", az.Realm().Schemas[0].Name)
+
+	az, err = integrity.New(&schemahcl.Resource{
+		Children: []*schemahcl.Resource{
+			{Type: "integrity", Attrs: []*schemahcl.Attr{schemahcl.StringAttr("schema", "app")}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "app", az.Schema)
+}
@@ -124,6 +124,7 @@ func (*diff) IndexPartAttrChanged(_, _ *schema.Index, _ int) bool {
 
 // ReferenceChanged reports if the foreign key referential action was changed.
 func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	from, to = sqlx.NormalizeReference(from), sqlx.NormalizeReference(to)
 	// According to SQLite, if an action is not explicitly
 	// specified, it defaults to "NO ACTION".
 	if from == "" {
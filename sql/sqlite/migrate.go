@@ -163,6 +163,35 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 // addition, the changes are applied using a temporary table following the procedure mentioned
 // in: https://www.sqlite.org/lang_altertable.html#making_other_kinds_of_table_schema_changes.
 func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) error {
+	// Custom attribute changes are applied by their statements, and changes
+	// without statements are skipped (reported only), as they do not require
+	// rebuilding the table.
+	var custom []*migrate.Change
+	changes := make([]schema.Change, 0, len(modify.Changes))
+	for _, c := range modify.Changes {
+		stmt, ok := sqlx.AttrStmt(c)
+		switch {
+		case !ok:
+			changes = append(changes, c)
+		case stmt != "":
+			custom = append(custom, &migrate.Change{
+				Cmd:     stmt,
+				Source:  modify,
+				Comment: fmt.Sprintf("modify %q table attribute", modify.T.Name),
+			})
+		}
+	}
+	defer func() {
+		for _, c := range custom {
+			s.append(c)
+		}
+	}()
+	if len(changes) == 0 {
+		return nil
+	}
+	if len(changes) < len(modify.Changes) {
+		modify = &schema.ModifyTable{T: modify.T, Changes: changes}
+	}
 	if alterable(modify) {
 		return s.alterTable(modify)
 	}
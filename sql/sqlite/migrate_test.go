@@ -25,6 +25,22 @@ func TestPlanChanges(t *testing.T) {
 		mock    func(mock)
 		plan    *migrate.Plan
 	}{
+		// Custom attribute changes do not rebuild the table.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer")),
+					Changes: []schema.Change{
+						&schema.AddAttr{A: &schema.Comment{Text: "a"}, Extra: []schema.Clause{&schema.AttrStmt{}}},
+						&schema.ModifyAttr{From: &schema.Comment{Text: "a"}, To: &schema.Comment{Text: "b"}, Extra: []schema.Clause{&schema.AttrStmt{Cmd: "SELECT audit_table('users')"}}},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: "SELECT audit_table('users')"}},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.AddTable{